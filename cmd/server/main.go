@@ -7,55 +7,234 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/VechkanovVV/assigner-pr/internal/api/handlers"
 	"github.com/VechkanovVV/assigner-pr/internal/api/router"
+	"github.com/VechkanovVV/assigner-pr/internal/audit"
+	"github.com/VechkanovVV/assigner-pr/internal/codehost"
 	"github.com/VechkanovVV/assigner-pr/internal/config"
+	"github.com/VechkanovVV/assigner-pr/internal/egress"
+	"github.com/VechkanovVV/assigner-pr/internal/idempotency"
 	"github.com/VechkanovVV/assigner-pr/internal/infra/postgres"
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+	"github.com/VechkanovVV/assigner-pr/internal/notify"
+	"github.com/VechkanovVV/assigner-pr/internal/oidc"
+	"github.com/VechkanovVV/assigner-pr/internal/ratelimit"
 	"github.com/VechkanovVV/assigner-pr/internal/service"
-	postgresRepo "github.com/VechkanovVV/assigner-pr/internal/storage/postgres"
+	"github.com/VechkanovVV/assigner-pr/internal/sla"
+	"github.com/VechkanovVV/assigner-pr/internal/webhook"
 )
 
 func main() {
 	ctx := context.Background()
 
-	dbCfg := config.LoadDB()
-	log.Printf("starting server with DB config: host=%s port=%d dbname=%s sslmode=%s",
-		dbCfg.Host, dbCfg.Port, dbCfg.Name, dbCfg.SSLmode)
-
-	pool, err := postgres.NewPool(
-		ctx,
-		dbCfg.Port,
-		dbCfg.Host,
-		dbCfg.User,
-		dbCfg.Password,
-		dbCfg.Name,
-		string(dbCfg.SSLmode),
-	)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(ctx, os.Args[2:]))
+	}
+
+	repos, err := openStorage(ctx, config.LoadStorage())
 	if err != nil {
-		log.Fatalf("failed to create DB pool: %v", err)
+		log.Fatalf("failed to open storage: %v", err)
 	}
-	log.Println("database connection pool created successfully")
 
-	teamRepo := postgresRepo.NewTeamRepository(pool)
-	userRepo := postgresRepo.NewUserRepository(pool)
-	prRepo := postgresRepo.NewPullRequestRepository(pool)
+	registry := metrics.NewRegistry()
+	if repos.pool != nil {
+		postgres.RegisterPoolMetrics(registry, repos.pool)
+	}
 
-	teamService := service.NewTeamService(teamRepo)
-	userService := service.NewUserService(userRepo, prRepo)
-	prService := service.NewPRService(userRepo, prRepo)
+	policy := service.NewPolicy(repos.users)
+	teamService := service.NewTeamService(repos.tx, repos.teams, repos.users, repos.audit, policy)
+	userService := service.NewUserService(repos.tx, repos.users, repos.teams, repos.prs, repos.outbox, repos.audit, policy)
+	assignCfg := config.LoadAssign()
+	var prOpts []service.PRServiceOption
+	if assignCfg.Seed != nil {
+		log.Printf("reviewer selection uses seeded PRNG (seed=%d)", *assignCfg.Seed)
+		prOpts = append(prOpts, service.WithRand(service.NewSeededRand(*assignCfg.Seed)))
+	}
+	if assignCfg.SeedPerPR {
+		log.Println("reviewer selection is seeded per PR")
+		prOpts = append(prOpts, service.WithSeedPerPR())
+	}
+	codeHostCfg := config.LoadCodeHost()
+	codeHostClients := newCodeHostClients(codeHostCfg)
+	if len(codeHostClients) > 0 {
+		providers := make([]string, 0, len(codeHostClients))
+		for _, c := range codeHostClients {
+			providers = append(providers, c.Provider())
+		}
+		log.Printf("assigned reviewers are pushed to code hosts: %v", providers)
+		prOpts = append(prOpts, service.WithReviewerSync(repos.syncs, providers...))
+	}
+	notifyCfg := config.LoadNotify()
+	templates, err := notify.LoadTemplates(notifyCfg.TemplatesDir)
+	if err != nil {
+		log.Fatalf("failed to load notification templates: %v", err)
+	}
+	prOpts = append(prOpts, service.WithChatNotifications(repos.notify, templates))
+	egressCfg := config.LoadEgress()
+	if egressCfg.AllowPrivate {
+		log.Println("warning: EGRESS_ALLOW_PRIVATE=true, webhooks and team chats may target internal addresses")
+	}
+	egressClient := egress.NewClient(egress.Config{AllowPrivate: egressCfg.AllowPrivate})
+	smtpCfg := config.LoadSMTP()
+	senders := []notify.Sender{notify.NewChatSender(egressClient)}
+	if smtpCfg.Host != "" {
+		log.Printf("email notifications are sent via %s:%d", smtpCfg.Host, smtpCfg.Port)
+		prOpts = append(prOpts, service.WithEmailNotifications(repos.notify, templates))
+		senders = append(senders, notify.NewEmailSender(notify.SMTPConfig{
+			Host:     smtpCfg.Host,
+			Port:     smtpCfg.Port,
+			Username: smtpCfg.Username,
+			Password: smtpCfg.Password,
+			From:     smtpCfg.From,
+			Security: notify.SMTPSecurity(smtpCfg.Security),
+		}))
+	}
+	prOpts = append(prOpts, service.WithMetrics(registry, repos.teams))
+	prService := service.NewPRService(repos.tx, repos.users, repos.prs, repos.logs, repos.events, repos.outbox, prOpts...)
+	webhookService := service.NewWebhookService(repos.webhooks, repos.outbox)
+	notificationService := service.NewNotificationService(repos.tx, repos.teams, repos.users, repos.notify, repos.audit, policy)
+	integrationService := service.NewIntegrationService(repos.tx, repos.users, repos.prs, repos.integrations, repos.audit, prService)
+	slaService := service.NewSLAService(repos.tx, repos.teams, repos.users, repos.prs, repos.slas, repos.audit, policy)
+	tokenService := service.NewTokenService(repos.tokens)
+	orgService := service.NewOrgService(repos.orgs)
+	auditService := service.NewAuditService(repos.audit)
+
+	authCfg := config.LoadAuth()
+	if authCfg.BootstrapToken != "" {
+		if err := tokenService.Bootstrap(ctx, "bootstrap", authCfg.BootstrapToken); err != nil {
+			repos.close()
+			log.Fatalf("failed to register bootstrap token: %v", err)
+		}
+	}
+	if !authCfg.Enabled {
+		log.Println("warning: AUTH_ENABLED=false, API is open to anyone who can reach it")
+	}
 
 	teamHandler := handlers.NewTeamHandler(teamService)
 	userHandler := handlers.NewUserHandler(userService, teamService)
 	prHandler := handlers.NewPRHandler(prService)
 
 	statsHandler := handlers.NewStatsHandler(prService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	integrationCfg := config.LoadIntegration()
+	integrationHandler := handlers.NewIntegrationHandler(integrationService, integrationCfg.GitHubSecret, integrationCfg.GitLabToken)
+
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	slaHandler := handlers.NewSLAHandler(slaService)
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	orgHandler := handlers.NewOrgHandler(orgService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	var verifier *oidc.Verifier
+	if oidcCfg := config.LoadOIDC(); oidcCfg.JWKS != "" {
+		keys, err := oidc.NewKeySource(ctx, oidcCfg.JWKS, &http.Client{Timeout: 10 * time.Second}, oidcCfg.Refresh)
+		if err != nil {
+			repos.close()
+			log.Fatalf("failed to load OIDC JWKS: %v", err)
+		}
+		verifier = oidc.NewVerifier(keys, oidc.Config{
+			Issuer:    oidcCfg.Issuer,
+			Audience:  oidcCfg.Audience,
+			UserClaim: oidcCfg.UserClaim,
+			RoleClaim: oidcCfg.RoleClaim,
+			OrgClaim:  oidcCfg.OrgClaim,
+			Leeway:    oidcCfg.Leeway,
+		})
+		log.Printf("OIDC tokens are verified against %s", oidcCfg.JWKS)
+	}
+	auth := handlers.NewAuthMiddleware(tokenService, orgService, verifier, authCfg.Enabled)
 
-	handler := router.NewRouter(teamHandler, userHandler, prHandler, statsHandler)
+	httpMetrics := handlers.NewHTTPMetrics(registry)
+	rateLimitCfg := config.LoadRateLimit()
+	routeLimits := make(map[string]ratelimit.Rule, len(rateLimitCfg.Routes))
+	for route, rule := range rateLimitCfg.Routes {
+		routeLimits[route] = ratelimit.Rule(rule)
+	}
+	limiter := handlers.NewRateLimiter(ratelimit.New(ratelimit.Rule(rateLimitCfg.Default), routeLimits), registry)
+
+	idempotencyCfg := config.LoadIdempotency()
+	idempotencyKeys := handlers.NewIdempotency(service.NewIdempotencyService(repos.idempotency, idempotencyCfg.TTL))
 
 	serverCfg := config.LoadServer()
+	handler := router.NewRouter(auth, httpMetrics, limiter, idempotencyKeys, registry, teamHandler, userHandler, prHandler, statsHandler, webhookHandler, integrationHandler, notificationHandler, slaHandler, tokenHandler, orgHandler, auditHandler)
+	handler = handlers.RequestInfo(handler, serverCfg.TrustProxy)
+
+	webhookCfg := config.LoadWebhook()
+	dispatcher := webhook.NewDispatcher(repos.outbox, egressClient, webhook.Config{
+		PollInterval: webhookCfg.PollInterval,
+		BatchSize:    webhookCfg.BatchSize,
+		MaxAttempts:  webhookCfg.MaxAttempts,
+		BaseBackoff:  webhookCfg.BaseBackoff,
+		MaxBackoff:   webhookCfg.MaxBackoff,
+		Timeout:      webhookCfg.Timeout,
+	})
+	jobs := []func(ctx context.Context){dispatcher.Run}
+
+	notifier := notify.NewWorker(repos.notify, senders, notify.Config{
+		PollInterval: notifyCfg.PollInterval,
+		BatchSize:    notifyCfg.BatchSize,
+		MaxAttempts:  notifyCfg.MaxAttempts,
+		BaseBackoff:  notifyCfg.BaseBackoff,
+		MaxBackoff:   notifyCfg.MaxBackoff,
+		Timeout:      notifyCfg.Timeout,
+	})
+	jobs = append(jobs, notifier.Run)
+
+	slaCfg := config.LoadSLA()
+	scheduler := sla.NewScheduler(repos.tx, repos.slas, prService, sla.Config{
+		PollInterval: slaCfg.PollInterval,
+		BatchSize:    slaCfg.BatchSize,
+	})
+	jobs = append(jobs, scheduler.Run)
+
+	if smtpCfg.Host != "" {
+		digester := notify.NewDigester(repos.tx, repos.notify, repos.users, repos.prs, templates, notify.DigestConfig{
+			Location:     notifyCfg.DigestLocation,
+			At:           notifyCfg.DigestAt,
+			PollInterval: notifyCfg.DigestInterval,
+			BatchSize:    notifyCfg.BatchSize,
+		})
+		jobs = append(jobs, digester.Run)
+	}
+
+	if len(codeHostClients) > 0 {
+		syncer := codehost.NewSyncer(repos.syncs, repos.prs, repos.integrations, codeHostClients, codehost.SyncConfig{
+			PollInterval: codeHostCfg.PollInterval,
+			BatchSize:    codeHostCfg.BatchSize,
+			MaxAttempts:  codeHostCfg.MaxAttempts,
+			BaseBackoff:  codeHostCfg.BaseBackoff,
+			MaxBackoff:   codeHostCfg.MaxBackoff,
+			Timeout:      codeHostCfg.Timeout,
+		})
+		jobs = append(jobs, syncer.Run)
+	}
+
+	if auditCfg := config.LoadAudit(); auditCfg.Retention > 0 {
+		pruner := audit.NewPruner(repos.audit, audit.Config{
+			Retention:    auditCfg.Retention,
+			PollInterval: auditCfg.PruneInterval,
+		})
+		jobs = append(jobs, pruner.Run)
+	}
+	jobs = append(jobs, idempotency.NewPruner(repos.idempotency, idempotencyCfg.PruneInterval).Run)
+
+	// Фоновые обработчики работают только на лидере, если хранилище выбирает его
+	// (Postgres с LEADER_ELECTION=true), иначе - на каждой реплике.
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		if repos.elector == nil {
+			runJobs(workersCtx, jobs)
+			return
+		}
+		repos.elector.Run(workersCtx, func(ctx context.Context) { runJobs(ctx, jobs) })
+	}()
+
 	srv := &http.Server{
 		Addr:         serverCfg.Addr,
 		Handler:      handler,
@@ -79,13 +258,41 @@ func main() {
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 
+	stopWorkers()
+	workers.Wait()
+
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		cancel()
-		pool.Close()
+		repos.close()
 		log.Fatalf("server forced to shutdown: %v", err)
 	}
 
 	cancel()
-	pool.Close()
+	repos.close()
 	log.Println("server exited gracefully")
 }
+
+// runJobs запускает jobs и ждёт их завершения после отмены ctx.
+func runJobs(ctx context.Context, jobs []func(ctx context.Context)) {
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// newCodeHostClients создаёт клиенты API код-хостингов, для которых задан токен.
+func newCodeHostClients(cfg config.CodeHostConfig) []service.CodeHostClient {
+	var clients []service.CodeHostClient
+	if cfg.GitHubToken != "" {
+		clients = append(clients, codehost.NewGitHubClient(&http.Client{}, cfg.GitHubAPIURL, cfg.GitHubToken))
+	}
+	if cfg.GitLabToken != "" {
+		clients = append(clients, codehost.NewGitLabClient(&http.Client{}, cfg.GitLabURL, cfg.GitLabToken))
+	}
+	return clients
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/config"
+	"github.com/VechkanovVV/assigner-pr/internal/infra/postgres"
+	"github.com/VechkanovVV/assigner-pr/migrations"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    revert the last N migrations (default 1)
+  status      list migrations and whether they are applied
+  version     print the current schema version`
+
+// runMigrate выполняет подкоманду migrate и возвращает код выхода процесса.
+func runMigrate(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if config.LoadStorage().Driver == config.DriverSQLite {
+		fmt.Fprintln(os.Stderr, "sqlite schema is migrated automatically on server start")
+		return 2
+	}
+
+	pool, err := newPool(ctx, config.LoadDB())
+	if err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+	defer pool.Close()
+
+	m, err := postgres.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+
+	if err := execMigrate(ctx, m, args); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		log.Printf("migrate %s failed: %v", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+var errUsage = errors.New("usage")
+
+func execMigrate(ctx context.Context, m *postgres.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mg := range applied {
+			fmt.Printf("applied %d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return errUsage
+			}
+			steps = n
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, mg := range reverted {
+			fmt.Printf("reverted %d_%s\n", mg.Version, mg.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("nothing to revert")
+		}
+		return err
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE")
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", st.Version, st.Name, state)
+		}
+		return w.Flush()
+
+	case "version":
+		version, dirty, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			fmt.Printf("%d (dirty)\n", version)
+			return nil
+		}
+		fmt.Println(version)
+		return nil
+
+	default:
+		return errUsage
+	}
+}
+
+// migrateUp применяет встроенные миграции при старте сервера (DB_AUTO_MIGRATE).
+func migrateUp(ctx context.Context, pool *pgxpool.Pool) error {
+	m, err := postgres.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.Up(ctx)
+	for _, mg := range applied {
+		log.Printf("migration %d_%s applied", mg.Version, mg.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("auto-migrate failed: %w", err)
+	}
+	return nil
+}
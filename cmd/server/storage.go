@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/config"
+	"github.com/VechkanovVV/assigner-pr/internal/infra/postgres"
+	sqliteInfra "github.com/VechkanovVV/assigner-pr/internal/infra/sqlite"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	postgresRepo "github.com/VechkanovVV/assigner-pr/internal/storage/postgres"
+	sqliteRepo "github.com/VechkanovVV/assigner-pr/internal/storage/sqlite"
+)
+
+// repositories - репозитории выбранного хранилища.
+type repositories struct {
+	tx           storage.TxManager
+	teams        storage.TeamRepository
+	users        storage.UserRepository
+	prs          storage.PullRequestRepository
+	logs         storage.AssignmentLogRepository
+	events       storage.PREventRepository
+	webhooks     storage.WebhookRepository
+	outbox       storage.OutboxRepository
+	integrations storage.IntegrationRepository
+	syncs        storage.ReviewerSyncRepository
+	notify       storage.NotificationRepository
+	slas         storage.ReviewSLARepository
+	tokens       storage.APITokenRepository
+	orgs         storage.OrganizationRepository
+	audit        storage.AuditLogRepository
+	idempotency  storage.IdempotencyRepository
+	// pool - пул соединений Postgres для метрик; nil для SQLite.
+	pool *pgxpool.Pool
+	// elector выбирает реплику, на которой работают фоновые обработчики; nil - на всех.
+	elector *postgres.Elector
+	close   func()
+}
+
+// openStorage подключается к хранилищу, выбранному STORAGE_DRIVER.
+func openStorage(ctx context.Context, cfg config.StorageConfig) (repositories, error) {
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		return openSQLite(ctx, cfg.SQLitePath)
+	default:
+		return openPostgres(ctx)
+	}
+}
+
+func openPostgres(ctx context.Context) (repositories, error) {
+	dbCfg := config.LoadDB()
+	pool, err := newPool(ctx, dbCfg)
+	if err != nil {
+		return repositories{}, err
+	}
+
+	if dbCfg.AutoMigrate {
+		if err := migrateUp(ctx, pool); err != nil {
+			pool.Close()
+			return repositories{}, err
+		}
+	}
+
+	var elector *postgres.Elector
+	if leaderCfg := config.LoadLeader(); leaderCfg.Enabled {
+		elector = postgres.NewElector(pool, postgres.WorkersLockID, postgres.ElectorConfig{
+			RetryInterval: leaderCfg.RetryInterval,
+			CheckInterval: leaderCfg.CheckInterval,
+		})
+	}
+
+	return repositories{
+		tx:           postgresRepo.NewTxManager(pool),
+		teams:        postgresRepo.NewTeamRepository(pool),
+		users:        postgresRepo.NewUserRepository(pool),
+		prs:          postgresRepo.NewPullRequestRepository(pool),
+		logs:         postgresRepo.NewAssignmentLogRepository(pool),
+		events:       postgresRepo.NewPREventRepository(pool),
+		webhooks:     postgresRepo.NewWebhookRepository(pool),
+		outbox:       postgresRepo.NewOutboxRepository(pool),
+		integrations: postgresRepo.NewIntegrationRepository(pool),
+		syncs:        postgresRepo.NewReviewerSyncRepository(pool),
+		notify:       postgresRepo.NewNotificationRepository(pool),
+		slas:         postgresRepo.NewReviewSLARepository(pool),
+		tokens:       postgresRepo.NewAPITokenRepository(pool),
+		orgs:         postgresRepo.NewOrganizationRepository(pool),
+		audit:        postgresRepo.NewAuditLogRepository(pool),
+		idempotency:  postgresRepo.NewIdempotencyRepository(pool),
+		elector:      elector,
+		pool:         pool,
+		close:        pool.Close,
+	}, nil
+}
+
+func newPool(ctx context.Context, dbCfg config.DBConfig) (*pgxpool.Pool, error) {
+	log.Printf("starting server with DB config: host=%s port=%d dbname=%s sslmode=%s",
+		dbCfg.Host, dbCfg.Port, dbCfg.Name, dbCfg.SSLmode)
+
+	pool, err := postgres.NewPool(
+		ctx,
+		dbCfg.Port,
+		dbCfg.Host,
+		dbCfg.User,
+		dbCfg.Password,
+		dbCfg.Name,
+		string(dbCfg.SSLmode),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DB pool: %w", err)
+	}
+	log.Println("database connection pool created successfully")
+	return pool, nil
+}
+
+func openSQLite(ctx context.Context, path string) (repositories, error) {
+	log.Printf("starting server with SQLite storage: path=%s", path)
+
+	db, err := sqliteInfra.NewDB(ctx, path)
+	if err != nil {
+		return repositories{}, err
+	}
+
+	if err := sqliteRepo.Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return repositories{}, fmt.Errorf("sqlite migration failed: %w", err)
+	}
+
+	return repositories{
+		tx:           sqliteRepo.NewTxManager(db),
+		teams:        sqliteRepo.NewTeamRepository(db),
+		users:        sqliteRepo.NewUserRepository(db),
+		prs:          sqliteRepo.NewPullRequestRepository(db),
+		logs:         sqliteRepo.NewAssignmentLogRepository(db),
+		events:       sqliteRepo.NewPREventRepository(db),
+		webhooks:     sqliteRepo.NewWebhookRepository(db),
+		outbox:       sqliteRepo.NewOutboxRepository(db),
+		integrations: sqliteRepo.NewIntegrationRepository(db),
+		syncs:        sqliteRepo.NewReviewerSyncRepository(db),
+		notify:       sqliteRepo.NewNotificationRepository(db),
+		slas:         sqliteRepo.NewReviewSLARepository(db),
+		tokens:       sqliteRepo.NewAPITokenRepository(db),
+		orgs:         sqliteRepo.NewOrganizationRepository(db),
+		audit:        sqliteRepo.NewAuditLogRepository(db),
+		idempotency:  sqliteRepo.NewIdempotencyRepository(db),
+		close: func() {
+			if err := db.Close(); err != nil {
+				log.Printf("sqlite close failed: %v", err)
+			}
+		},
+	}, nil
+}
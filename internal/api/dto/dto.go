@@ -1,7 +1,10 @@
 // Package dto содержит структуры DTO для HTTP API.
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // ErrorResponse - формат ошибки.
 type ErrorResponse struct {
@@ -31,9 +34,10 @@ type TeamMember struct {
 type TeamResponse struct {
 	TeamName string       `json:"team_name"`
 	Members  []TeamMember `json:"members"`
+	Version  int64        `json:"version"`
 }
 
-// UserResponse - POST /users/setIsActive response.
+// UserResponse - GET /users/get, POST /users/setIsActive response.
 type UserResponse struct {
 	User UserDetail `json:"user"`
 }
@@ -44,6 +48,7 @@ type UserDetail struct {
 	Username string `json:"username"`
 	TeamName string `json:"team_name"`
 	IsActive bool   `json:"is_active"`
+	Version  int64  `json:"version"`
 }
 
 // SetActiveRequest - POST /users/setIsActive body.
@@ -59,6 +64,24 @@ type CreatePRRequest struct {
 	AuthorID        string `json:"author_id"`
 }
 
+// PreviewPRRequest - POST /pullRequest/preview body.
+type PreviewPRRequest struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+}
+
+// PreviewPRResponse - POST /pullRequest/preview response.
+type PreviewPRResponse struct {
+	PullRequestID     string              `json:"pull_request_id,omitempty"`
+	PullRequestName   string              `json:"pull_request_name,omitempty"`
+	AuthorID          string              `json:"author_id"`
+	Strategy          string              `json:"strategy"`
+	AssignedReviewers []string            `json:"assigned_reviewers"`
+	EligiblePool      []string            `json:"eligible_pool"`
+	Excluded          []ExcludedCandidate `json:"excluded"`
+}
+
 // PullRequestResponse - формат PR.
 type PullRequestResponse struct {
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
@@ -68,6 +91,7 @@ type PullRequestResponse struct {
 	AuthorID          string     `json:"author_id"`
 	Status            string     `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
+	Version           int64      `json:"version"`
 }
 
 // MergeRequest - POST /pullRequest/merge body.
@@ -75,6 +99,13 @@ type MergeRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+// ReviewRequest - POST /pullRequest/review body. Пустой reviewer_id - вызывающий.
+type ReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	State         string `json:"state"`
+}
+
 // ReassignRequest - POST /pullRequest/reassign body.
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id"`
@@ -100,3 +131,262 @@ type PullRequestShort struct {
 	AuthorID        string `json:"author_id"`
 	Status          string `json:"status"`
 }
+
+// AssignmentLogResponse - GET /pullRequest/assignmentLog response.
+type AssignmentLogResponse struct {
+	PullRequestID string               `json:"pull_request_id"`
+	Entries       []AssignmentLogEntry `json:"entries"`
+}
+
+// AssignmentLogEntry - одна запись журнала назначений.
+type AssignmentLogEntry struct {
+	AssignedAt         time.Time           `json:"assigned_at"`
+	Seed               *int64              `json:"seed,omitempty"`
+	Draw               *int                `json:"draw,omitempty"`
+	ReviewerID         string              `json:"reviewer_id"`
+	ReplacedReviewerID string              `json:"replaced_reviewer_id,omitempty"`
+	Strategy           string              `json:"strategy"`
+	Candidates         []string            `json:"candidates"`
+	Excluded           []ExcludedCandidate `json:"excluded"`
+	PoolSize           int                 `json:"pool_size"`
+}
+
+// ExcludedCandidate - участник команды, не попавший в пул кандидатов, и причина.
+type ExcludedCandidate struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+// PRHistoryResponse - GET /pullRequest/history response.
+type PRHistoryResponse struct {
+	PullRequestID string           `json:"pull_request_id"`
+	Events        []PRHistoryEvent `json:"events"`
+}
+
+// PRHistoryEvent - одно событие истории PR.
+type PRHistoryEvent struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Type      string          `json:"type"`
+	Actor     string          `json:"actor"`
+	Payload   json.RawMessage `json:"payload"`
+	ID        int64           `json:"id"`
+}
+
+// WebhookRequest - POST /webhooks request.
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types"`
+}
+
+// Webhook - зарегистрированный подписчик. Secret возвращается только при регистрации.
+type Webhook struct {
+	CreatedAt  time.Time `json:"created_at"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	ID         int64     `json:"id"`
+}
+
+// WebhookListResponse - GET /webhooks response.
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// WebhookDelivery - доставка события подписчику.
+type WebhookDelivery struct {
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	EventType     string          `json:"event_type"`
+	Status        string          `json:"status"`
+	LastError     string          `json:"last_error,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	ID            int64           `json:"id"`
+	WebhookID     int64           `json:"webhook_id"`
+	EventID       int64           `json:"event_id"`
+	Attempts      int             `json:"attempts"`
+}
+
+// WebhookDeliveriesResponse - GET /webhooks/deliveries response.
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// IngestResponse - ответ на входящий вебхук код-хостинга.
+type IngestResponse struct {
+	Result        string `json:"result"`
+	PullRequestID string `json:"pull_request_id,omitempty"`
+	Action        string `json:"action,omitempty"`
+}
+
+// IdentityRequest - POST /integrations/identities request.
+type IdentityRequest struct {
+	Provider string `json:"provider"`
+	Login    string `json:"login"`
+	UserID   string `json:"user_id"`
+}
+
+// InboundSecretRequest - POST /integrations/secrets request.
+type InboundSecretRequest struct {
+	Provider string `json:"provider"`
+}
+
+// InboundSecret - секрет входящих вебхуков провайдера организации.
+type InboundSecret struct {
+	CreatedAt time.Time `json:"created_at"`
+	Provider  string    `json:"provider"`
+	Secret    string    `json:"secret"`
+}
+
+// TeamChatRequest - POST /team/chat request.
+type TeamChatRequest struct {
+	TeamName   string `json:"team_name"`
+	WebhookURL string `json:"webhook_url"`
+}
+
+// TeamChat - входящий вебхук чата команды.
+type TeamChat struct {
+	UpdatedAt  time.Time `json:"updated_at"`
+	TeamName   string    `json:"team_name"`
+	WebhookURL string    `json:"webhook_url"`
+}
+
+// Notification - уведомление в очереди отправки.
+type Notification struct {
+	CreatedAt     time.Time `json:"created_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Channel       string    `json:"channel"`
+	Target        string    `json:"target"`
+	Subject       string    `json:"subject,omitempty"`
+	Body          string    `json:"body"`
+	Status        string    `json:"status"`
+	LastError     string    `json:"last_error,omitempty"`
+	ID            int64     `json:"id"`
+	Attempts      int       `json:"attempts"`
+}
+
+// NotificationsResponse - GET /notifications response.
+type NotificationsResponse struct {
+	Notifications []Notification `json:"notifications"`
+}
+
+// EmailPreferenceRequest - POST /users/setEmailPreference request.
+type EmailPreferenceRequest struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Mode   string `json:"mode"`
+}
+
+// EmailPreference - адрес и режим писем пользователя.
+type EmailPreference struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Mode      string    `json:"mode"`
+}
+
+// TeamSLARequest - POST /team/sla request. Сроки - строки time.ParseDuration ("24h", "90m").
+type TeamSLARequest struct {
+	TeamName      string `json:"team_name"`
+	Within        string `json:"within"`
+	Escalation    string `json:"escalation"`
+	EscalateAfter string `json:"escalate_after"`
+	LeadID        string `json:"lead_id"`
+}
+
+// TeamSLA - срок ревью команды.
+type TeamSLA struct {
+	UpdatedAt     time.Time `json:"updated_at"`
+	TeamName      string    `json:"team_name"`
+	Within        string    `json:"within"`
+	Escalation    string    `json:"escalation"`
+	EscalateAfter string    `json:"escalate_after,omitempty"`
+	LeadID        string    `json:"lead_id,omitempty"`
+}
+
+// ReviewerSLA - SLA назначенного ревьюера.
+type ReviewerSLA struct {
+	AssignedAt  time.Time  `json:"assigned_at"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	RemindedAt  *time.Time `json:"reminded_at,omitempty"`
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+	ReviewerID  string     `json:"reviewer_id"`
+	State       string     `json:"state,omitempty"`
+}
+
+// PRSLAResponse - GET /pullRequest/sla response. SLA равен null, если у команды автора нет срока ревью.
+type PRSLAResponse struct {
+	SLA           *TeamSLA      `json:"sla"`
+	PullRequestID string        `json:"pull_request_id"`
+	Status        string        `json:"status"`
+	Reviewers     []ReviewerSLA `json:"reviewers"`
+}
+
+// APITokenRequest - POST /admin/tokens request.
+type APITokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// APIToken - API-токен. Token (секрет) возвращается только при выпуске.
+type APIToken struct {
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Name      string     `json:"name"`
+	Token     string     `json:"token,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	ID        int64      `json:"id"`
+}
+
+// APITokenListResponse - GET /admin/tokens response.
+type APITokenListResponse struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+// Caller - GET /auth/whoami response.
+type Caller struct {
+	UserID    string   `json:"user_id,omitempty"`
+	TokenName string   `json:"token_name,omitempty"`
+	Role      string   `json:"role"`
+	Scopes    []string `json:"scopes"`
+	OrgID     int64    `json:"org_id"`
+}
+
+// OrganizationRequest - POST /admin/orgs request.
+type OrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// Organization - организация.
+type Organization struct {
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+}
+
+// OrganizationListResponse - GET /admin/orgs response.
+type OrganizationListResponse struct {
+	Organizations []Organization `json:"organizations"`
+}
+
+// AuditEntry - запись журнала аудита. Before и After - снимки объекта, null - объекта не было.
+type AuditEntry struct {
+	CreatedAt  time.Time       `json:"created_at"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id,omitempty"`
+	SourceIP   string          `json:"source_ip,omitempty"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	ID         int64           `json:"id"`
+}
+
+// AuditLogResponse - GET /admin/audit response. NextCursor передаётся в параметре cursor
+// за следующей страницей; отсутствует на последней.
+type AuditLogResponse struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor int64        `json:"next_cursor,omitempty"`
+}
@@ -1,6 +1,11 @@
 package dto
 
-import "github.com/VechkanovVV/assigner-pr/internal/storage"
+import (
+	"encoding/json"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
 
 // ToStorageTeam DTO -> storage.Team.
 func (r TeamRequest) ToStorageTeam() storage.Team {
@@ -31,6 +36,7 @@ func FromStorageTeam(t storage.Team) TeamResponse {
 	return TeamResponse{
 		TeamName: t.TeamName,
 		Members:  members,
+		Version:  t.Version,
 	}
 }
 
@@ -44,6 +50,7 @@ func FromStoragePR(pr storage.PullRequest) PullRequestResponse {
 		AssignedReviewers: pr.AssignedReviewers,
 		CreatedAt:         &pr.CreatedAt,
 		MergedAt:          pr.MergedAt,
+		Version:           pr.Version,
 	}
 }
 
@@ -75,6 +82,7 @@ func FromStorageUser(u storage.User, teamName string) UserDetail {
 		Username: u.Username,
 		TeamName: teamName,
 		IsActive: u.IsActive,
+		Version:  u.Version,
 	}
 }
 
@@ -85,3 +93,264 @@ func FromStoragePRWithReplacedBy(pr storage.PullRequest, replacedBy string) Reas
 		ReplacedBy:  replacedBy,
 	}
 }
+
+// FromStorageAssignmentLog []storage.AssignmentLog -> AssignmentLogResponse.
+func FromStorageAssignmentLog(prID string, entries []storage.AssignmentLog) AssignmentLogResponse {
+	res := make([]AssignmentLogEntry, 0, len(entries))
+	for _, e := range entries {
+		candidates := e.Candidates
+		if candidates == nil {
+			candidates = []string{}
+		}
+
+		res = append(res, AssignmentLogEntry{
+			AssignedAt:         e.CreatedAt,
+			Seed:               e.Seed,
+			Draw:               e.Draw,
+			ReviewerID:         e.ReviewerID,
+			ReplacedReviewerID: e.ReplacedReviewerID,
+			Strategy:           string(e.Strategy),
+			Candidates:         candidates,
+			Excluded:           fromStorageExcluded(e.Excluded),
+			PoolSize:           e.PoolSize,
+		})
+	}
+
+	return AssignmentLogResponse{
+		PullRequestID: prID,
+		Entries:       res,
+	}
+}
+
+// FromStoragePREvents []storage.PREvent -> PRHistoryResponse.
+func FromStoragePREvents(prID string, events []storage.PREvent) PRHistoryResponse {
+	res := make([]PRHistoryEvent, 0, len(events))
+	for _, e := range events {
+		res = append(res, PRHistoryEvent{
+			ID:        e.ID,
+			CreatedAt: e.CreatedAt,
+			Type:      string(e.Type),
+			Actor:     e.Actor,
+			Payload:   json.RawMessage(e.Payload),
+		})
+	}
+
+	return PRHistoryResponse{
+		PullRequestID: prID,
+		Events:        res,
+	}
+}
+
+// FromPreview service.AssignmentPreview + запрос -> PreviewPRResponse.
+func FromPreview(req PreviewPRRequest, p service.AssignmentPreview) PreviewPRResponse {
+	return PreviewPRResponse{
+		PullRequestID:     req.PullRequestID,
+		PullRequestName:   req.PullRequestName,
+		AuthorID:          req.AuthorID,
+		Strategy:          string(p.Strategy),
+		AssignedReviewers: p.Reviewers,
+		EligiblePool:      p.Candidates,
+		Excluded:          fromStorageExcluded(p.Excluded),
+	}
+}
+
+func fromStorageExcluded(excluded []storage.ExcludedCandidate) []ExcludedCandidate {
+	res := make([]ExcludedCandidate, 0, len(excluded))
+	for _, ex := range excluded {
+		res = append(res, ExcludedCandidate{UserID: ex.UserID, Reason: string(ex.Reason)})
+	}
+	return res
+}
+
+// FromStorageWebhook storage.Webhook -> DTO. Секрет включается только при withSecret.
+func FromStorageWebhook(w storage.Webhook, withSecret bool) Webhook {
+	res := Webhook{
+		ID:         w.ID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		CreatedAt:  w.CreatedAt,
+	}
+	if res.EventTypes == nil {
+		res.EventTypes = []string{}
+	}
+	if withSecret {
+		res.Secret = w.Secret
+	}
+	return res
+}
+
+// FromStorageWebhooks []storage.Webhook -> DTO без секретов.
+func FromStorageWebhooks(webhooks []storage.Webhook) WebhookListResponse {
+	res := WebhookListResponse{Webhooks: make([]Webhook, 0, len(webhooks))}
+	for _, w := range webhooks {
+		res.Webhooks = append(res.Webhooks, FromStorageWebhook(w, false))
+	}
+	return res
+}
+
+// FromStorageDeliveries []storage.WebhookDelivery -> DTO.
+func FromStorageDeliveries(deliveries []storage.WebhookDelivery) WebhookDeliveriesResponse {
+	res := WebhookDeliveriesResponse{Deliveries: make([]WebhookDelivery, 0, len(deliveries))}
+	for _, d := range deliveries {
+		res.Deliveries = append(res.Deliveries, WebhookDelivery{
+			ID:            d.ID,
+			WebhookID:     d.WebhookID,
+			EventID:       d.Event.ID,
+			EventType:     d.Event.Type,
+			Payload:       json.RawMessage(d.Event.Payload),
+			Status:        string(d.Status),
+			Attempts:      d.Attempts,
+			NextAttemptAt: d.NextAttemptAt,
+			LastError:     d.LastError,
+			UpdatedAt:     d.UpdatedAt,
+		})
+	}
+	return res
+}
+
+// FromStorageTeamChat storage.TeamChat -> DTO.
+func FromStorageTeamChat(teamName string, chat storage.TeamChat) TeamChat {
+	return TeamChat{TeamName: teamName, WebhookURL: chat.WebhookURL, UpdatedAt: chat.UpdatedAt}
+}
+
+// FromStorageNotifications []storage.Notification -> DTO.
+func FromStorageNotifications(notifications []storage.Notification) NotificationsResponse {
+	res := NotificationsResponse{Notifications: make([]Notification, 0, len(notifications))}
+	for _, n := range notifications {
+		res.Notifications = append(res.Notifications, Notification{
+			ID:            n.ID,
+			Channel:       string(n.Channel),
+			Target:        n.Target,
+			Subject:       n.Subject,
+			Body:          n.Body,
+			Status:        string(n.Status),
+			Attempts:      n.Attempts,
+			NextAttemptAt: n.NextAttemptAt,
+			LastError:     n.LastError,
+			CreatedAt:     n.CreatedAt,
+			UpdatedAt:     n.UpdatedAt,
+		})
+	}
+	return res
+}
+
+// FromStorageEmailPreference storage.EmailPreference -> DTO.
+func FromStorageEmailPreference(pref storage.EmailPreference) EmailPreference {
+	return EmailPreference{UserID: pref.UserID, Email: pref.Email, Mode: string(pref.Mode), UpdatedAt: pref.UpdatedAt}
+}
+
+// FromStorageReviewSLA storage.ReviewSLA -> DTO.
+func FromStorageReviewSLA(teamName string, sla storage.ReviewSLA) TeamSLA {
+	res := TeamSLA{
+		TeamName:   teamName,
+		Within:     sla.Within.String(),
+		Escalation: string(sla.Escalation),
+		LeadID:     sla.LeadID,
+		UpdatedAt:  sla.UpdatedAt,
+	}
+	if sla.Escalation != storage.EscalateNone {
+		res.EscalateAfter = sla.EscalateAfter.String()
+	}
+	return res
+}
+
+// FromServicePRSLA service.PRSLA -> DTO.
+func FromServicePRSLA(prSLA service.PRSLA) PRSLAResponse {
+	res := PRSLAResponse{
+		PullRequestID: prSLA.PR.ID,
+		Status:        string(prSLA.PR.Status),
+		Reviewers:     make([]ReviewerSLA, 0, len(prSLA.Reviewers)),
+	}
+	if prSLA.SLA != nil {
+		sla := FromStorageReviewSLA(prSLA.TeamName, *prSLA.SLA)
+		res.SLA = &sla
+	}
+	for _, r := range prSLA.Reviewers {
+		rs := ReviewerSLA{
+			ReviewerID:  r.ReviewerID,
+			AssignedAt:  r.AssignedAt,
+			RemindedAt:  r.RemindedAt,
+			EscalatedAt: r.EscalatedAt,
+			State:       string(r.State),
+		}
+		if prSLA.SLA != nil {
+			dueAt := r.DueAt
+			rs.DueAt = &dueAt
+		}
+		res.Reviewers = append(res.Reviewers, rs)
+	}
+	return res
+}
+
+// FromStorageAPIToken storage.APIToken -> DTO; secret - секрет только что выпущенного токена.
+func FromStorageAPIToken(t storage.APIToken, secret string) APIToken {
+	res := APIToken{
+		ID:        t.ID,
+		Name:      t.Name,
+		Token:     secret,
+		Scopes:    make([]string, 0, len(t.Scopes)),
+		CreatedAt: t.CreatedAt,
+		RevokedAt: t.RevokedAt,
+	}
+	for _, s := range t.Scopes {
+		res.Scopes = append(res.Scopes, string(s))
+	}
+	return res
+}
+
+// FromStorageAPITokens []storage.APIToken -> DTO.
+func FromStorageAPITokens(tokens []storage.APIToken) APITokenListResponse {
+	res := APITokenListResponse{Tokens: make([]APIToken, 0, len(tokens))}
+	for _, t := range tokens {
+		res.Tokens = append(res.Tokens, FromStorageAPIToken(t, ""))
+	}
+	return res
+}
+
+// FromServiceCaller service.Caller -> DTO.
+func FromServiceCaller(c service.Caller) Caller {
+	res := Caller{UserID: c.UserID, TokenName: c.Name, Role: string(c.Role), Scopes: make([]string, 0, len(c.Scopes)), OrgID: c.OrgID}
+	for _, s := range c.Scopes {
+		res.Scopes = append(res.Scopes, string(s))
+	}
+	return res
+}
+
+// FromStorageOrganization storage.Organization -> DTO.
+func FromStorageOrganization(o storage.Organization) Organization {
+	return Organization{ID: o.ID, Name: o.Name, CreatedAt: o.CreatedAt}
+}
+
+// FromStorageOrganizations []storage.Organization -> DTO.
+func FromStorageOrganizations(orgs []storage.Organization) OrganizationListResponse {
+	res := OrganizationListResponse{Organizations: make([]Organization, 0, len(orgs))}
+	for _, o := range orgs {
+		res.Organizations = append(res.Organizations, FromStorageOrganization(o))
+	}
+	return res
+}
+
+// FromStorageAuditEntries []storage.AuditEntry + курсор -> DTO.
+func FromStorageAuditEntries(entries []storage.AuditEntry, nextCursor int64) AuditLogResponse {
+	res := AuditLogResponse{Entries: make([]AuditEntry, 0, len(entries)), NextCursor: nextCursor}
+	for _, e := range entries {
+		res.Entries = append(res.Entries, AuditEntry{
+			ID:         e.ID,
+			CreatedAt:  e.CreatedAt,
+			Action:     string(e.Action),
+			Actor:      e.Actor,
+			RequestID:  e.RequestID,
+			SourceIP:   e.SourceIP,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Before:     json.RawMessage(e.Before),
+			After:      json.RawMessage(e.After),
+		})
+	}
+	return res
+}
+
+// FromStorageInboundSecret маппит storage.InboundSecret -> InboundSecret.
+func FromStorageInboundSecret(s storage.InboundSecret) InboundSecret {
+	return InboundSecret{Provider: s.Provider, Secret: s.Secret, CreatedAt: s.CreatedAt}
+}
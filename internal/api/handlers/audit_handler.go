@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// defaultAuditLimit и maxAuditLimit ограничивают выдачу GET /admin/audit.
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditHandler обрабатывает HTTP-запросы к журналу аудита.
+type AuditHandler struct {
+	AuditService *service.AuditService
+}
+
+// NewAuditHandler возвращает новый AuditHandler.
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{AuditService: auditService}
+}
+
+// List обрабатывает GET /admin/audit?actor=&action=&target_type=&target_id=&since=&until=&cursor=&limit=.
+// since и until - RFC 3339, cursor - next_cursor предыдущей страницы.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := storage.AuditFilter{
+		Actor:      q.Get("actor"),
+		Action:     storage.AuditAction(q.Get("action")),
+		TargetType: q.Get("target_type"),
+		TargetID:   q.Get("target_id"),
+		Limit:      defaultAuditLimit,
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), p.name+" must be an RFC 3339 timestamp")
+			return
+		}
+		*p.dst = t
+	}
+
+	if raw := q.Get("cursor"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "cursor must be a positive integer")
+			return
+		}
+		filter.BeforeID = id
+	}
+
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxAuditLimit {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "limit must be between 1 and 500")
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, next, appErr := h.AuditService.List(r.Context(), filter)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageAuditEntries(entries, next))
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/oidc"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// orgHeader - заголовок с именем организации запроса.
+const orgHeader = "X-Org"
+
+// AuthMiddleware проверяет заголовок Authorization: Bearer - API-токен или JWT
+// корпоративного SSO - и права вызывающего, и определяет организацию запроса.
+type AuthMiddleware struct {
+	TokenService *service.TokenService
+	OrgService   *service.OrgService
+	// Verifier проверяет JWT; nil - принимаются только API-токены.
+	Verifier *oidc.Verifier
+	// Enabled - требовать токен; false пропускает все запросы (локальная разработка).
+	Enabled bool
+}
+
+// NewAuthMiddleware возвращает новый AuthMiddleware.
+func NewAuthMiddleware(tokenService *service.TokenService, orgService *service.OrgService, verifier *oidc.Verifier, enabled bool) *AuthMiddleware {
+	return &AuthMiddleware{TokenService: tokenService, OrgService: orgService, Verifier: verifier, Enabled: enabled}
+}
+
+// Require пропускает к next только аутентифицированные запросы с правом scope (пустой
+// scope - достаточно аутентификации): без токена или с недействительным токеном ответ 401,
+// без права - 403. Вызывающий кладётся в контекст (service.WithCaller), и операции запроса
+// записываются в историю от его имени.
+//
+// Запрос работает с данными организации вызывающего (storage.WithOrg): API-токена или
+// claim организации в JWT. Заголовок X-Org с именем другой организации принимается только
+// от администраторов организации по умолчанию, остальным - 403. При Enabled = false
+// организация берётся из X-Org.
+func (m *AuthMiddleware) Require(scope storage.TokenScope, next http.HandlerFunc) http.HandlerFunc {
+	if !m.Enabled {
+		return m.Public(next)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w, "bearer token is required")
+			return
+		}
+
+		caller, ok := m.authenticate(w, r, secret)
+		if !ok {
+			return
+		}
+
+		if scope != "" && !caller.Allows(scope) {
+			respondError(w, http.StatusForbidden, string(Forbidden), "caller lacks scope "+string(scope))
+			return
+		}
+
+		if name := r.Header.Get(orgHeader); name != "" {
+			orgID, ok := m.resolveOrg(w, r, name)
+			if !ok {
+				return
+			}
+			if !caller.CanSwitchOrg(orgID) {
+				respondError(w, http.StatusForbidden, string(Forbidden), "caller may not act in organization "+name)
+				return
+			}
+			caller.OrgID = orgID
+		}
+
+		ctx := storage.WithOrg(service.WithCaller(r.Context(), caller), caller.OrgID)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// Public пропускает запрос без аутентификации в организации из заголовка X-Org, без
+// него - в организации по умолчанию. Используется только при Enabled = false.
+func (m *AuthMiddleware) Public(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(orgHeader)
+		if name == "" {
+			next(w, r)
+			return
+		}
+
+		orgID, ok := m.resolveOrg(w, r, name)
+		if !ok {
+			return
+		}
+		next(w, r.WithContext(storage.WithOrg(r.Context(), orgID)))
+	}
+}
+
+// resolveOrg возвращает id организации по имени; при ошибке ответ уже записан.
+func (m *AuthMiddleware) resolveOrg(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	orgID, appErr := m.OrgService.Resolve(r.Context(), name)
+	if appErr != nil {
+		if appErr.Code == apperrors.ErrNotFound {
+			respondError(w, http.StatusNotFound, string(apperrors.ErrNotFound), "organization "+name+" not found")
+			return 0, false
+		}
+		respondAppError(w, appErr)
+		return 0, false
+	}
+	return orgID, true
+}
+
+// authenticate определяет вызывающего по секрету; при ошибке ответ уже записан.
+func (m *AuthMiddleware) authenticate(w http.ResponseWriter, r *http.Request, secret string) (service.Caller, bool) {
+	if m.Verifier != nil && oidc.LooksLikeJWT(secret) {
+		identity, err := m.Verifier.Verify(r.Context(), secret)
+		if err != nil {
+			log.Printf("reject jwt: %v", err)
+			unauthorized(w, "invalid token")
+			return service.Caller{}, false
+		}
+		caller := service.UserCaller(identity.UserID, identity.Roles)
+		if identity.Org != "" {
+			orgID, appErr := m.OrgService.Resolve(r.Context(), identity.Org)
+			if appErr != nil {
+				if appErr.Code == apperrors.ErrNotFound {
+					unauthorized(w, "unknown organization "+identity.Org)
+					return service.Caller{}, false
+				}
+				respondAppError(w, appErr)
+				return service.Caller{}, false
+			}
+			caller.OrgID = orgID
+		}
+		return caller, true
+	}
+
+	token, appErr := m.TokenService.Authenticate(r.Context(), secret)
+	if appErr != nil {
+		if appErr.Code == apperrors.ErrNotFound {
+			unauthorized(w, "invalid or revoked token")
+			return service.Caller{}, false
+		}
+		respondAppError(w, appErr)
+		return service.Caller{}, false
+	}
+	return service.TokenCaller(token), true
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="assigner"`)
+	respondError(w, http.StatusUnauthorized, string(Unauthorized), message)
+}
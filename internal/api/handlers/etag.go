@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+)
+
+// IfMatch передаёт версию ресурса из заголовка If-Match в сервис (см. service.WithIfMatch):
+// изменение выполняется, только если версия не изменилась с момента чтения, иначе
+// 412 VERSION_CONFLICT. Без заголовка и с "*" версия не проверяется; значение, отличное
+// от ETag вида "N", - 400.
+func IfMatch(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get("If-Match"))
+		if header == "" || header == "*" {
+			next(w, r)
+			return
+		}
+
+		version, ok := parseETag(header)
+		if !ok {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), `If-Match must be an ETag like "3"`)
+			return
+		}
+		next(w, r.WithContext(service.WithIfMatch(r.Context(), version)))
+	}
+}
+
+// setETag отдаёт версию ресурса в заголовке ETag.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", formatETag(version))
+}
+
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETag разбирает ETag вида "N"; слабые ETag (W/"N") не принимаются.
+func parseETag(etag string) (int64, bool) {
+	if len(etag) < 3 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(etag[1:len(etag)-1], 10, 63)
+	if err != nil {
+		return 0, false
+	}
+	return int64(version), true
+}
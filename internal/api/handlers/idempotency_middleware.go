@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+)
+
+// idempotencyKeyHeader - ключ идемпотентности запроса, выбранный клиентом.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLen ограничивает длину ключа идемпотентности.
+const maxIdempotencyKeyLen = 255
+
+// maxIdempotentBody - ограничение размера тела запроса с ключом идемпотентности: тело
+// читается в память целиком, чтобы посчитать отпечаток.
+const maxIdempotentBody = 1 << 20
+
+// replayedHeaders - заголовки ответа, которые сохраняются и повторяются вместе с ним.
+var replayedHeaders = []string{"ETag", "Location"}
+
+// Idempotency повторяет сохранённый ответ на запрос с уже использованным заголовком
+// Idempotency-Key вместо повторного выполнения операции (см. service.IdempotencyService).
+// Ключ принадлежит вызывающему (см. requestClient): ключи разных клиентов, в том числе
+// API-токенов с одинаковым именем, не пересекаются.
+type Idempotency struct {
+	service *service.IdempotencyService
+}
+
+// NewIdempotency возвращает новый Idempotency.
+func NewIdempotency(svc *service.IdempotencyService) *Idempotency {
+	return &Idempotency{service: svc}
+}
+
+// Wrap применяет ключ идемпотентности к запросам next; запросы без заголовка проходят как есть.
+// Повтор получает исходные статус, тело и replayedHeaders с заголовком Idempotent-Replayed: true;
+// тот же ключ с другим методом, путём или телом - 409 IDEMPOTENCY_KEY_REUSED. Тело больше
+// maxIdempotentBody - 413.
+func (m *Idempotency) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if m == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest),
+				"Idempotency-Key must be 1-255 printable ASCII characters")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, http.StatusRequestEntityTooLarge, string(TooLarge), "request body is too large")
+			return
+		}
+		if err != nil {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		_, client := requestClient(r)
+		rec, replay, appErr := m.service.Begin(r.Context(), client, key, requestFingerprint(r, body))
+		if appErr != nil {
+			respondAppError(w, appErr)
+			return
+		}
+		if replay {
+			w.Header().Set("Content-Type", "application/json")
+			for name, value := range rec.Headers {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(rec.Status)
+			if _, err := w.Write(rec.Response); err != nil {
+				log.Printf("failed to write replayed response: %v", err)
+			}
+			return
+		}
+
+		rw := &recordingWriter{ResponseWriter: w}
+		next(rw, r)
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		if appErr := m.service.Finish(r.Context(), client, key, rw.status, responseHeaders(rw.Header()), rw.body.Bytes()); appErr != nil {
+			log.Printf("failed to save response for idempotency key: %v", appErr)
+		}
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		return false
+	}
+	for _, c := range key {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// responseHeaders выбирает из заголовков ответа replayedHeaders; nil - их нет.
+func responseHeaders(header http.Header) map[string]string {
+	var res map[string]string
+	for _, name := range replayedHeaders {
+		if value := header.Get(name); value != "" {
+			if res == nil {
+				res = make(map[string]string, len(replayedHeaders))
+			}
+			res[name] = value
+		}
+	}
+	return res
+}
+
+// requestFingerprint - хеш метода, пути с параметрами и тела запроса.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter запоминает статус и тело ответа, передавая их клиенту.
+type recordingWriter struct {
+	http.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/codehost"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// maxWebhookBody - ограничение размера тела входящего вебхука.
+const maxWebhookBody = 5 << 20
+
+// IntegrationHandler принимает вебхуки код-хостингов и управляет их секретами.
+// GitHubSecret и GitLabToken - секреты из конфигурации, они принадлежат организации
+// по умолчанию; остальные организации выпускают свои (см. RotateSecret).
+type IntegrationHandler struct {
+	IntegrationService *service.IntegrationService
+	GitHubSecret       string
+	GitLabToken        string
+}
+
+// NewIntegrationHandler возвращает новый IntegrationHandler. Пустой секрет из конфигурации
+// не принимается; без секретов у провайдера приём его вебхуков отключён.
+func NewIntegrationHandler(integrationService *service.IntegrationService, githubSecret, gitlabToken string) *IntegrationHandler {
+	return &IntegrationHandler{
+		IntegrationService: integrationService,
+		GitHubSecret:       githubSecret,
+		GitLabToken:        gitlabToken,
+	}
+}
+
+type parseFunc func(header http.Header, body []byte, secret string) (service.CodeHostEvent, bool, error)
+
+// GitHubWebhook обрабатывает POST /integrations/github/webhook.
+func (h *IntegrationHandler) GitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	h.ingest(w, r, storage.ProviderGitHub, h.GitHubSecret, codehost.ParseGitHub)
+}
+
+// GitLabWebhook обрабатывает POST /integrations/gitlab/webhook.
+func (h *IntegrationHandler) GitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	h.ingest(w, r, storage.ProviderGitLab, h.GitLabToken, codehost.ParseGitLab)
+}
+
+// ingest применяет доставку в организации секрета, которым она подписана
+// (service.IntegrationService.MatchInboundSecret); заголовки и параметры запроса на
+// выбор организации не влияют.
+func (h *IntegrationHandler) ingest(w http.ResponseWriter, r *http.Request, provider, fallback string, parse parseFunc) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "cannot read body")
+		return
+	}
+
+	secret, ok, appErr := h.IntegrationService.MatchInboundSecret(r.Context(), provider, fallback, func(secret string) bool {
+		_, _, err := parse(r.Header, body, secret)
+		return !errors.Is(err, codehost.ErrSignature)
+	})
+	if appErr != nil {
+		if appErr.Code == apperrors.ErrNotFound {
+			respondError(w, http.StatusNotFound, string(apperrors.ErrNotFound), "integration is not configured")
+			return
+		}
+		respondAppError(w, appErr)
+		return
+	}
+	if !ok {
+		respondError(w, http.StatusUnauthorized, string(Unauthorized), "invalid webhook signature")
+		return
+	}
+	ctx := storage.WithOrg(r.Context(), secret.OrgID)
+
+	ev, ok, err := parse(r.Header, body, secret.Secret)
+	if err != nil {
+		log.Printf("invalid webhook payload: %v", err)
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid webhook payload")
+		return
+	}
+	if !ok {
+		respondJSON(w, http.StatusAccepted, dto.IngestResponse{Result: string(service.IngestIgnored)})
+		return
+	}
+
+	result, appErr := h.IntegrationService.Ingest(ctx, ev)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.IngestResponse{
+		Result:        string(result),
+		PullRequestID: ev.PullRequestID,
+		Action:        string(ev.Action),
+	})
+}
+
+// LinkIdentity обрабатывает POST /integrations/identities.
+func (h *IntegrationHandler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	var req dto.IdentityRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.Provider != storage.ProviderGitHub && req.Provider != storage.ProviderGitLab {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "provider must be github or gitlab")
+		return
+	}
+	if req.Login == "" || req.UserID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "login and user_id are required")
+		return
+	}
+
+	if appErr := h.IntegrationService.LinkIdentity(r.Context(), req.Provider, req.Login, req.UserID); appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"identity": req})
+}
+
+// RotateSecret обрабатывает POST /integrations/secrets: выпускает новый секрет входящих
+// вебхуков провайдера для организации вызывающего. Секрет показывается только в ответе.
+func (h *IntegrationHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	var req dto.InboundSecretRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.Provider != storage.ProviderGitHub && req.Provider != storage.ProviderGitLab {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "provider must be github or gitlab")
+		return
+	}
+
+	secret, appErr := h.IntegrationService.RotateInboundSecret(r.Context(), req.Provider)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]any{"secret": dto.FromStorageInboundSecret(secret)})
+}
+
+// DeleteSecret обрабатывает DELETE /integrations/secrets?provider=.
+func (h *IntegrationHandler) DeleteSecret(w http.ResponseWriter, r *http.Request) {
+	provider := r.URL.Query().Get("provider")
+	if provider != storage.ProviderGitHub && provider != storage.ProviderGitLab {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "provider must be github or gitlab")
+		return
+	}
+
+	if appErr := h.IntegrationService.DeleteInboundSecret(r.Context(), provider); appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
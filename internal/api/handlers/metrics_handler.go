@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Metrics обрабатывает GET /metrics: отдаёт метрики registry. Метрики общие для всех
+// организаций, поэтому их читают только администраторы организации по умолчанию.
+func Metrics(registry *metrics.Registry) http.HandlerFunc {
+	h := registry.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if caller, ok := service.CallerFrom(r.Context()); ok && caller.OrgID != storage.DefaultOrgID {
+			respondError(w, http.StatusForbidden, string(Forbidden), "only admins of the default organization may read metrics")
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+)
+
+// HTTPMetrics считает запросы к маршрутам и их длительность по маршруту и статусу ответа.
+type HTTPMetrics struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+// NewHTTPMetrics возвращает новый HTTPMetrics и регистрирует в registry счётчик
+// assigner_http_requests_total и гистограмму assigner_http_request_duration_seconds.
+func NewHTTPMetrics(registry *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.NewCounterVec("assigner_http_requests_total",
+			"HTTP requests by route and response status.", "route", "status"),
+		duration: registry.NewHistogramVec("assigner_http_request_duration_seconds",
+			"HTTP request latency by route and response status.", metrics.DefBuckets, "route", "status"),
+	}
+}
+
+// Observe учитывает запросы к маршруту route. Оборачивает обработчик целиком, поэтому
+// отказы аутентификации и лимитов тоже попадают в метрики.
+func (m *HTTPMetrics) Observe(route string, next http.HandlerFunc) http.HandlerFunc {
+	if m == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next(sw, r)
+
+		status := sw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		code := strconv.Itoa(status)
+		m.requests.Inc(route, code)
+		m.duration.Observe(time.Since(start).Seconds(), route, code)
+	}
+}
+
+// statusWriter запоминает статус ответа, передавая ответ клиенту.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap открывает исходный ResponseWriter для http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// NotificationHandler обрабатывает HTTP-запросы настройки чатов команд, писем пользователей
+// и очереди уведомлений.
+type NotificationHandler struct {
+	NotificationService *service.NotificationService
+}
+
+// NewNotificationHandler возвращает новый NotificationHandler.
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{NotificationService: notificationService}
+}
+
+// SetTeamChat обрабатывает POST /team/chat.
+func (h *NotificationHandler) SetTeamChat(w http.ResponseWriter, r *http.Request) {
+	var req dto.TeamChatRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.TeamName == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "team_name is required")
+		return
+	}
+
+	u, err := url.Parse(req.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "webhook_url must be an absolute http(s) URL")
+		return
+	}
+
+	chat, appErr := h.NotificationService.SetTeamChat(r.Context(), req.TeamName, req.WebhookURL)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team_chat": dto.FromStorageTeamChat(req.TeamName, chat),
+	})
+}
+
+// GetTeamChat обрабатывает GET /team/chat?team_name=.
+func (h *NotificationHandler) GetTeamChat(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "team_name is required")
+		return
+	}
+
+	chat, version, appErr := h.NotificationService.GetTeamChat(r.Context(), teamName)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	setETag(w, version)
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team_chat": dto.FromStorageTeamChat(teamName, chat),
+	})
+}
+
+// DeleteTeamChat обрабатывает DELETE /team/chat?team_name=.
+func (h *NotificationHandler) DeleteTeamChat(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "team_name is required")
+		return
+	}
+
+	if appErr := h.NotificationService.DeleteTeamChat(r.Context(), teamName); appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetEmailPreference обрабатывает POST /users/setEmailPreference.
+func (h *NotificationHandler) SetEmailPreference(w http.ResponseWriter, r *http.Request) {
+	var req dto.EmailPreferenceRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.UserID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "user_id is required")
+		return
+	}
+
+	mode := storage.EmailMode(req.Mode)
+	switch mode {
+	case storage.EmailImmediate, storage.EmailDigest, storage.EmailNone:
+	default:
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "mode must be one of immediate, digest, none")
+		return
+	}
+
+	email := req.Email
+	if mode != storage.EmailNone || email != "" {
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "email must be a valid address")
+			return
+		}
+		email = addr.Address
+	}
+
+	pref, appErr := h.NotificationService.SetEmailPreference(r.Context(), req.UserID, email, mode)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"email_preference": dto.FromStorageEmailPreference(pref),
+	})
+}
+
+// GetEmailPreference обрабатывает GET /users/getEmailPreference?user_id=.
+func (h *NotificationHandler) GetEmailPreference(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "user_id query parameter is required")
+		return
+	}
+
+	pref, appErr := h.NotificationService.GetEmailPreference(r.Context(), userID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"email_preference": dto.FromStorageEmailPreference(pref),
+	})
+}
+
+// List обрабатывает GET /notifications?status=&limit=.
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	status := storage.DeliveryStatus(q.Get("status"))
+	switch status {
+	case "", storage.DeliveryPending, storage.DeliveryDelivered, storage.DeliveryDead:
+	default:
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "status must be one of PENDING, DELIVERED, DEAD")
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxDeliveriesLimit {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	notifications, appErr := h.NotificationService.List(r.Context(), status, limit)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageNotifications(notifications))
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+)
+
+// OrgHandler обрабатывает HTTP-запросы управления организациями.
+type OrgHandler struct {
+	OrgService *service.OrgService
+}
+
+// NewOrgHandler возвращает новый OrgHandler.
+func NewOrgHandler(orgService *service.OrgService) *OrgHandler {
+	return &OrgHandler{OrgService: orgService}
+}
+
+// Create обрабатывает POST /admin/orgs.
+func (h *OrgHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.OrganizationRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "name is required")
+		return
+	}
+
+	org, appErr := h.OrgService.Create(r.Context(), req.Name)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]any{
+		"organization": dto.FromStorageOrganization(org),
+	})
+}
+
+// List обрабатывает GET /admin/orgs.
+func (h *OrgHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgs, appErr := h.OrgService.List(r.Context())
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageOrganizations(orgs))
+}
@@ -35,11 +35,56 @@ func (p *PRHandler) CreatePR(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusCreated, map[string]any{
 		"pr": dto.FromStoragePR(pr),
 	})
 }
 
+// GetPR обрабатывает GET /pullRequest/get; ETag - версия pr.
+func (p *PRHandler) GetPR(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "pull_request_id query parameter is required")
+		return
+	}
+
+	pr, appErr := p.PRService.GetPR(r.Context(), prID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	setETag(w, pr.Version)
+	respondJSON(w, http.StatusOK, map[string]any{
+		"pr": dto.FromStoragePR(pr),
+	})
+}
+
+// PreviewPR обрабатывает POST /pullRequest/preview.
+func (p *PRHandler) PreviewPR(w http.ResponseWriter, r *http.Request) {
+	var req dto.PreviewPRRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.AuthorID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "author_id is required")
+		return
+	}
+
+	preview, appErr := p.PRService.PreviewPR(r.Context(), req.PullRequestID, req.AuthorID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromPreview(req, preview))
+}
+
 // Merge обрабатывает POST /pullRequest/merge
 func (p *PRHandler) Merge(w http.ResponseWriter, r *http.Request) {
 	var req dto.MergeRequest
@@ -56,11 +101,50 @@ func (p *PRHandler) Merge(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusOK, map[string]any{
 		"pr": dto.FromStoragePR(pr),
 	})
 }
 
+// SubmitReview обрабатывает POST /pullRequest/review.
+func (p *PRHandler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "pull_request_id is required")
+		return
+	}
+	if !service.IsReviewState(req.State) {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "state must be approved, changes_requested or commented")
+		return
+	}
+	if req.ReviewerID == "" {
+		if caller, ok := service.CallerFrom(r.Context()); ok {
+			req.ReviewerID = caller.UserID
+		}
+	}
+	if req.ReviewerID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "reviewer_id is required")
+		return
+	}
+
+	appErr := p.PRService.SubmitReview(r.Context(), req.PullRequestID, req.ReviewerID, service.ReviewState(req.State))
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]any{
+		"review": req,
+	})
+}
+
 // ReassignReviewer обрабатывает POST /pullRequest/reassign.
 func (p *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 	var req dto.ReassignRequest
@@ -77,5 +161,42 @@ func (p *PRHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, pr.Version)
 	respondJSON(w, http.StatusOK, dto.FromStoragePRWithReplacedBy(pr, replacedBy))
 }
+
+// GetAssignmentLog обрабатывает GET /pullRequest/assignmentLog.
+func (p *PRHandler) GetAssignmentLog(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "pull_request_id query parameter is required")
+		return
+	}
+
+	entries, appErr := p.PRService.GetAssignmentLog(r.Context(), prID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageAssignmentLog(prID, entries))
+}
+
+// GetHistory обрабатывает GET /pullRequest/history.
+func (p *PRHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "pull_request_id query parameter is required")
+		return
+	}
+
+	events, appErr := p.PRService.GetHistory(r.Context(), prID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStoragePREvents(prID, events))
+}
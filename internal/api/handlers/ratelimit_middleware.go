@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+	"github.com/VechkanovVV/assigner-pr/internal/ratelimit"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+)
+
+// RateLimiter ограничивает частоту запросов клиента к маршруту (см. ratelimit.Limiter).
+// Клиент - API-токен или пользователь SSO из контекста (после AuthMiddleware), без них -
+// адрес клиента (service.RequestInfo).
+type RateLimiter struct {
+	limiter  *ratelimit.Limiter
+	rejected *metrics.CounterVec
+}
+
+// NewRateLimiter возвращает новый RateLimiter и регистрирует в registry счётчик
+// отклонённых запросов assigner_http_rate_limited_total.
+func NewRateLimiter(limiter *ratelimit.Limiter, registry *metrics.Registry) *RateLimiter {
+	return &RateLimiter{
+		limiter: limiter,
+		rejected: registry.NewCounterVec("assigner_http_rate_limited_total",
+			"Requests rejected by the rate limiter.", "route", "client"),
+	}
+}
+
+// Limit пропускает к next запросы в пределах лимита маршрута route; сверх лимита ответ 429
+// с Retry-After в секундах.
+func (l *RateLimiter) Limit(route string, next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		kind, client := requestClient(r)
+		ok, retryAfter := l.limiter.Allow(route, client)
+		if ok {
+			next(w, r)
+			return
+		}
+
+		l.rejected.Inc(route, kind)
+		seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		respondError(w, http.StatusTooManyRequests, string(RateLimited), "rate limit exceeded, retry in "+strconv.Itoa(seconds)+"s")
+	}
+}
+
+// requestClient возвращает вид клиента запроса (метка метрики) и его ключ: корзина
+// лимита и владелец ключей идемпотентности. API-токен определяется по id, а не по имени:
+// имена не уникальны и могут достаться новому токену после отзыва старого.
+func requestClient(r *http.Request) (kind, key string) {
+	if caller, ok := service.CallerFrom(r.Context()); ok {
+		if caller.UserID != "" {
+			return "user", "user:" + strconv.FormatInt(caller.OrgID, 10) + ":" + caller.UserID
+		}
+		return "token", "token:" + strconv.FormatInt(caller.TokenID, 10)
+	}
+	return "ip", "ip:" + service.RequestFrom(r.Context()).SourceIP
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+)
+
+// requestIDHeader - идентификатор запроса: принимается от клиента или прокси
+// и возвращается в ответе.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen ограничивает X-Request-ID клиента; более длинный заменяется своим.
+const maxRequestIDLen = 128
+
+// RequestInfo передаёт в контекст запроса его идентификатор и адрес клиента
+// (см. service.RequestInfo). Адрес берётся из соединения; с trustProxy - из первого
+// адреса X-Forwarded-For, который выставляет обратный прокси.
+func RequestInfo(next http.Handler, trustProxy bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		info := service.RequestInfo{ID: id, SourceIP: sourceIP(r, trustProxy)}
+		next.ServeHTTP(w, r.WithContext(service.WithRequest(r.Context(), info)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func sourceIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -12,8 +12,18 @@ import (
 // InvalidType - тип ошибок запроса.
 type InvalidType string
 
-// InvalidRequest - некорректный запрос.
-const InvalidRequest InvalidType = "INVALID_REQUEST"
+const (
+	// InvalidRequest - некорректный запрос.
+	InvalidRequest InvalidType = "INVALID_REQUEST"
+	// Unauthorized - подпись или учётные данные запроса не прошли проверку.
+	Unauthorized InvalidType = "UNAUTHORIZED"
+	// Forbidden - у вызывающего нет права на операцию.
+	Forbidden InvalidType = "FORBIDDEN"
+	// RateLimited - клиент превысил лимит запросов к маршруту.
+	RateLimited InvalidType = "RATE_LIMITED"
+	// TooLarge - тело запроса превышает допустимый размер.
+	TooLarge InvalidType = "REQUEST_TOO_LARGE"
+)
 
 // respondJSON отправляет JSON-ответ с заданным статусом.
 func respondJSON(w http.ResponseWriter, status int, data any) {
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// SLAHandler обрабатывает HTTP-запросы сроков ревью команд и SLA PR.
+type SLAHandler struct {
+	SLAService *service.SLAService
+}
+
+// NewSLAHandler возвращает новый SLAHandler.
+func NewSLAHandler(slaService *service.SLAService) *SLAHandler {
+	return &SLAHandler{SLAService: slaService}
+}
+
+// SetTeamSLA обрабатывает POST /team/sla.
+func (h *SLAHandler) SetTeamSLA(w http.ResponseWriter, r *http.Request) {
+	var req dto.TeamSLARequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.TeamName == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "team_name is required")
+		return
+	}
+
+	within, err := time.ParseDuration(req.Within)
+	if err != nil || within <= 0 {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "within must be a positive duration")
+		return
+	}
+
+	sla := storage.ReviewSLA{Within: within, Escalation: storage.SLAEscalation(req.Escalation), LeadID: req.LeadID}
+	if sla.Escalation == "" {
+		sla.Escalation = storage.EscalateNone
+	}
+	switch sla.Escalation {
+	case storage.EscalateNone:
+	case storage.EscalateReassign, storage.EscalateLead:
+		escalateAfter, err := time.ParseDuration(req.EscalateAfter)
+		if err != nil || escalateAfter <= within {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "escalate_after must be a duration longer than within")
+			return
+		}
+		sla.EscalateAfter = escalateAfter
+	default:
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "escalation must be one of none, reassign, lead")
+		return
+	}
+	if sla.Escalation == storage.EscalateLead && sla.LeadID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "lead_id is required for lead escalation")
+		return
+	}
+
+	saved, appErr := h.SLAService.SetTeamSLA(r.Context(), req.TeamName, sla)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team_sla": dto.FromStorageReviewSLA(req.TeamName, saved),
+	})
+}
+
+// GetTeamSLA обрабатывает GET /team/sla?team_name=.
+func (h *SLAHandler) GetTeamSLA(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "team_name is required")
+		return
+	}
+
+	sla, version, appErr := h.SLAService.GetTeamSLA(r.Context(), teamName)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	setETag(w, version)
+	respondJSON(w, http.StatusOK, map[string]any{
+		"team_sla": dto.FromStorageReviewSLA(teamName, sla),
+	})
+}
+
+// DeleteTeamSLA обрабатывает DELETE /team/sla?team_name=.
+func (h *SLAHandler) DeleteTeamSLA(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "team_name is required")
+		return
+	}
+
+	if appErr := h.SLAService.DeleteTeamSLA(r.Context(), teamName); appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPRSLA обрабатывает GET /pullRequest/sla?pull_request_id=.
+func (h *SLAHandler) GetPRSLA(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "pull_request_id query parameter is required")
+		return
+	}
+
+	prSLA, appErr := h.SLAService.GetPRSLA(r.Context(), prID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromServicePRSLA(prSLA))
+}
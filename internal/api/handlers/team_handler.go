@@ -18,7 +18,7 @@ func NewTeamHandler(teamService *service.TeamService) *TeamHandler {
 	return &TeamHandler{TeamService: teamService}
 }
 
-// CreateTeam обрабатывает создание команды.
+// CreateTeam обрабатывает создание команды; ETag - версия созданной команды.
 func (t *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 	var req dto.TeamRequest
 
@@ -34,23 +34,18 @@ func (t *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 
 	team := req.ToStorageTeam()
 
-	if appErr := t.TeamService.CreateTeam(r.Context(), team); appErr != nil {
-		respondAppError(w, appErr)
-		return
-	}
-
-	ct, appErr := t.TeamService.GetTeamByName(r.Context(), req.TeamName)
-
+	ct, appErr := t.TeamService.CreateTeam(r.Context(), team)
 	if appErr != nil {
 		respondAppError(w, appErr)
 		return
 	}
+	setETag(w, ct.Version)
 	respondJSON(w, http.StatusCreated, map[string]any{
 		"team": dto.FromStorageTeam(ct),
 	})
 }
 
-// GetTeam поиск команды по имени.
+// GetTeam поиск команды по имени; ETag - версия команды.
 func (t *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 	tName := r.URL.Query().Get("team_name")
 
@@ -65,5 +60,6 @@ func (t *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
 		respondAppError(w, appErr)
 		return
 	}
+	setETag(w, team.Version)
 	respondJSON(w, http.StatusOK, dto.FromStorageTeam(team))
 }
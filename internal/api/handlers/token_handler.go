@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// TokenHandler обрабатывает HTTP-запросы выпуска и отзыва API-токенов.
+type TokenHandler struct {
+	TokenService *service.TokenService
+}
+
+// NewTokenHandler возвращает новый TokenHandler.
+func NewTokenHandler(tokenService *service.TokenService) *TokenHandler {
+	return &TokenHandler{TokenService: tokenService}
+}
+
+// Issue обрабатывает POST /admin/tokens.
+func (h *TokenHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req dto.APITokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	if req.Name == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "name is required")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "scopes must not be empty")
+		return
+	}
+
+	scopes := make([]storage.TokenScope, 0, len(req.Scopes))
+	for _, s := range req.Scopes {
+		if !service.IsTokenScope(s) {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "unknown scope: "+s)
+			return
+		}
+		scopes = append(scopes, storage.TokenScope(s))
+	}
+
+	token, secret, appErr := h.TokenService.Issue(r.Context(), req.Name, scopes)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]any{
+		"token": dto.FromStorageAPIToken(token, secret),
+	})
+}
+
+// List обрабатывает GET /admin/tokens.
+func (h *TokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	tokens, appErr := h.TokenService.List(r.Context())
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageAPITokens(tokens))
+}
+
+// Revoke обрабатывает DELETE /admin/tokens?id=.
+func (h *TokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "id must be a positive integer")
+		return
+	}
+
+	if appErr := h.TokenService.Revoke(r.Context(), id); appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Whoami обрабатывает GET /auth/whoami.
+func (h *TokenHandler) Whoami(w http.ResponseWriter, r *http.Request) {
+	caller, ok := service.CallerFrom(r.Context())
+	if !ok {
+		unauthorized(w, "request is not authenticated")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromServiceCaller(caller))
+}
@@ -22,7 +22,7 @@ func NewUserHandler(userService *service.UserService, teamService *service.TeamS
 	}
 }
 
-// SetActiveStatus устанавливает флаг активности пользователя.
+// SetActiveStatus устанавливает флаг активности пользователя; ETag - новая версия пользователя.
 func (u *UserHandler) SetActiveStatus(w http.ResponseWriter, r *http.Request) {
 	var req dto.SetActiveRequest
 
@@ -48,14 +48,33 @@ func (u *UserHandler) SetActiveStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, map[string]any{
-		"user": dto.UserDetail{
-			UserID:   user.ID,
-			Username: user.Username,
-			TeamName: team.TeamName,
-			IsActive: user.IsActive,
-		},
-	})
+	setETag(w, user.Version)
+	respondJSON(w, http.StatusOK, dto.UserResponse{User: dto.FromStorageUser(user, team.TeamName)})
+}
+
+// GetUser обрабатывает GET /users/get; ETag - версия пользователя.
+func (u *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+
+	if userID == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "user_id query parameter is required")
+		return
+	}
+
+	user, appErr := u.UserService.GetUser(r.Context(), userID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	team, appErr := u.TeamService.GetTeamByID(r.Context(), user.TeamID)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	setETag(w, user.Version)
+	respondJSON(w, http.StatusOK, dto.UserResponse{User: dto.FromStorageUser(user, team.TeamName)})
 }
 
 // GetUserReviews - GET /users/getReview.
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// defaultDeliveriesLimit и maxDeliveriesLimit ограничивают выдачу GET /webhooks/deliveries.
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// WebhookHandler обрабатывает HTTP-запросы управления подписчиками вебхуков.
+type WebhookHandler struct {
+	WebhookService *service.WebhookService
+}
+
+// NewWebhookHandler возвращает новый WebhookHandler.
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: webhookService}
+}
+
+// Register обрабатывает POST /webhooks.
+func (h *WebhookHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req dto.WebhookRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "invalid JSON")
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "url must be an absolute http(s) URL")
+		return
+	}
+
+	for _, typ := range req.EventTypes {
+		if !service.IsDomainEventType(typ) {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "unknown event type: "+typ)
+			return
+		}
+	}
+
+	webhook, appErr := h.WebhookService.Register(r.Context(), req.URL, req.Secret, req.EventTypes)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, map[string]any{
+		"webhook": dto.FromStorageWebhook(webhook, true),
+	})
+}
+
+// List обрабатывает GET /webhooks.
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	webhooks, appErr := h.WebhookService.List(r.Context())
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageWebhooks(webhooks))
+}
+
+// ListDeliveries обрабатывает GET /webhooks/deliveries?webhook_id=&status=&limit=.
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var webhookID int64
+	if raw := q.Get("webhook_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "webhook_id must be a positive integer")
+			return
+		}
+		webhookID = id
+	}
+
+	status := storage.DeliveryStatus(q.Get("status"))
+	switch status {
+	case "", storage.DeliveryPending, storage.DeliveryDelivered, storage.DeliveryDead:
+	default:
+		respondError(w, http.StatusBadRequest, string(InvalidRequest), "status must be one of PENDING, DELIVERED, DEAD")
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxDeliveriesLimit {
+			respondError(w, http.StatusBadRequest, string(InvalidRequest), "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	deliveries, appErr := h.WebhookService.ListDeliveries(r.Context(), webhookID, status, limit)
+	if appErr != nil {
+		respondAppError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, dto.FromStorageDeliveries(deliveries))
+}
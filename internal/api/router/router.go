@@ -3,30 +3,110 @@ package router
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/VechkanovVV/assigner-pr/internal/api/handlers"
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
 )
 
-// NewRouter создаёт HTTP router с зарегистрированными маршрутами.
+// NewRouter создаёт HTTP router с зарегистрированными маршрутами. Маршруты API требуют
+// токен с указанным правом (см. handlers.AuthMiddleware); /health и входящие вебхуки
+// код-хостингов, проверяющие свою подпись, открыты. Каждый запрос, кроме /health,
+// работает с данными одной организации.
+//
+// Запросы к маршрутам, кроме /health и /metrics, учитываются в httpMetrics и ограничены
+// limiter по вызывающему (nil - без метрик и без ограничения); /metrics отдаёт метрики
+// registry администраторам (см. handlers.Metrics). POST-маршруты принимают заголовок
+// Idempotency-Key (см. handlers.Idempotency). Изменения версионируемых команд,
+// пользователей и PR принимают If-Match (см. handlers.IfMatch).
 func NewRouter(
+	auth *handlers.AuthMiddleware,
+	httpMetrics *handlers.HTTPMetrics,
+	limiter *handlers.RateLimiter,
+	idempotency *handlers.Idempotency,
+	registry *metrics.Registry,
 	teamHandler *handlers.TeamHandler,
 	userHandler *handlers.UserHandler,
 	prHandler *handlers.PRHandler,
 	statsHandler *handlers.StatsHandler,
+	webhookHandler *handlers.WebhookHandler,
+	integrationHandler *handlers.IntegrationHandler,
+	notificationHandler *handlers.NotificationHandler,
+	slaHandler *handlers.SLAHandler,
+	tokenHandler *handlers.TokenHandler,
+	orgHandler *handlers.OrgHandler,
+	auditHandler *handlers.AuditHandler,
 ) http.Handler {
 	mux := http.NewServeMux()
+	wrap := func(pattern string, h http.HandlerFunc) http.HandlerFunc {
+		if strings.HasPrefix(pattern, http.MethodPost+" ") {
+			h = idempotency.Wrap(h)
+		}
+		return limiter.Limit(pattern, h)
+	}
+	handle := func(pattern string, scope storage.TokenScope, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, httpMetrics.Observe(pattern, auth.Require(scope, wrap(pattern, h))))
+	}
+	// Входящие вебхуки без токена: организацию определяет секрет подписи (см. handlers.IntegrationHandler).
+	public := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, httpMetrics.Observe(pattern, wrap(pattern, h)))
+	}
+
+	handle("POST /team/add", storage.ScopeTeamAdmin, teamHandler.CreateTeam)
+	handle("GET /team/get", storage.ScopeRead, teamHandler.GetTeam)
+	handle("POST /team/chat", storage.ScopeTeamAdmin, handlers.IfMatch(notificationHandler.SetTeamChat))
+	handle("GET /team/chat", storage.ScopeRead, notificationHandler.GetTeamChat)
+	handle("DELETE /team/chat", storage.ScopeTeamAdmin, handlers.IfMatch(notificationHandler.DeleteTeamChat))
+	handle("POST /team/sla", storage.ScopeTeamAdmin, handlers.IfMatch(slaHandler.SetTeamSLA))
+	handle("GET /team/sla", storage.ScopeRead, slaHandler.GetTeamSLA)
+	handle("DELETE /team/sla", storage.ScopeTeamAdmin, handlers.IfMatch(slaHandler.DeleteTeamSLA))
+
+	// Право проверяет Policy.CanSetActive: свою активность пользователь меняет сам.
+	handle("POST /users/setIsActive", "", handlers.IfMatch(userHandler.SetActiveStatus))
+	handle("GET /users/get", storage.ScopeRead, userHandler.GetUser)
+	handle("GET /users/getReview", storage.ScopeRead, userHandler.GetUserReviews)
+	// Право проверяет Policy.CanSetEmailPreference: свои письма пользователь настраивает сам.
+	handle("POST /users/setEmailPreference", "", notificationHandler.SetEmailPreference)
+	handle("GET /users/getEmailPreference", storage.ScopeRead, notificationHandler.GetEmailPreference)
+
+	handle("POST /pullRequest/create", storage.ScopePRWrite, prHandler.CreatePR)
+	handle("GET /pullRequest/get", storage.ScopeRead, prHandler.GetPR)
+	handle("POST /pullRequest/preview", storage.ScopeRead, prHandler.PreviewPR)
+	handle("POST /pullRequest/merge", storage.ScopePRWrite, handlers.IfMatch(prHandler.Merge))
+	handle("POST /pullRequest/reassign", storage.ScopePRWrite, handlers.IfMatch(prHandler.ReassignReviewer))
+	handle("POST /pullRequest/review", storage.ScopePRWrite, handlers.IfMatch(prHandler.SubmitReview))
+	handle("GET /pullRequest/assignmentLog", storage.ScopeRead, prHandler.GetAssignmentLog)
+	handle("GET /pullRequest/history", storage.ScopeRead, prHandler.GetHistory)
+	handle("GET /pullRequest/sla", storage.ScopeRead, slaHandler.GetPRSLA)
+
+	handle("GET /stats/assignments", storage.ScopeStatsRead, statsHandler.GetAssignments)
+
+	handle("POST /webhooks", storage.ScopeAdmin, webhookHandler.Register)
+	handle("GET /webhooks", storage.ScopeAdmin, webhookHandler.List)
+	handle("GET /webhooks/deliveries", storage.ScopeAdmin, webhookHandler.ListDeliveries)
+
+	handle("GET /notifications", storage.ScopeAdmin, notificationHandler.List)
+
+	public("POST /integrations/github/webhook", integrationHandler.GitHubWebhook)
+	public("POST /integrations/gitlab/webhook", integrationHandler.GitLabWebhook)
+	handle("POST /integrations/identities", storage.ScopeTeamAdmin, integrationHandler.LinkIdentity)
+	handle("POST /integrations/secrets", storage.ScopeAdmin, integrationHandler.RotateSecret)
+	handle("DELETE /integrations/secrets", storage.ScopeAdmin, integrationHandler.DeleteSecret)
+
+	handle("GET /auth/whoami", "", tokenHandler.Whoami)
 
-	mux.HandleFunc("POST /team/add", teamHandler.CreateTeam)
-	mux.HandleFunc("GET /team/get", teamHandler.GetTeam)
+	handle("POST /admin/tokens", storage.ScopeAdmin, tokenHandler.Issue)
+	handle("GET /admin/tokens", storage.ScopeAdmin, tokenHandler.List)
+	handle("DELETE /admin/tokens", storage.ScopeAdmin, tokenHandler.Revoke)
 
-	mux.HandleFunc("POST /users/setIsActive", userHandler.SetActiveStatus)
-	mux.HandleFunc("GET /users/getReview", userHandler.GetUserReviews)
+	// Право проверяет OrgService: организациями управляют администраторы организации по умолчанию.
+	handle("POST /admin/orgs", storage.ScopeAdmin, orgHandler.Create)
+	handle("GET /admin/orgs", storage.ScopeAdmin, orgHandler.List)
 
-	mux.HandleFunc("POST /pullRequest/create", prHandler.CreatePR)
-	mux.HandleFunc("POST /pullRequest/merge", prHandler.Merge)
-	mux.HandleFunc("POST /pullRequest/reassign", prHandler.ReassignReviewer)
+	handle("GET /admin/audit", storage.ScopeAdmin, auditHandler.List)
 
-	mux.HandleFunc("GET /stats/assignments", statsHandler.GetAssignments)
+	mux.HandleFunc("GET /metrics", auth.Require(storage.ScopeAdmin, handlers.Metrics(registry)))
 
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
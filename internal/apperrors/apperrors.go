@@ -34,35 +34,62 @@ func (e *AppError) HTTPStatus() int {
 
 // Коды ошибок
 const (
-	ErrTeamExists    Code = "TEAM_EXISTS"
-	ErrPRExists      Code = "PR_EXISTS"
-	ErrPRMerged      Code = "PR_MERGED"
-	ErrNotAssigned   Code = "NOT_ASSIGNED"
-	ErrNoCandidate   Code = "NO_CANDIDATE"
-	ErrNotFound      Code = "NOT_FOUND"
-	ErrInternalIssue Code = "INTERNAL_ISSUE"
+	ErrTeamExists     Code = "TEAM_EXISTS"
+	ErrPRExists       Code = "PR_EXISTS"
+	ErrPRMerged       Code = "PR_MERGED"
+	ErrPRClosed       Code = "PR_CLOSED"
+	ErrPRInvalidState Code = "PR_INVALID_STATE"
+	ErrNotAssigned    Code = "NOT_ASSIGNED"
+	ErrNoCandidate    Code = "NO_CANDIDATE"
+	ErrNotFound       Code = "NOT_FOUND"
+	ErrForbidden      Code = "FORBIDDEN"
+	ErrOrgExists      Code = "ORG_EXISTS"
+	ErrInternalIssue  Code = "INTERNAL_ISSUE"
+
+	ErrIdempotencyKeyReused  Code = "IDEMPOTENCY_KEY_REUSED"
+	ErrIdempotencyInProgress Code = "IDEMPOTENCY_IN_PROGRESS"
+
+	ErrVersionConflict Code = "VERSION_CONFLICT"
 )
 
 // messages - человекочитаемые строки по коду.
 var messages = map[Code]string{
-	ErrTeamExists:    "team_name already exists",
-	ErrPRExists:      "PR id already exists",
-	ErrPRMerged:      "cannot reassign on merged PR",
-	ErrNotAssigned:   "reviewer is not assigned to this PR",
-	ErrNoCandidate:   "no active replacement candidate in team",
-	ErrNotFound:      "resource not found",
-	ErrInternalIssue: "internal server issue, please try again",
+	ErrTeamExists:     "team_name already exists",
+	ErrPRExists:       "PR id already exists",
+	ErrPRMerged:       "cannot reassign on merged PR",
+	ErrPRClosed:       "PR is closed",
+	ErrPRInvalidState: "PR status does not allow this transition",
+	ErrNotAssigned:    "reviewer is not assigned to this PR",
+	ErrNoCandidate:    "no active replacement candidate in team",
+	ErrNotFound:       "resource not found",
+	ErrForbidden:      "caller is not allowed to perform this operation",
+	ErrOrgExists:      "organization already exists",
+	ErrInternalIssue:  "internal server issue, please try again",
+
+	ErrIdempotencyKeyReused:  "Idempotency-Key was already used for a different request",
+	ErrIdempotencyInProgress: "request with this Idempotency-Key is still in progress",
+
+	ErrVersionConflict: "resource was modified, If-Match version is stale",
 }
 
 // statusByCode - HTTP-статусы по коду.
 var statusByCode = map[Code]int{
-	ErrTeamExists:    http.StatusBadRequest,
-	ErrPRExists:      http.StatusConflict,
-	ErrPRMerged:      http.StatusConflict,
-	ErrNotAssigned:   http.StatusConflict,
-	ErrNoCandidate:   http.StatusConflict,
-	ErrNotFound:      http.StatusNotFound,
-	ErrInternalIssue: http.StatusInternalServerError,
+	ErrTeamExists:     http.StatusBadRequest,
+	ErrPRExists:       http.StatusConflict,
+	ErrPRMerged:       http.StatusConflict,
+	ErrPRClosed:       http.StatusConflict,
+	ErrPRInvalidState: http.StatusConflict,
+	ErrNotAssigned:    http.StatusConflict,
+	ErrNoCandidate:    http.StatusConflict,
+	ErrNotFound:       http.StatusNotFound,
+	ErrForbidden:      http.StatusForbidden,
+	ErrOrgExists:      http.StatusConflict,
+	ErrInternalIssue:  http.StatusInternalServerError,
+
+	ErrIdempotencyKeyReused:  http.StatusConflict,
+	ErrIdempotencyInProgress: http.StatusConflict,
+
+	ErrVersionConflict: http.StatusPreconditionFailed,
 }
 
 // New создаёт AppError по коду.
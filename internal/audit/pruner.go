@@ -0,0 +1,59 @@
+// Package audit следит за сроком хранения журнала административных действий.
+package audit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Config - параметры очистки журнала.
+type Config struct {
+	// Retention - сколько хранятся записи.
+	Retention time.Duration
+	// PollInterval - период очистки.
+	PollInterval time.Duration
+}
+
+// Pruner периодически удаляет записи журнала аудита всех организаций старше Retention.
+type Pruner struct {
+	repo storage.AuditLogRepository
+	now  func() time.Time
+	cfg  Config
+}
+
+// NewPruner создаёт новый Pruner.
+func NewPruner(repo storage.AuditLogRepository, cfg Config) *Pruner {
+	return &Pruner{repo: repo, now: time.Now, cfg: cfg}
+}
+
+// Run очищает журнал до отмены ctx.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.PruneOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneOnce удаляет устаревшие записи и возвращает их число.
+func (p *Pruner) PruneOnce(ctx context.Context) int64 {
+	deleted, err := p.repo.DeleteBefore(ctx, p.now().Add(-p.cfg.Retention))
+	if err != nil {
+		log.Printf("audit log pruning failed: %v", err)
+		return 0
+	}
+	if deleted > 0 {
+		log.Printf("audit log: deleted %d entries older than %s", deleted, p.cfg.Retention)
+	}
+	return deleted
+}
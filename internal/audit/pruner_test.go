@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestPrunerDeletesEntriesOlderThanRetention(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	repo := memory.NewAuditLogRepository(store)
+	acme, err := memory.NewOrganizationRepository(store).Create(ctx, "acme")
+	require.Nil(t, err)
+
+	require.Nil(t, repo.Add(ctx, storage.AuditEntry{Action: storage.AuditTeamCreate, Actor: "admin", TargetType: "team", TargetID: "backend"}))
+	require.Nil(t, repo.Add(storage.WithOrg(ctx, acme.ID), storage.AuditEntry{Action: storage.AuditTeamCreate, Actor: "admin", TargetType: "team", TargetID: "backend"}))
+
+	pruner := NewPruner(repo, Config{Retention: 24 * time.Hour, PollInterval: time.Hour})
+	clock := time.Now()
+	pruner.now = func() time.Time { return clock }
+
+	require.Zero(t, pruner.PruneOnce(ctx), "entries within retention are kept")
+
+	clock = clock.Add(25 * time.Hour)
+	require.EqualValues(t, 2, pruner.PruneOnce(ctx), "retention applies to every organization")
+
+	entries, err := repo.List(ctx, storage.AuditFilter{Limit: 10})
+	require.Nil(t, err)
+	require.Empty(t, entries)
+}
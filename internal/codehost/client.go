@@ -0,0 +1,119 @@
+package codehost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBody ограничивает чтение ответа API.
+const maxResponseBody = 1 << 20
+
+// StatusError - API код-хостинга ответил кодом не из 2xx.
+type StatusError struct {
+	Method string
+	URL    string
+	Body   string
+	Status int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d: %s", e.Method, e.URL, e.Status, e.Body)
+}
+
+// Permanent сообщает, что повтор запроса не поможет: ошибка клиента, кроме таймаута
+// и превышения лимита запросов.
+func (e *StatusError) Permanent() bool {
+	return e.Status >= 400 && e.Status < 500 &&
+		e.Status != http.StatusRequestTimeout && e.Status != http.StatusTooManyRequests
+}
+
+// IsPermanent сообщает, что err не исправится повтором запроса.
+func IsPermanent(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Permanent()
+	}
+	var permErr *permanentError
+	return errors.As(err, &permErr)
+}
+
+// permanentError - ошибка, которую повтор не исправит: неверный id pr, неизвестный
+// пользователь или ненастроенный провайдер.
+type permanentError struct {
+	msg string
+}
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+func permanentf(format string, args ...any) error {
+	return &permanentError{msg: fmt.Sprintf(format, args...)}
+}
+
+// apiClient выполняет JSON-запросы к REST API с общими заголовками.
+type apiClient struct {
+	client  *http.Client
+	header  http.Header
+	baseURL string
+}
+
+func newAPIClient(client *http.Client, baseURL string, header http.Header) apiClient {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return apiClient{client: client, baseURL: strings.TrimRight(baseURL, "/"), header: header}
+}
+
+// do отправляет in как JSON-тело (если не nil) и декодирует ответ в out (если не nil).
+// path должен быть уже экранирован.
+func (a apiClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	url := a.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	for key, values := range a.header {
+		req.Header[key] = values
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return fmt.Errorf("%s %s: read response: %w", method, url, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{Method: method, URL: url, Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", method, url, err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package codehost
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type apiCall struct {
+	header http.Header
+	method string
+	path   string
+	query  string
+	body   string
+}
+
+type apiResponse struct {
+	body   string
+	status int
+}
+
+// fakeAPI - локальный REST API код-хостинга: отвечает заданными ответами на
+// "METHOD /escaped/path" и записывает все запросы.
+type fakeAPI struct {
+	responses map[string][]apiResponse
+	calls     []apiCall
+	mu        sync.Mutex
+}
+
+func newFakeAPI(t *testing.T) (*fakeAPI, *httptest.Server) {
+	t.Helper()
+	api := &fakeAPI{responses: make(map[string][]apiResponse)}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+	return api, srv
+}
+
+// on добавляет ответ на запрос; последний ответ повторяется для всех следующих запросов.
+func (f *fakeAPI) on(method, path string, status int, body string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := method + " " + path
+	f.responses[key] = append(f.responses[key], apiResponse{status: status, body: body})
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, apiCall{
+		method: req.Method,
+		path:   req.URL.EscapedPath(),
+		query:  req.URL.RawQuery,
+		header: req.Header.Clone(),
+		body:   string(body),
+	})
+	key := req.Method + " " + req.URL.EscapedPath()
+	resp := apiResponse{status: http.StatusNotFound, body: `{"message":"Not Found"}`}
+	if queue := f.responses[key]; len(queue) > 0 {
+		resp = queue[0]
+		if len(queue) > 1 {
+			f.responses[key] = queue[1:]
+		}
+	}
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.status)
+	_, _ = io.WriteString(w, resp.body)
+}
+
+func (f *fakeAPI) recorded() []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]apiCall(nil), f.calls...)
+}
+
+func TestParsePullRequestIDs(t *testing.T) {
+	repo, number, ok := ParseGitHubPullRequestID(GitHubPullRequestID("acme/widgets", 42))
+	require.True(t, ok)
+	require.Equal(t, "acme/widgets", repo)
+	require.Equal(t, 42, number)
+
+	project, iid, ok := ParseGitLabMergeRequestID(GitLabMergeRequestID("group/sub/project", 7))
+	require.True(t, ok)
+	require.Equal(t, "group/sub/project", project)
+	require.Equal(t, 7, iid)
+
+	for _, id := range []string{"pr-1", "github:acme/widgets", "github:#1", "github:acme/widgets#0", "gitlab:group/project!1"} {
+		_, _, ok := ParseGitHubPullRequestID(id)
+		require.False(t, ok, id)
+	}
+	for _, id := range []string{"pr-1", "gitlab:group/project", "gitlab:!1", "gitlab:group/project!x", "github:acme/widgets#1"} {
+		_, _, ok := ParseGitLabMergeRequestID(id)
+		require.False(t, ok, id)
+	}
+}
+
+func TestGitHubClientRequestsReviewers(t *testing.T) {
+	api, srv := newFakeAPI(t)
+	const path = "/repos/acme/widgets/pulls/42/requested_reviewers"
+	api.on(http.MethodDelete, path, http.StatusOK, `{}`)
+	api.on(http.MethodPost, path, http.StatusCreated, `{}`)
+
+	client := NewGitHubClient(srv.Client(), srv.URL+"/", "gh-token")
+	err := client.RequestReviewers(context.Background(), "github:acme/widgets#42", []string{"octocat", "hubot"}, []string{"monalisa"})
+	require.NoError(t, err)
+
+	calls := api.recorded()
+	require.Len(t, calls, 2)
+	require.Equal(t, http.MethodDelete, calls[0].method)
+	require.Equal(t, path, calls[0].path)
+	require.JSONEq(t, `{"reviewers":["monalisa"]}`, calls[0].body)
+	require.Equal(t, http.MethodPost, calls[1].method)
+	require.JSONEq(t, `{"reviewers":["octocat","hubot"]}`, calls[1].body)
+
+	for _, c := range calls {
+		require.Equal(t, "Bearer gh-token", c.header.Get("Authorization"))
+		require.Equal(t, "application/vnd.github+json", c.header.Get("Accept"))
+		require.Equal(t, "2022-11-28", c.header.Get("X-GitHub-Api-Version"))
+		require.Equal(t, "application/json", c.header.Get("Content-Type"))
+	}
+}
+
+func TestGitHubClientErrors(t *testing.T) {
+	api, srv := newFakeAPI(t)
+	const path = "/repos/acme/widgets/pulls/42/requested_reviewers"
+	api.on(http.MethodPost, path, http.StatusUnprocessableEntity, `{"message":"Reviews may only be requested from collaborators."}`)
+	api.on(http.MethodPost, path, http.StatusBadGateway, `bad gateway`)
+	client := NewGitHubClient(srv.Client(), srv.URL, "gh-token")
+	ctx := context.Background()
+
+	err := client.RequestReviewers(ctx, "github:acme/widgets#42", []string{"stranger"}, nil)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusUnprocessableEntity, statusErr.Status)
+	require.Contains(t, statusErr.Body, "collaborators")
+	require.True(t, IsPermanent(err))
+
+	err = client.RequestReviewers(ctx, "github:acme/widgets#42", []string{"octocat"}, nil)
+	require.Error(t, err)
+	require.False(t, IsPermanent(err), "5xx is retried")
+
+	err = client.RequestReviewers(ctx, "pr-1", []string{"octocat"}, nil)
+	require.Error(t, err)
+	require.True(t, IsPermanent(err))
+
+	require.NoError(t, client.RequestReviewers(ctx, "github:acme/widgets#42", nil, nil))
+	require.Len(t, api.recorded(), 2, "nothing to send makes no requests")
+}
+
+func TestGitLabClientSetsReviewers(t *testing.T) {
+	api, srv := newFakeAPI(t)
+	const path = "/api/v4/projects/group%2Fsub%2Fproject/merge_requests/7"
+	api.on(http.MethodGet, path, http.StatusOK, `{"iid":7,"reviewers":[{"id":1,"username":"root"},{"id":5,"username":"old"}]}`)
+	api.on(http.MethodGet, "/api/v4/users", http.StatusOK, `[{"id":9,"username":"alice"}]`)
+	api.on(http.MethodGet, "/api/v4/users", http.StatusOK, `[{"id":1,"username":"root"}]`)
+	api.on(http.MethodPut, path, http.StatusOK, `{}`)
+
+	client := NewGitLabClient(srv.Client(), srv.URL, "gl-token")
+	err := client.RequestReviewers(context.Background(), "gitlab:group/sub/project!7", []string{"alice", "root"}, []string{"old"})
+	require.NoError(t, err)
+
+	calls := api.recorded()
+	require.Len(t, calls, 4)
+	require.Equal(t, http.MethodGet, calls[0].method)
+	require.Equal(t, path, calls[0].path)
+	require.Equal(t, "username=alice", calls[1].query)
+	require.Equal(t, "username=root", calls[2].query)
+	require.Equal(t, http.MethodPut, calls[3].method)
+	require.JSONEq(t, `{"reviewer_ids":[1,9]}`, calls[3].body, "manual reviewers stay, removed ones go, no duplicates")
+
+	for _, c := range calls {
+		require.Equal(t, "gl-token", c.header.Get("PRIVATE-TOKEN"))
+	}
+}
+
+func TestGitLabClientUnknownUser(t *testing.T) {
+	api, srv := newFakeAPI(t)
+	const path = "/api/v4/projects/group%2Fproject/merge_requests/1"
+	api.on(http.MethodGet, path, http.StatusOK, `{"reviewers":[]}`)
+	api.on(http.MethodGet, "/api/v4/users", http.StatusOK, `[]`)
+
+	client := NewGitLabClient(srv.Client(), srv.URL, "gl-token")
+	err := client.RequestReviewers(context.Background(), "gitlab:group/project!1", []string{"ghost"}, nil)
+	require.Error(t, err)
+	require.True(t, IsPermanent(err))
+
+	for _, c := range api.recorded() {
+		require.NotEqual(t, http.MethodPut, c.method, "reviewers are not overwritten on failure")
+	}
+}
@@ -0,0 +1,20 @@
+// Package codehost - интеграция с код-хостингами (GitHub, GitLab): разбор входящих
+// вебхуков в service.CodeHostEvent и клиенты REST API, которые выставляют назначенных
+// ревьюеров на реальном PR (Syncer).
+package codehost
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrSignature - подпись или токен доставки не совпали с секретом.
+var ErrSignature = errors.New("invalid webhook signature")
+
+// bodyDeliveryID - идентификатор доставки, если провайдер его не передал: хэш тела,
+// который одинаков при повторной доставке того же события.
+func bodyDeliveryID(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package codehost
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+const testSecret = "It's a Secret to Everybody"
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return body
+}
+
+func githubHeader(event, delivery string, body []byte, secret string) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	h := http.Header{}
+	h.Set(GitHubEventHeader, event)
+	h.Set(GitHubDeliveryHeader, delivery)
+	h.Set(GitHubSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return h
+}
+
+func gitlabHeader(token, uuid string) http.Header {
+	h := http.Header{}
+	h.Set(GitLabEventHeader, "Merge Request Hook")
+	h.Set(GitLabTokenHeader, token)
+	h.Set(GitLabDeliveryHeader, uuid)
+	return h
+}
+
+func TestParseGitHub(t *testing.T) {
+	tests := []struct {
+		fixture string
+		action  service.CodeHostAction
+		sender  string
+	}{
+		{"github_pull_request_opened.json", service.ActionOpened, "octocat"},
+		{"github_pull_request_reopened.json", service.ActionReopened, "octocat"},
+		{"github_pull_request_closed.json", service.ActionClosed, "hubot"},
+		{"github_pull_request_merged.json", service.ActionMerged, "hubot"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			body := fixture(t, tc.fixture)
+
+			ev, ok, err := ParseGitHub(githubHeader("pull_request", "d-1", body, testSecret), body, testSecret)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, service.CodeHostEvent{
+				Provider:      storage.ProviderGitHub,
+				DeliveryID:    "d-1",
+				Action:        tc.action,
+				PullRequestID: "github:acme/widgets#42",
+				Title:         "Add retry to webhook dispatcher",
+				AuthorLogin:   "octocat",
+				SenderLogin:   tc.sender,
+			}, ev)
+		})
+	}
+}
+
+func TestParseGitHubIgnoresOtherEvents(t *testing.T) {
+	body := fixture(t, "github_ping.json")
+	_, ok, err := ParseGitHub(githubHeader("ping", "d-1", body, testSecret), body, testSecret)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	body = fixture(t, "github_pull_request_synchronize.json")
+	_, ok, err = ParseGitHub(githubHeader("pull_request", "d-2", body, testSecret), body, testSecret)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestParseGitHubRejectsBadSignature(t *testing.T) {
+	body := fixture(t, "github_pull_request_opened.json")
+
+	_, _, err := ParseGitHub(githubHeader("pull_request", "d-1", body, "wrong"), body, testSecret)
+	require.ErrorIs(t, err, ErrSignature)
+
+	h := githubHeader("pull_request", "d-1", body, testSecret)
+	tampered := append([]byte(nil), body...)
+	tampered[len(tampered)-2] = ' '
+	_, _, err = ParseGitHub(h, tampered, testSecret)
+	require.ErrorIs(t, err, ErrSignature)
+
+	h.Del(GitHubSignatureHeader)
+	_, _, err = ParseGitHub(h, body, testSecret)
+	require.ErrorIs(t, err, ErrSignature)
+}
+
+func TestParseGitHubDeliveryFallback(t *testing.T) {
+	body := fixture(t, "github_pull_request_opened.json")
+	h := githubHeader("pull_request", "", body, testSecret)
+
+	first, _, err := ParseGitHub(h, body, testSecret)
+	require.NoError(t, err)
+	again, _, err := ParseGitHub(h, body, testSecret)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.DeliveryID)
+	require.Equal(t, first.DeliveryID, again.DeliveryID, "redelivery of the same body has the same id")
+}
+
+func TestParseGitLab(t *testing.T) {
+	tests := []struct {
+		fixture string
+		action  service.CodeHostAction
+	}{
+		{"gitlab_merge_request_open.json", service.ActionOpened},
+		{"gitlab_merge_request_reopen.json", service.ActionReopened},
+		{"gitlab_merge_request_close.json", service.ActionClosed},
+		{"gitlab_merge_request_merge.json", service.ActionMerged},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			body := fixture(t, tc.fixture)
+
+			ev, ok, err := ParseGitLab(gitlabHeader(testSecret, "uuid-1"), body, testSecret)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, service.CodeHostEvent{
+				Provider:      storage.ProviderGitLab,
+				DeliveryID:    "uuid-1",
+				Action:        tc.action,
+				PullRequestID: "gitlab:gitlabhq/gitlab-test!1",
+				Title:         "MS-Viewport",
+				AuthorLogin:   "root",
+				SenderLogin:   "root",
+			}, ev)
+		})
+	}
+}
+
+func TestParseGitLabIgnoresOtherActions(t *testing.T) {
+	body := fixture(t, "gitlab_merge_request_update.json")
+	_, ok, err := ParseGitLab(gitlabHeader(testSecret, "uuid-1"), body, testSecret)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	h := gitlabHeader(testSecret, "uuid-2")
+	h.Set(GitLabEventHeader, "Push Hook")
+	_, ok, err = ParseGitLab(h, body, testSecret)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestParseGitLabRejectsBadToken(t *testing.T) {
+	body := fixture(t, "gitlab_merge_request_open.json")
+
+	_, _, err := ParseGitLab(gitlabHeader("wrong", "uuid-1"), body, testSecret)
+	require.ErrorIs(t, err, ErrSignature)
+
+	_, _, err = ParseGitLab(gitlabHeader("", "uuid-1"), body, testSecret)
+	require.ErrorIs(t, err, ErrSignature)
+}
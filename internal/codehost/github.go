@@ -0,0 +1,113 @@
+package codehost
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Заголовки доставки GitHub.
+const (
+	GitHubSignatureHeader = "X-Hub-Signature-256"
+	GitHubEventHeader     = "X-GitHub-Event"
+	GitHubDeliveryHeader  = "X-GitHub-Delivery"
+)
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+}
+
+// VerifyGitHub проверяет подпись X-Hub-Signature-256: HMAC-SHA256 тела с секретом вебхука.
+func VerifyGitHub(header http.Header, body []byte, secret string) error {
+	sig, ok := strings.CutPrefix(header.Get(GitHubSignatureHeader), "sha256=")
+	if !ok {
+		return ErrSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// ParseGitHub проверяет подпись доставки GitHub и разбирает событие pull_request.
+// false без ошибки - доставка не касается жизненного цикла PR (ping, push, edited и т.п.).
+func ParseGitHub(header http.Header, body []byte, secret string) (service.CodeHostEvent, bool, error) {
+	if err := VerifyGitHub(header, body, secret); err != nil {
+		return service.CodeHostEvent{}, false, err
+	}
+	if header.Get(GitHubEventHeader) != "pull_request" {
+		return service.CodeHostEvent{}, false, nil
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return service.CodeHostEvent{}, false, fmt.Errorf("decode pull_request payload: %w", err)
+	}
+
+	var action service.CodeHostAction
+	switch payload.Action {
+	case "opened":
+		action = service.ActionOpened
+	case "reopened":
+		action = service.ActionReopened
+	case "closed":
+		action = service.ActionClosed
+		if payload.PullRequest.Merged {
+			action = service.ActionMerged
+		}
+	default:
+		return service.CodeHostEvent{}, false, nil
+	}
+
+	if payload.Repository.FullName == "" || payload.Number == 0 {
+		return service.CodeHostEvent{}, false, fmt.Errorf("pull_request payload has no repository or number")
+	}
+
+	deliveryID := header.Get(GitHubDeliveryHeader)
+	if deliveryID == "" {
+		deliveryID = bodyDeliveryID(body)
+	}
+
+	return service.CodeHostEvent{
+		Provider:      storage.ProviderGitHub,
+		DeliveryID:    deliveryID,
+		Action:        action,
+		PullRequestID: GitHubPullRequestID(payload.Repository.FullName, payload.Number),
+		Title:         payload.PullRequest.Title,
+		AuthorLogin:   payload.PullRequest.User.Login,
+		SenderLogin:   payload.Sender.Login,
+	}, true, nil
+}
+
+// GitHubPullRequestID возвращает id pr в сервисе для PR GitHub: github:owner/repo#number.
+func GitHubPullRequestID(repo string, number int) string {
+	return fmt.Sprintf("%s:%s#%d", storage.ProviderGitHub, repo, number)
+}
@@ -0,0 +1,84 @@
+package codehost
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// GitHubClient запрашивает ревью на PR через REST API GitHub.
+type GitHubClient struct {
+	api apiClient
+}
+
+// NewGitHubClient создаёт клиент API по адресу baseURL с токеном token
+// (нужно право на запись в pull requests репозитория).
+func NewGitHubClient(client *http.Client, baseURL, token string) *GitHubClient {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("Authorization", "Bearer "+token)
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return &GitHubClient{api: newAPIClient(client, baseURL, header)}
+}
+
+// Provider возвращает storage.ProviderGitHub.
+func (g *GitHubClient) Provider() string {
+	return storage.ProviderGitHub
+}
+
+// githubReviewers - тело запросов к requested_reviewers.
+type githubReviewers struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+// RequestReviewers снимает запрос ревью с removed и запрашивает его у reviewers.
+// Повторный запрос у уже запрошенного ревьюера GitHub принимает без изменений.
+func (g *GitHubClient) RequestReviewers(ctx context.Context, prID string, reviewers, removed []string) error {
+	repo, number, ok := ParseGitHubPullRequestID(prID)
+	if !ok {
+		return permanentf("invalid pull request id %q", prID)
+	}
+	path := "/repos/" + escapeSegments(repo) + "/pulls/" + strconv.Itoa(number) + "/requested_reviewers"
+
+	if len(removed) > 0 {
+		if err := g.api.do(ctx, http.MethodDelete, path, githubReviewers{Reviewers: removed}, nil); err != nil {
+			return err
+		}
+	}
+	if len(reviewers) > 0 {
+		if err := g.api.do(ctx, http.MethodPost, path, githubReviewers{Reviewers: reviewers}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseGitHubPullRequestID разбирает id pr, построенный GitHubPullRequestID.
+func ParseGitHubPullRequestID(prID string) (repo string, number int, ok bool) {
+	rest, ok := strings.CutPrefix(prID, storage.ProviderGitHub+":")
+	if !ok {
+		return "", 0, false
+	}
+	i := strings.LastIndexByte(rest, '#')
+	if i <= 0 {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(rest[i+1:])
+	if err != nil || number <= 0 {
+		return "", 0, false
+	}
+	return rest[:i], number, true
+}
+
+// escapeSegments экранирует каждый сегмент пути "owner/repo" отдельно.
+func escapeSegments(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
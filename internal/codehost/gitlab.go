@@ -0,0 +1,103 @@
+package codehost
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Заголовки доставки GitLab.
+const (
+	GitLabTokenHeader    = "X-Gitlab-Token"
+	GitLabEventHeader    = "X-Gitlab-Event"
+	GitLabDeliveryHeader = "X-Gitlab-Event-UUID"
+)
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		Title  string `json:"title"`
+		Action string `json:"action"`
+		IID    int    `json:"iid"`
+	} `json:"object_attributes"`
+}
+
+// VerifyGitLab проверяет секретный токен вебхука из X-Gitlab-Token. GitLab не подписывает
+// тело, а передаёт настроенный секрет как есть; сравнение - за постоянное время.
+func VerifyGitLab(header http.Header, secret string) error {
+	token := header.Get(GitLabTokenHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return ErrSignature
+	}
+	return nil
+}
+
+// ParseGitLab проверяет токен доставки GitLab и разбирает событие Merge Request Hook.
+// false без ошибки - доставка не касается жизненного цикла MR.
+//
+// Логин автора в событии GitLab не передаётся (только числовой author_id), поэтому автором
+// нового MR считается пользователь, выполнивший open/reopen.
+func ParseGitLab(header http.Header, body []byte, secret string) (service.CodeHostEvent, bool, error) {
+	if err := VerifyGitLab(header, secret); err != nil {
+		return service.CodeHostEvent{}, false, err
+	}
+	if header.Get(GitLabEventHeader) != "Merge Request Hook" {
+		return service.CodeHostEvent{}, false, nil
+	}
+
+	var payload gitlabMergeRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return service.CodeHostEvent{}, false, fmt.Errorf("decode merge_request payload: %w", err)
+	}
+	if payload.ObjectKind != "merge_request" {
+		return service.CodeHostEvent{}, false, nil
+	}
+
+	var action service.CodeHostAction
+	switch payload.ObjectAttributes.Action {
+	case "open":
+		action = service.ActionOpened
+	case "reopen":
+		action = service.ActionReopened
+	case "close":
+		action = service.ActionClosed
+	case "merge":
+		action = service.ActionMerged
+	default:
+		return service.CodeHostEvent{}, false, nil
+	}
+
+	if payload.Project.PathWithNamespace == "" || payload.ObjectAttributes.IID == 0 {
+		return service.CodeHostEvent{}, false, fmt.Errorf("merge_request payload has no project or iid")
+	}
+
+	deliveryID := header.Get(GitLabDeliveryHeader)
+	if deliveryID == "" {
+		deliveryID = bodyDeliveryID(body)
+	}
+
+	return service.CodeHostEvent{
+		Provider:      storage.ProviderGitLab,
+		DeliveryID:    deliveryID,
+		Action:        action,
+		PullRequestID: GitLabMergeRequestID(payload.Project.PathWithNamespace, payload.ObjectAttributes.IID),
+		Title:         payload.ObjectAttributes.Title,
+		AuthorLogin:   payload.User.Username,
+		SenderLogin:   payload.User.Username,
+	}, true, nil
+}
+
+// GitLabMergeRequestID возвращает id pr в сервисе для MR GitLab: gitlab:group/project!iid.
+func GitLabMergeRequestID(project string, iid int) string {
+	return fmt.Sprintf("%s:%s!%d", storage.ProviderGitLab, project, iid)
+}
@@ -0,0 +1,106 @@
+package codehost
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// GitLabClient выставляет ревьюеров MR через REST API GitLab (v4).
+type GitLabClient struct {
+	api apiClient
+}
+
+// NewGitLabClient создаёт клиент инстанса baseURL с токеном token (scope api).
+func NewGitLabClient(client *http.Client, baseURL, token string) *GitLabClient {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("PRIVATE-TOKEN", token)
+	return &GitLabClient{api: newAPIClient(client, strings.TrimRight(baseURL, "/")+"/api/v4", header)}
+}
+
+// Provider возвращает storage.ProviderGitLab.
+func (g *GitLabClient) Provider() string {
+	return storage.ProviderGitLab
+}
+
+// gitlabUser - пользователь в ответах API.
+type gitlabUser struct {
+	Username string `json:"username"`
+	ID       int64  `json:"id"`
+}
+
+// RequestReviewers добавляет reviewers к ревьюерам MR и убирает removed. API GitLab
+// принимает только полный список id ревьюеров, поэтому текущий список читается,
+// изменяется и записывается целиком; ревьюеры, назначенные вручную, сохраняются.
+func (g *GitLabClient) RequestReviewers(ctx context.Context, prID string, reviewers, removed []string) error {
+	project, iid, ok := ParseGitLabMergeRequestID(prID)
+	if !ok {
+		return permanentf("invalid pull request id %q", prID)
+	}
+	path := "/projects/" + url.PathEscape(project) + "/merge_requests/" + strconv.Itoa(iid)
+
+	var mr struct {
+		Reviewers []gitlabUser `json:"reviewers"`
+	}
+	if err := g.api.do(ctx, http.MethodGet, path, nil, &mr); err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(mr.Reviewers)+len(reviewers))
+	for _, u := range mr.Reviewers {
+		if !slices.Contains(removed, u.Username) {
+			ids = append(ids, u.ID)
+		}
+	}
+	for _, login := range reviewers {
+		id, err := g.userID(ctx, login)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	body := struct {
+		ReviewerIDs []int64 `json:"reviewer_ids"`
+	}{ReviewerIDs: ids}
+	return g.api.do(ctx, http.MethodPut, path, body, nil)
+}
+
+// userID ищет id пользователя GitLab по логину.
+func (g *GitLabClient) userID(ctx context.Context, login string) (int64, error) {
+	var users []gitlabUser
+	if err := g.api.do(ctx, http.MethodGet, "/users?username="+url.QueryEscape(login), nil, &users); err != nil {
+		return 0, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Username, login) {
+			return u.ID, nil
+		}
+	}
+	return 0, permanentf("gitlab user %q not found", login)
+}
+
+// ParseGitLabMergeRequestID разбирает id pr, построенный GitLabMergeRequestID.
+func ParseGitLabMergeRequestID(prID string) (project string, iid int, ok bool) {
+	rest, ok := strings.CutPrefix(prID, storage.ProviderGitLab+":")
+	if !ok {
+		return "", 0, false
+	}
+	i := strings.LastIndexByte(rest, '!')
+	if i <= 0 {
+		return "", 0, false
+	}
+	iid, err := strconv.Atoi(rest[i+1:])
+	if err != nil || iid <= 0 {
+		return "", 0, false
+	}
+	return rest[:i], iid, true
+}
@@ -0,0 +1,174 @@
+package codehost
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/retry"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// SyncConfig - параметры передачи ревьюеров на код-хостинг.
+type SyncConfig struct {
+	// PollInterval - период опроса очереди.
+	PollInterval time.Duration
+	// BatchSize - сколько задач берётся за один опрос.
+	BatchSize int
+	// MaxAttempts - после стольких неудачных попыток задача переходит в DEAD.
+	MaxAttempts int
+	// BaseBackoff и MaxBackoff - задержка перед повтором: BaseBackoff*2^(попытка-1), не больше MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout - таймаут одной задачи (все запросы к API); он же срок аренды задачи.
+	Timeout time.Duration
+}
+
+// Syncer забирает из очереди задачи передачи ревьюеров и выполняет их клиентом
+// провайдера. Ревьюеры берутся из текущего состояния PR, а не из момента постановки,
+// поэтому поздний повтор не вернёт уже заменённого ревьюера. Ошибки клиента, которые
+// не исправятся повтором (4xx, неизвестный провайдер), сразу переводят задачу в DEAD.
+type Syncer struct {
+	syncs      storage.ReviewerSyncRepository
+	prs        storage.PullRequestRepository
+	identities storage.IntegrationRepository
+	clients    map[string]service.CodeHostClient
+	now        func() time.Time
+	cfg        SyncConfig
+}
+
+// NewSyncer создаёт новый Syncer.
+func NewSyncer(
+	syncs storage.ReviewerSyncRepository,
+	prs storage.PullRequestRepository,
+	identities storage.IntegrationRepository,
+	clients []service.CodeHostClient,
+	cfg SyncConfig,
+) *Syncer {
+	byProvider := make(map[string]service.CodeHostClient, len(clients))
+	for _, c := range clients {
+		byProvider[c.Provider()] = c
+	}
+	return &Syncer{
+		syncs:      syncs,
+		prs:        prs,
+		identities: identities,
+		clients:    byProvider,
+		now:        time.Now,
+		cfg:        cfg,
+	}
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.SyncOnce(ctx) == s.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce выполняет одну пачку задач и возвращает её размер.
+func (s *Syncer) SyncOnce(ctx context.Context) int {
+	syncs, appErr := s.syncs.ClaimDue(ctx, s.now(), 2*s.cfg.Timeout, s.cfg.BatchSize)
+	if appErr != nil {
+		log.Printf("claim reviewer syncs failed: %v", appErr)
+		return 0
+	}
+
+	for _, sync := range syncs {
+		s.process(ctx, sync)
+	}
+	return len(syncs)
+}
+
+func (s *Syncer) process(ctx context.Context, sync storage.ReviewerSync) {
+	err := s.sync(storage.WithOrg(ctx, sync.OrgID), sync)
+	if err == nil {
+		if appErr := s.syncs.MarkDone(ctx, sync.ID); appErr != nil {
+			log.Printf("mark reviewer sync %d done failed: %v", sync.ID, appErr)
+		}
+		return
+	}
+
+	dead := sync.Attempts >= s.cfg.MaxAttempts || IsPermanent(err)
+	msg := retry.TruncateError(err)
+	if dead {
+		log.Printf("reviewer sync %d for %s is dead after %d attempts: %s", sync.ID, sync.PullRequestID, sync.Attempts, msg)
+	}
+	if appErr := s.syncs.MarkFailed(ctx, sync.ID, s.now().Add(s.backoff(sync.Attempts)), msg, dead); appErr != nil {
+		log.Printf("mark reviewer sync %d failed failed: %v", sync.ID, appErr)
+	}
+}
+
+func (s *Syncer) sync(ctx context.Context, sync storage.ReviewerSync) error {
+	client, ok := s.clients[sync.Provider]
+	if !ok {
+		return permanentf("provider %q is not configured", sync.Provider)
+	}
+
+	pr, appErr := s.prs.Get(ctx, sync.PullRequestID)
+	if appErr != nil {
+		return appErr
+	}
+	// На закрытом или смерженном PR запрашивать ревью уже незачем.
+	if pr.Status != storage.StatusOpen {
+		return nil
+	}
+
+	reviewers, err := s.logins(ctx, sync.Provider, pr.AssignedReviewers)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for _, id := range sync.Removed {
+		if !slices.Contains(pr.AssignedReviewers, id) {
+			stale = append(stale, id)
+		}
+	}
+	removed, err := s.logins(ctx, sync.Provider, stale)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	return client.RequestReviewers(ctx, pr.ID, reviewers, removed)
+}
+
+// logins переводит user_id в логины провайдера: связь из POST /integrations/identities,
+// иначе логин считается равным user_id (как при разборе входящих вебхуков).
+func (s *Syncer) logins(ctx context.Context, provider string, userIDs []string) ([]string, error) {
+	logins := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		login, appErr := s.identities.IdentityLogin(ctx, provider, id)
+		switch {
+		case appErr == nil:
+		case appErr.Code == apperrors.ErrNotFound:
+			login = id
+		default:
+			return nil, appErr
+		}
+		logins = append(logins, login)
+	}
+	return logins, nil
+}
+
+// backoff возвращает задержку перед попыткой, следующей за attempt.
+func (s *Syncer) backoff(attempt int) time.Duration {
+	return retry.Backoff(s.cfg.BaseBackoff, s.cfg.MaxBackoff, attempt)
+}
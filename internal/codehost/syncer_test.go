@@ -0,0 +1,204 @@
+package codehost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+const syncPRID = "github:acme/widgets#42"
+
+const syncPath = "/repos/acme/widgets/pulls/42/requested_reviewers"
+
+type syncFixture struct {
+	api       *fakeAPI
+	syncer    *Syncer
+	prService *service.PRService
+	syncs     *memory.ReviewerSyncRepository
+	clock     time.Time
+}
+
+// newSyncFixture поднимает PRService с передачей ревьюеров в GitHub поверх памяти
+// и Syncer, который ходит в локальный fakeAPI. u2 связан с логином octocat.
+func newSyncFixture(t *testing.T) *syncFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	api, srv := newFakeAPI(t)
+
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	prs := memory.NewPullRequestRepository(store)
+	integrations := memory.NewIntegrationRepository(store)
+	f := &syncFixture{api: api, syncs: memory.NewReviewerSyncRepository(store)}
+
+	require.Nil(t, memory.NewTeamRepository(store).Create(ctx, storage.Team{
+		TeamName: "backend",
+		Members: []storage.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Carol", IsActive: true},
+			{ID: "u4", Username: "Dave", IsActive: true},
+		},
+	}))
+	require.Nil(t, integrations.LinkIdentity(ctx, storage.ProviderGitHub, "octocat", "u2"))
+
+	f.prService = service.NewPRService(
+		memory.NewTxManager(store), users, prs,
+		memory.NewAssignmentLogRepository(store), memory.NewPREventRepository(store), memory.NewOutboxRepository(store),
+		service.WithReviewerSync(f.syncs, storage.ProviderGitHub),
+	)
+
+	client := NewGitHubClient(srv.Client(), srv.URL, "gh-token")
+	f.syncer = NewSyncer(f.syncs, prs, integrations, []service.CodeHostClient{client}, SyncConfig{
+		PollInterval: time.Second,
+		BatchSize:    10,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   time.Minute,
+		Timeout:      time.Second,
+	})
+	f.syncer.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *syncFixture) createPR(t *testing.T) storage.PullRequest {
+	t.Helper()
+	pr, err := f.prService.CreatePR(context.Background(), syncPRID, "Add search", "u1")
+	require.Nil(t, err)
+	f.clock = time.Now()
+	return pr
+}
+
+func (f *syncFixture) list(t *testing.T) []storage.ReviewerSync {
+	t.Helper()
+	syncs, err := f.syncs.List(context.Background(), syncPRID)
+	require.Nil(t, err)
+	return syncs
+}
+
+// logins - ожидаемые логины GitHub для user_id ревьюеров.
+func logins(userIDs []string) []string {
+	out := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id == "u2" {
+			id = "octocat"
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+func reviewersBody(t *testing.T, body string) []string {
+	t.Helper()
+	var req githubReviewers
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+	return req.Reviewers
+}
+
+func TestSyncerRequestsReviewersAfterCreate(t *testing.T) {
+	f := newSyncFixture(t)
+	f.api.on(http.MethodPost, syncPath, http.StatusCreated, `{}`)
+	pr := f.createPR(t)
+
+	require.Equal(t, 1, f.syncer.SyncOnce(context.Background()))
+
+	calls := f.api.recorded()
+	require.Len(t, calls, 1)
+	require.Equal(t, http.MethodPost, calls[0].method)
+	require.ElementsMatch(t, logins(pr.AssignedReviewers), reviewersBody(t, calls[0].body))
+
+	syncs := f.list(t)
+	require.Len(t, syncs, 1)
+	require.Equal(t, storage.DeliveryDelivered, syncs[0].Status)
+}
+
+func TestSyncerReassignRemovesOldReviewer(t *testing.T) {
+	f := newSyncFixture(t)
+	f.api.on(http.MethodPost, syncPath, http.StatusCreated, `{}`)
+	f.api.on(http.MethodDelete, syncPath, http.StatusOK, `{}`)
+	pr := f.createPR(t)
+	require.Equal(t, 1, f.syncer.SyncOnce(context.Background()))
+
+	old := pr.AssignedReviewers[0]
+	updated, newID, err := f.prService.ReassignReviewer(context.Background(), syncPRID, old)
+	require.Nil(t, err)
+	f.clock = time.Now()
+	require.Equal(t, 1, f.syncer.SyncOnce(context.Background()))
+
+	calls := f.api.recorded()[1:]
+	require.Len(t, calls, 2)
+	require.Equal(t, http.MethodDelete, calls[0].method)
+	require.Equal(t, logins([]string{old}), reviewersBody(t, calls[0].body))
+	require.Equal(t, http.MethodPost, calls[1].method)
+	require.ElementsMatch(t, logins(updated.AssignedReviewers), reviewersBody(t, calls[1].body))
+	require.Contains(t, reviewersBody(t, calls[1].body), logins([]string{newID})[0])
+}
+
+func TestSyncerRetriesWithBackoff(t *testing.T) {
+	f := newSyncFixture(t)
+	f.api.on(http.MethodPost, syncPath, http.StatusBadGateway, `bad gateway`)
+	f.api.on(http.MethodPost, syncPath, http.StatusCreated, `{}`)
+	f.createPR(t)
+	ctx := context.Background()
+
+	require.Equal(t, 1, f.syncer.SyncOnce(ctx))
+	syncs := f.list(t)
+	require.Equal(t, storage.DeliveryPending, syncs[0].Status)
+	require.Contains(t, syncs[0].LastError, "unexpected status 502")
+	require.True(t, f.clock.Add(time.Second).Equal(syncs[0].NextAttemptAt))
+
+	require.Equal(t, 0, f.syncer.SyncOnce(ctx), "not due before the backoff")
+
+	f.clock = f.clock.Add(time.Second)
+	require.Equal(t, 1, f.syncer.SyncOnce(ctx))
+	syncs = f.list(t)
+	require.Equal(t, storage.DeliveryDelivered, syncs[0].Status)
+	require.Equal(t, 2, syncs[0].Attempts)
+	require.Len(t, f.api.recorded(), 2)
+}
+
+func TestSyncerPermanentErrorIsDead(t *testing.T) {
+	f := newSyncFixture(t)
+	f.api.on(http.MethodPost, syncPath, http.StatusUnprocessableEntity, `{"message":"Validation Failed"}`)
+	f.createPR(t)
+
+	require.Equal(t, 1, f.syncer.SyncOnce(context.Background()))
+	syncs := f.list(t)
+	require.Equal(t, storage.DeliveryDead, syncs[0].Status)
+	require.Equal(t, 1, syncs[0].Attempts)
+	require.Contains(t, syncs[0].LastError, "422")
+}
+
+func TestSyncerSkipsClosedPR(t *testing.T) {
+	f := newSyncFixture(t)
+	f.createPR(t)
+	_, err := f.prService.Close(context.Background(), syncPRID)
+	require.Nil(t, err)
+
+	require.Equal(t, 1, f.syncer.SyncOnce(context.Background()))
+	require.Empty(t, f.api.recorded())
+	require.Equal(t, storage.DeliveryDelivered, f.list(t)[0].Status)
+}
+
+func TestSyncIsOnlyEnqueuedForConfiguredProviders(t *testing.T) {
+	f := newSyncFixture(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"pr-1001", "gitlab:group/project!1"} {
+		_, err := f.prService.CreatePR(ctx, id, "Manual", "u1")
+		require.Nil(t, err)
+
+		syncs, err := f.syncs.List(ctx, id)
+		require.Nil(t, err)
+		require.Empty(t, syncs, id)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // DBSSLmode определяет режим SSL-подключения к PostgreSQL.
@@ -22,12 +24,15 @@ const (
 // ServerConfig - конфигурация HTTP-сервера.
 type ServerConfig struct {
 	Addr string
+	// TrustProxy - брать адрес клиента из X-Forwarded-For (сервис за обратным прокси).
+	TrustProxy bool
 }
 
 // LoadServer загружает конфигурацию сервера из окружения.
 func LoadServer() ServerConfig {
 	return ServerConfig{
-		Addr: getEnv("SERVER_ADDR", ":8080"),
+		Addr:       getEnv("SERVER_ADDR", ":8080"),
+		TrustProxy: getBool("SERVER_TRUST_PROXY", false),
 	}
 }
 
@@ -49,6 +54,8 @@ type DBConfig struct {
 	Name     string
 	SSLmode  DBSSLmode
 	Port     int
+	// AutoMigrate - применять встроенные миграции при старте сервера.
+	AutoMigrate bool
 }
 
 // LoadDB загружает конфигурацию бд из окружения и возвращает DBConfig.
@@ -72,7 +79,465 @@ func LoadDB() DBConfig {
 		Password: getEnv("DB_PASSWORD", "assigner"),
 		Name:     getEnv("DB_NAME", "assigner"),
 		SSLmode:  mode,
+
+		AutoMigrate: getBool("DB_AUTO_MIGRATE", false),
+	}
+}
+
+// StorageDriver - тип хранилища данных.
+type StorageDriver string
+
+const (
+	// DriverPostgres - PostgreSQL (по умолчанию).
+	DriverPostgres StorageDriver = "postgres"
+	// DriverSQLite - файл SQLite, для однонодовых установок без сервера БД.
+	DriverSQLite StorageDriver = "sqlite"
+)
+
+// StorageConfig - выбор хранилища.
+type StorageConfig struct {
+	Driver StorageDriver
+	// SQLitePath - путь к файлу базы для DriverSQLite.
+	SQLitePath string
+}
+
+// LoadStorage загружает выбор хранилища из окружения.
+func LoadStorage() StorageConfig {
+	driver := StorageDriver(getEnv("STORAGE_DRIVER", string(DriverPostgres)))
+	switch driver {
+	case DriverPostgres, DriverSQLite:
+	default:
+		log.Fatalf("invalid STORAGE_DRIVER %q (expected %q or %q)", driver, DriverPostgres, DriverSQLite)
+	}
+
+	return StorageConfig{
+		Driver:     driver,
+		SQLitePath: getEnv("SQLITE_PATH", "assigner.db"),
+	}
+}
+
+// AssignConfig - настройки случайного выбора ревьюеров.
+type AssignConfig struct {
+	// Seed - seed общего PRNG; nil означает crypto/rand.
+	Seed *int64
+	// SeedPerPR - инициализировать источник seed'ом из хэша id pr для каждой операции.
+	SeedPerPR bool
+}
+
+// LoadAssign загружает настройки выбора ревьюеров из окружения.
+func LoadAssign() AssignConfig {
+	var cfg AssignConfig
+
+	if raw := os.Getenv("ASSIGN_RAND_SEED"); raw != "" {
+		seed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid ASSIGN_RAND_SEED %v", err)
+		}
+		cfg.Seed = &seed
+	}
+
+	cfg.SeedPerPR = getBool("ASSIGN_SEED_PER_PR", false)
+
+	return cfg
+}
+
+// WebhookConfig - настройки доставки исходящих вебхуков.
+type WebhookConfig struct {
+	PollInterval time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// LoadWebhook загружает настройки доставки вебхуков из окружения.
+func LoadWebhook() WebhookConfig {
+	return WebhookConfig{
+		PollInterval: getDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+		BaseBackoff:  getDuration("WEBHOOK_BACKOFF_BASE", 5*time.Second),
+		MaxBackoff:   getDuration("WEBHOOK_BACKOFF_MAX", time.Hour),
+		Timeout:      getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		BatchSize:    getInt("WEBHOOK_BATCH_SIZE", 20),
+		MaxAttempts:  getInt("WEBHOOK_MAX_ATTEMPTS", 10),
+	}
+}
+
+// EgressConfig - ограничения исходящих запросов по адресам, которые задают пользователи.
+type EgressConfig struct {
+	// AllowPrivate разрешает loopback, частные и link-local адреса назначения.
+	AllowPrivate bool
+}
+
+// LoadEgress загружает ограничения исходящих запросов из окружения.
+func LoadEgress() EgressConfig {
+	return EgressConfig{AllowPrivate: getBool("EGRESS_ALLOW_PRIVATE", false)}
+}
+
+// IntegrationConfig - секреты входящих вебхуков код-хостингов; пустое значение
+// отключает приём от провайдера.
+type IntegrationConfig struct {
+	GitHubSecret string
+	GitLabToken  string
+}
+
+// LoadIntegration загружает секреты входящих вебхуков из окружения.
+func LoadIntegration() IntegrationConfig {
+	return IntegrationConfig{
+		GitHubSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitLabToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+	}
+}
+
+// AuthConfig - аутентификация запросов к API.
+type AuthConfig struct {
+	// BootstrapToken - секрет токена "bootstrap" с правом admin, который регистрируется
+	// при старте; через него выпускаются остальные токены. Пустой - не регистрировать.
+	BootstrapToken string
+	// Enabled - требовать Authorization: Bearer; false открывает API (только для разработки).
+	Enabled bool
+}
+
+// LoadAuth загружает настройки аутентификации из окружения.
+func LoadAuth() AuthConfig {
+	return AuthConfig{
+		Enabled:        getBool("AUTH_ENABLED", true),
+		BootstrapToken: os.Getenv("AUTH_BOOTSTRAP_TOKEN"),
+	}
+}
+
+// OIDCConfig - проверка JWT корпоративного SSO. Пустой JWKS отключает вход по JWT.
+type OIDCConfig struct {
+	// JWKS - путь к файлу или http(s) URL набора ключей.
+	JWKS      string
+	Issuer    string
+	Audience  string
+	UserClaim string
+	RoleClaim string
+	// OrgClaim - claim с именем организации; без него пользователь работает в
+	// организации по умолчанию.
+	OrgClaim string
+	// Refresh - как часто перечитывать JWKS.
+	Refresh time.Duration
+	// Leeway - допустимое расхождение часов с SSO.
+	Leeway time.Duration
+}
+
+// LoadOIDC загружает настройки проверки JWT из окружения.
+func LoadOIDC() OIDCConfig {
+	return OIDCConfig{
+		JWKS:      os.Getenv("OIDC_JWKS"),
+		Issuer:    os.Getenv("OIDC_ISSUER"),
+		Audience:  os.Getenv("OIDC_AUDIENCE"),
+		UserClaim: getEnv("OIDC_USER_CLAIM", "sub"),
+		RoleClaim: getEnv("OIDC_ROLE_CLAIM", "role"),
+		OrgClaim:  getEnv("OIDC_ORG_CLAIM", "org"),
+		Refresh:   getDuration("OIDC_JWKS_REFRESH", time.Hour),
+		Leeway:    getDuration("OIDC_LEEWAY", time.Minute),
+	}
+}
+
+// CodeHostConfig - доступ к REST API код-хостингов для передачи ревьюеров на PR
+// и параметры повторов. Пустой токен отключает передачу для провайдера.
+type CodeHostConfig struct {
+	GitHubToken  string
+	GitHubAPIURL string
+	GitLabToken  string
+	GitLabURL    string
+	PollInterval time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// LoadCodeHost загружает настройки API код-хостингов из окружения.
+func LoadCodeHost() CodeHostConfig {
+	return CodeHostConfig{
+		GitHubToken:  os.Getenv("GITHUB_API_TOKEN"),
+		GitHubAPIURL: getEnv("GITHUB_API_URL", "https://api.github.com"),
+		GitLabToken:  os.Getenv("GITLAB_API_TOKEN"),
+		GitLabURL:    getEnv("GITLAB_URL", "https://gitlab.com"),
+		PollInterval: getDuration("CODEHOST_SYNC_INTERVAL", 2*time.Second),
+		BaseBackoff:  getDuration("CODEHOST_BACKOFF_BASE", 10*time.Second),
+		MaxBackoff:   getDuration("CODEHOST_BACKOFF_MAX", time.Hour),
+		Timeout:      getDuration("CODEHOST_TIMEOUT", 15*time.Second),
+		BatchSize:    getInt("CODEHOST_BATCH_SIZE", 20),
+		MaxAttempts:  getInt("CODEHOST_MAX_ATTEMPTS", 10),
+	}
+}
+
+// NotifyConfig - настройки отправки уведомлений.
+type NotifyConfig struct {
+	// TemplatesDir - каталог с шаблонами сообщений (assigned.tmpl, replaced.tmpl, reminder.tmpl);
+	// пустой - встроенные шаблоны.
+	TemplatesDir string
+	// DigestLocation и DigestAt - часовой пояс и время суток ежедневной сводки писем.
+	DigestLocation *time.Location
+	DigestAt       time.Duration
+	// DigestInterval - как часто проверять, не пора ли формировать сводки.
+	DigestInterval time.Duration
+	PollInterval   time.Duration
+	BaseBackoff    time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	BatchSize      int
+	MaxAttempts    int
+}
+
+// LoadNotify загружает настройки уведомлений из окружения.
+func LoadNotify() NotifyConfig {
+	return NotifyConfig{
+		TemplatesDir:   os.Getenv("NOTIFY_TEMPLATES_DIR"),
+		DigestLocation: getLocation("NOTIFY_DIGEST_TZ", time.UTC),
+		DigestAt:       getClock("NOTIFY_DIGEST_AT", 9*time.Hour),
+		DigestInterval: getDuration("NOTIFY_DIGEST_INTERVAL", time.Minute),
+		PollInterval:   getDuration("NOTIFY_POLL_INTERVAL", time.Second),
+		BaseBackoff:    getDuration("NOTIFY_BACKOFF_BASE", 5*time.Second),
+		MaxBackoff:     getDuration("NOTIFY_BACKOFF_MAX", 30*time.Minute),
+		Timeout:        getDuration("NOTIFY_TIMEOUT", 10*time.Second),
+		BatchSize:      getInt("NOTIFY_BATCH_SIZE", 20),
+		MaxAttempts:    getInt("NOTIFY_MAX_ATTEMPTS", 8),
+	}
+}
+
+// SMTPConfig - SMTP-сервер для писем. Пустой Host отключает письма.
+type SMTPConfig struct {
+	Host     string
+	Username string
+	Password string
+	From     string
+	// Security - starttls, tls или none.
+	Security string
+	Port     int
+}
+
+// LoadSMTP загружает настройки SMTP из окружения.
+func LoadSMTP() SMTPConfig {
+	security := getEnv("SMTP_SECURITY", "starttls")
+	switch security {
+	case "starttls", "tls", "none":
+	default:
+		log.Printf("warning: invalid SMTP_SECURITY=%q; using default %q", security, "starttls")
+		security = "starttls"
+	}
+
+	return SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     getInt("SMTP_PORT", 587),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     getEnv("SMTP_FROM", "assigner@localhost"),
+		Security: security,
+	}
+}
+
+// SLAConfig - настройки проверки сроков ревью.
+type SLAConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// LoadSLA загружает настройки проверки сроков ревью из окружения.
+func LoadSLA() SLAConfig {
+	return SLAConfig{
+		PollInterval: getDuration("SLA_POLL_INTERVAL", time.Minute),
+		BatchSize:    getInt("SLA_BATCH_SIZE", 50),
+	}
+}
+
+// AuditConfig - срок хранения журнала аудита.
+type AuditConfig struct {
+	// Retention - сколько хранятся записи; 0 - бессрочно.
+	Retention time.Duration
+	// PruneInterval - период удаления устаревших записей.
+	PruneInterval time.Duration
+}
+
+// LoadAudit загружает настройки журнала аудита из окружения.
+func LoadAudit() AuditConfig {
+	cfg := AuditConfig{
+		Retention:     90 * 24 * time.Hour,
+		PruneInterval: getDuration("AUDIT_PRUNE_INTERVAL", time.Hour),
+	}
+	if os.Getenv("AUDIT_RETENTION") == "0" {
+		cfg.Retention = 0
+	} else {
+		cfg.Retention = getDuration("AUDIT_RETENTION", cfg.Retention)
+	}
+	return cfg
+}
+
+// IdempotencyConfig - хранение ответов на запросы с ключом идемпотентности.
+type IdempotencyConfig struct {
+	// TTL - сколько хранится ответ; повтор позже выполняет запрос заново.
+	TTL time.Duration
+	// PruneInterval - период удаления просроченных ключей.
+	PruneInterval time.Duration
+}
+
+// LoadIdempotency загружает настройки ключей идемпотентности из окружения.
+func LoadIdempotency() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:           getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		PruneInterval: getDuration("IDEMPOTENCY_PRUNE_INTERVAL", time.Hour),
+	}
+}
+
+// RateLimitRule - лимит запросов: Rate в секунду в среднем и до Burst подряд.
+// Нулевой Rate - без ограничения.
+type RateLimitRule struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig - ограничение частоты запросов клиентов.
+type RateLimitConfig struct {
+	// Routes - лимиты отдельных маршрутов по шаблону ServeMux ("POST /pullRequest/create").
+	Routes map[string]RateLimitRule
+	// Default - лимит маршрутов, не перечисленных в Routes.
+	Default RateLimitRule
+}
+
+// LoadRateLimit загружает лимиты из окружения. Лимит задаётся как "<число>/<s|m|h>[:<burst>]",
+// "off" снимает ограничение; RATE_LIMIT_ROUTES - список "<маршрут>=<лимит>" через запятую.
+func LoadRateLimit() RateLimitConfig {
+	cfg := RateLimitConfig{
+		Default: parseRateLimitRule("RATE_LIMIT_DEFAULT", getEnv("RATE_LIMIT_DEFAULT", "100/s:200")),
+		Routes:  make(map[string]RateLimitRule),
+	}
+	routes := os.Getenv("RATE_LIMIT_ROUTES")
+	if routes == "" {
+		return cfg
+	}
+	for _, entry := range strings.Split(routes, ",") {
+		i := strings.LastIndex(entry, "=")
+		route := strings.TrimSpace(entry[:max(i, 0)])
+		if i < 0 || route == "" {
+			log.Fatalf("invalid RATE_LIMIT_ROUTES entry %q (expected <route>=<limit>)", entry)
+		}
+		cfg.Routes[route] = parseRateLimitRule("RATE_LIMIT_ROUTES", entry[i+1:])
+	}
+	return cfg
+}
+
+func parseRateLimitRule(key, value string) RateLimitRule {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "off" {
+		return RateLimitRule{}
+	}
+
+	limit, burst, hasBurst := strings.Cut(value, ":")
+	count, unit, ok := strings.Cut(limit, "/")
+	n, err := strconv.ParseFloat(count, 64)
+	if !ok || err != nil || n <= 0 {
+		log.Fatalf("invalid %s %q (expected <count>/<s|m|h>[:<burst>])", key, value)
+	}
+
+	rule := RateLimitRule{}
+	switch unit {
+	case "s":
+		rule.Rate = n
+	case "m":
+		rule.Rate = n / 60
+	case "h":
+		rule.Rate = n / 3600
+	default:
+		log.Fatalf("invalid %s %q: unknown unit %q", key, value, unit)
+	}
+
+	rule.Burst = max(int(n), 1)
+	if hasBurst {
+		b, err := strconv.Atoi(burst)
+		if err != nil || b <= 0 {
+			log.Fatalf("invalid %s %q: bad burst %q", key, value, burst)
+		}
+		rule.Burst = b
+	}
+	return rule
+}
+
+// LeaderConfig - выбор лидера для фоновых обработчиков при нескольких репликах на Postgres.
+type LeaderConfig struct {
+	RetryInterval time.Duration
+	CheckInterval time.Duration
+	Enabled       bool
+}
+
+// LoadLeader загружает настройки выбора лидера из окружения.
+func LoadLeader() LeaderConfig {
+	return LeaderConfig{
+		Enabled:       getBool("LEADER_ELECTION", true),
+		RetryInterval: getDuration("LEADER_RETRY_INTERVAL", 5*time.Second),
+		CheckInterval: getDuration("LEADER_CHECK_INTERVAL", 5*time.Second),
+	}
+}
+
+// getClock читает время суток в формате HH:MM и возвращает смещение от полуночи.
+func getClock(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		log.Printf("warning: invalid %s=%q; using default %s", key, raw, fallback)
+		return fallback
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+func getLocation(key string, fallback *time.Location) *time.Location {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	loc, err := time.LoadLocation(raw)
+	if err != nil {
+		log.Printf("warning: invalid %s=%q; using default %s", key, raw, fallback)
+		return fallback
+	}
+	return loc
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil || v <= 0 {
+		log.Printf("warning: invalid %s=%q; using default %s", key, raw, fallback)
+		return fallback
+	}
+	return v
+}
+
+func getInt(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v <= 0 {
+		log.Printf("warning: invalid %s=%q; using default %d", key, raw, fallback)
+		return fallback
+	}
+	return v
+}
+
+func getBool(key string, fallback bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("warning: invalid %s=%q; using default %t", key, raw, fallback)
+		return fallback
 	}
+	return v
 }
 
 func getEnv(key, fallback string) string {
@@ -0,0 +1,79 @@
+// Package egress - HTTP-клиент для запросов по адресам, которые задают пользователи
+// сервиса (исходящие вебхуки, чаты команд).
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress - адрес назначения внутренний, а запросы туда не разрешены.
+var ErrForbiddenAddress = errors.New("destination address is not allowed")
+
+// Config - параметры клиента.
+type Config struct {
+	// AllowPrivate разрешает внутренние адреса (см. Allowed): для локальной разработки
+	// и сетей, где получатели вебхуков живут рядом с сервисом.
+	AllowPrivate bool
+}
+
+// reserved - диапазоны, которые не покрывают методы netip.Addr: "этот" хост и
+// общее адресное пространство провайдеров (CGNAT).
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Allowed сообщает, можно ли обращаться к addr без AllowPrivate: запрещены loopback,
+// частные, link-local, multicast и неопределённые адреса.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range reserved {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewClient возвращает клиент, который не следует редиректам (ответ 3xx возвращается
+// как есть) и, если не задан AllowPrivate, не соединяется с внутренними адресами.
+// Адрес проверяется после разрешения имени, при каждом соединении, поэтому имя,
+// которое позже начало указывать во внутреннюю сеть, тоже отклоняется. Прокси из
+// окружения не используется: через него проверка обходилась бы.
+func NewClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !cfg.AllowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+			}
+			if !Allowed(ap.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, ap.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
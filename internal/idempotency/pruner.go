@@ -0,0 +1,51 @@
+// Package idempotency удаляет просроченные ключи идемпотентности запросов.
+package idempotency
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Pruner периодически удаляет ключи идемпотентности всех организаций с истёкшим сроком.
+type Pruner struct {
+	repo         storage.IdempotencyRepository
+	now          func() time.Time
+	pollInterval time.Duration
+}
+
+// NewPruner создаёт новый Pruner, очищающий ключи раз в pollInterval.
+func NewPruner(repo storage.IdempotencyRepository, pollInterval time.Duration) *Pruner {
+	return &Pruner{repo: repo, now: time.Now, pollInterval: pollInterval}
+}
+
+// Run очищает ключи до отмены ctx.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.PruneOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PruneOnce удаляет просроченные ключи и возвращает их число.
+func (p *Pruner) PruneOnce(ctx context.Context) int64 {
+	deleted, err := p.repo.DeleteExpired(ctx, p.now())
+	if err != nil {
+		log.Printf("idempotency keys pruning failed: %v", err)
+		return 0
+	}
+	if deleted > 0 {
+		log.Printf("idempotency: deleted %d expired keys", deleted)
+	}
+	return deleted
+}
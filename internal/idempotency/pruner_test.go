@@ -0,0 +1,33 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestPrunerDeletesExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewIdempotencyRepository(memory.NewStore())
+
+	clock := time.Now()
+	for _, key := range []string{"k1", "k2"} {
+		_, ok, err := repo.Claim(ctx, storage.IdempotencyRecord{
+			Client: "token:ci", Key: key, Fingerprint: "fp", ExpiresAt: clock.Add(time.Hour),
+		}, clock)
+		require.Nil(t, err)
+		require.True(t, ok)
+	}
+
+	pruner := NewPruner(repo, time.Minute)
+	pruner.now = func() time.Time { return clock }
+	require.Zero(t, pruner.PruneOnce(ctx), "live keys are kept")
+
+	clock = clock.Add(time.Hour)
+	require.EqualValues(t, 2, pruner.PruneOnce(ctx))
+}
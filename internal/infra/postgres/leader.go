@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WorkersLockID - ключ advisory-блокировки лидера фоновых обработчиков сервера.
+const WorkersLockID = int64(0x61737369676e6572)
+
+// ElectorConfig - параметры выбора лидера.
+type ElectorConfig struct {
+	// RetryInterval - как часто не-лидер пытается взять блокировку.
+	RetryInterval time.Duration
+	// CheckInterval - как часто лидер проверяет соединение, которым держит блокировку.
+	CheckInterval time.Duration
+}
+
+// electorAppName - application_name соединения, которым Elector держит блокировку.
+const electorAppName = "assigner-pr-elector"
+
+// Elector выбирает среди реплик одного лидера сессионной advisory-блокировкой. Блокировка
+// берётся на собственном соединении Elector, открытом с параметрами pool, но вне его:
+// соединение живёт между попытками и переоткрывается, только когда рвётся. Если оно
+// рвётся у лидера, Postgres снимает блокировку, и её берёт другая реплика. Бывший лидер
+// узнаёт об обрыве при ближайшей проверке, поэтому работа лидера может ненадолго (до
+// CheckInterval) выполняться дважды.
+type Elector struct {
+	pool   *pgxpool.Pool
+	lockID int64
+	cfg    ElectorConfig
+
+	// conn используется только горутиной Run; nil - соединения нет.
+	conn *pgx.Conn
+}
+
+// NewElector создаёт новый Elector.
+func NewElector(pool *pgxpool.Pool, lockID int64, cfg ElectorConfig) *Elector {
+	return &Elector{pool: pool, lockID: lockID, cfg: cfg}
+}
+
+// Run до отмены ctx пытается стать лидером и, став им, вызывает lead. Контекст lead
+// отменяется при потере лидерства или отмене ctx; Run ждёт возврата lead и снова
+// участвует в выборах.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	defer e.disconnect()
+	for {
+		locked, err := e.tryLock(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("leader election failed: %v", err)
+		}
+		if locked {
+			log.Printf("leader election: lock %d acquired, starting leader work", e.lockID)
+			e.hold(ctx, lead)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.cfg.RetryInterval):
+		}
+	}
+}
+
+// tryLock берёт блокировку на соединении Elector, при необходимости открывая его. false
+// без ошибки - блокировку держит другая реплика; соединение при этом остаётся открытым.
+func (e *Elector) tryLock(ctx context.Context) (bool, error) {
+	if e.conn == nil {
+		cfg := e.pool.Config().ConnConfig.Copy()
+		cfg.RuntimeParams["application_name"] = electorAppName
+		conn, err := pgx.ConnectConfig(ctx, cfg)
+		if err != nil {
+			return false, fmt.Errorf("connect: %w", err)
+		}
+		e.conn = conn
+	}
+
+	var locked bool
+	if err := e.conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, e.lockID).Scan(&locked); err != nil {
+		e.disconnect()
+		return false, fmt.Errorf("try advisory lock: %w", err)
+	}
+	return locked, nil
+}
+
+// disconnect закрывает соединение Elector; следующая попытка откроет новое.
+func (e *Elector) disconnect() {
+	if e.conn == nil {
+		return
+	}
+	closeCtx, cancel := context.WithTimeout(context.Background(), e.cfg.CheckInterval)
+	defer cancel()
+	_ = e.conn.Close(closeCtx)
+	e.conn = nil
+}
+
+// hold выполняет lead, пока соединение Elector живо и ctx не отменён, затем снимает
+// блокировку. Оборванное соединение закрывается.
+func (e *Elector) hold(ctx context.Context, lead func(ctx context.Context)) {
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leadCtx)
+	}()
+
+	ticker := time.NewTicker(e.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	broken := false
+	for lost := false; !lost; {
+		select {
+		case <-ctx.Done():
+			lost = true
+		case <-done:
+			lost = true
+		case <-ticker.C:
+			pingCtx, cancelPing := context.WithTimeout(ctx, e.cfg.CheckInterval)
+			err := e.conn.Ping(pingCtx)
+			cancelPing()
+			if err != nil && ctx.Err() == nil {
+				log.Printf("leader election: lock connection lost: %v", err)
+				lost, broken = true, true
+			}
+		}
+	}
+
+	cancel()
+	<-done
+
+	if broken {
+		e.disconnect()
+		log.Printf("leader election: lock %d lost", e.lockID)
+		return
+	}
+	// Закрытие сессии снимает блокировку и тогда, когда unlock не дошёл до сервера.
+	unlockCtx, cancelUnlock := context.WithTimeout(context.Background(), e.cfg.CheckInterval)
+	defer cancelUnlock()
+	if _, err := e.conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, e.lockID); err != nil {
+		log.Printf("leader election: unlock failed: %v", err)
+		e.disconnect()
+	}
+	log.Printf("leader election: lock %d released", e.lockID)
+}
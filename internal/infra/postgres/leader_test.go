@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+// testPool подключается к БД интеграционных тестов (см. internal/integration/run_tests.sh).
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	host := os.Getenv("INTEGRATION_DB_HOST")
+	if testing.Short() || host == "" {
+		t.Skip("INTEGRATION_DB_HOST is not set")
+	}
+
+	port, err := strconv.Atoi(getenv("INTEGRATION_DB_PORT", "5432"))
+	require.NoError(t, err)
+	pool, err := NewPool(context.Background(), port, host,
+		getenv("INTEGRATION_DB_USER", "admin"),
+		getenv("INTEGRATION_DB_PASSWORD", "admin"),
+		getenv("INTEGRATION_DB_NAME", "db"),
+		getenv("INTEGRATION_DB_SSLMODE", "disable"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// candidate - реплика в выборах: leading закрывается, когда она стала лидером,
+// stopped - когда её работа лидера остановлена.
+type candidate struct {
+	cancel  context.CancelFunc
+	leading chan struct{}
+	stopped chan struct{}
+}
+
+func campaign(pool *pgxpool.Pool, lockID int64) *candidate {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &candidate{cancel: cancel, leading: make(chan struct{}), stopped: make(chan struct{})}
+	e := NewElector(pool, lockID, ElectorConfig{RetryInterval: 20 * time.Millisecond, CheckInterval: 20 * time.Millisecond})
+	first := true
+	go e.Run(ctx, func(ctx context.Context) {
+		if !first {
+			<-ctx.Done()
+			return
+		}
+		first = false
+		close(c.leading)
+		<-ctx.Done()
+		close(c.stopped)
+	})
+	return c
+}
+
+func waitClosed(t *testing.T, ch chan struct{}, msg string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal(msg)
+	}
+}
+
+func requireOpen(t *testing.T, ch chan struct{}, msg string) {
+	t.Helper()
+	select {
+	case <-ch:
+		t.Fatal(msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestElectorHandsOverOnStop(t *testing.T) {
+	pool := testPool(t)
+	lockID := time.Now().UnixNano()
+
+	first := campaign(pool, lockID)
+	t.Cleanup(first.cancel)
+	waitClosed(t, first.leading, "first replica did not become the leader")
+
+	second := campaign(pool, lockID)
+	t.Cleanup(second.cancel)
+	requireOpen(t, second.leading, "two leaders at once")
+
+	first.cancel()
+	waitClosed(t, first.stopped, "leader work was not stopped")
+	waitClosed(t, second.leading, "second replica did not take over")
+}
+
+func TestElectorFailsOverWhenConnectionDrops(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	lockID := time.Now().UnixNano()
+
+	first := campaign(pool, lockID)
+	t.Cleanup(first.cancel)
+	waitClosed(t, first.leading, "first replica did not become the leader")
+	second := campaign(pool, lockID)
+	t.Cleanup(second.cancel)
+
+	_, err := pool.Exec(ctx, `
+		SELECT pg_terminate_backend(pid) FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND ((classid::bigint << 32) | objid::bigint) = $1
+	`, lockID)
+	require.NoError(t, err)
+
+	waitClosed(t, first.stopped, "former leader kept working after losing its connection")
+	waitClosed(t, second.leading, "second replica did not take over")
+}
+
+// electorPIDs возвращает процессы сессий, которыми электоры держат или ждут блокировку.
+func electorPIDs(t *testing.T, pool *pgxpool.Pool) []int32 {
+	t.Helper()
+	rows, err := pool.Query(context.Background(),
+		`SELECT pid FROM pg_stat_activity WHERE application_name = $1 ORDER BY pid`, electorAppName)
+	require.NoError(t, err)
+	pids, err := pgx.CollectRows(rows, pgx.RowTo[int32])
+	require.NoError(t, err)
+	return pids
+}
+
+func TestElectorRetriesOnItsOwnConnection(t *testing.T) {
+	pool := testPool(t)
+	lockID := time.Now().UnixNano()
+	before := len(electorPIDs(t, pool))
+
+	first := campaign(pool, lockID)
+	t.Cleanup(first.cancel)
+	waitClosed(t, first.leading, "first replica did not become the leader")
+	second := campaign(pool, lockID)
+	t.Cleanup(second.cancel)
+
+	time.Sleep(100 * time.Millisecond)
+	pids := electorPIDs(t, pool)
+	require.Len(t, pids, before+2, "one connection per elector")
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, pids, electorPIDs(t, pool), "failed attempts reuse the connection")
+}
@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockID - ключ advisory-блокировки, которой сериализуются параллельные запуски миграций.
+const migrationLockID = int64(0x6d69677261746521)
+
+// ErrDirty - предыдущая миграция завершилась с ошибкой вне транзакции (например, у golang-migrate),
+// и схему нужно исправить вручную.
+var ErrDirty = errors.New("database is dirty")
+
+// Migration - пара файлов NNN_name.up.sql / NNN_name.down.sql.
+type Migration struct {
+	Name    string
+	up      string
+	down    string
+	Version int64
+}
+
+// HasDown сообщает, есть ли у миграции down-файл.
+func (m Migration) HasDown() bool {
+	return m.down != ""
+}
+
+// MigrationStatus - миграция и признак того, что она применена.
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Migrator применяет и откатывает миграции из fs.FS. Текущая версия хранится
+// в schema_migrations в формате golang-migrate (одна строка version, dirty), поэтому
+// мигратор можно использовать вперемешку с контейнером migrate/migrate.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator читает миграции из корня fsys.
+func NewMigrator(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Version возвращает текущую версию схемы; 0 - ни одна миграция не применена.
+func (m *Migrator) Version(ctx context.Context) (version int64, dirty bool, err error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+	return readVersion(ctx, m.pool)
+}
+
+// Status возвращает все известные миграции с признаком применения.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	version, _, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		res = append(res, MigrationStatus{Migration: mg, Applied: mg.Version <= version})
+	}
+	return res, nil
+}
+
+// Up применяет все ещё не применённые миграции и возвращает их.
+// Каждая миграция выполняется в своей транзакции вместе с обновлением версии.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, mg := range m.migrations {
+		ok, err := m.step(ctx, func(version int64) (string, int64, bool) {
+			if mg.Version <= version {
+				return "", 0, false
+			}
+			return mg.up, mg.Version, true
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s up failed: %w", mg.Version, mg.Name, err)
+		}
+		if ok {
+			applied = append(applied, mg)
+		}
+	}
+	return applied, nil
+}
+
+// Down откатывает steps последних применённых миграций и возвращает их.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := 0; i < steps; i++ {
+		var current Migration
+		ok, err := m.step(ctx, func(version int64) (string, int64, bool) {
+			idx := m.index(version)
+			if idx < 0 {
+				return "", 0, false
+			}
+			current = m.migrations[idx]
+			var prev int64
+			if idx > 0 {
+				prev = m.migrations[idx-1].Version
+			}
+			return current.down, prev, true
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d_%s down failed: %w", current.Version, current.Name, err)
+		}
+		if !ok {
+			break
+		}
+		reverted = append(reverted, current)
+	}
+	return reverted, nil
+}
+
+// step в одной транзакции под advisory-блокировкой читает версию, спрашивает у plan,
+// какой SQL выполнить и какую версию записать, и применяет это. false - делать нечего.
+func (m *Migrator) step(ctx context.Context, plan func(version int64) (sql string, next int64, ok bool)) (bool, error) {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return false, fmt.Errorf("acquire migration lock: %w", err)
+	}
+
+	version, dirty, err := readVersion(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if dirty {
+		return false, fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+
+	body, next, ok := plan(version)
+	if !ok {
+		return false, nil
+	}
+	if body == "" && next < version {
+		return false, fmt.Errorf("no down migration for version %d", version)
+	}
+
+	if _, err := tx.Exec(ctx, body); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return false, err
+	}
+	if next > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, next); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	const query = `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`
+	if _, err := m.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("create schema_migrations failed: %w", err)
+	}
+	return nil
+}
+
+// index возвращает позицию миграции с данной версией или -1.
+func (m *Migrator) index(version int64) int {
+	for i, mg := range m.migrations {
+		if mg.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func readVersion(ctx context.Context, q queryRower) (version int64, dirty bool, err error) {
+	err = q.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version failed: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// loadMigrations собирает пары up/down из корня fsys, отсортированные по версии.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations failed: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration name %q", e.Name())
+		}
+		base = strings.TrimSuffix(base, "."+direction)
+
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name %q", e.Name())
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", e.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s failed: %w", e.Name(), err)
+		}
+
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: name}
+			byVersion[version] = mg
+		}
+		if direction == "up" {
+			mg.up = string(body)
+		} else {
+			mg.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mg.Version, mg.Name)
+		}
+		migrations = append(migrations, *mg)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/migrations"
+)
+
+func TestEmbeddedMigrationsArePaired(t *testing.T) {
+	loaded, err := loadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+
+	for i, mg := range loaded {
+		require.Equal(t, int64(i+1), mg.Version, "versions are contiguous")
+		require.True(t, mg.HasDown(), "migration %d_%s has no down file", mg.Version, mg.Name)
+	}
+}
+
+func TestLoadMigrationsRejectsInvalidNames(t *testing.T) {
+	_, err := loadMigrations(fstest.MapFS{"init.up.sql": {Data: []byte("SELECT 1")}})
+	require.Error(t, err)
+
+	_, err = loadMigrations(fstest.MapFS{"001_init.sql": {Data: []byte("SELECT 1")}})
+	require.Error(t, err)
+
+	_, err = loadMigrations(fstest.MapFS{"001_init.down.sql": {Data: []byte("SELECT 1")}})
+	require.Error(t, err, "down without up")
+}
+
+func TestLoadMigrationsOrdersByVersion(t *testing.T) {
+	loaded, err := loadMigrations(fstest.MapFS{
+		"010_b.up.sql":   {Data: []byte("B")},
+		"002_a.up.sql":   {Data: []byte("A")},
+		"002_a.down.sql": {Data: []byte("-A")},
+		"embed.go":       {Data: []byte("package migrations")},
+	})
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	require.Equal(t, int64(2), loaded[0].Version)
+	require.Equal(t, "a", loaded[0].Name)
+	require.True(t, loaded[0].HasDown())
+	require.Equal(t, int64(10), loaded[1].Version)
+	require.False(t, loaded[1].HasDown())
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+)
+
+// RegisterPoolMetrics регистрирует в registry метрики pool: соединения по состоянию и
+// ожидание соединения. Значения читаются из pool.Stat при каждом запросе /metrics.
+func RegisterPoolMetrics(registry *metrics.Registry, pool *pgxpool.Pool) {
+	registry.NewGaugeFunc("assigner_db_pool_acquired_connections",
+		"Connections currently in use.", func() float64 { return float64(pool.Stat().AcquiredConns()) })
+	registry.NewGaugeFunc("assigner_db_pool_idle_connections",
+		"Idle connections in the pool.", func() float64 { return float64(pool.Stat().IdleConns()) })
+	registry.NewGaugeFunc("assigner_db_pool_total_connections",
+		"Open connections in the pool.", func() float64 { return float64(pool.Stat().TotalConns()) })
+	registry.NewGaugeFunc("assigner_db_pool_max_connections",
+		"Maximum size of the pool.", func() float64 { return float64(pool.Stat().MaxConns()) })
+	registry.NewCounterFunc("assigner_db_pool_acquires_total",
+		"Successful connection acquires.", func() float64 { return float64(pool.Stat().AcquireCount()) })
+	registry.NewCounterFunc("assigner_db_pool_empty_acquires_total",
+		"Acquires that waited because the pool had no idle connection.",
+		func() float64 { return float64(pool.Stat().EmptyAcquireCount()) })
+	registry.NewCounterFunc("assigner_db_pool_acquire_wait_seconds_total",
+		"Total time spent acquiring connections.", func() float64 { return pool.Stat().AcquireDuration().Seconds() })
+}
@@ -0,0 +1,41 @@
+// Package sqlite предоставляет подключение к SQLite через database/sql.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	// Регистрирует драйвер "sqlite" (pure Go, без cgo).
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// BusyTimeoutMs - сколько SQLite ждёт снятия блокировки файла другим процессом.
+	BusyTimeoutMs = 5000
+)
+
+// NewDB открывает базу SQLite по пути path (":memory:" - база в памяти).
+// Пул ограничен одним соединением: SQLite допускает одного писателя, а транзакции
+// сервиса должны видеть и блокировать одну и ту же базу.
+func NewDB(ctx context.Context, path string) (*sql.DB, error) {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", BusyTimeoutMs))
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite failed: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("sqlite ping failed: %w", err)
+	}
+
+	return db, nil
+}
@@ -3,12 +3,20 @@ package integration
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,7 +24,9 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/VechkanovVV/assigner-pr/internal/api/dto"
+	"github.com/VechkanovVV/assigner-pr/internal/codehost"
 	"github.com/VechkanovVV/assigner-pr/internal/infra/postgres"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
 )
 
 type APIIntegrationTestSuite struct {
@@ -36,7 +46,8 @@ func TestAPIIntegrationSuite(t *testing.T) {
 func (s *APIIntegrationTestSuite) SetupSuite() {
 	s.baseURL = "http://localhost:8080"
 	s.httpClient = &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout:   30 * time.Second,
+		Transport: bearerTransport{token: getenv("INTEGRATION_API_TOKEN", "integration-admin-token")},
 	}
 
 	dbHost := getenv("INTEGRATION_DB_HOST", "localhost")
@@ -108,10 +119,16 @@ func (s *APIIntegrationTestSuite) waitForServiceReady() {
 func (s *APIIntegrationTestSuite) cleanDatabase() {
 	ctx := context.Background()
 	queries := []string{
+		"TRUNCATE webhook_deliveries, outbox_events, webhooks",
+		"TRUNCATE review_slas, email_preferences, notifications, team_chats, reviewer_syncs, inbound_deliveries, user_identities",
+		"TRUNCATE pr_events, audit_log, idempotency_keys",
+		"DELETE FROM assignment_log",
 		"DELETE FROM reviews",
 		"DELETE FROM pull_requests",
 		"DELETE FROM users",
 		"DELETE FROM teams",
+		"DELETE FROM api_tokens WHERE org_id <> 1",
+		"DELETE FROM organizations WHERE id <> 1",
 	}
 
 	for _, query := range queries {
@@ -122,6 +139,19 @@ func (s *APIIntegrationTestSuite) cleanDatabase() {
 	}
 }
 
+// bearerTransport подставляет токен администратора в запросы без заголовка Authorization.
+type bearerTransport struct {
+	token string
+}
+
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 func (s *APIIntegrationTestSuite) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
 	var jsonBody []byte
 	var err error
@@ -639,3 +669,1583 @@ func (s *APIIntegrationTestSuite) TestCompleteWorkflow() {
 	}
 	s.Assert().True(dev2Found, "dev2 should be found in team members")
 }
+
+func (s *APIIntegrationTestSuite) TestAssignmentLog() {
+	teamReq := dto.TeamRequest{
+		TeamName: "log-team",
+		Members: []dto.TeamMember{
+			{UserID: "author1", Username: "Author", IsActive: true},
+			{UserID: "reviewer1", Username: "Reviewer1", IsActive: true},
+			{UserID: "reviewer2", Username: "Reviewer2", IsActive: true},
+			{UserID: "reviewer3", Username: "Reviewer3", IsActive: true},
+			{UserID: "sleeper", Username: "Sleeper", IsActive: false},
+		},
+	}
+
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	prReq := dto.CreatePRRequest{
+		PullRequestID:   "pr-log",
+		PullRequestName: "Log Test",
+		AuthorID:        "author1",
+	}
+
+	resp, err = s.makeRequest("POST", "/pullRequest/create", prReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var prResp map[string]dto.PullRequestResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	originalPR := prResp["pr"]
+	s.Require().Len(originalPR.AssignedReviewers, 2)
+
+	reassignReq := dto.ReassignRequest{
+		PullRequestID: "pr-log",
+		OldReviewerID: originalPR.AssignedReviewers[0],
+	}
+
+	resp, err = s.makeRequest("POST", "/pullRequest/reassign", reassignReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var reassignResp dto.ReassignResponse
+	err = json.NewDecoder(resp.Body).Decode(&reassignResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	resp, err = s.makeRequest("GET", "/pullRequest/assignmentLog?pull_request_id=pr-log", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var logResp dto.AssignmentLogResponse
+	err = json.NewDecoder(resp.Body).Decode(&logResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Require().Len(logResp.Entries, 3)
+
+	created := logResp.Entries[0]
+	s.Assert().Equal("RANDOM", created.Strategy)
+	s.Assert().Equal(3, created.PoolSize)
+	s.Assert().Len(created.Candidates, 3)
+	s.Assert().Empty(created.ReplacedReviewerID)
+	s.Assert().Contains(created.Excluded, dto.ExcludedCandidate{UserID: "author1", Reason: "AUTHOR"})
+	s.Assert().Contains(created.Excluded, dto.ExcludedCandidate{UserID: "sleeper", Reason: "INACTIVE"})
+
+	reassigned := logResp.Entries[2]
+	s.Assert().Equal(reassignResp.ReplacedBy, reassigned.ReviewerID)
+	s.Assert().Equal(originalPR.AssignedReviewers[0], reassigned.ReplacedReviewerID)
+	s.Assert().Equal("WHOLE_POOL", reassigned.Strategy)
+	s.Assert().Equal(1, reassigned.PoolSize)
+	s.Assert().Contains(reassigned.Excluded, dto.ExcludedCandidate{
+		UserID: originalPR.AssignedReviewers[0],
+		Reason: "REPLACED_REVIEWER",
+	})
+	s.Assert().Contains(reassigned.Excluded, dto.ExcludedCandidate{
+		UserID: originalPR.AssignedReviewers[1],
+		Reason: "ALREADY_ASSIGNED",
+	})
+}
+
+func (s *APIIntegrationTestSuite) TestAssignmentLogForNonExistentPR() {
+	resp, err := s.makeRequest("GET", "/pullRequest/assignmentLog?pull_request_id=missing", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+	var errorResp dto.ErrorResponse
+	err = json.NewDecoder(resp.Body).Decode(&errorResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Assert().Equal("NOT_FOUND", errorResp.Error.Code)
+}
+
+func (s *APIIntegrationTestSuite) TestPRHistory() {
+	s.createSeededTeam()
+	pr := s.createSeededPR()
+	replacedBy := s.reassignSeeded(pr.AssignedReviewers[0])
+
+	for i := 0; i < 2; i++ {
+		resp, err := s.makeRequest("POST", "/pullRequest/merge", dto.MergeRequest{PullRequestID: "pr-seeded"})
+		s.Require().NoError(err)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	resp, err := s.makeRequest("GET", "/pullRequest/history?pull_request_id=pr-seeded", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var history dto.PRHistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Assert().Equal("pr-seeded", history.PullRequestID)
+	s.Require().Len(history.Events, 5, "created, 2x reviewer_assigned, reviewer_replaced, merged once")
+
+	types := make([]string, 0, len(history.Events))
+	for _, e := range history.Events {
+		types = append(types, e.Type)
+		s.Assert().Equal("token:bootstrap", e.Actor)
+	}
+	s.Assert().Equal([]string{"created", "reviewer_assigned", "reviewer_assigned", "reviewer_replaced", "merged"}, types)
+
+	s.Assert().JSONEq(`{"pull_request_name":"Seeded","author_id":"author1","status":"OPEN"}`, string(history.Events[0].Payload))
+	s.Assert().JSONEq(
+		fmt.Sprintf(`{"old_reviewer_id":%q,"new_reviewer_id":%q}`, pr.AssignedReviewers[0], replacedBy),
+		string(history.Events[3].Payload),
+	)
+}
+
+func (s *APIIntegrationTestSuite) TestSubmitReview() {
+	s.createSeededTeam()
+	pr := s.createSeededPR()
+	reviewer := pr.AssignedReviewers[0]
+
+	cases := []struct {
+		req    dto.ReviewRequest
+		status int
+	}{
+		{dto.ReviewRequest{PullRequestID: "pr-seeded", ReviewerID: reviewer, State: "lgtm"}, http.StatusBadRequest},
+		{dto.ReviewRequest{PullRequestID: "pr-seeded", State: "approved"}, http.StatusBadRequest},
+		{dto.ReviewRequest{PullRequestID: "pr-seeded", ReviewerID: "author1", State: "approved"}, http.StatusConflict},
+		{dto.ReviewRequest{PullRequestID: "missing", ReviewerID: reviewer, State: "approved"}, http.StatusNotFound},
+		{dto.ReviewRequest{PullRequestID: "pr-seeded", ReviewerID: reviewer, State: "approved"}, http.StatusCreated},
+	}
+	for _, c := range cases {
+		resp, err := s.makeRequest("POST", "/pullRequest/review", c.req)
+		s.Require().NoError(err)
+		s.Assert().Equal(c.status, resp.StatusCode, "%+v", c.req)
+		resp.Body.Close()
+	}
+
+	resp, err := s.makeRequest("GET", "/pullRequest/history?pull_request_id=pr-seeded", nil)
+	s.Require().NoError(err)
+	var history dto.PRHistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	last := history.Events[len(history.Events)-1]
+	s.Assert().Equal("review_submitted", last.Type)
+	s.Assert().Equal("token:bootstrap", last.Actor)
+	s.Assert().JSONEq(fmt.Sprintf(`{"reviewer_id":%q,"state":"approved"}`, reviewer), string(last.Payload))
+}
+
+func (s *APIIntegrationTestSuite) TestPRHistoryIsAppendOnly() {
+	s.createSeededTeam()
+	s.createSeededPR()
+
+	_, err := s.dbPool.Exec(context.Background(), "UPDATE pr_events SET actor = 'someone-else'")
+	s.Require().Error(err)
+
+	_, err = s.dbPool.Exec(context.Background(), "DELETE FROM pr_events")
+	s.Require().Error(err)
+}
+
+func (s *APIIntegrationTestSuite) TestPRHistoryForNonExistentPR() {
+	resp, err := s.makeRequest("GET", "/pullRequest/history?pull_request_id=missing", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func (s *APIIntegrationTestSuite) TestWebhookOutbox() {
+	resp, err := s.makeRequest("POST", "/webhooks", dto.WebhookRequest{
+		URL:        "http://127.0.0.1:1/hook",
+		EventTypes: []string{"pr.created", "pr.merged"},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var created struct {
+		Webhook dto.Webhook `json:"webhook"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().NotZero(created.Webhook.ID)
+	s.Assert().Len(created.Webhook.Secret, 64, "secret is generated when omitted")
+
+	resp, err = s.makeRequest("GET", "/webhooks", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var list dto.WebhookListResponse
+	err = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Require().Len(list.Webhooks, 1)
+	s.Assert().Empty(list.Webhooks[0].Secret, "secret is not listed")
+	s.Assert().Equal([]string{"pr.created", "pr.merged"}, list.Webhooks[0].EventTypes)
+
+	s.createSeededTeam()
+	pr := s.createSeededPR()
+	s.reassignSeeded(pr.AssignedReviewers[0])
+
+	resp, err = s.makeRequest("GET", fmt.Sprintf("/webhooks/deliveries?webhook_id=%d", created.Webhook.ID), nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var deliveries dto.WebhookDeliveriesResponse
+	err = json.NewDecoder(resp.Body).Decode(&deliveries)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Require().Len(deliveries.Deliveries, 1, "reassignment is not subscribed")
+	s.Assert().Equal("pr.created", deliveries.Deliveries[0].EventType)
+
+	var payload struct {
+		ID        string   `json:"pull_request_id"`
+		Reviewers []string `json:"assigned_reviewers"`
+	}
+	s.Require().NoError(json.Unmarshal(deliveries.Deliveries[0].Payload, &payload))
+	s.Assert().Equal("pr-seeded", payload.ID)
+	s.Assert().Equal(pr.AssignedReviewers, payload.Reviewers)
+
+	var outboxCount int
+	err = s.dbPool.QueryRow(context.Background(), "SELECT count(*) FROM outbox_events").Scan(&outboxCount)
+	s.Require().NoError(err)
+	s.Assert().Equal(2, outboxCount, "pr.created and pr.reviewer_reassigned are written to the outbox")
+}
+
+func (s *APIIntegrationTestSuite) TestWebhookValidation() {
+	cases := []dto.WebhookRequest{
+		{URL: "not-a-url"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "http://example.com/hook", EventTypes: []string{"pr.unknown"}},
+	}
+
+	for _, req := range cases {
+		resp, err := s.makeRequest("POST", "/webhooks", req)
+		s.Require().NoError(err)
+		s.Assert().Equal(http.StatusBadRequest, resp.StatusCode, req.URL)
+		resp.Body.Close()
+	}
+
+	resp, err := s.makeRequest("GET", "/webhooks/deliveries?status=LOST", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+}
+
+// Секреты входящих вебхуков test-app из docker-compose.test.yml.
+const (
+	testGitHubSecret = "github-test-secret"
+	testGitLabToken  = "gitlab-test-token"
+)
+
+func (s *APIIntegrationTestSuite) createIntegrationTeam() {
+	teamReq := dto.TeamRequest{
+		TeamName: "integration-team",
+		Members: []dto.TeamMember{
+			{UserID: "octocat", Username: "Octocat", IsActive: true},
+			{UserID: "gl-admin", Username: "Admin", IsActive: true},
+			{UserID: "reviewer1", Username: "Reviewer1", IsActive: true},
+			{UserID: "reviewer2", Username: "Reviewer2", IsActive: true},
+		},
+	}
+
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+}
+
+// sendCodeHostFixture отправляет записанный вебхук из internal/codehost/testdata.
+// Для ответов не 200 в Result возвращается HTTP-статус.
+func (s *APIIntegrationTestSuite) sendCodeHostFixture(provider, fixture, delivery string, header http.Header) dto.IngestResponse {
+	body, err := os.ReadFile(filepath.Join("..", "codehost", "testdata", fixture))
+	s.Require().NoError(err)
+
+	req, err := http.NewRequest("POST", s.baseURL+"/integrations/"+provider+"/webhook", bytes.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	switch provider {
+	case "github":
+		req.Header.Set(codehost.GitHubEventHeader, "pull_request")
+		req.Header.Set(codehost.GitHubDeliveryHeader, delivery)
+		if req.Header.Get(codehost.GitHubSignatureHeader) == "" {
+			mac := hmac.New(sha256.New, []byte(testGitHubSecret))
+			mac.Write(body)
+			req.Header.Set(codehost.GitHubSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+	case "gitlab":
+		req.Header.Set(codehost.GitLabEventHeader, "Merge Request Hook")
+		req.Header.Set(codehost.GitLabDeliveryHeader, delivery)
+		if req.Header.Get(codehost.GitLabTokenHeader) == "" {
+			req.Header.Set(codehost.GitLabTokenHeader, testGitLabToken)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	var res dto.IngestResponse
+	if resp.StatusCode == http.StatusOK {
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&res))
+	} else {
+		res.Result = strconv.Itoa(resp.StatusCode)
+	}
+	return res
+}
+
+// prState возвращает статус pr и типы событий его истории.
+func (s *APIIntegrationTestSuite) prState(prID string) (string, []string) {
+	resp, err := s.makeRequest("GET", "/pullRequest/history?pull_request_id="+url.QueryEscape(prID), nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var history dto.PRHistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	types := make([]string, 0, len(history.Events))
+	for _, e := range history.Events {
+		types = append(types, e.Type)
+	}
+
+	var status string
+	err = s.dbPool.QueryRow(context.Background(), "SELECT status::text FROM pull_requests WHERE pull_request_id = $1", prID).Scan(&status)
+	s.Require().NoError(err)
+	return status, types
+}
+
+func (s *APIIntegrationTestSuite) TestGitHubWebhookLifecycle() {
+	s.createIntegrationTeam()
+	const prID = "github:acme/widgets#42"
+
+	res := s.sendCodeHostFixture("github", "github_pull_request_opened.json", "d-open", nil)
+	s.Require().Equal("applied", res.Result)
+	s.Assert().Equal(prID, res.PullRequestID)
+
+	res = s.sendCodeHostFixture("github", "github_pull_request_opened.json", "d-open", nil)
+	s.Assert().Equal("duplicate", res.Result, "redelivery is not applied twice")
+
+	res = s.sendCodeHostFixture("github", "github_pull_request_closed.json", "d-close", nil)
+	s.Require().Equal("applied", res.Result)
+	status, _ := s.prState(prID)
+	s.Assert().Equal("CLOSED", status)
+
+	res = s.sendCodeHostFixture("github", "github_pull_request_reopened.json", "d-reopen", nil)
+	s.Require().Equal("applied", res.Result)
+
+	res = s.sendCodeHostFixture("github", "github_pull_request_merged.json", "d-merge", nil)
+	s.Require().Equal("applied", res.Result)
+	res = s.sendCodeHostFixture("github", "github_pull_request_merged.json", "d-merge", nil)
+	s.Assert().Equal("duplicate", res.Result)
+
+	status, types := s.prState(prID)
+	s.Assert().Equal("MERGED", status)
+	s.Assert().Equal([]string{"created", "reviewer_assigned", "reviewer_assigned", "closed", "reopened", "merged"}, types)
+}
+
+func (s *APIIntegrationTestSuite) TestGitHubWebhookRejectsBadSignature() {
+	s.createIntegrationTeam()
+
+	header := http.Header{}
+	header.Set(codehost.GitHubSignatureHeader, "sha256=00")
+	res := s.sendCodeHostFixture("github", "github_pull_request_opened.json", "d-bad", header)
+	s.Assert().Equal("401", res.Result)
+
+	res = s.sendCodeHostFixture("github", "github_pull_request_opened.json", "d-bad", nil)
+	s.Assert().Equal("applied", res.Result, "rejected delivery is not recorded")
+}
+
+func (s *APIIntegrationTestSuite) TestInboundSecretsScopeDeliveriesToOrganization() {
+	for _, org := range []string{"acme", "beta"} {
+		resp, err := s.makeRequest("POST", "/admin/orgs", dto.OrganizationRequest{Name: org})
+		s.Require().NoError(err)
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+	resp := s.makeOrgRequest("POST", "/team/add", "acme", "", dto.TeamRequest{
+		TeamName: "integration-team",
+		Members: []dto.TeamMember{
+			{UserID: "octocat", Username: "Octocat", IsActive: true},
+			{UserID: "reviewer1", Username: "Reviewer1", IsActive: true},
+		},
+	})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.makeOrgRequest("POST", "/integrations/secrets", "acme", "", dto.InboundSecretRequest{Provider: "github"})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	var secretResp map[string]dto.InboundSecret
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&secretResp))
+	resp.Body.Close()
+	acmeSecret := secretResp["secret"].Secret
+	s.Require().NotEmpty(acmeSecret)
+
+	body, err := os.ReadFile(filepath.Join("..", "codehost", "testdata", "github_pull_request_opened.json"))
+	s.Require().NoError(err)
+	mac := hmac.New(sha256.New, []byte(acmeSecret))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set(codehost.GitHubSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	header.Set("X-Org", "beta")
+
+	res := s.sendCodeHostFixture("github", "github_pull_request_opened.json", "d-acme", header)
+	s.Require().Equal("applied", res.Result, "X-Org does not select the organization")
+	resp = s.makeOrgRequest("GET", "/pullRequest/history?pull_request_id="+url.QueryEscape(res.PullRequestID), "acme", "", nil)
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	resp = s.makeOrgRequest("GET", "/pullRequest/history?pull_request_id="+url.QueryEscape(res.PullRequestID), "beta", "", nil)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode, "delivery signed for acme is not applied in beta")
+	resp.Body.Close()
+	resp = s.makeOrgRequest("GET", "/pullRequest/history?pull_request_id="+url.QueryEscape(res.PullRequestID), storage.DefaultOrgName, "", nil)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.makeOrgRequest("DELETE", "/integrations/secrets?provider=github", "acme", "", nil)
+	s.Require().Equal(http.StatusNoContent, resp.StatusCode)
+	resp.Body.Close()
+	res = s.sendCodeHostFixture("github", "github_pull_request_opened.json", "d-acme-2", header)
+	s.Assert().Equal("401", res.Result, "deleted secret no longer verifies deliveries")
+}
+
+func (s *APIIntegrationTestSuite) TestGitLabWebhookWithIdentityMapping() {
+	s.createIntegrationTeam()
+
+	res := s.sendCodeHostFixture("gitlab", "gitlab_merge_request_open.json", "uuid-open", nil)
+	s.Require().Equal("404", res.Result, "GitLab user root is not mapped yet")
+
+	resp, err := s.makeRequest("POST", "/integrations/identities", dto.IdentityRequest{Provider: "gitlab", Login: "root", UserID: "gl-admin"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	res = s.sendCodeHostFixture("gitlab", "gitlab_merge_request_open.json", "uuid-open", nil)
+	s.Require().Equal("applied", res.Result, "failed delivery is processed on retry")
+	s.Assert().Equal("gitlab:gitlabhq/gitlab-test!1", res.PullRequestID)
+
+	res = s.sendCodeHostFixture("gitlab", "gitlab_merge_request_merge.json", "uuid-merge", nil)
+	s.Require().Equal("applied", res.Result)
+	status, _ := s.prState("gitlab:gitlabhq/gitlab-test!1")
+	s.Assert().Equal("MERGED", status)
+
+	header := http.Header{}
+	header.Set(codehost.GitLabTokenHeader, "wrong")
+	res = s.sendCodeHostFixture("gitlab", "gitlab_merge_request_close.json", "uuid-close", header)
+	s.Assert().Equal("401", res.Result)
+}
+
+func (s *APIIntegrationTestSuite) TestTeamChatNotifications() {
+	s.createSeededTeam()
+
+	resp, err := s.makeRequest("POST", "/team/chat", dto.TeamChatRequest{TeamName: "seeded-team", WebhookURL: "ftp://chat"})
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/team/chat", dto.TeamChatRequest{TeamName: "missing", WebhookURL: "http://127.0.0.1:9/hooks/x"})
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	// Чат недоступен: уведомление остаётся в очереди, а PR создаётся.
+	resp, err = s.makeRequest("POST", "/team/chat", dto.TeamChatRequest{TeamName: "seeded-team", WebhookURL: "http://127.0.0.1:9/hooks/x"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/team/chat?team_name=seeded-team", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var chatResp map[string]dto.TeamChat
+	err = json.NewDecoder(resp.Body).Decode(&chatResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Equal("http://127.0.0.1:9/hooks/x", chatResp["team_chat"].WebhookURL)
+
+	pr := s.createSeededPR()
+	s.Require().NotEmpty(pr.AssignedReviewers)
+
+	resp, err = s.makeRequest("GET", "/notifications?limit=10", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var notifications dto.NotificationsResponse
+	err = json.NewDecoder(resp.Body).Decode(&notifications)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Require().Len(notifications.Notifications, 1)
+	s.Assert().Equal("chat", notifications.Notifications[0].Channel)
+	s.Assert().Contains(notifications.Notifications[0].Body, "pr-seeded")
+
+	resp, err = s.makeRequest("DELETE", "/team/chat?team_name=seeded-team", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNoContent, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func (s *APIIntegrationTestSuite) TestEmailPreferences() {
+	s.createSeededTeam()
+
+	for _, req := range []dto.EmailPreferenceRequest{
+		{UserID: "reviewer1", Email: "reviewer1@example.com", Mode: "weekly"},
+		{UserID: "reviewer1", Email: "not-an-address", Mode: "immediate"},
+		{UserID: "reviewer1", Mode: "digest"},
+	} {
+		resp, err := s.makeRequest("POST", "/users/setEmailPreference", req)
+		s.Require().NoError(err)
+		s.Assert().Equal(http.StatusBadRequest, resp.StatusCode, "%+v", req)
+		resp.Body.Close()
+	}
+
+	resp, err := s.makeRequest("POST", "/users/setEmailPreference", dto.EmailPreferenceRequest{UserID: "ghost", Email: "ghost@example.com", Mode: "digest"})
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/users/getEmailPreference?user_id=reviewer1", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/users/setEmailPreference", dto.EmailPreferenceRequest{UserID: "reviewer1", Email: "Reviewer One <reviewer1@example.com>", Mode: "digest"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/users/setEmailPreference", dto.EmailPreferenceRequest{UserID: "reviewer2", Mode: "none"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/users/getEmailPreference?user_id=reviewer1", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var prefResp map[string]dto.EmailPreference
+	err = json.NewDecoder(resp.Body).Decode(&prefResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Equal("reviewer1@example.com", prefResp["email_preference"].Email)
+	s.Assert().Equal("digest", prefResp["email_preference"].Mode)
+}
+
+func (s *APIIntegrationTestSuite) TestReviewSLA() {
+	s.createSeededTeam()
+
+	for _, req := range []dto.TeamSLARequest{
+		{TeamName: "seeded-team", Within: "soon"},
+		{TeamName: "seeded-team", Within: "-1h"},
+		{TeamName: "seeded-team", Within: "24h", Escalation: "page"},
+		{TeamName: "seeded-team", Within: "24h", Escalation: "reassign", EscalateAfter: "12h"},
+		{TeamName: "seeded-team", Within: "24h", Escalation: "lead", EscalateAfter: "48h"},
+	} {
+		resp, err := s.makeRequest("POST", "/team/sla", req)
+		s.Require().NoError(err)
+		s.Assert().Equal(http.StatusBadRequest, resp.StatusCode, "%+v", req)
+		resp.Body.Close()
+	}
+
+	resp, err := s.makeRequest("POST", "/team/sla", dto.TeamSLARequest{
+		TeamName: "seeded-team", Within: "24h", Escalation: "lead", EscalateAfter: "48h", LeadID: "ghost",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	// Без срока у команды SLA PR пуст.
+	pr := s.createSeededPR()
+	resp, err = s.makeRequest("GET", "/pullRequest/sla?pull_request_id=pr-seeded", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var prSLA dto.PRSLAResponse
+	err = json.NewDecoder(resp.Body).Decode(&prSLA)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Nil(prSLA.SLA)
+	s.Assert().Len(prSLA.Reviewers, len(pr.AssignedReviewers))
+
+	resp, err = s.makeRequest("POST", "/team/sla", dto.TeamSLARequest{
+		TeamName: "seeded-team", Within: "24h", Escalation: "lead", EscalateAfter: "48h", LeadID: "reviewer5",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/team/sla?team_name=seeded-team", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var slaResp map[string]dto.TeamSLA
+	err = json.NewDecoder(resp.Body).Decode(&slaResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Equal("24h0m0s", slaResp["team_sla"].Within)
+	s.Assert().Equal("lead", slaResp["team_sla"].Escalation)
+	s.Assert().Equal("48h0m0s", slaResp["team_sla"].EscalateAfter)
+	s.Assert().Equal("reviewer5", slaResp["team_sla"].LeadID)
+
+	resp, err = s.makeRequest("GET", "/pullRequest/sla?pull_request_id=pr-seeded", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	prSLA = dto.PRSLAResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&prSLA)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Require().NotNil(prSLA.SLA)
+	s.Assert().Equal("OPEN", prSLA.Status)
+	for _, r := range prSLA.Reviewers {
+		s.Assert().Equal("ON_TRACK", r.State)
+		s.Require().NotNil(r.DueAt)
+		s.Assert().WithinDuration(r.AssignedAt.Add(24*time.Hour), *r.DueAt, time.Second)
+	}
+
+	resp, err = s.makeRequest("GET", "/pullRequest/sla?pull_request_id=missing", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("DELETE", "/team/sla?team_name=seeded-team", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNoContent, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/team/sla?team_name=seeded-team", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func (s *APIIntegrationTestSuite) requestWithToken(method, endpoint, token string) *http.Response {
+	req, err := http.NewRequest(method, s.baseURL+endpoint, nil)
+	s.Require().NoError(err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func (s *APIIntegrationTestSuite) TestAPITokens() {
+	s.createSeededTeam()
+
+	resp := s.requestWithToken("GET", "/team/get?team_name=seeded-team", "")
+	s.Assert().Equal(http.StatusUnauthorized, resp.StatusCode)
+	s.Assert().Contains(resp.Header.Get("WWW-Authenticate"), "Bearer")
+	var errResp dto.ErrorResponse
+	err := json.NewDecoder(resp.Body).Decode(&errResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Equal("UNAUTHORIZED", errResp.Error.Code)
+
+	resp = s.requestWithToken("GET", "/team/get?team_name=seeded-team", "apr_unknown")
+	s.Assert().Equal(http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.requestWithToken("GET", "/health", "")
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	for _, req := range []dto.APITokenRequest{
+		{Scopes: []string{"read"}},
+		{Name: "reader"},
+		{Name: "reader", Scopes: []string{"write-everything"}},
+	} {
+		resp, err := s.makeRequest("POST", "/admin/tokens", req)
+		s.Require().NoError(err)
+		s.Assert().Equal(http.StatusBadRequest, resp.StatusCode, "%+v", req)
+		resp.Body.Close()
+	}
+
+	resp, err = s.makeRequest("POST", "/admin/tokens", dto.APITokenRequest{Name: "reader", Scopes: []string{"read"}})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	var tokenResp map[string]dto.APIToken
+	err = json.NewDecoder(resp.Body).Decode(&tokenResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	reader := tokenResp["token"]
+	s.Require().NotEmpty(reader.Token)
+	s.Assert().Equal([]string{"read"}, reader.Scopes)
+
+	resp = s.requestWithToken("GET", "/team/get?team_name=seeded-team", reader.Token)
+	s.Assert().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.requestWithToken("POST", "/pullRequest/merge", reader.Token)
+	s.Assert().Equal(http.StatusForbidden, resp.StatusCode)
+	errResp = dto.ErrorResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&errResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Equal("FORBIDDEN", errResp.Error.Code)
+
+	resp = s.requestWithToken("GET", "/admin/tokens", reader.Token)
+	s.Assert().Equal(http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/admin/tokens", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var list dto.APITokenListResponse
+	err = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	found := false
+	for _, t := range list.Tokens {
+		s.Assert().Empty(t.Token, "secrets are never listed")
+		found = found || t.ID == reader.ID
+	}
+	s.Assert().True(found)
+
+	resp, err = s.makeRequest("DELETE", fmt.Sprintf("/admin/tokens?id=%d", reader.ID), nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusNoContent, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.requestWithToken("GET", "/team/get?team_name=seeded-team", reader.Token)
+	s.Assert().Equal(http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+}
+
+// makeOrgRequest выполняет запрос администратора в организации org (заголовок X-Org).
+func (s *APIIntegrationTestSuite) makeOrgRequest(method, endpoint, org, token string, body any) *http.Response {
+	jsonBody, err := json.Marshal(body)
+	s.Require().NoError(err)
+	req, err := http.NewRequest(method, s.baseURL+endpoint, bytes.NewBuffer(jsonBody))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	if org != "" {
+		req.Header.Set("X-Org", org)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.httpClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func (s *APIIntegrationTestSuite) TestOrganizationsIsolateTenants() {
+	s.createSeededTeam()
+
+	resp, err := s.makeRequest("POST", "/admin/orgs", dto.OrganizationRequest{})
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/admin/orgs", dto.OrganizationRequest{Name: "acme"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+	resp, err = s.makeRequest("POST", "/admin/orgs", dto.OrganizationRequest{Name: "acme"})
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusConflict, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.makeOrgRequest("GET", "/team/get?team_name=seeded-team", "acme", "", nil)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode, "teams of the default organization are invisible in acme")
+	resp.Body.Close()
+	resp = s.makeOrgRequest("GET", "/team/get?team_name=seeded-team", "missing", "", nil)
+	s.Assert().Equal(http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.makeOrgRequest("POST", "/team/add", "acme", "", dto.TeamRequest{
+		TeamName: "seeded-team",
+		Members:  []dto.TeamMember{{UserID: "acme-1", Username: "Ann", IsActive: true}},
+	})
+	s.Assert().Equal(http.StatusCreated, resp.StatusCode, "team names are unique per organization")
+	resp.Body.Close()
+	resp = s.makeOrgRequest("POST", "/team/add", "acme", "", dto.TeamRequest{
+		TeamName: "stolen",
+		Members:  []dto.TeamMember{{UserID: "author1", Username: "Thief", IsActive: true}},
+	})
+	s.Assert().Equal(http.StatusCreated, resp.StatusCode, "user ids are unique per organization")
+	resp.Body.Close()
+	resp, err = s.makeRequest("GET", "/team/get?team_name=seeded-team", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var seeded dto.TeamResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&seeded))
+	resp.Body.Close()
+	for _, m := range seeded.Members {
+		if m.UserID == "author1" {
+			s.Assert().Equal("Author", m.Username, "the user of the default organization is unchanged")
+		}
+	}
+
+	resp = s.makeOrgRequest("POST", "/admin/tokens", "acme", "", dto.APITokenRequest{Name: "acme-ci", Scopes: []string{"read"}})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	var tokenResp map[string]dto.APIToken
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&tokenResp))
+	resp.Body.Close()
+	acmeToken := tokenResp["token"].Token
+
+	resp = s.makeOrgRequest("GET", "/team/get?team_name=seeded-team", "", acmeToken, nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var team dto.TeamResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&team))
+	resp.Body.Close()
+	s.Require().Len(team.Members, 1)
+	s.Assert().Equal("acme-1", team.Members[0].UserID, "the token works in its own organization")
+
+	resp = s.makeOrgRequest("GET", "/team/get?team_name=seeded-team", storage.DefaultOrgName, acmeToken, nil)
+	s.Assert().Equal(http.StatusForbidden, resp.StatusCode, "only default organization admins may switch organizations")
+	resp.Body.Close()
+	resp = s.makeOrgRequest("GET", "/admin/orgs", "", acmeToken, nil)
+	s.Assert().Equal(http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/admin/orgs", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var orgs dto.OrganizationListResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&orgs))
+	resp.Body.Close()
+	s.Require().Len(orgs.Organizations, 2)
+	s.Assert().Equal(storage.DefaultOrgName, orgs.Organizations[0].Name)
+	s.Assert().Equal("acme", orgs.Organizations[1].Name)
+}
+
+func (s *APIIntegrationTestSuite) TestAuditLog() {
+	s.createSeededTeam()
+
+	req, err := http.NewRequest("POST", s.baseURL+"/users/setIsActive",
+		bytes.NewBufferString(`{"user_id": "reviewer1", "is_active": false}`))
+	s.Require().NoError(err)
+	req.Header.Set("X-Request-ID", "audit-req-1")
+	resp, err := s.httpClient.Do(req)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal("audit-req-1", resp.Header.Get("X-Request-ID"))
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/admin/audit?target_type=user&target_id=reviewer1", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var audit dto.AuditLogResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&audit))
+	resp.Body.Close()
+	s.Require().Len(audit.Entries, 1)
+	entry := audit.Entries[0]
+	s.Assert().Equal("user.set_active", entry.Action)
+	s.Assert().Equal("token:bootstrap", entry.Actor)
+	s.Assert().Equal("audit-req-1", entry.RequestID)
+	s.Assert().NotEmpty(entry.SourceIP)
+	s.Assert().JSONEq(`{"user_id":"reviewer1","username":"Reviewer1","team_id":`+
+		strconv.Itoa(s.teamIDFromDB("seeded-team"))+`,"is_active":true}`, string(entry.Before))
+	var after map[string]any
+	s.Require().NoError(json.Unmarshal(entry.After, &after))
+	s.Assert().Equal(false, after["is_active"])
+
+	resp, err = s.makeRequest("GET", "/admin/audit?limit=1", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&audit))
+	resp.Body.Close()
+	s.Require().Len(audit.Entries, 1)
+	s.Assert().Equal("user.set_active", audit.Entries[0].Action, "newest first")
+	s.Require().NotZero(audit.NextCursor)
+
+	resp, err = s.makeRequest("GET", "/admin/audit?limit=1&cursor="+strconv.FormatInt(audit.NextCursor, 10), nil)
+	s.Require().NoError(err)
+	audit = dto.AuditLogResponse{}
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&audit))
+	resp.Body.Close()
+	s.Require().Len(audit.Entries, 1)
+	s.Assert().Equal("team.create", audit.Entries[0].Action)
+	s.Assert().Equal("seeded-team", audit.Entries[0].TargetID)
+	s.Assert().Zero(audit.NextCursor, "last page")
+
+	resp, err = s.makeRequest("GET", "/admin/audit?since=yesterday", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+}
+
+// TestRateLimit опирается на RATE_LIMIT_ROUTES тестового окружения: GET /notifications=1/m:2.
+func (s *APIIntegrationTestSuite) TestRateLimit() {
+	resp, err := s.makeRequest("POST", "/admin/tokens", dto.APITokenRequest{Name: "hammer", Scopes: []string{"admin"}})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	var tokenResp map[string]dto.APIToken
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&tokenResp))
+	resp.Body.Close()
+	hammer := tokenResp["token"].Token
+
+	for i := 0; i < 2; i++ {
+		resp = s.requestWithToken("GET", "/notifications", hammer)
+		s.Assert().Equal(http.StatusOK, resp.StatusCode, "request %d fits the burst", i)
+		resp.Body.Close()
+	}
+
+	resp = s.requestWithToken("GET", "/notifications", hammer)
+	s.Require().Equal(http.StatusTooManyRequests, resp.StatusCode)
+	retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	s.Require().NoError(err)
+	s.Assert().Positive(retryAfter)
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	s.Assert().Equal("RATE_LIMITED", errResp.Error.Code)
+
+	resp, err = s.makeRequest("GET", "/notifications", nil)
+	s.Require().NoError(err)
+	s.Assert().Equal(http.StatusOK, resp.StatusCode, "other clients have their own bucket")
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/admin/tokens", dto.APITokenRequest{Name: "hammer", Scopes: []string{"admin"}})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&tokenResp))
+	resp.Body.Close()
+	resp = s.requestWithToken("GET", "/notifications", tokenResp["token"].Token)
+	s.Assert().Equal(http.StatusOK, resp.StatusCode, "a token with the same name has its own bucket")
+	resp.Body.Close()
+
+	resp = s.requestWithToken("GET", "/metrics", "")
+	s.Assert().Equal(http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/metrics", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Contains(string(body), `assigner_http_rate_limited_total{route="GET /notifications",client="token"}`)
+}
+
+func (s *APIIntegrationTestSuite) TestMetrics() {
+	teamReq := dto.TeamRequest{
+		TeamName: "metrics-team",
+		Members: []dto.TeamMember{
+			{UserID: "metrics-author", Username: "Author", IsActive: true},
+			{UserID: "metrics-reviewer", Username: "Reviewer", IsActive: true},
+		},
+	}
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/pullRequest/create", dto.CreatePRRequest{
+		PullRequestID:   "metrics-pr",
+		PullRequestName: "Metrics",
+		AuthorID:        "metrics-author",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/pullRequest/reassign", dto.ReassignRequest{
+		PullRequestID: "metrics-pr",
+		OldReviewerID: "metrics-reviewer",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/pullRequest/merge", dto.MergeRequest{PullRequestID: "metrics-pr"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/metrics", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	metrics := string(body)
+	s.Assert().Contains(metrics, `assigner_http_requests_total{route="POST /pullRequest/create",status="201"}`)
+	s.Assert().Contains(metrics, `assigner_http_request_duration_seconds_bucket{route="POST /pullRequest/merge",status="200",le="+Inf"}`)
+	s.Assert().Contains(metrics, `assigner_pull_requests_created_total{org="1",team="metrics-team"} 1`)
+	s.Assert().Contains(metrics, `assigner_reviewers_assigned_total{org="1",team="metrics-team"} 1`)
+	s.Assert().Contains(metrics, `assigner_no_candidate_total{org="1",team="metrics-team"} 1`)
+	s.Assert().Contains(metrics, `assigner_pull_requests_merged_total{org="1",team="metrics-team"} 1`)
+	s.Assert().Contains(metrics, "assigner_db_pool_total_connections ")
+	s.Assert().Contains(metrics, "assigner_db_pool_acquire_wait_seconds_total ")
+}
+
+func (s *APIIntegrationTestSuite) idempotentPost(endpoint, key string, body any) *http.Response {
+	jsonBody, err := json.Marshal(body)
+	s.Require().NoError(err)
+	req, err := http.NewRequest("POST", s.baseURL+endpoint, bytes.NewBuffer(jsonBody))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	resp, err := s.httpClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+// idempotentPostAs выполняет idempotentPost от имени API-токена token.
+func (s *APIIntegrationTestSuite) idempotentPostAs(token, endpoint, key string, body any) *http.Response {
+	jsonBody, err := json.Marshal(body)
+	s.Require().NoError(err)
+	req, err := http.NewRequest("POST", s.baseURL+endpoint, bytes.NewBuffer(jsonBody))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := s.httpClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func (s *APIIntegrationTestSuite) TestIdempotencyKeys() {
+	s.createSeededTeam()
+	prReq := dto.CreatePRRequest{PullRequestID: "pr-retry", PullRequestName: "Retry", AuthorID: "author1"}
+
+	resp := s.idempotentPost("/pullRequest/create", "create-1", prReq)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	s.Require().Equal(`"1"`, resp.Header.Get("ETag"))
+	first, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	resp = s.idempotentPost("/pullRequest/create", "create-1", prReq)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode, "a retry gets the original response, not PR_EXISTS")
+	s.Assert().Equal("true", resp.Header.Get("Idempotent-Replayed"))
+	s.Assert().Equal(`"1"`, resp.Header.Get("ETag"), "the replay keeps the original ETag")
+	replayed, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Assert().Equal(string(first), string(replayed))
+
+	var created map[string]dto.PullRequestResponse
+	s.Require().NoError(json.Unmarshal(first, &created))
+	s.Require().NotEmpty(created["pr"].AssignedReviewers)
+	reassignReq := dto.ReassignRequest{PullRequestID: "pr-retry", OldReviewerID: created["pr"].AssignedReviewers[0]}
+
+	resp = s.idempotentPost("/pullRequest/reassign", "reassign-1", reassignReq)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var reassigned dto.ReassignResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&reassigned))
+	resp.Body.Close()
+
+	resp = s.idempotentPost("/pullRequest/reassign", "reassign-1", reassignReq)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	var retried dto.ReassignResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&retried))
+	resp.Body.Close()
+	s.Assert().Equal(reassigned.ReplacedBy, retried.ReplacedBy, "a retry does not pick another reviewer")
+	s.Assert().ElementsMatch(reassigned.PullRequest.AssignedReviewers, s.reviewersFromDB("pr-retry"))
+
+	resp = s.idempotentPost("/pullRequest/create", "create-1",
+		dto.CreatePRRequest{PullRequestID: "pr-other", PullRequestName: "Other", AuthorID: "author1"})
+	s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	s.Assert().Equal("IDEMPOTENCY_KEY_REUSED", errResp.Error.Code)
+
+	resp = s.idempotentPost("/pullRequest/create", "create-large",
+		dto.CreatePRRequest{PullRequestID: "pr-large", PullRequestName: strings.Repeat("x", 2<<20), AuthorID: "author1"})
+	s.Require().Equal(http.StatusRequestEntityTooLarge, resp.StatusCode)
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	s.Assert().Equal("REQUEST_TOO_LARGE", errResp.Error.Code)
+
+	// Имена токенов не уникальны: токены с одним именем не делят ключи идемпотентности.
+	var bots []string
+	for range 2 {
+		resp, err = s.makeRequest("POST", "/admin/tokens", dto.APITokenRequest{Name: "retry-bot", Scopes: []string{"admin"}})
+		s.Require().NoError(err)
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		var tokenResp map[string]dto.APIToken
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&tokenResp))
+		resp.Body.Close()
+		bots = append(bots, tokenResp["token"].Token)
+	}
+	sharedReq := dto.CreatePRRequest{PullRequestID: "pr-bot", PullRequestName: "Bot", AuthorID: "author1"}
+	resp = s.idempotentPostAs(bots[0], "/pullRequest/create", "bot-1", sharedReq)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+	resp = s.idempotentPostAs(bots[1], "/pullRequest/create", "bot-1", sharedReq)
+	s.Assert().Equal(http.StatusConflict, resp.StatusCode, "the other token's response is not replayed")
+	s.Assert().Empty(resp.Header.Get("Idempotent-Replayed"))
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	s.Assert().Equal("PR_EXISTS", errResp.Error.Code)
+}
+
+func (s *APIIntegrationTestSuite) ifMatchPost(endpoint, etag string, body any) *http.Response {
+	jsonBody, err := json.Marshal(body)
+	s.Require().NoError(err)
+	req, err := http.NewRequest("POST", s.baseURL+endpoint, bytes.NewBuffer(jsonBody))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", etag)
+	resp, err := s.httpClient.Do(req)
+	s.Require().NoError(err)
+	return resp
+}
+
+func (s *APIIntegrationTestSuite) TestOptimisticConcurrency() {
+	pr := s.setupConcurrencyPR("versions", "pr-versions", 4)
+	s.Assert().EqualValues(1, pr.Version)
+
+	resp, err := s.makeRequest("GET", "/pullRequest/get?pull_request_id=pr-versions", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	resp.Body.Close()
+	s.Require().Equal(`"1"`, etag)
+
+	resp = s.ifMatchPost("/pullRequest/reassign", etag,
+		dto.ReassignRequest{PullRequestID: "pr-versions", OldReviewerID: pr.AssignedReviewers[0]})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(`"2"`, resp.Header.Get("ETag"))
+	resp.Body.Close()
+
+	resp = s.ifMatchPost("/pullRequest/merge", etag, dto.MergeRequest{PullRequestID: "pr-versions"})
+	s.Require().Equal(http.StatusPreconditionFailed, resp.StatusCode, "the PR changed since the ETag was read")
+	var errResp dto.ErrorResponse
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+	resp.Body.Close()
+	s.Assert().Equal("VERSION_CONFLICT", errResp.Error.Code)
+	status, _ := s.prState("pr-versions")
+	s.Assert().Equal("OPEN", status)
+
+	resp = s.ifMatchPost("/pullRequest/merge", `"2"`, dto.MergeRequest{PullRequestID: "pr-versions"})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = s.ifMatchPost("/pullRequest/merge", "2", dto.MergeRequest{PullRequestID: "pr-versions"})
+	s.Assert().Equal(http.StatusBadRequest, resp.StatusCode, "If-Match must be a quoted ETag")
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/users/get?user_id=versions-author", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(`"1"`, resp.Header.Get("ETag"))
+	resp.Body.Close()
+
+	resp = s.ifMatchPost("/users/setIsActive", `"1"`, dto.SetActiveRequest{UserID: "versions-author", IsActive: false})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(`"2"`, resp.Header.Get("ETag"))
+	resp.Body.Close()
+
+	resp = s.ifMatchPost("/users/setIsActive", `"1"`, dto.SetActiveRequest{UserID: "versions-author", IsActive: true})
+	s.Assert().Equal(http.StatusPreconditionFailed, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("GET", "/team/get?team_name=versions", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(`"2"`, resp.Header.Get("ETag"), "member activity bumps the team version")
+	resp.Body.Close()
+
+	resp = s.ifMatchPost("/team/chat", `"2"`, dto.TeamChatRequest{TeamName: "versions", WebhookURL: "http://127.0.0.1:9/hooks/x"})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	resp, err = s.makeRequest("GET", "/team/chat?team_name=versions", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(`"3"`, resp.Header.Get("ETag"), "team settings carry the team version")
+	resp.Body.Close()
+
+	resp = s.ifMatchPost("/team/sla", `"3"`, dto.TeamSLARequest{TeamName: "versions", Within: "24h"})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	resp, err = s.makeRequest("GET", "/team/sla?team_name=versions", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Assert().Equal(`"4"`, resp.Header.Get("ETag"))
+	resp.Body.Close()
+}
+
+func (s *APIIntegrationTestSuite) teamIDFromDB(teamName string) int {
+	var id int
+	err := s.dbPool.QueryRow(context.Background(), `SELECT id FROM teams WHERE team_name = $1`, teamName).Scan(&id)
+	s.Require().NoError(err)
+	return id
+}
+
+func (s *APIIntegrationTestSuite) TestPreviewPRDoesNotPersist() {
+	teamReq := dto.TeamRequest{
+		TeamName: "preview-team",
+		Members: []dto.TeamMember{
+			{UserID: "author1", Username: "Author", IsActive: true},
+			{UserID: "reviewer1", Username: "Reviewer1", IsActive: true},
+			{UserID: "reviewer2", Username: "Reviewer2", IsActive: true},
+			{UserID: "reviewer3", Username: "Reviewer3", IsActive: true},
+			{UserID: "sleeper", Username: "Sleeper", IsActive: false},
+		},
+	}
+
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	previewReq := dto.PreviewPRRequest{
+		PullRequestID:   "pr-preview",
+		PullRequestName: "Preview",
+		AuthorID:        "author1",
+	}
+
+	resp, err = s.makeRequest("POST", "/pullRequest/preview", previewReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var previewResp dto.PreviewPRResponse
+	err = json.NewDecoder(resp.Body).Decode(&previewResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Assert().Equal("RANDOM", previewResp.Strategy)
+	s.Assert().ElementsMatch([]string{"reviewer1", "reviewer2", "reviewer3"}, previewResp.EligiblePool)
+	s.Assert().Len(previewResp.AssignedReviewers, 2)
+	for _, rev := range previewResp.AssignedReviewers {
+		s.Assert().Contains(previewResp.EligiblePool, rev)
+	}
+	s.Assert().Contains(previewResp.Excluded, dto.ExcludedCandidate{UserID: "sleeper", Reason: "INACTIVE"})
+
+	var prCount int
+	err = s.dbPool.QueryRow(context.Background(), "SELECT COUNT(*) FROM pull_requests").Scan(&prCount)
+	s.Require().NoError(err)
+	s.Assert().Zero(prCount)
+
+	var reviewCount int
+	err = s.dbPool.QueryRow(context.Background(), "SELECT COUNT(*) FROM reviews").Scan(&reviewCount)
+	s.Require().NoError(err)
+	s.Assert().Zero(reviewCount)
+}
+
+func (s *APIIntegrationTestSuite) TestPreviewPRWithEmptyPool() {
+	teamReq := dto.TeamRequest{
+		TeamName: "preview-empty-team",
+		Members: []dto.TeamMember{
+			{UserID: "author1", Username: "Author", IsActive: true},
+			{UserID: "reviewer1", Username: "Reviewer1", IsActive: false},
+		},
+	}
+
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/pullRequest/preview", dto.PreviewPRRequest{AuthorID: "author1"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var previewResp dto.PreviewPRResponse
+	err = json.NewDecoder(resp.Body).Decode(&previewResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Assert().Empty(previewResp.AssignedReviewers)
+	s.Assert().Empty(previewResp.EligiblePool)
+	s.Assert().ElementsMatch([]dto.ExcludedCandidate{
+		{UserID: "author1", Reason: "AUTHOR"},
+		{UserID: "reviewer1", Reason: "INACTIVE"},
+	}, previewResp.Excluded)
+}
+
+func (s *APIIntegrationTestSuite) TestPreviewPRForNonExistentAuthor() {
+	resp, err := s.makeRequest("POST", "/pullRequest/preview", dto.PreviewPRRequest{AuthorID: "ghost"})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+	var errorResp dto.ErrorResponse
+	err = json.NewDecoder(resp.Body).Decode(&errorResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Assert().Equal("NOT_FOUND", errorResp.Error.Code)
+}
+
+func (s *APIIntegrationTestSuite) createSeededTeam() {
+	teamReq := dto.TeamRequest{
+		TeamName: "seeded-team",
+		Members: []dto.TeamMember{
+			{UserID: "author1", Username: "Author", IsActive: true},
+			{UserID: "reviewer1", Username: "Reviewer1", IsActive: true},
+			{UserID: "reviewer2", Username: "Reviewer2", IsActive: true},
+			{UserID: "reviewer3", Username: "Reviewer3", IsActive: true},
+			{UserID: "reviewer4", Username: "Reviewer4", IsActive: true},
+			{UserID: "reviewer5", Username: "Reviewer5", IsActive: true},
+		},
+	}
+
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func (s *APIIntegrationTestSuite) createSeededPR() dto.PullRequestResponse {
+	prReq := dto.CreatePRRequest{
+		PullRequestID:   "pr-seeded",
+		PullRequestName: "Seeded",
+		AuthorID:        "author1",
+	}
+
+	resp, err := s.makeRequest("POST", "/pullRequest/create", prReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var prResp map[string]dto.PullRequestResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	return prResp["pr"]
+}
+
+func (s *APIIntegrationTestSuite) reassignSeeded(oldReviewerID string) string {
+	reassignReq := dto.ReassignRequest{
+		PullRequestID: "pr-seeded",
+		OldReviewerID: oldReviewerID,
+	}
+
+	resp, err := s.makeRequest("POST", "/pullRequest/reassign", reassignReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var reassignResp dto.ReassignResponse
+	err = json.NewDecoder(resp.Body).Decode(&reassignResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	return reassignResp.ReplacedBy
+}
+
+// Тестовое окружение запускается с ASSIGN_SEED_PER_PR=true.
+func (s *APIIntegrationTestSuite) TestSeededAssignmentIsReproducible() {
+	s.createSeededTeam()
+
+	resp, err := s.makeRequest("POST", "/pullRequest/preview", dto.PreviewPRRequest{
+		PullRequestID: "pr-seeded",
+		AuthorID:      "author1",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var previewResp dto.PreviewPRResponse
+	err = json.NewDecoder(resp.Body).Decode(&previewResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	first := s.createSeededPR()
+	s.Require().Len(first.AssignedReviewers, 2)
+	s.Assert().Equal(previewResp.AssignedReviewers, first.AssignedReviewers)
+	firstReplacement := s.reassignSeeded(first.AssignedReviewers[0])
+
+	s.cleanDatabase()
+	s.createSeededTeam()
+
+	second := s.createSeededPR()
+	s.Assert().Equal(first.AssignedReviewers, second.AssignedReviewers)
+	s.Assert().Equal(firstReplacement, s.reassignSeeded(second.AssignedReviewers[0]))
+
+	resp, err = s.makeRequest("GET", "/pullRequest/assignmentLog?pull_request_id=pr-seeded", nil)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var logResp dto.AssignmentLogResponse
+	err = json.NewDecoder(resp.Body).Decode(&logResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+
+	s.Require().Len(logResp.Entries, 3)
+	for _, e := range logResp.Entries {
+		s.Assert().NotNil(e.Seed)
+	}
+}
+
+func (s *APIIntegrationTestSuite) reviewersFromDB(prID string) []string {
+	rows, err := s.dbPool.Query(context.Background(),
+		"SELECT reviewer_id FROM reviews WHERE pull_request_id = $1", prID)
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	var reviewers []string
+	for rows.Next() {
+		var rev string
+		s.Require().NoError(rows.Scan(&rev))
+		reviewers = append(reviewers, rev)
+	}
+	s.Require().NoError(rows.Err())
+	return reviewers
+}
+
+func (s *APIIntegrationTestSuite) setupConcurrencyPR(teamName, prID string, members int) dto.PullRequestResponse {
+	teamReq := dto.TeamRequest{TeamName: teamName}
+	teamReq.Members = append(teamReq.Members, dto.TeamMember{UserID: teamName + "-author", Username: "Author", IsActive: true})
+	for i := 1; i < members; i++ {
+		teamReq.Members = append(teamReq.Members, dto.TeamMember{
+			UserID:   fmt.Sprintf("%s-rev%d", teamName, i),
+			Username: fmt.Sprintf("Reviewer%d", i),
+			IsActive: true,
+		})
+	}
+
+	resp, err := s.makeRequest("POST", "/team/add", teamReq)
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = s.makeRequest("POST", "/pullRequest/create", dto.CreatePRRequest{
+		PullRequestID:   prID,
+		PullRequestName: "Concurrency",
+		AuthorID:        teamName + "-author",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var prResp map[string]dto.PullRequestResponse
+	err = json.NewDecoder(resp.Body).Decode(&prResp)
+	resp.Body.Close()
+	s.Require().NoError(err)
+	s.Require().Len(prResp["pr"].AssignedReviewers, 2)
+
+	return prResp["pr"]
+}
+
+// concurrentPost отправляет запросы одновременно и возвращает статусы и коды ошибок.
+func (s *APIIntegrationTestSuite) concurrentPost(endpoint string, bodies []any) ([]int, []string) {
+	statuses := make([]int, len(bodies))
+	codes := make([]string, len(bodies))
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i, body := range bodies {
+		wg.Add(1)
+		go func(i int, body any) {
+			defer wg.Done()
+			<-start
+
+			jsonBody, err := json.Marshal(body)
+			if err != nil {
+				return
+			}
+			resp, err := s.httpClient.Post(s.baseURL+endpoint, "application/json", bytes.NewReader(jsonBody))
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+
+			statuses[i] = resp.StatusCode
+			if resp.StatusCode != http.StatusOK {
+				var errorResp dto.ErrorResponse
+				if json.NewDecoder(resp.Body).Decode(&errorResp) == nil {
+					codes[i] = errorResp.Error.Code
+				}
+			}
+		}(i, body)
+	}
+	close(start)
+	wg.Wait()
+
+	return statuses, codes
+}
+
+func (s *APIIntegrationTestSuite) TestConcurrentReassignOfSameReviewer() {
+	pr := s.setupConcurrencyPR("race-same", "pr-race-same", 8)
+	old := pr.AssignedReviewers[0]
+
+	bodies := make([]any, 10)
+	for i := range bodies {
+		bodies[i] = dto.ReassignRequest{PullRequestID: pr.PullRequestID, OldReviewerID: old}
+	}
+
+	statuses, codes := s.concurrentPost("/pullRequest/reassign", bodies)
+
+	var succeeded int
+	for i, st := range statuses {
+		if st == http.StatusOK {
+			succeeded++
+			continue
+		}
+		s.Assert().Equal(http.StatusConflict, st)
+		s.Assert().Equal("NOT_ASSIGNED", codes[i])
+	}
+	s.Assert().Equal(1, succeeded)
+
+	reviewers := s.reviewersFromDB(pr.PullRequestID)
+	s.Require().Len(reviewers, 2)
+	s.Assert().NotEqual(reviewers[0], reviewers[1])
+	s.Assert().NotContains(reviewers, old)
+	s.Assert().NotContains(reviewers, pr.AuthorID)
+}
+
+func (s *APIIntegrationTestSuite) TestConcurrentReassignOfBothReviewers() {
+	pr := s.setupConcurrencyPR("race-both", "pr-race-both", 4)
+
+	for round := 0; round < 10; round++ {
+		current := s.reviewersFromDB(pr.PullRequestID)
+		s.Require().Len(current, 2)
+
+		statuses, _ := s.concurrentPost("/pullRequest/reassign", []any{
+			dto.ReassignRequest{PullRequestID: pr.PullRequestID, OldReviewerID: current[0]},
+			dto.ReassignRequest{PullRequestID: pr.PullRequestID, OldReviewerID: current[1]},
+		})
+		for _, st := range statuses {
+			s.Assert().Contains([]int{http.StatusOK, http.StatusConflict}, st)
+		}
+
+		reviewers := s.reviewersFromDB(pr.PullRequestID)
+		s.Require().Len(reviewers, 2)
+		s.Assert().NotEqual(reviewers[0], reviewers[1])
+		s.Assert().NotContains(reviewers, pr.AuthorID)
+	}
+}
+
+func (s *APIIntegrationTestSuite) TestConcurrentReassignAndMerge() {
+	pr := s.setupConcurrencyPR("race-merge", "pr-race-merge", 8)
+
+	bodies := make([]any, 0, 10)
+	for i := 0; i < 10; i++ {
+		bodies = append(bodies, dto.ReassignRequest{
+			PullRequestID: pr.PullRequestID,
+			OldReviewerID: pr.AssignedReviewers[i%2],
+		})
+	}
+
+	var wg sync.WaitGroup
+	var mergeResp map[string]dto.PullRequestResponse
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		resp, err := s.makeRequest("POST", "/pullRequest/merge", dto.MergeRequest{PullRequestID: pr.PullRequestID})
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		_ = json.NewDecoder(resp.Body).Decode(&mergeResp)
+	}()
+	statuses, codes := s.concurrentPost("/pullRequest/reassign", bodies)
+	wg.Wait()
+
+	for i, st := range statuses {
+		if st == http.StatusOK {
+			continue
+		}
+		s.Assert().Equal(http.StatusConflict, st)
+		s.Assert().Contains([]string{"NOT_ASSIGNED", "PR_MERGED"}, codes[i])
+	}
+
+	s.Require().Equal("MERGED", mergeResp["pr"].Status)
+
+	reviewers := s.reviewersFromDB(pr.PullRequestID)
+	s.Assert().ElementsMatch(mergeResp["pr"].AssignedReviewers, reviewers)
+
+	resp, err := s.makeRequest("POST", "/pullRequest/reassign", dto.ReassignRequest{
+		PullRequestID: pr.PullRequestID,
+		OldReviewerID: reviewers[0],
+	})
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Assert().Equal(http.StatusConflict, resp.StatusCode)
+	s.Assert().ElementsMatch(reviewers, s.reviewersFromDB(pr.PullRequestID))
+}
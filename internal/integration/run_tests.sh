@@ -25,6 +25,11 @@ TEST_RESULT=0
 set +e
 go test -v -tags=integration ./internal/integration/... -timeout=5m
 TEST_RESULT=$?
+if [ $TEST_RESULT -eq 0 ]; then
+    echo "Running storage conformance tests against Postgres..."
+    go test -v -p 1 ./internal/storage/postgres/... ./internal/infra/postgres/... -timeout=5m
+    TEST_RESULT=$?
+fi
 set -e
 popd >/dev/null
 echo "Cleaning up test environment..."
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryExposition(t *testing.T) {
+	r := NewRegistry()
+	rejected := r.NewCounterVec("test_rejected_total", "Rejected requests.", "route", "client")
+	rejected.Inc("POST /pullRequest/create", "token")
+	rejected.Add(2, "POST /pullRequest/create", "token")
+	rejected.Inc(`GET "quoted"`, "ip")
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, `# HELP test_rejected_total Rejected requests.
+# TYPE test_rejected_total counter
+test_rejected_total{route="GET \"quoted\"",client="ip"} 1
+test_rejected_total{route="POST /pullRequest/create",client="token"} 3
+`, string(body))
+}
+
+func TestCounterVecPanicsOnLabelMismatch(t *testing.T) {
+	c := NewRegistry().NewCounterVec("test_total", "Test.", "route")
+	require.Panics(t, func() { c.Inc() })
+}
+
+func TestHistogramVecExposition(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogramVec("test_duration_seconds", "Request duration.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "GET /a")
+	h.Observe(0.5, "GET /a")
+	h.Observe(3, "GET /a")
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	require.Equal(t, `# HELP test_duration_seconds Request duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="GET /a",le="0.1"} 1
+test_duration_seconds_bucket{route="GET /a",le="1"} 2
+test_duration_seconds_bucket{route="GET /a",le="+Inf"} 3
+test_duration_seconds_sum{route="GET /a"} 3.55
+test_duration_seconds_count{route="GET /a"} 3
+`, rec.Body.String())
+}
+
+func TestFuncMetricsReadOnScrape(t *testing.T) {
+	r := NewRegistry()
+	var conns float64
+	r.NewGaugeFunc("test_conns", "Open connections.", func() float64 { return conns })
+	r.NewCounterFunc("test_acquires_total", "Acquires.", func() float64 { return 7 })
+
+	conns = 3
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	require.Equal(t, `# HELP test_conns Open connections.
+# TYPE test_conns gauge
+test_conns 3
+# HELP test_acquires_total Acquires.
+# TYPE test_acquires_total counter
+test_acquires_total 7
+`, rec.Body.String())
+}
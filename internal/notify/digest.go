@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// DigestConfig - расписание ежедневных сводок.
+type DigestConfig struct {
+	// Location - часовой пояс, в котором отсчитывается At; nil означает UTC.
+	Location *time.Location
+	// At - время суток отправки сводок, смещение от полуночи.
+	At time.Duration
+	// PollInterval - как часто проверять, не пора ли формировать сводки.
+	PollInterval time.Duration
+	// BatchSize - сколько пользователей обрабатывается в одной транзакции.
+	BatchSize int
+}
+
+// Digester раз в сутки ставит в очередь письма со сводкой открытых PR, ожидающих ревью,
+// пользователям с режимом storage.EmailDigest. Отметка об отправке и письмо
+// записываются одной транзакцией, поэтому сводка не теряется и не дублируется
+// при нескольких экземплярах.
+type Digester struct {
+	txm       storage.TxManager
+	repo      storage.NotificationRepository
+	users     storage.UserRepository
+	prs       storage.PullRequestRepository
+	templates *Templates
+	now       func() time.Time
+	cfg       DigestConfig
+}
+
+// NewDigester создаёт новый Digester.
+func NewDigester(
+	txm storage.TxManager,
+	repo storage.NotificationRepository,
+	users storage.UserRepository,
+	prs storage.PullRequestRepository,
+	templates *Templates,
+	cfg DigestConfig,
+) *Digester {
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	return &Digester{txm: txm, repo: repo, users: users, prs: prs, templates: templates, now: time.Now, cfg: cfg}
+}
+
+// Run формирует сводки до отмены ctx.
+func (d *Digester) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for d.DigestOnce(ctx) == d.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DigestOnce формирует сводки для одной пачки пользователей, которым они положены,
+// и возвращает её размер.
+func (d *Digester) DigestOnce(ctx context.Context) int {
+	now := d.now()
+	var claimed int
+	appErr := d.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		prefs, err := d.repo.ClaimDigests(ctx, d.cutoff(now), now, d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		claimed = len(prefs)
+
+		for _, pref := range prefs {
+			ctx := storage.WithOrg(ctx, pref.OrgID)
+			n, ok, err := d.digest(ctx, pref)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := d.repo.Enqueue(ctx, []storage.Notification{n}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if appErr != nil {
+		log.Printf("build email digests failed: %v", appErr)
+		return 0
+	}
+	return claimed
+}
+
+// digest собирает сводку пользователя; без открытых PR на ревью письма нет.
+func (d *Digester) digest(ctx context.Context, pref storage.EmailPreference) (storage.Notification, bool, *apperrors.AppError) {
+	user, err := d.users.Get(ctx, pref.UserID)
+	if err != nil {
+		return storage.Notification{}, false, err
+	}
+	prs, err := d.prs.GetByReviewer(ctx, pref.UserID)
+	if err != nil {
+		return storage.Notification{}, false, err
+	}
+
+	open := make([]storage.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if pr.Status == storage.StatusOpen {
+			open = append(open, pr)
+		}
+	}
+	if len(open) == 0 {
+		return storage.Notification{}, false, nil
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].CreatedAt.Before(open[j].CreatedAt) })
+
+	subject, body, renderErr := d.templates.RenderDigest(Digest{User: user, PullRequests: open})
+	if renderErr != nil {
+		log.Printf("render digest for user %s failed: %v", pref.UserID, renderErr)
+		return storage.Notification{}, false, nil
+	}
+	return storage.Notification{Channel: storage.ChannelEmail, Target: pref.Email, Subject: subject, Body: body}, true, nil
+}
+
+// cutoff возвращает момент последней по расписанию сводки не позже now: сводка
+// положена тем, кому она последний раз формировалась раньше него.
+func (d *Digester) cutoff(now time.Time) time.Time {
+	local := now.In(d.cfg.Location)
+	y, m, day := local.Date()
+	at := time.Date(y, m, day, 0, 0, 0, 0, d.cfg.Location).Add(d.cfg.At)
+	if local.Before(at) {
+		at = time.Date(y, m, day-1, 0, 0, 0, 0, d.cfg.Location).Add(d.cfg.At)
+	}
+	return at
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// SMTPSecurity - как защищается соединение с SMTP-сервером.
+type SMTPSecurity string
+
+const (
+	// SMTPStartTLS - STARTTLS, если сервер его объявляет (обычно порт 587).
+	SMTPStartTLS SMTPSecurity = "starttls"
+	// SMTPImplicitTLS - TLS с момента подключения (обычно порт 465).
+	SMTPImplicitTLS SMTPSecurity = "tls"
+	// SMTPPlain - без шифрования; только для локальных релеев и тестов.
+	SMTPPlain SMTPSecurity = "none"
+)
+
+// SMTPConfig - параметры SMTP-сервера.
+type SMTPConfig struct {
+	// TLSConfig - настройки TLS; nil означает проверку сертификата для Host.
+	TLSConfig *tls.Config
+	Host      string
+	Username  string
+	Password  string
+	From      string
+	Security  SMTPSecurity
+	Port      int
+}
+
+// EmailSender отправляет письма через SMTP. Тема - Subject уведомления, текст - Body,
+// получатель - Target.
+type EmailSender struct {
+	now func() time.Time
+	cfg SMTPConfig
+}
+
+// NewEmailSender создаёт новый EmailSender.
+func NewEmailSender(cfg SMTPConfig) *EmailSender {
+	if cfg.TLSConfig == nil {
+		cfg.TLSConfig = &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+	}
+	return &EmailSender{now: time.Now, cfg: cfg}
+}
+
+// Channel возвращает storage.ChannelEmail.
+func (e *EmailSender) Channel() storage.NotificationChannel {
+	return storage.ChannelEmail
+}
+
+// Send отправляет письмо. Отказ сервера с кодом 5xx (например, несуществующий
+// адрес) повторять бессмысленно, такая ошибка постоянная.
+func (e *EmailSender) Send(ctx context.Context, n storage.Notification) error {
+	msg, err := e.message(n)
+	if err != nil {
+		return err
+	}
+
+	err = e.send(ctx, n.Target, msg)
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return permanent(err)
+	}
+	return err
+}
+
+func (e *EmailSender) send(ctx context.Context, to string, msg []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	if e.cfg.Security == SMTPImplicitTLS {
+		conn = tls.Client(conn, e.cfg.TLSConfig)
+	}
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.cfg.Security == SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(e.cfg.TLSConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message собирает письмо: UTF-8 текст в quoted-printable, тема в кодировке RFC 2047.
+func (e *EmailSender) message(n storage.Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", n.Target)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", e.now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(n.Body)); err != nil {
+		return nil, fmt.Errorf("encode body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("encode body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,336 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+// sentMail - письмо, принятое smtpSink.
+type sentMail struct {
+	from    string
+	to      []string
+	subject string
+	body    string
+}
+
+// smtpSink - локальный SMTP-сервер: принимает письма и отклоняет адреса на rejectDomain с кодом 550.
+type smtpSink struct {
+	ln           net.Listener
+	rejectDomain string
+	mu           sync.Mutex
+	mails        []sentMail
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &smtpSink{ln: ln, rejectDomain: "@invalid.example"}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(t, conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpSink) config() SMTPConfig {
+	addr := s.ln.Addr().(*net.TCPAddr)
+	return SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "assigner@example.com", Security: SMTPPlain}
+}
+
+func (s *smtpSink) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 sink ESMTP")
+	var m sentMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch verb := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0]); verb {
+		case "EHLO", "HELO":
+			reply("250 sink")
+		case "MAIL":
+			m = sentMail{from: addrArg(cmd)}
+			reply("250 OK")
+		case "RCPT":
+			to := addrArg(cmd)
+			if strings.HasSuffix(to, s.rejectDomain) {
+				reply("550 no such user")
+				continue
+			}
+			m.to = append(m.to, to)
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			if err := s.parse(&m, data.String()); err != nil {
+				t.Errorf("parse mail: %v", err)
+			}
+			s.mu.Lock()
+			s.mails = append(s.mails, m)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown command")
+		}
+	}
+}
+
+func addrArg(cmd string) string {
+	start, end := strings.Index(cmd, "<"), strings.Index(cmd, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return cmd[start+1 : end]
+}
+
+func (s *smtpSink) parse(m *sentMail, data string) error {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		return err
+	}
+	m.subject, err = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	m.body = strings.ReplaceAll(string(body), "\r\n", "\n")
+	return nil
+}
+
+func (s *smtpSink) received() []sentMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sentMail(nil), s.mails...)
+}
+
+func TestEmailSenderDeliversToSink(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender := NewEmailSender(sink.config())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	body := "Здравствуйте!\n\n" + strings.Repeat("длинная строка ", 10) + "\n"
+	require.NoError(t, sender.Send(ctx, storage.Notification{
+		Channel: storage.ChannelEmail,
+		Target:  "bob@example.com",
+		Subject: "Ревью: Add search",
+		Body:    body,
+	}))
+
+	mails := sink.received()
+	require.Len(t, mails, 1)
+	require.Equal(t, "assigner@example.com", mails[0].from)
+	require.Equal(t, []string{"bob@example.com"}, mails[0].to)
+	require.Equal(t, "Ревью: Add search", mails[0].subject)
+	require.Equal(t, body, mails[0].body)
+
+	err := sender.Send(ctx, storage.Notification{Channel: storage.ChannelEmail, Target: "ghost@invalid.example", Subject: "x", Body: "x"})
+	var perm *permanentError
+	require.ErrorAs(t, err, &perm, "5xx from the server is not retried")
+	require.Contains(t, err.Error(), "550")
+
+	closed := SMTPConfig{Host: "127.0.0.1", Port: freePort(t), From: "assigner@example.com", Security: SMTPPlain}
+	err = NewEmailSender(closed).Send(ctx, storage.Notification{Target: "bob@example.com"})
+	require.Error(t, err)
+	require.False(t, errors.As(err, &perm), "connection errors are retried")
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return port
+}
+
+type emailFixture struct {
+	sink      *smtpSink
+	repo      *memory.NotificationRepository
+	settings  *service.NotificationService
+	prService *service.PRService
+	worker    *Worker
+	digester  *Digester
+	clock     time.Time
+}
+
+// newEmailFixture поднимает PRService с письмами поверх памяти, Worker с отправкой
+// в локальный SMTP и Digester со сводкой в 09:00 UTC.
+func newEmailFixture(t *testing.T) *emailFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	f := &emailFixture{sink: newSMTPSink(t), repo: memory.NewNotificationRepository(store)}
+	teams := memory.NewTeamRepository(store)
+	users := memory.NewUserRepository(store)
+	prs := memory.NewPullRequestRepository(store)
+	txm := memory.NewTxManager(store)
+
+	require.Nil(t, teams.Create(ctx, storage.Team{
+		TeamName: "backend",
+		Members: []storage.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Carol", IsActive: true},
+		},
+	}))
+	f.settings = service.NewNotificationService(txm, teams, users, f.repo, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+
+	templates := DefaultTemplates()
+	f.prService = service.NewPRService(
+		txm, users, prs,
+		memory.NewAssignmentLogRepository(store), memory.NewPREventRepository(store), memory.NewOutboxRepository(store),
+		service.WithEmailNotifications(f.repo, templates),
+	)
+	f.worker = NewWorker(f.repo, []Sender{NewEmailSender(f.sink.config())}, Config{
+		PollInterval: time.Second,
+		BatchSize:    10,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   time.Minute,
+		Timeout:      5 * time.Second,
+	})
+	f.worker.now = func() time.Time { return f.clock }
+	f.digester = NewDigester(txm, f.repo, users, prs, templates, DigestConfig{
+		At:           9 * time.Hour,
+		PollInterval: time.Minute,
+		BatchSize:    10,
+	})
+	f.digester.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *emailFixture) setMode(t *testing.T, userID string, mode storage.EmailMode) {
+	t.Helper()
+	_, err := f.settings.SetEmailPreference(context.Background(), userID, userID+"@example.com", mode)
+	require.Nil(t, err)
+}
+
+func TestImmediateEmailOnAssignment(t *testing.T) {
+	f := newEmailFixture(t)
+	ctx := context.Background()
+	f.setMode(t, "u2", storage.EmailImmediate)
+	f.setMode(t, "u3", storage.EmailDigest)
+
+	pr, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+
+	f.clock = time.Now()
+	require.Equal(t, 1, f.worker.SendOnce(ctx), "only the immediate reviewer gets a mail")
+
+	mails := f.sink.received()
+	require.Len(t, mails, 1)
+	require.Equal(t, []string{"u2@example.com"}, mails[0].to)
+	require.Equal(t, "Ревью: Add search (pr-1)", mails[0].subject)
+	require.Contains(t, mails[0].body, "Здравствуйте, Bob!")
+	require.Contains(t, mails[0].body, "Alice ждёт вашего ревью")
+}
+
+func TestDailyDigest(t *testing.T) {
+	f := newEmailFixture(t)
+	ctx := context.Background()
+	f.setMode(t, "u2", storage.EmailDigest)
+	f.setMode(t, "u3", storage.EmailNone)
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		_, err := f.prService.CreatePR(ctx, id, "Feature "+id, "u1")
+		require.Nil(t, err)
+	}
+	_, err := f.prService.Merge(ctx, "pr-2")
+	require.Nil(t, err)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC)
+	f.clock = today.Add(-time.Hour)
+	if now.After(today) {
+		// Настройка создана после сегодняшней сводки: первая придёт завтра.
+		f.clock = today.Add(23 * time.Hour)
+	}
+	require.Equal(t, 0, f.digester.DigestOnce(ctx), "not due before the digest time")
+
+	f.clock = f.clock.Add(2 * time.Hour)
+	require.Equal(t, 1, f.digester.DigestOnce(ctx))
+	require.Equal(t, 0, f.digester.DigestOnce(ctx), "one digest per day")
+	require.Equal(t, 1, f.worker.SendOnce(ctx))
+
+	mails := f.sink.received()
+	require.Len(t, mails, 1)
+	require.Equal(t, []string{"u2@example.com"}, mails[0].to)
+	require.Equal(t, "Ожидают ревью: 2", mails[0].subject)
+	require.Contains(t, mails[0].body, "Feature pr-1 (pr-1)")
+	require.Contains(t, mails[0].body, "Feature pr-3 (pr-3)")
+	require.NotContains(t, mails[0].body, "pr-2", "merged PRs are not in the digest")
+
+	for _, id := range []string{"pr-1", "pr-3"} {
+		_, err := f.prService.Merge(ctx, id)
+		require.Nil(t, err)
+	}
+	f.clock = f.clock.Add(24 * time.Hour)
+	require.Equal(t, 1, f.digester.DigestOnce(ctx))
+	require.Equal(t, 0, f.worker.SendOnce(ctx), "no mail without open reviews")
+}
+
+func TestDigestCutoff(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	d := NewDigester(nil, nil, nil, nil, nil, DigestConfig{Location: msk, At: 9*time.Hour + 30*time.Minute})
+
+	for _, tc := range []struct{ now, want string }{
+		{"2026-03-10T09:30:00+03:00", "2026-03-10T09:30:00+03:00"},
+		{"2026-03-10T23:59:00+03:00", "2026-03-10T09:30:00+03:00"},
+		{"2026-03-10T09:29:00+03:00", "2026-03-09T09:30:00+03:00"},
+		{"2026-03-01T05:00:00Z", "2026-02-28T09:30:00+03:00"},
+	} {
+		now, err := time.Parse(time.RFC3339, tc.now)
+		require.NoError(t, err)
+		want, err := time.Parse(time.RFC3339, tc.want)
+		require.NoError(t, err)
+		require.True(t, want.Equal(d.cutoff(now)), "%s: got %s", tc.now, d.cutoff(now))
+	}
+}
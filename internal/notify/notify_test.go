@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/egress"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+// chat - локальный входящий вебхук чата: отвечает заданными статусами и записывает тексты сообщений.
+type chat struct {
+	mu       sync.Mutex
+	texts    []string
+	statuses []int
+}
+
+func (c *chat) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var msg chatMessage
+	_ = json.Unmarshal(body, &msg)
+
+	c.mu.Lock()
+	c.texts = append(c.texts, msg.Text)
+	status := http.StatusOK
+	if len(c.statuses) > 0 {
+		status = c.statuses[0]
+		c.statuses = c.statuses[1:]
+	}
+	c.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (c *chat) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.texts...)
+}
+
+type fixture struct {
+	chat      *chat
+	url       string
+	worker    *Worker
+	repo      *memory.NotificationRepository
+	prService *service.PRService
+	// notifications управляет чатом команды backend.
+	notifications *service.NotificationService
+	clock         time.Time
+}
+
+// newFixture поднимает PRService с чат-уведомлениями поверх памяти и Worker,
+// который отправляет их в локальный чат команды backend.
+func newFixture(t *testing.T, templates *Templates, statuses ...int) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	c := &chat{statuses: statuses}
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+
+	store := memory.NewStore()
+	teams := memory.NewTeamRepository(store)
+	f := &fixture{chat: c, url: srv.URL + "/hooks/backend", repo: memory.NewNotificationRepository(store)}
+
+	require.Nil(t, teams.Create(ctx, storage.Team{
+		TeamName: "backend",
+		Members: []storage.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+		},
+	}))
+	users := memory.NewUserRepository(store)
+	f.notifications = service.NewNotificationService(memory.NewTxManager(store), teams, users, f.repo, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+	_, err := f.notifications.SetTeamChat(ctx, "backend", f.url)
+	require.Nil(t, err)
+
+	f.prService = service.NewPRService(
+		memory.NewTxManager(store), memory.NewUserRepository(store), memory.NewPullRequestRepository(store),
+		memory.NewAssignmentLogRepository(store), memory.NewPREventRepository(store), memory.NewOutboxRepository(store),
+		service.WithChatNotifications(f.repo, templates),
+	)
+
+	f.worker = NewWorker(f.repo, []Sender{NewChatSender(srv.Client())}, Config{
+		PollInterval: time.Second,
+		BatchSize:    10,
+		MaxAttempts:  2,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   time.Minute,
+		Timeout:      time.Second,
+	})
+	f.worker.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *fixture) list(t *testing.T) []storage.Notification {
+	t.Helper()
+	notifications, err := f.repo.List(context.Background(), "", 10)
+	require.Nil(t, err)
+	return notifications
+}
+
+func TestAssignmentIsPostedToTeamChat(t *testing.T) {
+	f := newFixture(t, DefaultTemplates())
+	ctx := context.Background()
+
+	_, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+	_, _, err = f.prService.ReassignReviewer(ctx, "pr-1", "u2")
+	require.NotNil(t, err, "no replacement in a team of two")
+
+	f.clock = time.Now()
+	require.Equal(t, 1, f.worker.SendOnce(ctx))
+
+	texts := f.chat.received()
+	require.Len(t, texts, 1)
+	require.Equal(t, ":eyes: *Add search* (pr-1) от Alice ждёт ревью: Bob", texts[0])
+
+	notifications := f.list(t)
+	require.Len(t, notifications, 1, "a failed reassignment enqueues nothing")
+	require.Equal(t, storage.DeliveryDelivered, notifications[0].Status)
+	require.Equal(t, f.url, notifications[0].Target)
+}
+
+func TestChatFailureDoesNotRollBackAssignment(t *testing.T) {
+	f := newFixture(t, DefaultTemplates(), http.StatusServiceUnavailable, http.StatusNotFound)
+	ctx := context.Background()
+
+	pr, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+	require.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+	f.clock = time.Now()
+	require.Equal(t, 1, f.worker.SendOnce(ctx))
+	n := f.list(t)[0]
+	require.Equal(t, storage.DeliveryPending, n.Status)
+	require.Equal(t, "unexpected status 503", n.LastError)
+	require.True(t, f.clock.Add(time.Second).Equal(n.NextAttemptAt))
+
+	require.Equal(t, 0, f.worker.SendOnce(ctx), "not due before the backoff")
+
+	f.clock = f.clock.Add(time.Second)
+	require.Equal(t, 1, f.worker.SendOnce(ctx))
+	n = f.list(t)[0]
+	require.Equal(t, storage.DeliveryDead, n.Status, "dead after MaxAttempts")
+	require.Equal(t, 2, n.Attempts)
+	require.Len(t, f.chat.received(), 2)
+}
+
+func TestChatSenderRefusesInternalAddresses(t *testing.T) {
+	c := &chat{}
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+
+	err := NewChatSender(egress.NewClient(egress.Config{})).Send(context.Background(),
+		storage.Notification{Channel: storage.ChannelChat, Target: srv.URL + "/hooks/backend", Body: "hi"})
+	require.ErrorIs(t, err, egress.ErrForbiddenAddress)
+	require.Empty(t, c.received())
+}
+
+func TestTeamWithoutChatIsNotNotified(t *testing.T) {
+	f := newFixture(t, DefaultTemplates())
+	ctx := context.Background()
+
+	require.Nil(t, f.notifications.DeleteTeamChat(ctx, "backend"))
+	_, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+	require.Empty(t, f.list(t))
+}
+
+func TestTemplatesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assigned.tmpl"),
+		[]byte("{{.PR.ID}} -> {{range .Reviewers}}@{{.ID}} {{end}}\n"), 0o600))
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	pr := storage.PullRequest{ID: "pr-1", Name: "Add search"}
+	text, err := templates.Render(service.Notice{
+		Kind:      service.NoticeAssigned,
+		PR:        pr,
+		Reviewers: []storage.User{{ID: "u2"}, {ID: "u3"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "pr-1 -> @u2 @u3 ", text)
+
+	text, err = templates.Render(service.Notice{
+		Kind:        service.NoticeReplaced,
+		PR:          pr,
+		Reviewers:   []storage.User{{Username: "Carol"}},
+		OldReviewer: &storage.User{Username: "Bob"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, text, "ревьюер Bob заменён на Carol", "other kinds keep the defaults")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reminder.tmpl"), []byte("{{.PR.ID"), 0o600))
+	_, err = LoadTemplates(dir)
+	require.ErrorContains(t, err, "parse reminder template")
+}
+
+func TestRenderErrorSkipsNotification(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assigned.tmpl"), []byte("{{.OldReviewer.Username}}"), 0o600))
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	f := newFixture(t, templates)
+	_, appErr := f.prService.CreatePR(context.Background(), "pr-1", "Add search", "u1")
+	require.Nil(t, appErr, "a broken template does not fail the assignment")
+	require.Empty(t, f.list(t))
+}
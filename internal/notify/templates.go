@@ -0,0 +1,151 @@
+// Package notify рендерит и отправляет уведомления о ревью (чаты команд, почта).
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Имена шаблонов писем. Шаблоны сообщений в чат называются по service.NoticeKind.
+const (
+	emailPrefix    = "email_"
+	digestTemplate = "digest"
+)
+
+// Шаблоны по умолчанию. Разметка сообщений в чат совместима со Slack и Mattermost.
+// Первая строка письма - тема, остальное после пустой строки - текст.
+var defaultTemplates = map[string]string{
+	string(service.NoticeAssigned): `:eyes: *{{.PR.Name}}* ({{.PR.ID}}) от {{.Author.Username}} ждёт ревью: {{names .Reviewers}}`,
+	string(service.NoticeReplaced): `:arrows_counterclockwise: *{{.PR.Name}}* ({{.PR.ID}}): ревьюер {{.OldReviewer.Username}} заменён на {{names .Reviewers}}`,
+	string(service.NoticeReminder): `:alarm_clock: *{{.PR.Name}}* ({{.PR.ID}}) от {{.Author.Username}} всё ещё ждёт ревью: {{names .Reviewers}}`,
+
+	emailPrefix + string(service.NoticeAssigned): `Ревью: {{.PR.Name}} ({{.PR.ID}})
+
+Здравствуйте, {{.Recipient.Username}}!
+
+{{.Author.Username}} ждёт вашего ревью PR «{{.PR.Name}}» ({{.PR.ID}}).
+Ревьюеры: {{names .Reviewers}}.`,
+	emailPrefix + string(service.NoticeReplaced): `Ревью: {{.PR.Name}} ({{.PR.ID}})
+
+Здравствуйте, {{.Recipient.Username}}!
+
+Вы назначены ревьюером PR «{{.PR.Name}}» ({{.PR.ID}}) от {{.Author.Username}} вместо {{.OldReviewer.Username}}.`,
+	emailPrefix + string(service.NoticeReminder): `Напоминание о ревью: {{.PR.Name}} ({{.PR.ID}})
+
+Здравствуйте, {{.Recipient.Username}}!
+
+PR «{{.PR.Name}}» ({{.PR.ID}}) от {{.Author.Username}} всё ещё ждёт вашего ревью.`,
+	digestTemplate: `Ожидают ревью: {{len .PullRequests}}
+
+Здравствуйте, {{.User.Username}}!
+
+Открытые PR, которые ждут вашего ревью:
+{{range .PullRequests}}- {{.Name}} ({{.ID}}), открыт {{.CreatedAt.Format "02.01.2006"}}
+{{end}}`,
+}
+
+var funcs = template.FuncMap{
+	"names": func(users []storage.User) string {
+		names := make([]string, 0, len(users))
+		for _, u := range users {
+			names = append(names, u.Username)
+		}
+		return strings.Join(names, ", ")
+	},
+}
+
+// Digest - данные шаблона ежедневной сводки.
+type Digest struct {
+	User         storage.User
+	PullRequests []storage.PullRequest
+}
+
+// Templates - шаблоны сообщений (text/template). Реализует service.NoticeRenderer.
+type Templates struct {
+	byName map[string]*template.Template
+}
+
+// DefaultTemplates возвращает встроенные шаблоны.
+func DefaultTemplates() *Templates {
+	t, err := LoadTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// LoadTemplates загружает шаблоны: файл <dir>/<имя>.tmpl, если он есть, заменяет
+// встроенный шаблон. Имена: assigned, replaced, reminder (чат), email_assigned,
+// email_replaced, email_reminder, digest (почта). Пустой dir означает только встроенные шаблоны.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{byName: make(map[string]*template.Template, len(defaultTemplates))}
+	for name, text := range defaultTemplates {
+		if dir != "" {
+			raw, err := os.ReadFile(filepath.Join(dir, name+".tmpl"))
+			switch {
+			case err == nil:
+				text = strings.TrimRight(string(raw), "\n")
+			case !errors.Is(err, os.ErrNotExist):
+				return nil, fmt.Errorf("read %s template: %w", name, err)
+			}
+		}
+
+		tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s template: %w", name, err)
+		}
+		t.byName[name] = tmpl
+	}
+	return t, nil
+}
+
+// Render рендерит сообщение в чат по шаблону повода уведомления.
+func (t *Templates) Render(notice service.Notice) (string, error) {
+	return t.execute(string(notice.Kind), notice)
+}
+
+// RenderEmail рендерит письмо по шаблону email_<повод>.
+func (t *Templates) RenderEmail(notice service.Notice) (string, string, error) {
+	return t.executeEmail(emailPrefix+string(notice.Kind), notice)
+}
+
+// RenderDigest рендерит ежедневную сводку.
+func (t *Templates) RenderDigest(digest Digest) (string, string, error) {
+	return t.executeEmail(digestTemplate, digest)
+}
+
+func (t *Templates) execute(name string, data any) (string, error) {
+	tmpl, ok := t.byName[name]
+	if !ok {
+		return "", fmt.Errorf("no template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// executeEmail рендерит письмо и отделяет тему (первую строку) от текста.
+func (t *Templates) executeEmail(name string, data any) (string, string, error) {
+	text, err := t.execute(name, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject, body, _ := strings.Cut(text, "\n")
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return "", "", fmt.Errorf("%s template: empty subject line", name)
+	}
+	return subject, strings.TrimLeft(body, "\n"), nil
+}
@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/retry"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Sender отправляет уведомление своего канала. Ошибку, которую бессмысленно повторять,
+// Sender оборачивает в permanent.
+type Sender interface {
+	Channel() storage.NotificationChannel
+	Send(ctx context.Context, n storage.Notification) error
+}
+
+// permanentError - ошибка, которую бессмысленно повторять: уведомление сразу переходит в DEAD.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// ChatSender отправляет сообщения во входящие вебхуки Slack и Mattermost:
+// POST на Target с телом {"text": ...}.
+type ChatSender struct {
+	client *http.Client
+}
+
+// NewChatSender создаёт новый ChatSender.
+func NewChatSender(client *http.Client) *ChatSender {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &ChatSender{client: client}
+}
+
+// Channel возвращает storage.ChannelChat.
+func (c *ChatSender) Channel() storage.NotificationChannel {
+	return storage.ChannelChat
+}
+
+type chatMessage struct {
+	Text string `json:"text"`
+}
+
+// Send отправляет n.Body в вебхук n.Target.
+func (c *ChatSender) Send(ctx context.Context, n storage.Notification) error {
+	body, err := json.Marshal(chatMessage{Text: n.Body})
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.Target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Config - параметры отправки уведомлений.
+type Config struct {
+	// PollInterval - период опроса очереди.
+	PollInterval time.Duration
+	// BatchSize - сколько уведомлений берётся за один опрос.
+	BatchSize int
+	// MaxAttempts - после стольких неудачных попыток уведомление переходит в DEAD.
+	MaxAttempts int
+	// BaseBackoff и MaxBackoff - задержка перед повтором: BaseBackoff*2^(попытка-1), не больше MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout - таймаут одной отправки; он же срок аренды уведомления.
+	Timeout time.Duration
+}
+
+// Worker периодически забирает из очереди уведомления, которым пора отправляться,
+// и отправляет их через Sender их канала.
+type Worker struct {
+	repo    storage.NotificationRepository
+	senders map[storage.NotificationChannel]Sender
+	now     func() time.Time
+	cfg     Config
+}
+
+// NewWorker создаёт новый Worker.
+func NewWorker(repo storage.NotificationRepository, senders []Sender, cfg Config) *Worker {
+	w := &Worker{
+		repo:    repo,
+		senders: make(map[storage.NotificationChannel]Sender, len(senders)),
+		now:     time.Now,
+		cfg:     cfg,
+	}
+	for _, s := range senders {
+		w.senders[s.Channel()] = s
+	}
+	return w
+}
+
+// Run обрабатывает очередь до отмены ctx.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for w.SendOnce(ctx) == w.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendOnce отправляет одну пачку уведомлений и возвращает её размер.
+func (w *Worker) SendOnce(ctx context.Context) int {
+	notifications, appErr := w.repo.ClaimDue(ctx, w.now(), 2*w.cfg.Timeout, w.cfg.BatchSize)
+	if appErr != nil {
+		log.Printf("claim notifications failed: %v", appErr)
+		return 0
+	}
+
+	for _, n := range notifications {
+		w.deliver(ctx, n)
+	}
+	return len(notifications)
+}
+
+func (w *Worker) deliver(ctx context.Context, n storage.Notification) {
+	err := w.send(ctx, n)
+	if err == nil {
+		if appErr := w.repo.MarkDone(ctx, n.ID); appErr != nil {
+			log.Printf("mark notification %d done failed: %v", n.ID, appErr)
+		}
+		return
+	}
+
+	var perm *permanentError
+	dead := n.Attempts >= w.cfg.MaxAttempts || errors.As(err, &perm)
+	msg := retry.TruncateError(err)
+	if dead {
+		log.Printf("%s notification %d is dead after %d attempts: %s", n.Channel, n.ID, n.Attempts, msg)
+	}
+	next := w.now().Add(retry.Backoff(w.cfg.BaseBackoff, w.cfg.MaxBackoff, n.Attempts))
+	if appErr := w.repo.MarkFailed(ctx, n.ID, next, msg, dead); appErr != nil {
+		log.Printf("mark notification %d failed failed: %v", n.ID, appErr)
+	}
+}
+
+func (w *Worker) send(ctx context.Context, n storage.Notification) error {
+	sender, ok := w.senders[n.Channel]
+	if !ok {
+		return fmt.Errorf("no sender for channel %q", n.Channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+	return sender.Send(ctx, n)
+}
@@ -0,0 +1,194 @@
+// Package oidc проверяет JWT, выпущенные корпоративным SSO (RS256 и ES256), по набору
+// ключей JWKS из файла или по URL.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxJWKSSize ограничивает размер загружаемого JWKS.
+const maxJWKSSize = 1 << 20
+
+// jwk - ключ JWKS (RFC 7517); поддерживаются RSA и EC P-256.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS разбирает JWKS и возвращает открытые ключи подписи по kid. Ключи для
+// шифрования (use=enc) и неподдерживаемых типов пропускаются.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA or EC P-256 signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point is not on P-256")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// KeySource - JWKS из файла или по http(s) URL. Ключи перечитываются не чаще раза
+// в refresh - по расписанию и когда встречается неизвестный kid (ротация ключей у SSO).
+type KeySource struct {
+	location string
+	client   *http.Client
+	refresh  time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+// NewKeySource создаёт KeySource и сразу загружает ключи: сервер с недоступным JWKS
+// при старте - ошибка конфигурации.
+func NewKeySource(ctx context.Context, location string, client *http.Client, refresh time.Duration) (*KeySource, error) {
+	s := &KeySource{location: location, client: client, refresh: refresh, now: time.Now}
+	keys, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.keys, s.loadedAt = keys, s.now()
+	return s, nil
+}
+
+// Key возвращает ключ kid. Если ключа нет или набор устарел, набор перечитывается;
+// при ошибке загрузки используются прежние ключи.
+func (s *KeySource) Key(ctx context.Context, kid string) (crypto.PublicKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[kid]
+	if (!ok || s.now().Sub(s.loadedAt) >= s.refresh) && s.now().Sub(s.loadedAt) >= s.minReload() {
+		keys, err := s.load(ctx)
+		s.loadedAt = s.now()
+		if err != nil {
+			log.Printf("reload jwks from %s failed: %v", s.location, err)
+		} else {
+			s.keys = keys
+			key, ok = s.keys[kid]
+		}
+	}
+	return key, ok
+}
+
+// minReload - не чаще какого интервала перечитывать набор из-за неизвестного kid,
+// чтобы поток токенов с чужим kid не превращался в поток запросов к SSO.
+func (s *KeySource) minReload() time.Duration {
+	return min(s.refresh, time.Minute)
+}
+
+func (s *KeySource) load(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(s.location, "http://") || strings.HasPrefix(s.location, "https://") {
+		data, err = s.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(s.location)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+func (s *KeySource) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
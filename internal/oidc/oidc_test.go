@@ -0,0 +1,223 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signer - локально сгенерированный ключ, которым тесты подписывают токены.
+type signer struct {
+	kid string
+	alg string
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newRSASigner(t *testing.T, kid string) signer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return signer{kid: kid, alg: "RS256", rsa: key}
+}
+
+func newECSigner(t *testing.T, kid string) signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return signer{kid: kid, alg: "ES256", ec: key}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s signer) jwk() map[string]string {
+	if s.rsa != nil {
+		return map[string]string{
+			"kty": "RSA", "kid": s.kid, "use": "sig", "alg": "RS256",
+			"n": b64(s.rsa.N.Bytes()), "e": b64(big.NewInt(int64(s.rsa.E)).Bytes()),
+		}
+	}
+	return map[string]string{
+		"kty": "EC", "kid": s.kid, "use": "sig", "crv": "P-256",
+		"x": b64(s.ec.X.FillBytes(make([]byte, 32))), "y": b64(s.ec.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwks(t *testing.T, signers ...signer) []byte {
+	t.Helper()
+	keys := make([]map[string]string, 0, len(signers))
+	for _, s := range signers {
+		keys = append(keys, s.jwk())
+	}
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func (s signer) sign(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+	h, err := json.Marshal(map[string]string{"alg": alg, "kid": s.kid, "typ": "JWT"})
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64(h) + "." + b64(c)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch {
+	case alg == "none":
+	case s.rsa != nil:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, s.rsa, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	default:
+		r, ss, err := ecdsa.Sign(rand.Reader, s.ec, digest[:])
+		require.NoError(t, err)
+		sig = append(r.FillBytes(make([]byte, 32)), ss.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":  "https://sso.example.com",
+		"aud":  []string{"assigner", "other"},
+		"sub":  "sso|42",
+		"uid":  "u1",
+		"role": []string{"lead", "unknown"},
+		"org":  "acme",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func newVerifier(t *testing.T, signers ...signer) *Verifier {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks(t, signers...), 0o600))
+	keys, err := NewKeySource(context.Background(), path, http.DefaultClient, time.Hour)
+	require.NoError(t, err)
+	return NewVerifier(keys, Config{
+		Issuer: "https://sso.example.com", Audience: "assigner",
+		UserClaim: "uid", RoleClaim: "role", OrgClaim: "org", Leeway: time.Minute,
+	})
+}
+
+func TestVerifyRS256AndES256(t *testing.T) {
+	rs, es := newRSASigner(t, "rsa-1"), newECSigner(t, "ec-1")
+	v := newVerifier(t, rs, es)
+
+	for _, s := range []signer{rs, es} {
+		id, err := v.Verify(context.Background(), s.sign(t, s.alg, validClaims()))
+		require.NoError(t, err, s.alg)
+		require.Equal(t, Identity{Subject: "sso|42", UserID: "u1", Roles: []string{"lead", "unknown"}, Org: "acme"}, id)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	rs, es := newRSASigner(t, "rsa-1"), newECSigner(t, "ec-1")
+	v := newVerifier(t, rs, es)
+	foreign := newRSASigner(t, "rsa-1")
+
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		if value == nil {
+			delete(c, key)
+		} else {
+			c[key] = value
+		}
+		return c
+	}
+
+	cases := map[string]string{
+		"expired":           rs.sign(t, "RS256", with("exp", time.Now().Add(-2*time.Minute).Unix())),
+		"no exp":            rs.sign(t, "RS256", with("exp", nil)),
+		"not yet valid":     rs.sign(t, "RS256", with("nbf", time.Now().Add(2*time.Minute).Unix())),
+		"wrong issuer":      rs.sign(t, "RS256", with("iss", "https://evil.example.com")),
+		"wrong audience":    rs.sign(t, "RS256", with("aud", "someone-else")),
+		"no user claim":     rs.sign(t, "RS256", with("uid", nil)),
+		"foreign key":       foreign.sign(t, "RS256", validClaims()),
+		"unknown kid":       newECSigner(t, "ec-2").sign(t, "ES256", validClaims()),
+		"alg none":          rs.sign(t, "none", validClaims()),
+		"alg of other type": es.sign(t, "RS256", validClaims()),
+		"malformed":         "not.a-token",
+	}
+	for name, token := range cases {
+		_, err := v.Verify(context.Background(), token)
+		require.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+
+	// Подпись не покрывает подменённые claims.
+	token := rs.sign(t, "RS256", validClaims())
+	forged, err := json.Marshal(with("uid", "admin"))
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+	_, err = v.Verify(context.Background(), parts[0]+"."+b64(forged)+"."+parts[2])
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifyLeeway(t *testing.T) {
+	rs := newRSASigner(t, "rsa-1")
+	v := newVerifier(t, rs)
+
+	c := validClaims()
+	c["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	_, err := v.Verify(context.Background(), rs.sign(t, "RS256", c))
+	require.NoError(t, err, "expired within leeway")
+}
+
+func TestURLKeySourceReloadsOnKeyRotation(t *testing.T) {
+	old, rotated := newRSASigner(t, "old"), newECSigner(t, "new")
+	var current atomic.Value
+	current.Store(jwks(t, old))
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(current.Load().([]byte))
+	}))
+	t.Cleanup(srv.Close)
+
+	keys, err := NewKeySource(context.Background(), srv.URL, srv.Client(), time.Hour)
+	require.NoError(t, err)
+	clock := time.Now()
+	keys.now = func() time.Time { return clock }
+	v := NewVerifier(keys, Config{UserClaim: "uid", RoleClaim: "role"})
+
+	_, err = v.Verify(context.Background(), old.sign(t, "RS256", validClaims()))
+	require.NoError(t, err)
+
+	current.Store(jwks(t, rotated))
+	token := rotated.sign(t, "ES256", validClaims())
+	_, err = v.Verify(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken, "reload is rate limited")
+	require.EqualValues(t, 1, fetches.Load())
+
+	clock = clock.Add(2 * time.Minute)
+	_, err = v.Verify(context.Background(), token)
+	require.NoError(t, err, "unknown kid triggers a reload")
+	require.EqualValues(t, 2, fetches.Load())
+}
+
+func TestParseJWKSRejectsEmptySet(t *testing.T) {
+	_, err := ParseJWKS([]byte(`{"keys":[{"kty":"oct","kid":"k","k":"c2VjcmV0"}]}`))
+	require.Error(t, err)
+	_, err = ParseJWKS([]byte(`not json`))
+	require.Error(t, err)
+}
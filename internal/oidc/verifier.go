@@ -0,0 +1,220 @@
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken - токен не прошёл проверку; подробности - в обёрнутом тексте.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Config - параметры проверки токенов.
+type Config struct {
+	// Issuer - ожидаемый iss; пустой - не проверяется.
+	Issuer string
+	// Audience - ожидаемое значение aud; пустое - не проверяется.
+	Audience string
+	// UserClaim - claim с users.user_id вызывающего.
+	UserClaim string
+	// RoleClaim - claim с ролью (строка или массив строк).
+	RoleClaim string
+	// OrgClaim - claim с именем организации вызывающего.
+	OrgClaim string
+	// Leeway - допустимое расхождение часов при проверке exp и nbf.
+	Leeway time.Duration
+}
+
+// Identity - вызывающий, извлечённый из проверенного токена.
+type Identity struct {
+	// Subject - claim sub.
+	Subject string
+	// UserID - значение Config.UserClaim.
+	UserID string
+	// Roles - значения Config.RoleClaim.
+	Roles []string
+	// Org - значение Config.OrgClaim; пустое, если claim нет.
+	Org string
+}
+
+// Verifier проверяет подпись и claims JWT.
+type Verifier struct {
+	keys *KeySource
+	cfg  Config
+	now  func() time.Time
+}
+
+// NewVerifier создаёт новый Verifier.
+func NewVerifier(keys *KeySource, cfg Config) *Verifier {
+	return &Verifier{keys: keys, cfg: cfg, now: time.Now}
+}
+
+// LooksLikeJWT сообщает, похож ли bearer-токен на JWS в компактной форме.
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify проверяет токен и возвращает вызывающего. Все ошибки проверки оборачивают
+// ErrInvalidToken.
+func (v *Verifier) Verify(ctx context.Context, token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Identity{}, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	key, ok := v.keys.Key(ctx, h.Kid)
+	if !ok {
+		return Identity{}, fmt.Errorf("%w: unknown kid %q", ErrInvalidToken, h.Kid)
+	}
+	if err := verifySignature(h.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	id := Identity{Roles: stringsClaim(claims[v.cfg.RoleClaim])}
+	id.Subject, _ = claims["sub"].(string)
+	id.UserID, _ = claims[v.cfg.UserClaim].(string)
+	if v.cfg.OrgClaim != "" {
+		id.Org, _ = claims[v.cfg.OrgClaim].(string)
+	}
+	if id.UserID == "" {
+		return Identity{}, fmt.Errorf("%w: claim %q is missing", ErrInvalidToken, v.cfg.UserClaim)
+	}
+	return id, nil
+}
+
+// verifySignature проверяет подпись; alg должен соответствовать типу ключа, поэтому
+// подмена alg (например, на none) отвергается.
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("RS256 requires an RSA key")
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return errors.New("bad signature")
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("ES256 requires an EC key")
+		}
+		// Подпись JWS ES256 - r||s по 32 байта, а не DER.
+		if len(sig) != 64 {
+			return errors.New("bad signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return errors.New("bad signature")
+		}
+	default:
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+	return nil
+}
+
+func (v *Verifier) checkClaims(claims map[string]any) error {
+	now := v.now()
+
+	exp, ok := numericClaim(claims["exp"])
+	if !ok {
+		return errors.New("exp is missing")
+	}
+	if now.After(time.Unix(exp, 0).Add(v.cfg.Leeway)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := numericClaim(claims["nbf"]); ok && now.Add(v.cfg.Leeway).Before(time.Unix(nbf, 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if v.cfg.Issuer != "" && claims["iss"] != v.cfg.Issuer {
+		return errors.New("unexpected issuer")
+	}
+	if v.cfg.Audience != "" {
+		found := false
+		for _, aud := range stringsClaim(claims["aud"]) {
+			found = found || aud == v.cfg.Audience
+		}
+		if !found {
+			return errors.New("unexpected audience")
+		}
+	}
+	return nil
+}
+
+func decodeSegment(segment string, dst any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(dst)
+}
+
+func numericClaim(v any) (int64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := n.Int64(); err == nil {
+		return i, true
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// stringsClaim приводит claim-строку или массив строк к срезу.
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
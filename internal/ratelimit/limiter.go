@@ -0,0 +1,97 @@
+// Package ratelimit ограничивает частоту запросов клиентов алгоритмом token bucket.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval - как часто удаляются корзины неактивных клиентов.
+const sweepInterval = time.Minute
+
+// Rule - лимит маршрута: Rate запросов в секунду в среднем и до Burst подряд.
+// Нулевой Rate - без ограничения.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+type bucketKey struct {
+	route  string
+	client string
+}
+
+type bucket struct {
+	last   time.Time
+	rule   Rule
+	tokens float64
+}
+
+// Limiter хранит корзины клиентов в памяти процесса; у каждой реплики сервиса они свои.
+type Limiter struct {
+	routes    map[string]Rule
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+	fallback  Rule
+	mu        sync.Mutex
+}
+
+// New создаёт Limiter с лимитами маршрутов routes; остальные маршруты ограничены fallback.
+func New(fallback Rule, routes map[string]Rule) *Limiter {
+	return &Limiter{
+		routes:   routes,
+		buckets:  make(map[bucketKey]*bucket),
+		now:      time.Now,
+		fallback: fallback,
+	}
+}
+
+// Allow списывает запрос клиента client к маршруту route. Если лимит исчерпан,
+// возвращает false и время до появления следующего запроса в корзине.
+func (l *Limiter) Allow(route, client string) (bool, time.Duration) {
+	rule, ok := l.routes[route]
+	if !ok {
+		rule = l.fallback
+	}
+	if rule.Rate <= 0 {
+		return true, 0
+	}
+	burst := float64(max(rule.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	key := bucketKey{route: route, client: client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now, rule: rule}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rule.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rule.Rate * float64(time.Second))
+}
+
+// sweep удаляет полные корзины: новая корзина клиента будет такой же.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rule.Rate >= float64(max(b.rule.Burst, 1)) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(fallback Rule, routes map[string]Rule) (*Limiter, *time.Time) {
+	l := New(fallback, routes)
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return clock }
+	return l, &clock
+}
+
+func TestLimiterAllowsBurstThenRefills(t *testing.T) {
+	l, clock := newTestLimiter(Rule{}, map[string]Rule{"POST /pullRequest/create": {Rate: 2, Burst: 3}})
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("POST /pullRequest/create", "token:ci")
+		require.True(t, ok, "request %d fits the burst", i)
+	}
+	ok, retryAfter := l.Allow("POST /pullRequest/create", "token:ci")
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _ = l.Allow("POST /pullRequest/create", "token:other")
+	require.True(t, ok, "clients have separate buckets")
+
+	*clock = clock.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("POST /pullRequest/create", "token:ci")
+	require.True(t, ok)
+	ok, _ = l.Allow("POST /pullRequest/create", "token:ci")
+	require.False(t, ok)
+
+	*clock = clock.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ = l.Allow("POST /pullRequest/create", "token:ci")
+		require.True(t, ok, "the bucket refills up to the burst only")
+	}
+	ok, _ = l.Allow("POST /pullRequest/create", "token:ci")
+	require.False(t, ok)
+}
+
+func TestLimiterFallbackRule(t *testing.T) {
+	l, _ := newTestLimiter(Rule{Rate: 1, Burst: 1}, map[string]Rule{"GET /team/get": {}})
+
+	for i := 0; i < 10; i++ {
+		ok, _ := l.Allow("GET /team/get", "ip:10.0.0.1")
+		require.True(t, ok, "zero rate disables the limit")
+	}
+
+	ok, _ := l.Allow("GET /users/getReview", "ip:10.0.0.1")
+	require.True(t, ok)
+	ok, retryAfter := l.Allow("GET /users/getReview", "ip:10.0.0.1")
+	require.False(t, ok)
+	require.Equal(t, time.Second, retryAfter)
+}
+
+func TestLimiterSweepsFullBuckets(t *testing.T) {
+	l, clock := newTestLimiter(Rule{Rate: 1, Burst: 2}, nil)
+
+	l.Allow("GET /team/get", "ip:10.0.0.1")
+	l.Allow("GET /team/get", "ip:10.0.0.2")
+	require.Len(t, l.buckets, 2)
+
+	*clock = clock.Add(2 * sweepInterval)
+	l.Allow("GET /team/get", "ip:10.0.0.3")
+	require.Len(t, l.buckets, 1, "refilled buckets of idle clients are dropped")
+}
@@ -0,0 +1,29 @@
+// Package retry - общие правила повторов фоновых доставок (вебхуки, код-хостинги, уведомления).
+package retry
+
+import "time"
+
+// MaxErrorLen ограничивает длину ошибки попытки, сохраняемой в хранилище.
+const MaxErrorLen = 512
+
+// Backoff возвращает задержку перед попыткой, следующей за attempt:
+// base*2^(attempt-1), не больше limit.
+func Backoff(base, limit time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= limit {
+			return limit
+		}
+	}
+	return min(delay, limit)
+}
+
+// TruncateError обрезает текст ошибки до MaxErrorLen.
+func TruncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > MaxErrorLen {
+		msg = msg[:MaxErrorLen]
+	}
+	return msg
+}
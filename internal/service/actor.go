@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// actorKey - ключ контекста с идентификатором вызывающего.
+type actorKey struct{}
+
+// WithActor возвращает контекст, операции в котором записываются в историю от имени actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom возвращает вызывающего из ctx или storage.ActorSystem, если он не задан.
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return storage.ActorSystem
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// candidatePool - участники команды, разделённые на пул кандидатов и исключённых.
+type candidatePool struct {
+	candidates []storage.User
+	excluded   []storage.ExcludedCandidate
+}
+
+// pick - выбранный ревьюер и выпавшее для него случайное значение (nil - выбран без
+// розыгрыша).
+type pick struct {
+	draw *int
+	user storage.User
+}
+
+// selection - пул кандидатов и выбранные из него ревьюеры.
+type selection struct {
+	seed     *int64
+	strategy storage.AssignmentStrategy
+	pool     candidatePool
+	picks    []pick
+}
+
+// reviewerIDs возвращает user_id выбранных ревьюеров.
+func (s selection) reviewerIDs() []string {
+	ids := make([]string, 0, len(s.picks))
+	for _, p := range s.picks {
+		ids = append(ids, p.user.ID)
+	}
+	return ids
+}
+
+// buildPool отбирает кандидатов из members. skip задаёт причины исключения по user_id,
+// неактивные участники исключаются всегда.
+func buildPool(members []storage.User, skip map[string]storage.ExclusionReason) candidatePool {
+	var pool candidatePool
+	for _, m := range members {
+		if reason, ok := skip[m.ID]; ok {
+			pool.excluded = append(pool.excluded, storage.ExcludedCandidate{UserID: m.ID, Reason: reason})
+			continue
+		}
+		if !m.IsActive {
+			pool.excluded = append(pool.excluded, storage.ExcludedCandidate{UserID: m.ID, Reason: storage.ExcludedInactive})
+			continue
+		}
+		pool.candidates = append(pool.candidates, m)
+	}
+	return pool
+}
+
+// candidateIDs возвращает user_id кандидатов пула.
+func (c candidatePool) candidateIDs() []string {
+	ids := make([]string, 0, len(c.candidates))
+	for _, u := range c.candidates {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+// logEntries строит записи журнала назначений для выбранных ревьюеров.
+func (s selection) logEntries(prID, replacedID string) []storage.AssignmentLog {
+	entries := make([]storage.AssignmentLog, 0, len(s.picks))
+	for _, p := range s.picks {
+		entries = append(entries, storage.AssignmentLog{
+			PullRequestID:      prID,
+			ReviewerID:         p.user.ID,
+			ReplacedReviewerID: replacedID,
+			Strategy:           s.strategy,
+			PoolSize:           len(s.pool.candidates),
+			Candidates:         s.pool.candidateIDs(),
+			Excluded:           s.pool.excluded,
+			Draw:               p.draw,
+			Seed:               s.seed,
+		})
+	}
+	return entries
+}
+
+// pickRev выбирает случайных reviewer'ов из списка users.
+func pickRev(rnd Rand, users []storage.User, amount int) ([]pick, storage.AssignmentStrategy, error) {
+	if amount <= 0 || len(users) == 0 {
+		return []pick{}, storage.StrategyWholePool, nil
+	}
+
+	if len(users) <= amount {
+		picks := make([]pick, 0, len(users))
+		for _, u := range users {
+			picks = append(picks, pick{user: u})
+		}
+		return picks, storage.StrategyWholePool, nil
+	}
+
+	picks := make([]pick, 0, amount)
+	used := make(map[int]bool, amount)
+	for len(picks) < amount {
+		i, err := rnd.Intn(len(users))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, u := used[i]; u {
+			continue
+		}
+		used[i] = true
+		picks = append(picks, pick{user: users[i], draw: &i})
+	}
+	return picks, storage.StrategyRandom, nil
+}
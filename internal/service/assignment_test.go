@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestAssignmentLogDraw(t *testing.T) {
+	store := memory.NewStore()
+	txm := memory.NewTxManager(store)
+	users := memory.NewUserRepository(store)
+	teams := service.NewTeamService(txm, memory.NewTeamRepository(store), users, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+	prs := service.NewPRService(txm, users, memory.NewPullRequestRepository(store), memory.NewAssignmentLogRepository(store),
+		memory.NewPREventRepository(store), memory.NewOutboxRepository(store), service.WithRand(service.NewSeededRand(7)))
+
+	ctx := context.Background()
+	_, err := teams.CreateTeam(ctx, storage.Team{TeamName: "small", Members: []storage.User{
+		{ID: "s1", Username: "Alice", IsActive: true},
+		{ID: "s2", Username: "Bob", IsActive: true},
+	}})
+	require.Nil(t, err)
+	_, err = teams.CreateTeam(ctx, storage.Team{TeamName: "large", Members: []storage.User{
+		{ID: "l1", Username: "Carol", IsActive: true},
+		{ID: "l2", Username: "Dave", IsActive: true},
+		{ID: "l3", Username: "Eve", IsActive: true},
+		{ID: "l4", Username: "Frank", IsActive: true},
+	}})
+	require.Nil(t, err)
+
+	_, err = prs.CreatePR(ctx, "pr-small", "Change", "s1")
+	require.Nil(t, err)
+	entries, err := prs.GetAssignmentLog(ctx, "pr-small")
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, storage.StrategyWholePool, entries[0].Strategy)
+	require.Nil(t, entries[0].Draw, "whole pool is assigned without a draw")
+
+	_, err = prs.CreatePR(ctx, "pr-large", "Change", "l1")
+	require.Nil(t, err)
+	entries, err = prs.GetAssignmentLog(ctx, "pr-large")
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		require.Equal(t, storage.StrategyRandom, e.Strategy)
+		require.NotNil(t, e.Draw)
+		require.Equal(t, e.ReviewerID, e.Candidates[*e.Draw], "draw indexes the chosen candidate")
+	}
+}
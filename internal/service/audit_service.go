@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Объекты журнала аудита (AuditEntry.TargetType).
+const (
+	AuditTargetTeam        = "team"
+	AuditTargetUser        = "user"
+	AuditTargetIntegration = "integration"
+)
+
+// auditUser - снимок пользователя в журнале аудита.
+type auditUser struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamID   int    `json:"team_id"`
+	IsActive bool   `json:"is_active"`
+}
+
+// auditTeam - снимок команды в журнале аудита.
+type auditTeam struct {
+	TeamName string      `json:"team_name"`
+	Members  []auditUser `json:"members"`
+	TeamID   int         `json:"team_id"`
+}
+
+// auditSLA - снимок срока ревью команды в журнале аудита.
+type auditSLA struct {
+	Within        string                `json:"within"`
+	Escalation    storage.SLAEscalation `json:"escalation"`
+	EscalateAfter string                `json:"escalate_after,omitempty"`
+	LeadID        string                `json:"lead_id,omitempty"`
+}
+
+// auditTeamChat - снимок чата команды в журнале аудита. URL входящего вебхука чата
+// служит секретом, поэтому в журнал попадает только его хост.
+type auditTeamChat struct {
+	UpdatedAt   time.Time `json:"updated_at"`
+	WebhookHost string    `json:"webhook_host"`
+}
+
+func newAuditTeamChat(chat storage.TeamChat) auditTeamChat {
+	res := auditTeamChat{UpdatedAt: chat.UpdatedAt}
+	if u, err := url.Parse(chat.WebhookURL); err == nil {
+		res.WebhookHost = u.Host
+	}
+	return res
+}
+
+// auditEmailPreference - снимок настройки писем пользователя в журнале аудита.
+type auditEmailPreference struct {
+	UserID string            `json:"user_id"`
+	Email  string            `json:"email"`
+	Mode   storage.EmailMode `json:"mode"`
+}
+
+func newAuditEmailPreference(pref storage.EmailPreference) auditEmailPreference {
+	return auditEmailPreference{UserID: pref.UserID, Email: pref.Email, Mode: pref.Mode}
+}
+
+// auditInboundSecret - снимок секрета входящих вебхуков в журнале аудита; сам секрет
+// в журнал не попадает.
+type auditInboundSecret struct {
+	CreatedAt time.Time `json:"created_at"`
+	Provider  string    `json:"provider"`
+}
+
+func newAuditInboundSecret(secret storage.InboundSecret) auditInboundSecret {
+	return auditInboundSecret{Provider: secret.Provider, CreatedAt: secret.CreatedAt}
+}
+
+func newAuditUser(user storage.User) auditUser {
+	return auditUser{UserID: user.ID, Username: user.Username, TeamID: user.TeamID, IsActive: user.IsActive}
+}
+
+func newAuditUsers(users []storage.User) []auditUser {
+	res := make([]auditUser, 0, len(users))
+	for _, u := range users {
+		res = append(res, newAuditUser(u))
+	}
+	return res
+}
+
+func newAuditTeam(team storage.Team) auditTeam {
+	return auditTeam{TeamName: team.TeamName, TeamID: team.ID, Members: newAuditUsers(team.Members)}
+}
+
+func newAuditSLA(sla storage.ReviewSLA) auditSLA {
+	res := auditSLA{Within: sla.Within.String(), Escalation: sla.Escalation, LeadID: sla.LeadID}
+	if sla.EscalateAfter > 0 {
+		res.EscalateAfter = sla.EscalateAfter.String()
+	}
+	return res
+}
+
+// audit записывает действие вызывающего из ctx в журнал аудита; вызывается в транзакции
+// самой операции. before и after сериализуются в JSON, nil - объекта не было.
+func audit(
+	ctx context.Context,
+	repo storage.AuditLogRepository,
+	action storage.AuditAction,
+	targetType, targetID string,
+	before, after any,
+) *apperrors.AppError {
+	entry := storage.AuditEntry{
+		Action:     action,
+		Actor:      ActorFrom(ctx),
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	req := RequestFrom(ctx)
+	entry.RequestID, entry.SourceIP = req.ID, req.SourceIP
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			log.Printf("marshal audit snapshot failed: %v", err)
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			log.Printf("marshal audit snapshot failed: %v", err)
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+	return repo.Add(ctx, entry)
+}
+
+// AuditService показывает журнал административных действий организации.
+type AuditService struct {
+	auditRepo storage.AuditLogRepository
+}
+
+// NewAuditService создаёт новый AuditService.
+func NewAuditService(auditRepo storage.AuditLogRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// List возвращает страницу журнала по фильтру, новые записи первыми, и курсор следующей
+// страницы (filter.BeforeID для продолжения); 0 - это последняя страница.
+func (s *AuditService) List(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, int64, *apperrors.AppError) {
+	limit := filter.Limit
+	filter.Limit++
+	entries, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(entries) <= limit {
+		return entries, 0, nil
+	}
+	entries = entries[:limit]
+	return entries, entries[limit-1].ID, nil
+}
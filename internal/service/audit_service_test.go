@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+func TestAdministrativeChangesAreAudited(t *testing.T) {
+	s := newServices(t)
+	ctx := service.WithRequest(as("lead", "lead"), service.RequestInfo{ID: "req-42", SourceIP: "192.0.2.7"})
+
+	_, err := s.users.SetActiveStatus(ctx, "u1", false)
+	require.Nil(t, err)
+	_, err = s.teams.CreateTeam(as("admin", "admin"), storage.Team{TeamName: "infra", Members: []storage.User{
+		{ID: "f1", Username: "Fred", IsActive: true},
+		{ID: "n1", Username: "New", IsActive: true},
+	}})
+	require.Nil(t, err)
+
+	entries, err := s.audit.List(context.Background(), storage.AuditFilter{Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, entries, 4, "two teams from the fixture, activity change and infra")
+
+	infra := entries[0]
+	require.Equal(t, storage.AuditTeamCreate, infra.Action)
+	require.Equal(t, "admin", infra.Actor)
+	require.Equal(t, "infra", infra.TargetID)
+	require.Empty(t, infra.RequestID)
+	require.JSONEq(t, `[{"user_id":"f1","username":"Fred","team_id":2,"is_active":true}]`, string(infra.Before),
+		"only members that existed before are snapshotted")
+	require.JSONEq(t, `{"team_name":"infra","team_id":3,"members":[
+		{"user_id":"f1","username":"Fred","team_id":3,"is_active":true},
+		{"user_id":"n1","username":"New","team_id":3,"is_active":true}]}`, string(infra.After))
+
+	deactivated := entries[1]
+	require.Equal(t, storage.AuditUserSetActive, deactivated.Action)
+	require.Equal(t, "lead", deactivated.Actor)
+	require.Equal(t, "req-42", deactivated.RequestID)
+	require.Equal(t, "192.0.2.7", deactivated.SourceIP)
+	require.Equal(t, service.AuditTargetUser, deactivated.TargetType)
+	require.Equal(t, "u1", deactivated.TargetID)
+	require.JSONEq(t, `{"user_id":"u1","username":"Alice","team_id":1,"is_active":true}`, string(deactivated.Before))
+	require.JSONEq(t, `{"user_id":"u1","username":"Alice","team_id":1,"is_active":false}`, string(deactivated.After))
+
+	require.Nil(t, entries[3].Before, "new team without existing members")
+	require.Equal(t, storage.ActorSystem, entries[3].Actor)
+}
+
+func TestRejectedChangesAreNotAudited(t *testing.T) {
+	s := newServices(t)
+
+	_, err := s.users.SetActiveStatus(as("u1"), "u2", false)
+	requireForbidden(t, err)
+
+	entries, err := s.audit.List(context.Background(), storage.AuditFilter{Action: storage.AuditUserSetActive, Limit: 10})
+	require.Nil(t, err)
+	require.Empty(t, entries)
+}
+
+func TestNotificationSettingsAreAudited(t *testing.T) {
+	s := newServices(t)
+	lead := as("lead", "lead")
+
+	_, err := s.notifications.SetTeamChat(lead, "backend", "https://hooks.slack.com/services/T1/B1/secret")
+	require.Nil(t, err)
+	require.Nil(t, s.notifications.DeleteTeamChat(lead, "backend"))
+	_, err = s.notifications.SetEmailPreference(as("u1"), "u1", "alice@example.com", storage.EmailImmediate)
+	require.Nil(t, err)
+	_, err = s.notifications.SetEmailPreference(as("u1"), "u1", "alice@example.com", storage.EmailDigest)
+	require.Nil(t, err)
+
+	entries, err := s.audit.List(context.Background(), storage.AuditFilter{TargetType: service.AuditTargetTeam, TargetID: "backend", Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, entries, 3, "team creation from the fixture, chat set and delete")
+	deleted, set := entries[0], entries[1]
+	require.Equal(t, storage.AuditTeamChatDelete, deleted.Action)
+	require.Equal(t, "lead", deleted.Actor)
+	require.Nil(t, deleted.After)
+	require.Equal(t, storage.AuditTeamChatSet, set.Action)
+	require.Nil(t, set.Before)
+	require.Contains(t, string(set.After), `"webhook_host":"hooks.slack.com"`)
+	require.NotContains(t, string(set.After), "secret", "webhook URL is not logged")
+	require.JSONEq(t, string(set.After), string(deleted.Before))
+
+	entries, err = s.audit.List(context.Background(), storage.AuditFilter{Action: storage.AuditUserEmailSet, Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "u1", entries[0].Actor)
+	require.Equal(t, "u1", entries[0].TargetID)
+	require.Equal(t, service.AuditTargetUser, entries[0].TargetType)
+	require.JSONEq(t, `{"user_id":"u1","email":"alice@example.com","mode":"immediate"}`, string(entries[0].Before))
+	require.JSONEq(t, `{"user_id":"u1","email":"alice@example.com","mode":"digest"}`, string(entries[0].After))
+	require.Nil(t, entries[1].Before)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Role - роль вызывающего.
+type Role string
+
+const (
+	// RoleMember - участник команды: читает данные и работает со своими PR.
+	RoleMember Role = "member"
+	// RoleLead - лид команды: дополнительно управляет командами и участниками.
+	RoleLead Role = "lead"
+	// RoleAdmin - администратор сервиса.
+	RoleAdmin Role = "admin"
+	// RoleAutomation - API-токен интеграции (CI, боты); права задаются scopes токена.
+	RoleAutomation Role = "automation"
+)
+
+// roleScopes - права пользователя SSO по роли.
+var roleScopes = map[Role][]storage.TokenScope{
+	RoleMember: {storage.ScopeRead, storage.ScopePRWrite, storage.ScopeStatsRead},
+	RoleLead:   {storage.ScopeRead, storage.ScopePRWrite, storage.ScopeStatsRead, storage.ScopeTeamAdmin},
+	RoleAdmin:  {storage.ScopeAdmin},
+}
+
+// Caller - аутентифицированный вызывающий: пользователь SSO или API-токен.
+type Caller struct {
+	// UserID - users.user_id пользователя SSO; пусто для API-токена.
+	UserID string
+	// Name - имя API-токена; пусто для пользователя SSO. Имена не уникальны, токен
+	// различает TokenID.
+	Name   string
+	Role   Role
+	Scopes []storage.TokenScope
+	// TokenID - id API-токена; 0 для пользователя SSO.
+	TokenID int64
+	// OrgID - организация, в которой работает вызывающий.
+	OrgID int64
+}
+
+// UserCaller возвращает вызывающего-пользователя SSO организации по умолчанию. Из нескольких
+// ролей берётся старшая, неизвестные роли игнорируются; без известных ролей пользователь - RoleMember.
+func UserCaller(userID string, roles []string) Caller {
+	role := RoleMember
+	for _, r := range []Role{RoleAdmin, RoleLead} {
+		if slices.Contains(roles, string(r)) {
+			role = r
+			break
+		}
+	}
+	return Caller{UserID: userID, Role: role, Scopes: roleScopes[role], OrgID: storage.DefaultOrgID}
+}
+
+// TokenCaller возвращает вызывающего по API-токену; он работает в организации токена.
+func TokenCaller(token storage.APIToken) Caller {
+	return Caller{Name: token.Name, Role: RoleAutomation, Scopes: token.Scopes, TokenID: token.ID, OrgID: token.OrgID}
+}
+
+// CanSwitchOrg сообщает, может ли вызывающий работать с данными организации orgID:
+// кроме своей организации, это доступно администраторам организации по умолчанию.
+func (c Caller) CanSwitchOrg(orgID int64) bool {
+	return orgID == c.OrgID || c.OrgID == storage.DefaultOrgID && c.Allows(storage.ScopeAdmin)
+}
+
+// Actor возвращает идентификатор вызывающего для истории: user_id пользователя
+// или "token:<имя>" для API-токена.
+func (c Caller) Actor() string {
+	if c.UserID != "" {
+		return c.UserID
+	}
+	return "token:" + c.Name
+}
+
+// Allows сообщает, есть ли у вызывающего право scope; ScopeAdmin даёт все права.
+func (c Caller) Allows(scope storage.TokenScope) bool {
+	return slices.Contains(c.Scopes, storage.ScopeAdmin) || slices.Contains(c.Scopes, scope)
+}
+
+// callerKey - ключ контекста с вызывающим.
+type callerKey struct{}
+
+// WithCaller возвращает контекст с вызывающим c; операции в нём записываются в историю
+// от имени c.Actor().
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return WithActor(context.WithValue(ctx, callerKey{}, c), c.Actor())
+}
+
+// CallerFrom возвращает вызывающего из ctx; false - запрос не аутентифицирован
+// (внутренние обработчики или AUTH_ENABLED=false).
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(Caller)
+	return c, ok
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// createdPayload - детали события created.
+type createdPayload struct {
+	Name     string           `json:"pull_request_name"`
+	AuthorID string           `json:"author_id"`
+	Status   storage.PRStatus `json:"status"`
+}
+
+// reviewerAssignedPayload - детали события reviewer_assigned.
+type reviewerAssignedPayload struct {
+	ReviewerID string `json:"reviewer_id"`
+}
+
+// reviewerReplacedPayload - детали события reviewer_replaced.
+type reviewerReplacedPayload struct {
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+// reviewOverduePayload - детали события review_overdue.
+type reviewOverduePayload struct {
+	DueAt      time.Time `json:"due_at"`
+	ReviewerID string    `json:"reviewer_id"`
+}
+
+// ReviewState - итог ревью.
+type ReviewState string
+
+const (
+	// ReviewApproved - изменения одобрены.
+	ReviewApproved ReviewState = "approved"
+	// ReviewChangesRequested - нужны исправления.
+	ReviewChangesRequested ReviewState = "changes_requested"
+	// ReviewCommented - только комментарии.
+	ReviewCommented ReviewState = "commented"
+)
+
+// IsReviewState сообщает, существует ли итог ревью state.
+func IsReviewState(state string) bool {
+	switch ReviewState(state) {
+	case ReviewApproved, ReviewChangesRequested, ReviewCommented:
+		return true
+	}
+	return false
+}
+
+// reviewSubmittedPayload - детали события review_submitted.
+type reviewSubmittedPayload struct {
+	ReviewerID string      `json:"reviewer_id"`
+	State      ReviewState `json:"state"`
+}
+
+// mergedPayload - детали события merged.
+type mergedPayload struct {
+	MergedAt *time.Time `json:"merged_at"`
+}
+
+// newEvent строит событие истории pr от имени вызывающего из ctx.
+func newEvent(ctx context.Context, prID string, typ storage.PREventType, payload any) (storage.PREvent, *apperrors.AppError) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("marshal %s payload failed: %v", typ, err)
+		return storage.PREvent{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return storage.PREvent{PullRequestID: prID, Type: typ, Actor: ActorFrom(ctx), Payload: data}, nil
+}
+
+// createEvents строит события created и reviewer_assigned для нового pr.
+func createEvents(ctx context.Context, pr storage.PullRequest) ([]storage.PREvent, *apperrors.AppError) {
+	events := make([]storage.PREvent, 0, len(pr.AssignedReviewers)+1)
+
+	ev, err := newEvent(ctx, pr.ID, storage.EventCreated, createdPayload{Name: pr.Name, AuthorID: pr.AuthorID, Status: pr.Status})
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, ev)
+
+	for _, rev := range pr.AssignedReviewers {
+		ev, err := newEvent(ctx, pr.ID, storage.EventReviewerAssigned, reviewerAssignedPayload{ReviewerID: rev})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// Типы доменных событий, которые публикуются подписчикам вебхуков через outbox.
+const (
+	DomainPRCreated            = "pr.created"
+	DomainPRReviewerReassigned = "pr.reviewer_reassigned"
+	DomainPRReviewerAdded      = "pr.reviewer_added"
+	DomainPRMerged             = "pr.merged"
+	DomainPRClosed             = "pr.closed"
+	DomainPRReopened           = "pr.reopened"
+	DomainUserActivityChanged  = "user.activity_changed"
+)
+
+// DomainEventTypes - все типы доменных событий, на которые можно подписаться.
+var DomainEventTypes = []string{
+	DomainPRCreated,
+	DomainPRReviewerReassigned,
+	DomainPRReviewerAdded,
+	DomainPRMerged,
+	DomainPRClosed,
+	DomainPRReopened,
+	DomainUserActivityChanged,
+}
+
+// prDomainPayload - состояние pr в доменных событиях pr.*.
+type prDomainPayload struct {
+	CreatedAt         time.Time        `json:"created_at"`
+	MergedAt          *time.Time       `json:"merged_at,omitempty"`
+	ID                string           `json:"pull_request_id"`
+	Name              string           `json:"pull_request_name"`
+	AuthorID          string           `json:"author_id"`
+	Status            storage.PRStatus `json:"status"`
+	OldReviewerID     string           `json:"old_reviewer_id,omitempty"`
+	NewReviewerID     string           `json:"new_reviewer_id,omitempty"`
+	Actor             string           `json:"actor"`
+	AssignedReviewers []string         `json:"assigned_reviewers"`
+}
+
+// userDomainPayload - детали события user.activity_changed.
+type userDomainPayload struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Actor    string `json:"actor"`
+	TeamID   int    `json:"team_id"`
+	IsActive bool   `json:"is_active"`
+}
+
+func newPRDomainPayload(ctx context.Context, pr storage.PullRequest) prDomainPayload {
+	reviewers := pr.AssignedReviewers
+	if reviewers == nil {
+		reviewers = []string{}
+	}
+	return prDomainPayload{
+		ID:                pr.ID,
+		Name:              pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		AssignedReviewers: reviewers,
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+		Actor:             ActorFrom(ctx),
+	}
+}
+
+// newOutboxEvent строит доменное событие для записи в outbox.
+func newOutboxEvent(typ string, payload any) (storage.OutboxEvent, *apperrors.AppError) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("marshal %s payload failed: %v", typ, err)
+		return storage.OutboxEvent{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return storage.OutboxEvent{Type: typ, Payload: data}, nil
+}
+
+// publish записывает доменное событие в outbox в текущей транзакции ctx.
+func publish(ctx context.Context, outbox storage.OutboxRepository, typ string, payload any) *apperrors.AppError {
+	ev, err := newOutboxEvent(typ, payload)
+	if err != nil {
+		return err
+	}
+	return outbox.Add(ctx, []storage.OutboxEvent{ev})
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// IdempotencyService запоминает ответы на запросы с ключом идемпотентности, чтобы повтор
+// запроса получил исходный ответ, а не выполнил операцию ещё раз.
+type IdempotencyService struct {
+	repo storage.IdempotencyRepository
+	now  func() time.Time
+	ttl  time.Duration
+}
+
+// NewIdempotencyService создаёт новый IdempotencyService; ответы хранятся ttl.
+func NewIdempotencyService(repo storage.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, now: time.Now, ttl: ttl}
+}
+
+// Begin занимает ключ key клиента client для запроса с отпечатком fingerprint. Если запрос
+// с этим ключом уже выполнен, возвращает его запись и true - ответ нужно повторить. Ключ
+// другого запроса - IDEMPOTENCY_KEY_REUSED, ещё выполняющегося - IDEMPOTENCY_IN_PROGRESS.
+func (s *IdempotencyService) Begin(ctx context.Context, client, key, fingerprint string) (storage.IdempotencyRecord, bool, *apperrors.AppError) {
+	now := s.now()
+	rec, claimed, err := s.repo.Claim(ctx, storage.IdempotencyRecord{
+		Client:      client,
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(s.ttl),
+	}, now)
+	switch {
+	case err != nil:
+		return storage.IdempotencyRecord{}, false, err
+	case claimed:
+		return storage.IdempotencyRecord{}, false, nil
+	case rec.Fingerprint != fingerprint:
+		return storage.IdempotencyRecord{}, false, apperrors.New(apperrors.ErrIdempotencyKeyReused)
+	case rec.Status == 0:
+		return storage.IdempotencyRecord{}, false, apperrors.New(apperrors.ErrIdempotencyInProgress)
+	}
+	return rec, true, nil
+}
+
+// Finish сохраняет ответ на запрос, начатый Begin, и заголовки headers, которые нужно
+// повторить вместе с ним. После ответа 5xx ключ освобождается:
+// операция могла не выполниться, и повтор должен выполнить её заново.
+func (s *IdempotencyService) Finish(ctx context.Context, client, key string, status int, headers map[string]string, response []byte) *apperrors.AppError {
+	if status >= http.StatusInternalServerError {
+		return s.repo.Release(ctx, client, key)
+	}
+	return s.repo.Complete(ctx, client, key, status, headers, response)
+}
@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestIdempotencyReplaysCompletedRequest(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewIdempotencyService(memory.NewIdempotencyRepository(memory.NewStore()), time.Hour)
+
+	_, replay, err := svc.Begin(ctx, "token:ci", "k1", "fp1")
+	require.Nil(t, err)
+	require.False(t, replay)
+
+	_, _, err = svc.Begin(ctx, "token:ci", "k1", "fp1")
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrIdempotencyInProgress, err.Code)
+
+	require.Nil(t, svc.Finish(ctx, "token:ci", "k1", http.StatusCreated, map[string]string{"ETag": `"1"`}, []byte(`{"pr":{}}`)))
+
+	rec, replay, err := svc.Begin(ctx, "token:ci", "k1", "fp1")
+	require.Nil(t, err)
+	require.True(t, replay)
+	require.Equal(t, http.StatusCreated, rec.Status)
+	require.Equal(t, `{"pr":{}}`, string(rec.Response))
+	require.Equal(t, map[string]string{"ETag": `"1"`}, rec.Headers)
+
+	_, _, err = svc.Begin(ctx, "token:ci", "k1", "fp2")
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrIdempotencyKeyReused, err.Code)
+	require.Equal(t, http.StatusConflict, err.HTTPStatus())
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewIdempotencyService(memory.NewIdempotencyRepository(memory.NewStore()), time.Hour)
+
+	_, _, err := svc.Begin(ctx, "token:ci", "k1", "fp1")
+	require.Nil(t, err)
+	require.Nil(t, svc.Finish(ctx, "token:ci", "k1", http.StatusInternalServerError, nil, []byte(`{}`)))
+
+	_, replay, err := svc.Begin(ctx, "token:ci", "k1", "fp1")
+	require.Nil(t, err)
+	require.False(t, replay, "the failed request runs again")
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// CodeHostAction - действие над PR на стороне код-хостинга.
+type CodeHostAction string
+
+const (
+	// ActionOpened - PR открыт.
+	ActionOpened CodeHostAction = "opened"
+	// ActionReopened - закрытый PR открыт снова.
+	ActionReopened CodeHostAction = "reopened"
+	// ActionClosed - PR закрыт без слияния.
+	ActionClosed CodeHostAction = "closed"
+	// ActionMerged - PR смержен.
+	ActionMerged CodeHostAction = "merged"
+)
+
+// CodeHostEvent - событие PR от код-хостинга, приведённое к общему виду.
+type CodeHostEvent struct {
+	Provider string
+	// DeliveryID - идентификатор доставки у провайдера; одинаков при повторной доставке.
+	DeliveryID    string
+	Action        CodeHostAction
+	PullRequestID string
+	Title         string
+	// AuthorLogin - логин автора PR у провайдера.
+	AuthorLogin string
+	// SenderLogin - логин того, кто выполнил действие.
+	SenderLogin string
+}
+
+// IngestResult - итог обработки входящего события.
+type IngestResult string
+
+const (
+	// IngestApplied - событие применено.
+	IngestApplied IngestResult = "applied"
+	// IngestDuplicate - доставка уже была обработана.
+	IngestDuplicate IngestResult = "duplicate"
+	// IngestIgnored - событие не требует действий (например, закрыт PR, который сервису не известен).
+	IngestIgnored IngestResult = "ignored"
+)
+
+// IntegrationService применяет события код-хостингов к pr.
+type IntegrationService struct {
+	txm             storage.TxManager
+	userRepo        storage.UserRepository
+	prRepo          storage.PullRequestRepository
+	integrationRepo storage.IntegrationRepository
+	auditRepo       storage.AuditLogRepository
+	prService       *PRService
+}
+
+// NewIntegrationService создаёт новый IntegrationService.
+func NewIntegrationService(
+	txm storage.TxManager,
+	userRepo storage.UserRepository,
+	prRepo storage.PullRequestRepository,
+	integrationRepo storage.IntegrationRepository,
+	auditRepo storage.AuditLogRepository,
+	prService *PRService,
+) *IntegrationService {
+	return &IntegrationService{
+		txm:             txm,
+		userRepo:        userRepo,
+		prRepo:          prRepo,
+		integrationRepo: integrationRepo,
+		auditRepo:       auditRepo,
+		prService:       prService,
+	}
+}
+
+// RotateInboundSecret выпускает новый случайный секрет входящих вебхуков provider для
+// организации из контекста и возвращает его; прежний секрет перестаёт действовать.
+// Изменение записывается в журнал аудита (без самого секрета).
+func (s *IntegrationService) RotateInboundSecret(ctx context.Context, provider string) (storage.InboundSecret, *apperrors.AppError) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("generate inbound secret failed: %v", err)
+		return storage.InboundSecret{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	var secret storage.InboundSecret
+	err := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		before, err := s.currentInboundSecret(ctx, provider)
+		if err != nil {
+			return err
+		}
+		if err := s.integrationRepo.SetInboundSecret(ctx, provider, hex.EncodeToString(buf)); err != nil {
+			return err
+		}
+		secret, err = s.integrationRepo.GetInboundSecret(ctx, provider)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditInboundSecretRotate, AuditTargetIntegration, provider,
+			before, newAuditInboundSecret(secret))
+	})
+	if err != nil {
+		return storage.InboundSecret{}, err
+	}
+	return secret, nil
+}
+
+// DeleteInboundSecret отключает приём вебхуков provider для организации из контекста
+// и записывает это в журнал аудита.
+func (s *IntegrationService) DeleteInboundSecret(ctx context.Context, provider string) *apperrors.AppError {
+	return s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		before, err := s.integrationRepo.GetInboundSecret(ctx, provider)
+		if err != nil {
+			return err
+		}
+		if err := s.integrationRepo.DeleteInboundSecret(ctx, provider); err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditInboundSecretDelete, AuditTargetIntegration, provider,
+			newAuditInboundSecret(before), nil)
+	})
+}
+
+// currentInboundSecret возвращает снимок секрета provider для журнала аудита; nil - секрета нет.
+func (s *IntegrationService) currentInboundSecret(ctx context.Context, provider string) (any, *apperrors.AppError) {
+	secret, err := s.integrationRepo.GetInboundSecret(ctx, provider)
+	switch {
+	case err == nil:
+		return newAuditInboundSecret(secret), nil
+	case err.Code == apperrors.ErrNotFound:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// MatchInboundSecret находит секрет provider, которым подписана входящая доставка: verify
+// проверяет доставку секретом. fallback - секрет из конфигурации (пустой - нет), он
+// принадлежит организации по умолчанию. Доставка применяется в организации найденного
+// секрета, поэтому подписанную для одной организации доставку нельзя направить в другую.
+// false - ни один секрет не подошёл; NOT_FOUND - у провайдера нет ни одного секрета.
+func (s *IntegrationService) MatchInboundSecret(
+	ctx context.Context,
+	provider, fallback string,
+	verify func(secret string) bool,
+) (storage.InboundSecret, bool, *apperrors.AppError) {
+	secrets, err := s.integrationRepo.InboundSecrets(ctx, provider)
+	if err != nil {
+		return storage.InboundSecret{}, false, err
+	}
+	if fallback != "" {
+		secrets = append(secrets, storage.InboundSecret{OrgID: storage.DefaultOrgID, Provider: provider, Secret: fallback})
+	}
+	if len(secrets) == 0 {
+		return storage.InboundSecret{}, false, apperrors.New(apperrors.ErrNotFound)
+	}
+
+	for _, secret := range secrets {
+		if verify(secret.Secret) {
+			return secret, true, nil
+		}
+	}
+	return storage.InboundSecret{}, false, nil
+}
+
+// LinkIdentity связывает логин провайдера с пользователем сервиса.
+func (s *IntegrationService) LinkIdentity(ctx context.Context, provider, login, userID string) *apperrors.AppError {
+	return s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		exists, err := s.userRepo.Exists(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return apperrors.New(apperrors.ErrNotFound)
+		}
+		return s.integrationRepo.LinkIdentity(ctx, provider, login, userID)
+	})
+}
+
+// Ingest применяет событие код-хостинга. Доставка отмечается обработанной в той же
+// транзакции, что и изменение pr, поэтому повторная доставка ничего не меняет, а доставка,
+// обработка которой упала, при повторе обрабатывается заново.
+//
+// opened и reopened неизвестного pr создают его с автоназначением ревьюеров; reopened
+// известного pr открывает его снова. closed и merged для неизвестного pr игнорируются.
+func (s *IntegrationService) Ingest(ctx context.Context, ev CodeHostEvent) (IngestResult, *apperrors.AppError) {
+	var result IngestResult
+	err := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		fresh, err := s.integrationRepo.RecordDelivery(ctx, ev.Provider, ev.DeliveryID)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			result = IngestDuplicate
+			return nil
+		}
+
+		actor, err := s.resolveUser(ctx, ev.Provider, ev.SenderLogin)
+		if err != nil && err.Code != apperrors.ErrNotFound {
+			return err
+		}
+		if err != nil {
+			actor = ev.Provider + ":" + ev.SenderLogin
+		}
+		ctx = WithActor(ctx, actor)
+
+		exists, err := s.prRepo.Exists(ctx, ev.PullRequestID)
+		if err != nil {
+			return err
+		}
+
+		result = IngestApplied
+		switch {
+		case !exists && (ev.Action == ActionOpened || ev.Action == ActionReopened):
+			authorID, err := s.resolveUser(ctx, ev.Provider, ev.AuthorLogin)
+			if err != nil {
+				return err
+			}
+			_, err = s.prService.CreatePR(ctx, ev.PullRequestID, ev.Title, authorID)
+			return err
+		case !exists, ev.Action == ActionOpened:
+			result = IngestIgnored
+			return nil
+		case ev.Action == ActionReopened:
+			_, err = s.prService.Reopen(ctx, ev.PullRequestID)
+		case ev.Action == ActionClosed:
+			_, err = s.prService.Close(ctx, ev.PullRequestID)
+		case ev.Action == ActionMerged:
+			_, err = s.prService.Merge(ctx, ev.PullRequestID)
+		default:
+			result = IngestIgnored
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// resolveUser находит пользователя по логину провайдера: сначала по явной связи,
+// затем по совпадению логина с user_id.
+func (s *IntegrationService) resolveUser(ctx context.Context, provider, login string) (string, *apperrors.AppError) {
+	userID, err := s.integrationRepo.ResolveIdentity(ctx, provider, login)
+	if err == nil {
+		return userID, nil
+	}
+	if err.Code != apperrors.ErrNotFound {
+		return "", err
+	}
+
+	exists, err := s.userRepo.Exists(ctx, login)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", apperrors.New(apperrors.ErrNotFound)
+	}
+	return login, nil
+}
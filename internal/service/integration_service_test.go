@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestInboundSecretsResolveOrganization(t *testing.T) {
+	store := memory.NewStore()
+	txm := memory.NewTxManager(store)
+	users := memory.NewUserRepository(store)
+	prs := memory.NewPullRequestRepository(store)
+	prService := service.NewPRService(txm, users, prs, memory.NewAssignmentLogRepository(store),
+		memory.NewPREventRepository(store), memory.NewOutboxRepository(store))
+	integrations := service.NewIntegrationService(txm, users, prs, memory.NewIntegrationRepository(store),
+		memory.NewAuditLogRepository(store), prService)
+
+	ctx := context.Background()
+	orgs := memory.NewOrganizationRepository(store)
+	acme, err := orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	beta, err := orgs.Create(ctx, "beta")
+	require.Nil(t, err)
+	acmeCtx := storage.WithOrg(ctx, acme.ID)
+	betaCtx := storage.WithOrg(ctx, beta.ID)
+
+	_, _, err = integrations.MatchInboundSecret(ctx, storage.ProviderGitHub, "", func(string) bool { return true })
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrNotFound, err.Code, "provider without secrets is not configured")
+
+	acmeSecret, err := integrations.RotateInboundSecret(acmeCtx, storage.ProviderGitHub)
+	require.Nil(t, err)
+	betaSecret, err := integrations.RotateInboundSecret(betaCtx, storage.ProviderGitHub)
+	require.Nil(t, err)
+	require.NotEqual(t, acmeSecret.Secret, betaSecret.Secret)
+
+	signedBy := func(secret string) func(string) bool {
+		return func(candidate string) bool { return candidate == secret }
+	}
+
+	// Организация из контекста запроса не влияет на результат: доставка, подписанная
+	// секретом acme, применяется только в acme.
+	matched, ok, err := integrations.MatchInboundSecret(betaCtx, storage.ProviderGitHub, "env-secret", signedBy(acmeSecret.Secret))
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, acme.ID, matched.OrgID)
+
+	matched, ok, err = integrations.MatchInboundSecret(ctx, storage.ProviderGitHub, "env-secret", signedBy("env-secret"))
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, storage.DefaultOrgID, matched.OrgID, "configured secret belongs to the default organization")
+
+	_, ok, err = integrations.MatchInboundSecret(ctx, storage.ProviderGitLab, "", signedBy(acmeSecret.Secret))
+	require.NotNil(t, err, "secrets are per provider")
+	require.False(t, ok)
+
+	require.Nil(t, integrations.DeleteInboundSecret(acmeCtx, storage.ProviderGitHub))
+	_, ok, err = integrations.MatchInboundSecret(ctx, storage.ProviderGitHub, "env-secret", signedBy(acmeSecret.Secret))
+	require.Nil(t, err)
+	require.False(t, ok, "deleted secret no longer verifies deliveries")
+
+	rotated, err := integrations.RotateInboundSecret(betaCtx, storage.ProviderGitHub)
+	require.Nil(t, err)
+	_, ok, err = integrations.MatchInboundSecret(ctx, storage.ProviderGitHub, "", signedBy(betaSecret.Secret))
+	require.Nil(t, err)
+	require.False(t, ok, "rotation revokes the previous secret")
+	matched, ok, err = integrations.MatchInboundSecret(ctx, storage.ProviderGitHub, "", signedBy(rotated.Secret))
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, beta.ID, matched.OrgID)
+}
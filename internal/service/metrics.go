@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// prMetrics - доменные счётчики PRService с метками org - id организации pr и team - имя
+// команды автора pr (имена команд уникальны только внутри организации).
+type prMetrics struct {
+	teamRepo    storage.TeamRepository
+	created     *metrics.CounterVec
+	assigned    *metrics.CounterVec
+	reassigned  *metrics.CounterVec
+	noCandidate *metrics.CounterVec
+	merged      *metrics.CounterVec
+}
+
+// WithMetrics регистрирует в registry доменные счётчики по командам: созданные pr,
+// назначенные ревьюеры, замены ревьюеров, отказы NO_CANDIDATE и merge. Счётчики
+// увеличиваются после фиксации внешней транзакции (storage.AfterCommit), поэтому операции
+// откатившейся транзакции планировщика не учитываются; имя команды читается из teamRepo.
+func WithMetrics(registry *metrics.Registry, teamRepo storage.TeamRepository) PRServiceOption {
+	return func(p *PRService) {
+		p.metrics = &prMetrics{
+			teamRepo: teamRepo,
+			created: registry.NewCounterVec("assigner_pull_requests_created_total",
+				"Pull requests created.", "org", "team"),
+			assigned: registry.NewCounterVec("assigner_reviewers_assigned_total",
+				"Reviewers assigned on creation, reassignment and escalation.", "org", "team"),
+			reassigned: registry.NewCounterVec("assigner_reviewer_reassignments_total",
+				"Successful reviewer reassignments.", "org", "team"),
+			noCandidate: registry.NewCounterVec("assigner_no_candidate_total",
+				"Reassignments rejected with NO_CANDIDATE.", "org", "team"),
+			merged: registry.NewCounterVec("assigner_pull_requests_merged_total",
+				"Pull requests merged.", "org", "team"),
+		}
+	}
+}
+
+// record передаёт inc метки организации и команды автора pr после фиксации внешней
+// транзакции ctx. Без WithMetrics ничего не делает; ошибка чтения команды пишется в лог
+// и не влияет на уже выполненную операцию.
+func (p *PRService) record(ctx context.Context, authorID string, inc func(m *prMetrics, org, team string)) {
+	if p.metrics == nil {
+		return
+	}
+
+	author, err := p.userRepo.Get(ctx, authorID)
+	if err != nil {
+		log.Printf("metrics: cannot load author %s: %v", authorID, err)
+		return
+	}
+	team, err := p.metrics.teamRepo.GetByID(ctx, author.TeamID)
+	if err != nil {
+		log.Printf("metrics: cannot load team %d: %v", author.TeamID, err)
+		return
+	}
+	org := strconv.FormatInt(storage.OrgFrom(ctx), 10)
+	storage.AfterCommit(ctx, func() { inc(p.metrics, org, team.TeamName) })
+}
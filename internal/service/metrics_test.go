@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/metrics"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestPRMetricsByTeam(t *testing.T) {
+	store := memory.NewStore()
+	txm := memory.NewTxManager(store)
+	users := memory.NewUserRepository(store)
+	teamRepo := memory.NewTeamRepository(store)
+	registry := metrics.NewRegistry()
+	teams := service.NewTeamService(txm, teamRepo, users, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+	prs := service.NewPRService(txm, users, memory.NewPullRequestRepository(store), memory.NewAssignmentLogRepository(store),
+		memory.NewPREventRepository(store), memory.NewOutboxRepository(store), service.WithMetrics(registry, teamRepo))
+
+	ctx := context.Background()
+	_, err := teams.CreateTeam(ctx, storage.Team{TeamName: "backend", Members: []storage.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+	}})
+	require.Nil(t, err)
+
+	pr, err := prs.CreatePR(ctx, "pr-1", "Change", "u1")
+	require.Nil(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	_, _, err = prs.ReassignReviewer(ctx, "pr-1", pr.AssignedReviewers[0])
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrNoCandidate, err.Code)
+
+	_, err = prs.Merge(ctx, "pr-1")
+	require.Nil(t, err)
+	_, err = prs.Merge(ctx, "pr-1")
+	require.Nil(t, err)
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	require.Contains(t, body, `assigner_pull_requests_created_total{org="1",team="backend"} 1`)
+	require.Contains(t, body, `assigner_reviewers_assigned_total{org="1",team="backend"} 2`)
+	require.Contains(t, body, `assigner_no_candidate_total{org="1",team="backend"} 1`)
+	require.Contains(t, body, `assigner_pull_requests_merged_total{org="1",team="backend"} 1`, "a repeated merge is not counted")
+	require.NotContains(t, body, `assigner_reviewer_reassignments_total{`)
+}
+
+func TestPRMetricsCountOnlyCommittedChanges(t *testing.T) {
+	store := memory.NewStore()
+	txm := memory.NewTxManager(store)
+	users := memory.NewUserRepository(store)
+	teamRepo := memory.NewTeamRepository(store)
+	registry := metrics.NewRegistry()
+	teams := service.NewTeamService(txm, teamRepo, users, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+	prs := service.NewPRService(txm, users, memory.NewPullRequestRepository(store), memory.NewAssignmentLogRepository(store),
+		memory.NewPREventRepository(store), memory.NewOutboxRepository(store), service.WithMetrics(registry, teamRepo))
+
+	acme, err := memory.NewOrganizationRepository(store).Create(context.Background(), "acme")
+	require.Nil(t, err)
+	ctx := storage.WithOrg(context.Background(), acme.ID)
+	_, err = teams.CreateTeam(ctx, storage.Team{TeamName: "backend", Members: []storage.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u4", Username: "Dave", IsActive: true},
+	}})
+	require.Nil(t, err)
+	pr, err := prs.CreatePR(ctx, "pr-1", "Change", "u1")
+	require.Nil(t, err)
+
+	// Как у планировщика SLA: замена во внешней транзакции, которая откатывается.
+	err = txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if _, _, err := prs.ReassignReviewer(ctx, "pr-1", pr.AssignedReviewers[0]); err != nil {
+			return err
+		}
+		return apperrors.New(apperrors.ErrInternalIssue)
+	})
+	require.NotNil(t, err)
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.NotContains(t, rec.Body.String(), `assigner_reviewer_reassignments_total{`, "a rolled back reassignment is not counted")
+
+	_, _, err = prs.ReassignReviewer(ctx, "pr-1", pr.AssignedReviewers[0])
+	require.Nil(t, err)
+
+	rec = httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	require.Contains(t, body, `assigner_reviewer_reassignments_total{org="2",team="backend"} 1`)
+	require.Contains(t, body, `assigner_reviewers_assigned_total{org="2",team="backend"} 3`)
+}
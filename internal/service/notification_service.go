@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// NotificationService управляет чатами команд и настройками писем и показывает очередь уведомлений.
+type NotificationService struct {
+	txm        storage.TxManager
+	teamRepo   storage.TeamRepository
+	userRepo   storage.UserRepository
+	notifyRepo storage.NotificationRepository
+	auditRepo  storage.AuditLogRepository
+	policy     *Policy
+}
+
+// NewNotificationService создаёт новый NotificationService.
+func NewNotificationService(
+	txm storage.TxManager,
+	teamRepo storage.TeamRepository,
+	userRepo storage.UserRepository,
+	notifyRepo storage.NotificationRepository,
+	auditRepo storage.AuditLogRepository,
+	policy *Policy,
+) *NotificationService {
+	return &NotificationService{
+		txm:        txm,
+		teamRepo:   teamRepo,
+		userRepo:   userRepo,
+		notifyRepo: notifyRepo,
+		auditRepo:  auditRepo,
+		policy:     policy,
+	}
+}
+
+// SetTeamChat подключает команду к входящему вебхуку чата и записывает это в журнал
+// аудита; версия команды растёт. Право проверяет Policy.CanManageTeamSettings.
+func (s *NotificationService) SetTeamChat(ctx context.Context, teamName, webhookURL string) (storage.TeamChat, *apperrors.AppError) {
+	var chat storage.TeamChat
+	err := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		team, err := s.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := s.policy.CanManageTeamSettings(ctx, team); err != nil {
+			return err
+		}
+		if err := touchTeam(ctx, s.teamRepo, team.ID); err != nil {
+			return err
+		}
+		before, err := s.currentTeamChat(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.notifyRepo.SetTeamChat(ctx, storage.TeamChat{TeamID: team.ID, WebhookURL: webhookURL}); err != nil {
+			return err
+		}
+		chat, err = s.notifyRepo.GetTeamChat(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditTeamChatSet, AuditTargetTeam, team.TeamName, before, newAuditTeamChat(chat))
+	})
+	if err != nil {
+		return storage.TeamChat{}, err
+	}
+	return chat, nil
+}
+
+// GetTeamChat возвращает вебхук чата команды и версию команды, которая служит ETag настройки
+// (POST и DELETE принимают её в If-Match).
+func (s *NotificationService) GetTeamChat(ctx context.Context, teamName string) (storage.TeamChat, int64, *apperrors.AppError) {
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return storage.TeamChat{}, 0, err
+	}
+	chat, err := s.notifyRepo.GetTeamChat(ctx, team.ID)
+	if err != nil {
+		return storage.TeamChat{}, 0, err
+	}
+	return chat, team.Version, nil
+}
+
+// DeleteTeamChat отключает чат-уведомления команды и записывает это в журнал аудита;
+// версия команды растёт. Право проверяет Policy.CanManageTeamSettings.
+func (s *NotificationService) DeleteTeamChat(ctx context.Context, teamName string) *apperrors.AppError {
+	return s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		team, err := s.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := s.policy.CanManageTeamSettings(ctx, team); err != nil {
+			return err
+		}
+		if err := touchTeam(ctx, s.teamRepo, team.ID); err != nil {
+			return err
+		}
+		before, err := s.notifyRepo.GetTeamChat(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.notifyRepo.DeleteTeamChat(ctx, team.ID); err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditTeamChatDelete, AuditTargetTeam, team.TeamName, newAuditTeamChat(before), nil)
+	})
+}
+
+// currentTeamChat возвращает снимок чата команды для журнала аудита; nil - чат не подключён.
+func (s *NotificationService) currentTeamChat(ctx context.Context, teamID int) (any, *apperrors.AppError) {
+	chat, err := s.notifyRepo.GetTeamChat(ctx, teamID)
+	switch {
+	case err == nil:
+		return newAuditTeamChat(chat), nil
+	case err.Code == apperrors.ErrNotFound:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// List возвращает последние уведомления с фильтром по статусу.
+func (s *NotificationService) List(ctx context.Context, status storage.DeliveryStatus, limit int) ([]storage.Notification, *apperrors.AppError) {
+	return s.notifyRepo.List(ctx, status, limit)
+}
+
+// SetEmailPreference задаёт адрес и режим писем пользователя и записывает это в журнал
+// аудита. Право проверяет Policy.CanSetEmailPreference.
+func (s *NotificationService) SetEmailPreference(ctx context.Context, userID, email string, mode storage.EmailMode) (storage.EmailPreference, *apperrors.AppError) {
+	if err := s.policy.CanSetEmailPreference(ctx, userID); err != nil {
+		return storage.EmailPreference{}, err
+	}
+
+	var pref storage.EmailPreference
+	err := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if _, err := s.userRepo.Get(ctx, userID); err != nil {
+			return err
+		}
+		before, err := s.currentEmailPreference(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.notifyRepo.SetEmailPreference(ctx, storage.EmailPreference{UserID: userID, Email: email, Mode: mode}); err != nil {
+			return err
+		}
+		pref, err = s.notifyRepo.GetEmailPreference(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditUserEmailSet, AuditTargetUser, userID, before, newAuditEmailPreference(pref))
+	})
+	if err != nil {
+		return storage.EmailPreference{}, err
+	}
+	return pref, nil
+}
+
+// currentEmailPreference возвращает снимок настройки писем для журнала аудита; nil -
+// настройки нет.
+func (s *NotificationService) currentEmailPreference(ctx context.Context, userID string) (any, *apperrors.AppError) {
+	pref, err := s.notifyRepo.GetEmailPreference(ctx, userID)
+	switch {
+	case err == nil:
+		return newAuditEmailPreference(pref), nil
+	case err.Code == apperrors.ErrNotFound:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// GetEmailPreference возвращает настройку писем пользователя.
+func (s *NotificationService) GetEmailPreference(ctx context.Context, userID string) (storage.EmailPreference, *apperrors.AppError) {
+	return s.notifyRepo.GetEmailPreference(ctx, userID)
+}
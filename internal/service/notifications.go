@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// NoticeKind - повод уведомления.
+type NoticeKind string
+
+// Поводы уведомлений.
+const (
+	NoticeAssigned NoticeKind = "assigned"
+	NoticeReplaced NoticeKind = "replaced"
+	NoticeReminder NoticeKind = "reminder"
+)
+
+// Notice - данные, из которых рендерится текст уведомления.
+type Notice struct {
+	Kind   NoticeKind
+	PR     storage.PullRequest
+	Author storage.User
+	// Reviewers - ревьюеры, к которым относится уведомление: назначенные при создании,
+	// новый ревьюер при замене, ревьюеры с просроченным ревью при напоминании.
+	Reviewers []storage.User
+	// OldReviewer заполнен только для NoticeReplaced.
+	OldReviewer *storage.User
+	// Recipient - получатель письма; для сообщения в чат пуст.
+	Recipient storage.User
+}
+
+// NoticeRenderer превращает Notice в текст сообщения.
+type NoticeRenderer interface {
+	// Render возвращает сообщение для чата команды.
+	Render(notice Notice) (string, error)
+	// RenderEmail возвращает тему и текст письма notice.Recipient.
+	RenderEmail(notice Notice) (subject, body string, err error)
+}
+
+// WithChatNotifications включает чат-уведомления: после CreatePR и ReassignReviewer,
+// если у команды автора настроен вебхук чата, в той же транзакции в repo ставится
+// сообщение, отрендеренное renderer. Отправкой занимается notify.Worker, поэтому
+// недоступный чат не откатывает назначение.
+func WithChatNotifications(repo storage.NotificationRepository, renderer NoticeRenderer) PRServiceOption {
+	return func(p *PRService) {
+		p.notifyRepo = repo
+		p.renderer = renderer
+		p.chatEnabled = true
+	}
+}
+
+// WithEmailNotifications включает письма: ревьюеры с режимом storage.EmailImmediate
+// получают письмо о назначении и замене, поставленное в repo в той же транзакции.
+func WithEmailNotifications(repo storage.NotificationRepository, renderer NoticeRenderer) PRServiceOption {
+	return func(p *PRService) {
+		p.notifyRepo = repo
+		p.renderer = renderer
+		p.emailEnabled = true
+	}
+}
+
+// notify ставит в очередь уведомления о pr: сообщение в чат команды автора и письма
+// ревьюерам из reviewerIDs. Ошибка рендеринга не мешает операции: сообщение
+// пропускается и пишется в лог.
+func (p *PRService) notify(ctx context.Context, kind NoticeKind, pr storage.PullRequest, reviewerIDs []string, oldReviewerID string) *apperrors.AppError {
+	if !p.chatEnabled && !p.emailEnabled {
+		return nil
+	}
+
+	notice, err := p.buildNotice(ctx, kind, pr, reviewerIDs, oldReviewerID)
+	if err != nil {
+		return err
+	}
+
+	var out []storage.Notification
+	if p.chatEnabled {
+		n, err := p.chatNotification(ctx, notice)
+		if err != nil {
+			return err
+		}
+		out = append(out, n...)
+	}
+	if p.emailEnabled {
+		n, err := p.emailNotifications(ctx, notice)
+		if err != nil {
+			return err
+		}
+		out = append(out, n...)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return p.notifyRepo.Enqueue(ctx, out)
+}
+
+func (p *PRService) buildNotice(ctx context.Context, kind NoticeKind, pr storage.PullRequest, reviewerIDs []string, oldReviewerID string) (Notice, *apperrors.AppError) {
+	author, err := p.userRepo.Get(ctx, pr.AuthorID)
+	if err != nil {
+		return Notice{}, err
+	}
+
+	notice := Notice{Kind: kind, PR: pr, Author: author}
+	for _, id := range reviewerIDs {
+		u, err := p.userRepo.Get(ctx, id)
+		if err != nil {
+			return Notice{}, err
+		}
+		notice.Reviewers = append(notice.Reviewers, u)
+	}
+	if oldReviewerID != "" {
+		old, err := p.userRepo.Get(ctx, oldReviewerID)
+		if err != nil {
+			return Notice{}, err
+		}
+		notice.OldReviewer = &old
+	}
+	return notice, nil
+}
+
+// chatNotification возвращает сообщение в чат команды автора, если чат настроен.
+func (p *PRService) chatNotification(ctx context.Context, notice Notice) ([]storage.Notification, *apperrors.AppError) {
+	chat, err := p.notifyRepo.GetTeamChat(ctx, notice.Author.TeamID)
+	if err != nil {
+		if err.Code == apperrors.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	text, renderErr := p.renderer.Render(notice)
+	if renderErr != nil {
+		log.Printf("render %s notification for pr %s failed: %v", notice.Kind, notice.PR.ID, renderErr)
+		return nil, nil
+	}
+	return []storage.Notification{{Channel: storage.ChannelChat, Target: chat.WebhookURL, Body: text}}, nil
+}
+
+// emailNotifications возвращает письма ревьюерам с режимом storage.EmailImmediate.
+func (p *PRService) emailNotifications(ctx context.Context, notice Notice) ([]storage.Notification, *apperrors.AppError) {
+	var out []storage.Notification
+	for _, reviewer := range notice.Reviewers {
+		pref, err := p.notifyRepo.GetEmailPreference(ctx, reviewer.ID)
+		if err != nil {
+			if err.Code == apperrors.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if pref.Mode != storage.EmailImmediate {
+			continue
+		}
+
+		notice.Recipient = reviewer
+		subject, body, renderErr := p.renderer.RenderEmail(notice)
+		if renderErr != nil {
+			log.Printf("render %s email for pr %s failed: %v", notice.Kind, notice.PR.ID, renderErr)
+			continue
+		}
+		out = append(out, storage.Notification{Channel: storage.ChannelEmail, Target: pref.Email, Subject: subject, Body: body})
+	}
+	return out, nil
+}
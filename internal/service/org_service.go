@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OrgService создаёт организации и определяет организацию запроса.
+type OrgService struct {
+	orgRepo storage.OrganizationRepository
+}
+
+// NewOrgService создаёт новый OrgService.
+func NewOrgService(orgRepo storage.OrganizationRepository) *OrgService {
+	return &OrgService{orgRepo: orgRepo}
+}
+
+// Create создаёт организацию. Управлять организациями могут только администраторы
+// организации по умолчанию.
+func (s *OrgService) Create(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	if err := canManageOrgs(ctx); err != nil {
+		return storage.Organization{}, err
+	}
+	return s.orgRepo.Create(ctx, name)
+}
+
+// List возвращает все организации.
+func (s *OrgService) List(ctx context.Context) ([]storage.Organization, *apperrors.AppError) {
+	if err := canManageOrgs(ctx); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.List(ctx)
+}
+
+// Resolve возвращает id организации по имени; неизвестная организация - NOT_FOUND.
+func (s *OrgService) Resolve(ctx context.Context, name string) (int64, *apperrors.AppError) {
+	org, err := s.orgRepo.GetByName(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return org.ID, nil
+}
+
+func canManageOrgs(ctx context.Context) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.OrgID == storage.DefaultOrgID && caller.Allows(storage.ScopeAdmin) {
+		return nil
+	}
+	return forbidden("only admins of the default organization may manage organizations")
+}
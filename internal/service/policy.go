@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Policy проверяет, может ли вызывающий из контекста выполнить операцию. Запросы без
+// вызывающего (внутренние обработчики, входящие вебхуки код-хостингов, AUTH_ENABLED=false)
+// разрешены. API-токены (RoleAutomation) ограничены своими правами, которые проверяет
+// HTTP-слой (кроме CanSetActive); правила ниже сужают права пользователей SSO.
+type Policy struct {
+	userRepo storage.UserRepository
+}
+
+// NewPolicy создаёт новый Policy.
+func NewPolicy(userRepo storage.UserRepository) *Policy {
+	return &Policy{userRepo: userRepo}
+}
+
+// CanChangeTeam - менять состав команды team могут администраторы и лиды. Лид
+// может добавлять новых пользователей и участников своей команды: пользователь из
+// другой команды при добавлении переходит в team, а это изменение той команды.
+func (p *Policy) CanChangeTeam(ctx context.Context, team storage.Team) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Role == RoleAdmin || caller.Role == RoleAutomation {
+		return nil
+	}
+	if caller.Role != RoleLead {
+		return forbidden("only team leads and admins may change team members")
+	}
+
+	leadTeamID, err := p.teamOf(ctx, caller.UserID)
+	if err != nil {
+		return err
+	}
+	for _, member := range team.Members {
+		teamID, err := p.teamOf(ctx, member.ID)
+		if err != nil {
+			return err
+		}
+		if teamID != 0 && teamID != leadTeamID {
+			return forbidden("user " + member.ID + " belongs to another team")
+		}
+	}
+	return nil
+}
+
+// CanSetActive - пользователь может менять свою активность; активность других меняют
+// лид их команды и администраторы. Маршрут открыт для всех аутентифицированных, поэтому
+// API-токену здесь нужно право team:admin.
+func (p *Policy) CanSetActive(ctx context.Context, userID string) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Role == RoleAdmin || caller.UserID == userID {
+		return nil
+	}
+	if caller.Role == RoleAutomation {
+		if caller.Allows(storage.ScopeTeamAdmin) {
+			return nil
+		}
+		return forbidden("caller lacks scope " + string(storage.ScopeTeamAdmin))
+	}
+	if caller.Role != RoleLead {
+		return forbidden("only team leads and admins may change activity of other users")
+	}
+
+	leadTeamID, err := p.teamOf(ctx, caller.UserID)
+	if err != nil {
+		return err
+	}
+	teamID, err := p.teamOf(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if teamID != 0 && teamID != leadTeamID {
+		return forbidden("user " + userID + " belongs to another team")
+	}
+	return nil
+}
+
+// CanManageTeamSettings - чат и срок ревью команды team меняют администраторы,
+// автоматизация и лид этой команды.
+func (p *Policy) CanManageTeamSettings(ctx context.Context, team storage.Team) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Role == RoleAdmin || caller.Role == RoleAutomation {
+		return nil
+	}
+	if caller.Role != RoleLead {
+		return forbidden("only team leads and admins may change team settings")
+	}
+
+	leadTeamID, err := p.teamOf(ctx, caller.UserID)
+	if err != nil {
+		return err
+	}
+	if leadTeamID != team.ID {
+		return forbidden("only the lead of team " + team.TeamName + " may change its settings")
+	}
+	return nil
+}
+
+// CanSetEmailPreference - адрес и режим писем пользователь задаёт себе сам, другим -
+// только администраторы. Маршрут открыт для всех аутентифицированных, поэтому API-токену
+// здесь нужно право team:admin.
+func (p *Policy) CanSetEmailPreference(ctx context.Context, userID string) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Role == RoleAdmin || caller.UserID == userID {
+		return nil
+	}
+	if caller.Role == RoleAutomation {
+		if caller.Allows(storage.ScopeTeamAdmin) {
+			return nil
+		}
+		return forbidden("caller lacks scope " + string(storage.ScopeTeamAdmin))
+	}
+	return forbidden("only the user and admins may change email preferences")
+}
+
+// CanMerge - мержить pr могут его автор, администраторы и автоматизация.
+func (p *Policy) CanMerge(ctx context.Context, pr storage.PullRequest) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Role == RoleAdmin || caller.Role == RoleAutomation || caller.UserID == pr.AuthorID {
+		return nil
+	}
+	return forbidden("only the author may merge the pull request")
+}
+
+// CanReview - ревью от имени reviewerID может оставить только сам reviewerID;
+// автоматизация (синхронизация с код-хостингом) действует от имени ревьюера.
+func (p *Policy) CanReview(ctx context.Context, reviewerID string) *apperrors.AppError {
+	caller, ok := CallerFrom(ctx)
+	if !ok || caller.Role == RoleAutomation || caller.UserID == reviewerID {
+		return nil
+	}
+	return forbidden("only the assigned reviewer may submit the review")
+}
+
+// teamOf возвращает команду пользователя; 0 - такого пользователя нет.
+func (p *Policy) teamOf(ctx context.Context, userID string) (int, *apperrors.AppError) {
+	user, err := p.userRepo.Get(ctx, userID)
+	if err != nil {
+		if err.Code == apperrors.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return user.TeamID, nil
+}
+
+func forbidden(message string) *apperrors.AppError {
+	return &apperrors.AppError{Code: apperrors.ErrForbidden, Message: message}
+}
@@ -0,0 +1,246 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+type services struct {
+	teams         *service.TeamService
+	users         *service.UserService
+	prs           *service.PRService
+	notifications *service.NotificationService
+	slas          *service.SLAService
+	events        *memory.PREventRepository
+	audit         *memory.AuditLogRepository
+}
+
+// newServices поднимает команды backend (lead, u1, u2) и frontend (f1) поверх памяти.
+func newServices(t *testing.T) services {
+	t.Helper()
+	store := memory.NewStore()
+	txm := memory.NewTxManager(store)
+	users := memory.NewUserRepository(store)
+	prs := memory.NewPullRequestRepository(store)
+	outbox := memory.NewOutboxRepository(store)
+	policy := service.NewPolicy(users)
+	s := services{
+		events: memory.NewPREventRepository(store),
+		audit:  memory.NewAuditLogRepository(store),
+	}
+	s.teams = service.NewTeamService(txm, memory.NewTeamRepository(store), users, s.audit, policy)
+	s.users = service.NewUserService(txm, users, memory.NewTeamRepository(store), prs, outbox, s.audit, policy)
+	s.prs = service.NewPRService(txm, users, prs, memory.NewAssignmentLogRepository(store), s.events, outbox)
+	s.notifications = service.NewNotificationService(txm, memory.NewTeamRepository(store), users, memory.NewNotificationRepository(store), s.audit, policy)
+	s.slas = service.NewSLAService(txm, memory.NewTeamRepository(store), users, prs, memory.NewReviewSLARepository(store), s.audit, policy)
+
+	ctx := context.Background()
+	_, err := s.teams.CreateTeam(ctx, storage.Team{TeamName: "backend", Members: []storage.User{
+		{ID: "lead", Username: "Lead", IsActive: true},
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+	}})
+	require.Nil(t, err)
+	_, err = s.teams.CreateTeam(ctx, storage.Team{TeamName: "frontend", Members: []storage.User{
+		{ID: "f1", Username: "Fred", IsActive: true},
+	}})
+	require.Nil(t, err)
+	return s
+}
+
+func as(userID string, roles ...string) context.Context {
+	return service.WithCaller(context.Background(), service.UserCaller(userID, roles))
+}
+
+func automation() context.Context {
+	return service.WithCaller(context.Background(), service.TokenCaller(storage.APIToken{
+		Name: "ci", Scopes: []storage.TokenScope{storage.ScopePRWrite},
+	}))
+}
+
+func requireForbidden(t *testing.T, err *apperrors.AppError) {
+	t.Helper()
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrForbidden, err.Code)
+	require.Equal(t, 403, err.HTTPStatus())
+}
+
+func TestTeamChangesRequireLeadOfAffectedTeams(t *testing.T) {
+	s := newServices(t)
+
+	_, err := s.teams.CreateTeam(as("u1"), storage.Team{TeamName: "infra", Members: []storage.User{{ID: "n1", Username: "New"}}})
+	requireForbidden(t, err)
+
+	_, err = s.teams.CreateTeam(as("lead", "lead"), storage.Team{TeamName: "infra", Members: []storage.User{
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "f1", Username: "Fred", IsActive: true},
+	}})
+	requireForbidden(t, err)
+
+	_, err = s.teams.CreateTeam(as("lead", "lead"), storage.Team{TeamName: "infra", Members: []storage.User{
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "n1", Username: "New", IsActive: true},
+	}})
+	require.Nil(t, err, "own teammates and new users")
+
+	_, err = s.teams.CreateTeam(as("admin", "admin"), storage.Team{TeamName: "web", Members: []storage.User{{ID: "f1", Username: "Fred"}}})
+	require.Nil(t, err)
+}
+
+func TestDeactivateOthersRequiresLead(t *testing.T) {
+	s := newServices(t)
+
+	_, err := s.users.SetActiveStatus(as("u1"), "u1", false)
+	require.Nil(t, err, "users may change their own activity")
+
+	_, err = s.users.SetActiveStatus(as("u1"), "u2", false)
+	requireForbidden(t, err)
+
+	_, err = s.users.SetActiveStatus(as("lead", "lead"), "f1", false)
+	requireForbidden(t, err)
+
+	_, err = s.users.SetActiveStatus(as("lead", "lead"), "u2", false)
+	require.Nil(t, err)
+
+	_, err = s.users.SetActiveStatus(automation(), "u1", true)
+	requireForbidden(t, err)
+
+	_, err = s.users.SetActiveStatus(context.Background(), "f1", false)
+	require.Nil(t, err, "internal callers are not restricted")
+}
+
+func TestTeamSettingsRequireLeadOfTeam(t *testing.T) {
+	s := newServices(t)
+	sla := storage.ReviewSLA{Within: time.Hour, EscalateAfter: 2 * time.Hour, Escalation: storage.EscalateLead, LeadID: "lead"}
+
+	_, err := s.notifications.SetTeamChat(as("u1"), "backend", "http://chat.example/hooks/backend")
+	requireForbidden(t, err)
+	_, err = s.slas.SetTeamSLA(as("u1"), "backend", sla)
+	requireForbidden(t, err)
+
+	_, err = s.notifications.SetTeamChat(as("lead", "lead"), "frontend", "http://evil.example/hooks")
+	requireForbidden(t, err)
+	requireForbidden(t, s.notifications.DeleteTeamChat(as("lead", "lead"), "frontend"))
+	_, err = s.slas.SetTeamSLA(as("lead", "lead"), "frontend", sla)
+	requireForbidden(t, err)
+	requireForbidden(t, s.slas.DeleteTeamSLA(as("lead", "lead"), "frontend"))
+
+	_, err = s.notifications.SetTeamChat(as("lead", "lead"), "backend", "http://chat.example/hooks/backend")
+	require.Nil(t, err)
+	_, err = s.slas.SetTeamSLA(as("lead", "lead"), "backend", sla)
+	require.Nil(t, err)
+	_, err = s.notifications.SetTeamChat(as("admin", "admin"), "frontend", "http://chat.example/hooks/frontend")
+	require.Nil(t, err)
+	require.Nil(t, s.slas.DeleteTeamSLA(automation(), "backend"))
+}
+
+func TestEmailPreferenceBySelfOrAdmin(t *testing.T) {
+	s := newServices(t)
+
+	_, err := s.notifications.SetEmailPreference(as("u1"), "u1", "alice@example.com", storage.EmailDigest)
+	require.Nil(t, err, "members set their own email preference")
+
+	_, err = s.notifications.SetEmailPreference(as("u1"), "u2", "alice@example.com", storage.EmailImmediate)
+	requireForbidden(t, err)
+	_, err = s.notifications.SetEmailPreference(as("lead", "lead"), "u2", "lead@example.com", storage.EmailImmediate)
+	requireForbidden(t, err)
+	_, err = s.notifications.SetEmailPreference(automation(), "u2", "bob@example.com", storage.EmailImmediate)
+	requireForbidden(t, err)
+
+	_, err = s.notifications.SetEmailPreference(as("admin", "admin"), "u2", "bob@example.com", storage.EmailImmediate)
+	require.Nil(t, err)
+}
+
+func TestMergeByAuthorAdminOrAutomation(t *testing.T) {
+	s := newServices(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		_, err := s.prs.CreatePR(ctx, id, "Change", "u1")
+		require.Nil(t, err)
+	}
+
+	_, err := s.prs.Merge(as("u2"), "pr-1")
+	requireForbidden(t, err)
+	_, err = s.prs.Merge(as("lead", "lead"), "pr-1")
+	requireForbidden(t, err)
+
+	_, err = s.prs.Merge(as("u1"), "pr-1")
+	require.Nil(t, err)
+	_, err = s.prs.Merge(as("admin", "admin"), "pr-2")
+	require.Nil(t, err)
+	_, err = s.prs.Merge(automation(), "pr-3")
+	require.Nil(t, err)
+}
+
+func TestReviewOnlyByAssignedReviewer(t *testing.T) {
+	s := newServices(t)
+	ctx := context.Background()
+
+	pr, err := s.prs.CreatePR(ctx, "pr-1", "Change", "u1")
+	require.Nil(t, err)
+	reviewer := pr.AssignedReviewers[0]
+
+	requireForbidden(t, s.prs.SubmitReview(as("u1"), "pr-1", reviewer, service.ReviewApproved))
+	requireForbidden(t, s.prs.SubmitReview(as("admin", "admin"), "pr-1", reviewer, service.ReviewApproved))
+
+	err = s.prs.SubmitReview(as("u1"), "pr-1", "u1", service.ReviewApproved)
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrNotAssigned, err.Code, "the author is not a reviewer")
+
+	require.Nil(t, s.prs.SubmitReview(as(reviewer), "pr-1", reviewer, service.ReviewChangesRequested))
+	require.Nil(t, s.prs.SubmitReview(automation(), "pr-1", reviewer, service.ReviewApproved))
+
+	events, err := s.events.GetByPR(ctx, "pr-1")
+	require.Nil(t, err)
+	var reviews []map[string]string
+	for _, e := range events {
+		if e.Type == storage.EventReviewSubmitted {
+			var payload map[string]string
+			require.NoError(t, json.Unmarshal(e.Payload, &payload))
+			payload["actor"] = e.Actor
+			reviews = append(reviews, payload)
+		}
+	}
+	require.Equal(t, []map[string]string{
+		{"reviewer_id": reviewer, "state": "changes_requested", "actor": reviewer},
+		{"reviewer_id": reviewer, "state": "approved", "actor": "token:ci"},
+	}, reviews)
+
+	_, err = s.prs.Merge(as("u1"), "pr-1")
+	require.Nil(t, err)
+	err = s.prs.SubmitReview(as(reviewer), "pr-1", reviewer, service.ReviewApproved)
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrPRMerged, err.Code)
+}
+
+func TestOrganizationsManagedByDefaultAdmins(t *testing.T) {
+	orgs := service.NewOrgService(memory.NewOrganizationRepository(memory.NewStore()))
+
+	acme, err := orgs.Create(as("admin", "admin"), "acme")
+	require.Nil(t, err)
+	id, err := orgs.Resolve(context.Background(), "acme")
+	require.Nil(t, err)
+	require.Equal(t, acme.ID, id)
+
+	_, err = orgs.Create(as("lead", "lead"), "other")
+	requireForbidden(t, err)
+	acmeAdmin := service.UserCaller("boss", []string{"admin"})
+	acmeAdmin.OrgID = acme.ID
+	_, err = orgs.List(service.WithCaller(context.Background(), acmeAdmin))
+	requireForbidden(t, err)
+
+	require.True(t, service.UserCaller("admin", []string{"admin"}).CanSwitchOrg(acme.ID))
+	require.False(t, service.UserCaller("lead", []string{"lead"}).CanSwitchOrg(acme.ID))
+	require.True(t, acmeAdmin.CanSwitchOrg(acme.ID))
+	require.False(t, acmeAdmin.CanSwitchOrg(storage.DefaultOrgID), "only admins of the default organization switch organizations")
+}
@@ -2,10 +2,9 @@ package service
 
 import (
 	"context"
-	crand "crypto/rand"
 	"fmt"
 	"log"
-	"math/big"
+	"slices"
 	"time"
 
 	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
@@ -14,28 +13,168 @@ import (
 
 // PRService управляет pr'ами.
 type PRService struct {
-	userRepo storage.UserRepository
-	prRepo   storage.PullRequestRepository
+	txm       storage.TxManager
+	userRepo  storage.UserRepository
+	prRepo    storage.PullRequestRepository
+	logRepo   storage.AssignmentLogRepository
+	eventRepo storage.PREventRepository
+	outbox    storage.OutboxRepository
+	policy    *Policy
+	rnd       Rand
+	seedPerPR bool
+
+	syncRepo      storage.ReviewerSyncRepository
+	syncProviders []string
+
+	notifyRepo   storage.NotificationRepository
+	renderer     NoticeRenderer
+	chatEnabled  bool
+	emailEnabled bool
+
+	metrics *prMetrics
+}
+
+// PRServiceOption настраивает PRService.
+type PRServiceOption func(*PRService)
+
+// WithRand задаёт источник случайности для выбора ревьюеров (по умолчанию CryptoRand).
+func WithRand(rnd Rand) PRServiceOption {
+	return func(p *PRService) { p.rnd = rnd }
+}
+
+// WithSeedPerPR включает детерминированный выбор: для каждой операции источник
+// инициализируется seed'ом из хэша id pr, поэтому результат воспроизводим.
+func WithSeedPerPR() PRServiceOption {
+	return func(p *PRService) { p.seedPerPR = true }
 }
 
 // NewPRService создаёт новый PRService.
-func NewPRService(userRepo storage.UserRepository, prRepo storage.PullRequestRepository) *PRService {
-	return &PRService{userRepo: userRepo, prRepo: prRepo}
+func NewPRService(
+	txm storage.TxManager,
+	userRepo storage.UserRepository,
+	prRepo storage.PullRequestRepository,
+	logRepo storage.AssignmentLogRepository,
+	eventRepo storage.PREventRepository,
+	outbox storage.OutboxRepository,
+	opts ...PRServiceOption,
+) *PRService {
+	p := &PRService{
+		txm:       txm,
+		userRepo:  userRepo,
+		prRepo:    prRepo,
+		logRepo:   logRepo,
+		eventRepo: eventRepo,
+		outbox:    outbox,
+		policy:    NewPolicy(userRepo),
+		rnd:       CryptoRand{},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// AssignmentPreview - результат пробного подбора ревьюеров без записи в хранилище.
+type AssignmentPreview struct {
+	Strategy   storage.AssignmentStrategy
+	Reviewers  []string
+	Candidates []string
+	Excluded   []storage.ExcludedCandidate
 }
 
 // CreatePR создаёт новый Pull Request, назначает ревьюеров и сохраняет его в репозитории.
+// PR, ревьюеры, журнал назначений, события истории, доменное событие pr.created
+// задача передачи ревьюеров на код-хостинг и уведомления записываются одной транзакцией.
 func (p *PRService) CreatePR(ctx context.Context, prID, prName, authorID string) (storage.PullRequest, *apperrors.AppError) {
-	auth, err := p.userRepo.Get(ctx, authorID)
+	var pr storage.PullRequest
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		sel, err := p.selectForAuthor(ctx, prID, authorID)
+		if err != nil {
+			return err
+		}
+
+		pr = storage.PullRequest{
+			ID:                prID,
+			Name:              prName,
+			AuthorID:          authorID,
+			Status:            storage.StatusOpen,
+			CreatedAt:         time.Now().UTC(),
+			AssignedReviewers: sel.reviewerIDs(),
+			// Новый PR хранится с версией 1 (см. PullRequestRepository.Create).
+			Version: 1,
+		}
+
+		if err := p.prRepo.Create(ctx, pr); err != nil {
+			return err
+		}
+
+		if err := p.logRepo.Add(ctx, sel.logEntries(prID, "")); err != nil {
+			return err
+		}
+
+		events, err := createEvents(ctx, pr)
+		if err != nil {
+			return err
+		}
+		if err := p.eventRepo.Add(ctx, events); err != nil {
+			return err
+		}
+		if err := p.enqueueSync(ctx, prID); err != nil {
+			return err
+		}
+		if err := p.notify(ctx, NoticeAssigned, pr, pr.AssignedReviewers, ""); err != nil {
+			return err
+		}
+		return publish(ctx, p.outbox, DomainPRCreated, newPRDomainPayload(ctx, pr))
+	})
 	if err != nil {
 		return storage.PullRequest{}, err
 	}
 
-	ts, err := p.userRepo.GetActiveTeammates(ctx, auth.TeamID, auth.ID)
+	p.record(ctx, authorID, func(m *prMetrics, org, team string) {
+		m.created.Inc(org, team)
+		m.assigned.Add(float64(len(pr.AssignedReviewers)), org, team)
+	})
+	return pr, nil
+}
+
+// PreviewPR прогоняет подбор ревьюеров для автора так же, как CreatePR,
+// но ничего не сохраняет. prID может быть пустым; он влияет на выбор только при WithSeedPerPR.
+func (p *PRService) PreviewPR(ctx context.Context, prID, authorID string) (AssignmentPreview, *apperrors.AppError) {
+	sel, err := p.selectForAuthor(ctx, prID, authorID)
 	if err != nil {
-		return storage.PullRequest{}, err
+		return AssignmentPreview{}, err
 	}
 
-	pick, pickErr := pickRev(ts, 2)
+	excluded := sel.pool.excluded
+	if excluded == nil {
+		excluded = []storage.ExcludedCandidate{}
+	}
+
+	return AssignmentPreview{
+		Strategy:   sel.strategy,
+		Reviewers:  sel.reviewerIDs(),
+		Candidates: sel.pool.candidateIDs(),
+		Excluded:   excluded,
+	}, nil
+}
+
+// selectForAuthor подбирает ревьюеров для нового pr из команды автора.
+func (p *PRService) selectForAuthor(ctx context.Context, prID, authorID string) (selection, *apperrors.AppError) {
+	auth, err := p.userRepo.Get(ctx, authorID)
+	if err != nil {
+		return selection{}, err
+	}
+
+	members, err := p.userRepo.GetByTeam(ctx, auth.TeamID)
+	if err != nil {
+		return selection{}, err
+	}
+
+	pool := buildPool(members, map[string]storage.ExclusionReason{auth.ID: storage.ExcludedAuthor})
+
+	rnd, seed := p.randFor(prID, "")
+	picks, strategy, pickErr := pickRev(rnd, pool.candidates, 2)
 	if pickErr != nil {
 		log.Println(fmt.Errorf("picking reviewers failed: %w", pickErr))
 		appErr := &apperrors.AppError{
@@ -43,52 +182,237 @@ func (p *PRService) CreatePR(ctx context.Context, prID, prName, authorID string)
 			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
 		}
 
-		return storage.PullRequest{}, appErr
+		return selection{}, appErr
 	}
 
-	revID := make([]string, 0, len(pick))
-	for _, u := range pick {
-		revID = append(revID, u.ID)
-	}
+	return selection{pool: pool, picks: picks, strategy: strategy, seed: seed}, nil
+}
 
-	pr := storage.PullRequest{
-		ID:                prID,
-		Name:              prName,
-		AuthorID:          authorID,
-		Status:            storage.StatusOpen,
-		CreatedAt:         time.Now().UTC(),
-		AssignedReviewers: revID,
-	}
+// GetPR возвращает pr с ревьюерами по id.
+func (p *PRService) GetPR(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	return p.prRepo.Get(ctx, prID)
+}
+
+// Merge - меняет флаг у pr на merged. Повторный merge ничего не меняет и не пишет событие.
+// Мержить pr могут его автор, администраторы и автоматизация (см. Policy.CanMerge).
+func (p *PRService) Merge(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	var pr storage.PullRequest
+	var merged bool
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		var err *apperrors.AppError
+		pr, err = p.prRepo.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if err := p.policy.CanMerge(ctx, pr); err != nil {
+			return err
+		}
+		if err := checkVersion(ctx, pr.Version); err != nil {
+			return err
+		}
+		if pr.Status == storage.StatusMerged {
+			return nil
+		}
+		if pr.Status == storage.StatusClosed {
+			return apperrors.New(apperrors.ErrPRClosed)
+		}
 
-	if err := p.prRepo.Create(ctx, pr); err != nil {
+		pr, err = p.prRepo.MarkMerged(ctx, prID)
+		if err != nil {
+			return err
+		}
+
+		ev, err := newEvent(ctx, prID, storage.EventMerged, mergedPayload{MergedAt: pr.MergedAt})
+		if err != nil {
+			return err
+		}
+		if err := p.eventRepo.Add(ctx, []storage.PREvent{ev}); err != nil {
+			return err
+		}
+		merged = true
+		return publish(ctx, p.outbox, DomainPRMerged, newPRDomainPayload(ctx, pr))
+	})
+	if err != nil {
 		return storage.PullRequest{}, err
 	}
-
+	if merged {
+		p.record(ctx, pr.AuthorID, func(m *prMetrics, org, team string) { m.merged.Inc(org, team) })
+	}
 	return pr, nil
 }
 
-// Merge - меняет флаг у pr на merged.
-func (p *PRService) Merge(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
-	pr, err := p.prRepo.MarkMerged(ctx, prID)
+// SubmitReview записывает в историю pr ревью reviewerID с итогом state. Ревью оставляет
+// только назначенный ревьюер (см. Policy.CanReview) и только на открытом pr.
+func (p *PRService) SubmitReview(ctx context.Context, prID, reviewerID string, state ReviewState) *apperrors.AppError {
+	return p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		pr, err := p.prRepo.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if err := p.policy.CanReview(ctx, reviewerID); err != nil {
+			return err
+		}
+		if err := checkVersion(ctx, pr.Version); err != nil {
+			return err
+		}
+		switch pr.Status {
+		case storage.StatusMerged:
+			return apperrors.New(apperrors.ErrPRMerged)
+		case storage.StatusClosed:
+			return apperrors.New(apperrors.ErrPRClosed)
+		}
+		if !slices.Contains(pr.AssignedReviewers, reviewerID) {
+			return apperrors.New(apperrors.ErrNotAssigned)
+		}
+
+		ev, err := newEvent(ctx, prID, storage.EventReviewSubmitted, reviewSubmittedPayload{ReviewerID: reviewerID, State: state})
+		if err != nil {
+			return err
+		}
+		return p.eventRepo.Add(ctx, []storage.PREvent{ev})
+	})
+}
+
+// Close закрывает pr без слияния (PR закрыт на код-хостинге). Повторное закрытие ничего
+// не меняет; смерженный pr закрыть нельзя.
+func (p *PRService) Close(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	return p.setStatus(ctx, prID, storage.StatusOpen, storage.StatusClosed, storage.EventClosed, DomainPRClosed)
+}
+
+// Reopen снова открывает закрытый pr. Для открытого pr ничего не меняет.
+func (p *PRService) Reopen(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	return p.setStatus(ctx, prID, storage.StatusClosed, storage.StatusOpen, storage.EventReopened, DomainPRReopened)
+}
+
+// setStatus переводит pr из from в to, записывая событие истории и доменное событие.
+// pr уже в статусе to не меняется; pr в другом статусе (смерженный) - ошибка PR_INVALID_STATE.
+func (p *PRService) setStatus(
+	ctx context.Context,
+	prID string,
+	from, to storage.PRStatus,
+	historyType storage.PREventType,
+	domainType string,
+) (storage.PullRequest, *apperrors.AppError) {
+	var pr storage.PullRequest
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		var err *apperrors.AppError
+		pr, err = p.prRepo.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(ctx, pr.Version); err != nil {
+			return err
+		}
+		switch pr.Status {
+		case to:
+			return nil
+		case from:
+		default:
+			return &apperrors.AppError{
+				Code:    apperrors.ErrPRInvalidState,
+				Message: fmt.Sprintf("cannot change PR status from %s to %s", pr.Status, to),
+			}
+		}
+
+		pr, err = p.prRepo.SetStatus(ctx, prID, to)
+		if err != nil {
+			return err
+		}
+
+		ev, err := newEvent(ctx, prID, historyType, struct{}{})
+		if err != nil {
+			return err
+		}
+		if err := p.eventRepo.Add(ctx, []storage.PREvent{ev}); err != nil {
+			return err
+		}
+		return publish(ctx, p.outbox, domainType, newPRDomainPayload(ctx, pr))
+	})
 	if err != nil {
-		return pr, err
+		return storage.PullRequest{}, err
 	}
 	return pr, nil
 }
 
-// ReassignReviewer - меняет ревьюера.
+// ReassignReviewer - меняет ревьюера. PR блокируется на всю транзакцию чтения, проверки
+// и записи, поэтому конкурентные переназначения и merge выполняются по очереди.
 func (p *PRService) ReassignReviewer(ctx context.Context, prID, oldReviewerID string) (storage.PullRequest, string, *apperrors.AppError) {
-	pr, err := p.prRepo.Get(ctx, prID)
+	var updatedPR storage.PullRequest
+	var newID, authorID string
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		pr, err := p.prRepo.GetForUpdate(ctx, prID)
+		if err != nil {
+			return err
+		}
+		authorID = pr.AuthorID
+		if err := checkVersion(ctx, pr.Version); err != nil {
+			return err
+		}
+
+		sel, err := p.selectReplacement(ctx, pr, oldReviewerID)
+		if err != nil {
+			return err
+		}
+		newID = sel.picks[0].user.ID
+
+		if err := p.prRepo.ReplaceReviewer(ctx, prID, oldReviewerID, newID); err != nil {
+			return err
+		}
+
+		if err := p.logRepo.Add(ctx, sel.logEntries(prID, oldReviewerID)); err != nil {
+			return err
+		}
+
+		ev, err := newEvent(ctx, prID, storage.EventReviewerReplaced,
+			reviewerReplacedPayload{OldReviewerID: oldReviewerID, NewReviewerID: newID})
+		if err != nil {
+			return err
+		}
+		if err := p.eventRepo.Add(ctx, []storage.PREvent{ev}); err != nil {
+			return err
+		}
+		if err := p.enqueueSync(ctx, prID, oldReviewerID); err != nil {
+			return err
+		}
+
+		updatedPR, err = p.prRepo.Get(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if err := p.notify(ctx, NoticeReplaced, updatedPR, []string{newID}, oldReviewerID); err != nil {
+			return err
+		}
+
+		payload := newPRDomainPayload(ctx, updatedPR)
+		payload.OldReviewerID, payload.NewReviewerID = oldReviewerID, newID
+		return publish(ctx, p.outbox, DomainPRReviewerReassigned, payload)
+	})
 	if err != nil {
+		if err.Code == apperrors.ErrNoCandidate {
+			p.record(ctx, authorID, func(m *prMetrics, org, team string) { m.noCandidate.Inc(org, team) })
+		}
 		return storage.PullRequest{}, "", err
 	}
 
+	p.record(ctx, authorID, func(m *prMetrics, org, team string) {
+		m.reassigned.Inc(org, team)
+		m.assigned.Inc(org, team)
+	})
+	return updatedPR, newID, nil
+}
+
+// selectReplacement проверяет, что oldReviewerID можно заменить на pr, и выбирает замену
+// из его команды.
+func (p *PRService) selectReplacement(ctx context.Context, pr storage.PullRequest, oldReviewerID string) (selection, *apperrors.AppError) {
 	if pr.Status == storage.StatusMerged {
 		appErr := &apperrors.AppError{
 			Code:    apperrors.ErrPRMerged,
 			Message: apperrors.FromCode(apperrors.ErrPRMerged),
 		}
-		return storage.PullRequest{}, "", appErr
+		return selection{}, appErr
+	}
+	if pr.Status == storage.StatusClosed {
+		return selection{}, apperrors.New(apperrors.ErrPRClosed)
 	}
 
 	var check bool
@@ -104,61 +428,48 @@ func (p *PRService) ReassignReviewer(ctx context.Context, prID, oldReviewerID st
 			Code:    apperrors.ErrNotAssigned,
 			Message: apperrors.FromCode(apperrors.ErrNotAssigned),
 		}
-		return storage.PullRequest{}, "", appErr
+		return selection{}, appErr
 	}
 
 	oldRev, err := p.userRepo.Get(ctx, oldReviewerID)
 	if err != nil {
-		return storage.PullRequest{}, "", err
+		return selection{}, err
 	}
 
-	cands, err := p.userRepo.GetActiveTeammates(ctx, oldRev.TeamID, oldReviewerID)
+	members, err := p.userRepo.GetByTeam(ctx, oldRev.TeamID)
 	if err != nil {
-		return storage.PullRequest{}, "", err
+		return selection{}, err
 	}
 
-	visit := make(map[string]bool, len(pr.AssignedReviewers)+1)
-	visit[pr.AuthorID] = true
+	skip := make(map[string]storage.ExclusionReason, len(pr.AssignedReviewers)+1)
 	for _, val := range pr.AssignedReviewers {
-		visit[val] = true
+		skip[val] = storage.ExcludedAlreadyAssigned
 	}
+	skip[pr.AuthorID] = storage.ExcludedAuthor
+	skip[oldReviewerID] = storage.ExcludedReplaced
 
-	var filteredCands []storage.User
-	for _, c := range cands {
-		if !visit[c.ID] {
-			filteredCands = append(filteredCands, c)
-		}
-	}
+	pool := buildPool(members, skip)
 
-	if len(filteredCands) == 0 {
+	if len(pool.candidates) == 0 {
 		appErr := &apperrors.AppError{
 			Code:    apperrors.ErrNoCandidate,
 			Message: apperrors.FromCode(apperrors.ErrNoCandidate),
 		}
-		return storage.PullRequest{}, "", appErr
+		return selection{}, appErr
 	}
 
-	idx, pickErr := randInt(len(filteredCands))
+	rnd, seed := p.randFor(pr.ID, oldReviewerID)
+	picks, strategy, pickErr := pickRev(rnd, pool.candidates, 1)
 	if pickErr != nil {
 		log.Println(fmt.Errorf("rand pick failed: %w", pickErr))
 		appErr := &apperrors.AppError{
 			Code:    apperrors.ErrInternalIssue,
 			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
 		}
-		return storage.PullRequest{}, "", appErr
+		return selection{}, appErr
 	}
-	newCandidate := filteredCands[idx]
 
-	if err := p.prRepo.ReplaceReviewer(ctx, prID, oldReviewerID, newCandidate.ID); err != nil {
-		return storage.PullRequest{}, "", err
-	}
-
-	updatedPR, err := p.prRepo.Get(ctx, prID)
-	if err != nil {
-		return storage.PullRequest{}, "", err
-	}
-
-	return updatedPR, newCandidate.ID, nil
+	return selection{pool: pool, picks: picks, strategy: strategy, seed: seed}, nil
 }
 
 // GetAssignmentStats возвращает статистику назначений по пользователям и pr.
@@ -178,41 +489,60 @@ func (p *PRService) GetAssignmentStats(ctx context.Context) (
 	return
 }
 
-// pickRev выбирает случайных reviewer'ов из списка users.
-func pickRev(users []storage.User, amount int) ([]storage.User, error) {
-	if amount <= 0 || len(users) == 0 {
-		return []storage.User{}, nil
-	}
+// GetAssignmentLog возвращает журнал назначений ревьюеров по pr.
+func (p *PRService) GetAssignmentLog(ctx context.Context, prID string) ([]storage.AssignmentLog, *apperrors.AppError) {
+	var entries []storage.AssignmentLog
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		exists, err := p.prRepo.Exists(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return &apperrors.AppError{
+				Code:    apperrors.ErrNotFound,
+				Message: apperrors.FromCode(apperrors.ErrNotFound),
+			}
+		}
 
-	if len(users) <= amount {
-		return users, nil
+		entries, err = p.logRepo.GetByPR(ctx, prID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return entries, nil
+}
 
-	pUsers := make([]storage.User, 0, amount)
-	used := make(map[int]bool, amount)
-	for len(pUsers) < amount {
-		i, err := randInt(len(users))
+// GetHistory возвращает историю событий pr в хронологическом порядке.
+func (p *PRService) GetHistory(ctx context.Context, prID string) ([]storage.PREvent, *apperrors.AppError) {
+	var events []storage.PREvent
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		exists, err := p.prRepo.Exists(ctx, prID)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if _, u := used[i]; u {
-			continue
+		if !exists {
+			return &apperrors.AppError{
+				Code:    apperrors.ErrNotFound,
+				Message: apperrors.FromCode(apperrors.ErrNotFound),
+			}
 		}
-		used[i] = true
-		pUsers = append(pUsers, users[i])
+
+		events, err = p.eventRepo.GetByPR(ctx, prID)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	return pUsers, nil
+	return events, nil
 }
 
-// RandInt возвращает случайное число в диапазоне [0, n).
-func randInt(n int) (int, error) {
-	if n <= 0 {
-		return 0, fmt.Errorf("invalid upper bound: %d", n)
-	}
-	bn := big.NewInt(int64(n))
-	x, err := crand.Int(crand.Reader, bn)
-	if err != nil {
-		return 0, fmt.Errorf("getting rand failed: %w", err)
+// randFor возвращает источник случайности для операции над pr. При WithSeedPerPR
+// источник детерминирован по (prID, key) и seed возвращается для журнала.
+func (p *PRService) randFor(prID, key string) (Rand, *int64) {
+	if !p.seedPerPR {
+		return p.rnd, nil
 	}
-	return int(x.Int64()), nil
+	seed := seedFor(prID, key)
+	return NewSeededRand(seed), &seed
 }
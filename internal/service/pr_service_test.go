@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+func TestCloseAndReopenMergedPR(t *testing.T) {
+	store := memory.NewStore()
+	txm := memory.NewTxManager(store)
+	users := memory.NewUserRepository(store)
+	teams := service.NewTeamService(txm, memory.NewTeamRepository(store), users, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+	prs := service.NewPRService(txm, users, memory.NewPullRequestRepository(store), memory.NewAssignmentLogRepository(store),
+		memory.NewPREventRepository(store), memory.NewOutboxRepository(store))
+
+	ctx := context.Background()
+	_, err := teams.CreateTeam(ctx, storage.Team{TeamName: "backend", Members: []storage.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+	}})
+	require.Nil(t, err)
+	_, err = prs.CreatePR(ctx, "pr-1", "Change", "u1")
+	require.Nil(t, err)
+
+	pr, err := prs.Close(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusClosed, pr.Status)
+	pr, err = prs.Reopen(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusOpen, pr.Status)
+
+	_, err = prs.Merge(ctx, "pr-1")
+	require.Nil(t, err)
+
+	_, err = prs.Close(ctx, "pr-1")
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrPRInvalidState, err.Code)
+	require.Equal(t, "cannot change PR status from MERGED to CLOSED", err.Message)
+
+	_, err = prs.Reopen(ctx, "pr-1")
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrPRInvalidState, err.Code)
+}
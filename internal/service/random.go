@@ -0,0 +1,63 @@
+package service
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"math/rand/v2"
+	"sync"
+)
+
+// Rand - источник случайных чисел для выбора ревьюеров.
+type Rand interface {
+	// Intn возвращает случайное число в диапазоне [0, n).
+	Intn(n int) (int, error)
+}
+
+// CryptoRand - источник на основе crypto/rand. Используется по умолчанию.
+type CryptoRand struct{}
+
+// Intn возвращает случайное число в диапазоне [0, n).
+func (CryptoRand) Intn(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid upper bound: %d", n)
+	}
+	bn := big.NewInt(int64(n))
+	x, err := crand.Int(crand.Reader, bn)
+	if err != nil {
+		return 0, fmt.Errorf("getting rand failed: %w", err)
+	}
+	return int(x.Int64()), nil
+}
+
+// SeededRand - детерминированный PRNG, безопасный для конкурентного использования.
+type SeededRand struct {
+	r  *rand.Rand
+	mu sync.Mutex
+}
+
+// NewSeededRand создаёт SeededRand с заданным seed.
+func NewSeededRand(seed int64) *SeededRand {
+	s := uint64(seed)
+	return &SeededRand{r: rand.New(rand.NewPCG(s, s))}
+}
+
+// Intn возвращает случайное число в диапазоне [0, n).
+func (s *SeededRand) Intn(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid upper bound: %d", n)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.IntN(n), nil
+}
+
+// seedFor вычисляет seed из id pr и ключа операции (FNV-1a).
+func seedFor(prID, key string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(prID))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,26 @@
+package service
+
+import "context"
+
+// RequestInfo - HTTP-запрос, в котором выполняется операция; записывается в журнал аудита.
+type RequestInfo struct {
+	// ID - X-Request-ID запроса.
+	ID string
+	// SourceIP - адрес клиента.
+	SourceIP string
+}
+
+// requestKey - ключ контекста со сведениями о запросе.
+type requestKey struct{}
+
+// WithRequest возвращает контекст со сведениями о HTTP-запросе.
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, info)
+}
+
+// RequestFrom возвращает сведения о запросе из ctx; пустые вне HTTP-запроса
+// (фоновые обработчики).
+func RequestFrom(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestKey{}).(RequestInfo)
+	return info
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// RemindOverdue записывает в историю PR просрочку ревью и ставит напоминания
+// (NoticeReminder) в чат команды и ревьюерам с письмами в режиме immediate: одно
+// уведомление на PR со всеми его просроченными ревьюерами. Вызывается в транзакции,
+// в которой ревью отмечены (storage.ReviewSLARepository.ClaimReminders); каждый PR
+// обрабатывается от имени его организации.
+func (p *PRService) RemindOverdue(ctx context.Context, overdue []storage.OverdueReview) *apperrors.AppError {
+	return p.txm.Do(ctx, func(txCtx context.Context) *apperrors.AppError {
+		for start := 0; start < len(overdue); {
+			orgID, prID := overdue[start].OrgID, overdue[start].PullRequestID
+			ctx := storage.WithOrg(txCtx, orgID)
+			end := start
+			var reviewerIDs []string
+			var events []storage.PREvent
+			for ; end < len(overdue) && overdue[end].OrgID == orgID && overdue[end].PullRequestID == prID; end++ {
+				o := overdue[end]
+				ev, err := newEvent(ctx, prID, storage.EventReviewOverdue,
+					reviewOverduePayload{ReviewerID: o.ReviewerID, DueAt: o.AssignedAt.Add(o.SLA.Within)})
+				if err != nil {
+					return err
+				}
+				events = append(events, ev)
+				reviewerIDs = append(reviewerIDs, o.ReviewerID)
+			}
+			start = end
+
+			pr, err := p.prRepo.Get(ctx, prID)
+			if err != nil {
+				return err
+			}
+			if err := p.eventRepo.Add(ctx, events); err != nil {
+				return err
+			}
+			if err := p.notify(ctx, NoticeReminder, pr, reviewerIDs, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// EscalateOverdue эскалирует ревью, просроченное сверх EscalateAfter: при
+// storage.EscalateReassign ревьюер заменяется так же, как в ReassignReviewer, при
+// storage.EscalateLead на PR дополнительно назначается лид команды. Если эскалация
+// невозможна (некого назначить, лид - автор или уже ревьюер), она пропускается с записью в лог.
+// Эскалация выполняется от имени организации ревью.
+func (p *PRService) EscalateOverdue(ctx context.Context, o storage.OverdueReview) *apperrors.AppError {
+	ctx = storage.WithOrg(ctx, o.OrgID)
+	switch o.SLA.Escalation {
+	case storage.EscalateReassign:
+		_, newID, err := p.ReassignReviewer(ctx, o.PullRequestID, o.ReviewerID)
+		if err != nil {
+			if err.Code == apperrors.ErrInternalIssue {
+				return err
+			}
+			log.Printf("escalation of pr %s: cannot replace reviewer %s: %v", o.PullRequestID, o.ReviewerID, err)
+			return nil
+		}
+		log.Printf("escalation of pr %s: reviewer %s replaced by %s", o.PullRequestID, o.ReviewerID, newID)
+		return nil
+	case storage.EscalateLead:
+		return p.addLead(ctx, o)
+	default:
+		return nil
+	}
+}
+
+func (p *PRService) addLead(ctx context.Context, o storage.OverdueReview) *apperrors.AppError {
+	if o.SLA.LeadID == "" {
+		log.Printf("escalation of pr %s: team %d has no lead", o.PullRequestID, o.SLA.TeamID)
+		return nil
+	}
+
+	var authorID string
+	err := p.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		pr, err := p.prRepo.GetForUpdate(ctx, o.PullRequestID)
+		if err != nil {
+			return err
+		}
+		if pr.Status != storage.StatusOpen || pr.AuthorID == o.SLA.LeadID || slices.Contains(pr.AssignedReviewers, o.SLA.LeadID) {
+			log.Printf("escalation of pr %s: lead %s is the author or already a reviewer", o.PullRequestID, o.SLA.LeadID)
+			return nil
+		}
+
+		if err := p.prRepo.AddReviewer(ctx, pr.ID, o.SLA.LeadID); err != nil {
+			return err
+		}
+		ev, err := newEvent(ctx, pr.ID, storage.EventReviewerAssigned, reviewerAssignedPayload{ReviewerID: o.SLA.LeadID})
+		if err != nil {
+			return err
+		}
+		if err := p.eventRepo.Add(ctx, []storage.PREvent{ev}); err != nil {
+			return err
+		}
+		if err := p.enqueueSync(ctx, pr.ID); err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers = append(pr.AssignedReviewers, o.SLA.LeadID)
+		if err := p.notify(ctx, NoticeAssigned, pr, []string{o.SLA.LeadID}, ""); err != nil {
+			return err
+		}
+		log.Printf("escalation of pr %s: lead %s added as a reviewer", pr.ID, o.SLA.LeadID)
+		authorID = pr.AuthorID
+
+		payload := newPRDomainPayload(ctx, pr)
+		payload.NewReviewerID = o.SLA.LeadID
+		return publish(ctx, p.outbox, DomainPRReviewerAdded, payload)
+	})
+	if err != nil {
+		return err
+	}
+
+	// authorID пуст, если лид не добавлен.
+	if authorID != "" {
+		p.record(ctx, authorID, func(m *prMetrics, org, team string) { m.assigned.Inc(org, team) })
+	}
+	return nil
+}
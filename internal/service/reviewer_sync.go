@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// CodeHostClient - клиент REST API код-хостинга, который выставляет ревьюеров на реальном PR.
+type CodeHostClient interface {
+	// Provider - имя провайдера (storage.ProviderGitHub, storage.ProviderGitLab).
+	Provider() string
+	// RequestReviewers запрашивает ревью у reviewers и снимает запрос с removed.
+	// prID - идентификатор PR в сервисе ("github:owner/repo#42"), логины - логины провайдера.
+	RequestReviewers(ctx context.Context, prID string, reviewers, removed []string) error
+}
+
+// WithReviewerSync включает передачу ревьюеров на код-хостинг: после CreatePR и
+// ReassignReviewer для PR провайдера из providers в той же транзакции ставится задача
+// в repo, которую затем асинхронно выполняет codehost.Syncer.
+func WithReviewerSync(repo storage.ReviewerSyncRepository, providers ...string) PRServiceOption {
+	return func(p *PRService) {
+		p.syncRepo = repo
+		p.syncProviders = providers
+	}
+}
+
+// ProviderOf возвращает провайдера из идентификатора PR вида "<provider>:<id>".
+func ProviderOf(prID string) (string, bool) {
+	provider, rest, ok := strings.Cut(prID, ":")
+	if !ok || provider == "" || rest == "" {
+		return "", false
+	}
+	return provider, true
+}
+
+// enqueueSync ставит передачу ревьюеров pr на код-хостинг, если она включена для его провайдера.
+func (p *PRService) enqueueSync(ctx context.Context, prID string, removed ...string) *apperrors.AppError {
+	if p.syncRepo == nil {
+		return nil
+	}
+	provider, ok := ProviderOf(prID)
+	if !ok || !slices.Contains(p.syncProviders, provider) {
+		return nil
+	}
+	return p.syncRepo.Enqueue(ctx, storage.ReviewerSync{Provider: provider, PullRequestID: prID, Removed: removed})
+}
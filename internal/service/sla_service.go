@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewSLAState - состояние ревью относительно SLA.
+type ReviewSLAState string
+
+// Состояния ревью.
+const (
+	// SLAOnTrack - срок ещё не истёк.
+	SLAOnTrack ReviewSLAState = "ON_TRACK"
+	// SLAOverdue - срок истёк, PR всё ещё открыт.
+	SLAOverdue ReviewSLAState = "OVERDUE"
+	// SLAEscalated - ревью эскалировано.
+	SLAEscalated ReviewSLAState = "ESCALATED"
+	// SLAFinished - PR смержен или закрыт, срок больше не отслеживается.
+	SLAFinished ReviewSLAState = "FINISHED"
+)
+
+// ReviewerSLA - SLA одного назначенного ревьюера.
+type ReviewerSLA struct {
+	DueAt time.Time
+	State ReviewSLAState
+	storage.ReviewState
+}
+
+// PRSLA - SLA ревью PR. SLA равен nil, если у команды автора нет срока ревью;
+// тогда DueAt и State у ревьюеров пустые.
+type PRSLA struct {
+	SLA       *storage.ReviewSLA
+	TeamName  string
+	PR        storage.PullRequest
+	Reviewers []ReviewerSLA
+}
+
+// SLAService управляет сроками ревью команд и показывает SLA PR.
+type SLAService struct {
+	txm       storage.TxManager
+	teamRepo  storage.TeamRepository
+	userRepo  storage.UserRepository
+	prRepo    storage.PullRequestRepository
+	slaRepo   storage.ReviewSLARepository
+	auditRepo storage.AuditLogRepository
+	policy    *Policy
+	now       func() time.Time
+}
+
+// NewSLAService создаёт новый SLAService.
+func NewSLAService(
+	txm storage.TxManager,
+	teamRepo storage.TeamRepository,
+	userRepo storage.UserRepository,
+	prRepo storage.PullRequestRepository,
+	slaRepo storage.ReviewSLARepository,
+	auditRepo storage.AuditLogRepository,
+	policy *Policy,
+) *SLAService {
+	return &SLAService{
+		txm:       txm,
+		teamRepo:  teamRepo,
+		userRepo:  userRepo,
+		prRepo:    prRepo,
+		slaRepo:   slaRepo,
+		auditRepo: auditRepo,
+		policy:    policy,
+		now:       time.Now,
+	}
+}
+
+// SetTeamSLA задаёт срок ревью команды teamName; TeamID в sla игнорируется.
+// Лид для storage.EscalateLead должен существовать. Изменение записывается в журнал аудита,
+// версия команды растёт (см. touchTeam). Право проверяет Policy.CanManageTeamSettings.
+func (s *SLAService) SetTeamSLA(ctx context.Context, teamName string, sla storage.ReviewSLA) (storage.ReviewSLA, *apperrors.AppError) {
+	var saved storage.ReviewSLA
+	err := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		team, err := s.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := s.policy.CanManageTeamSettings(ctx, team); err != nil {
+			return err
+		}
+		if err := touchTeam(ctx, s.teamRepo, team.ID); err != nil {
+			return err
+		}
+		if sla.LeadID != "" {
+			if _, err := s.userRepo.Get(ctx, sla.LeadID); err != nil {
+				return err
+			}
+		}
+		before, err := s.currentSLA(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+
+		sla.TeamID = team.ID
+		if err := s.slaRepo.SetTeamSLA(ctx, sla); err != nil {
+			return err
+		}
+		saved, err = s.slaRepo.GetTeamSLA(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditTeamSLASet, AuditTargetTeam, team.TeamName, before, newAuditSLA(saved))
+	})
+	if err != nil {
+		return storage.ReviewSLA{}, err
+	}
+	return saved, nil
+}
+
+// GetTeamSLA возвращает срок ревью команды и версию команды, которая служит ETag настройки
+// (POST и DELETE принимают её в If-Match).
+func (s *SLAService) GetTeamSLA(ctx context.Context, teamName string) (storage.ReviewSLA, int64, *apperrors.AppError) {
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if err != nil {
+		return storage.ReviewSLA{}, 0, err
+	}
+	sla, err := s.slaRepo.GetTeamSLA(ctx, team.ID)
+	if err != nil {
+		return storage.ReviewSLA{}, 0, err
+	}
+	return sla, team.Version, nil
+}
+
+// DeleteTeamSLA снимает срок ревью с команды и записывает это в журнал аудита; версия
+// команды растёт (см. touchTeam). Право проверяет Policy.CanManageTeamSettings.
+func (s *SLAService) DeleteTeamSLA(ctx context.Context, teamName string) *apperrors.AppError {
+	return s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		team, err := s.teamRepo.GetByName(ctx, teamName)
+		if err != nil {
+			return err
+		}
+		if err := s.policy.CanManageTeamSettings(ctx, team); err != nil {
+			return err
+		}
+		if err := touchTeam(ctx, s.teamRepo, team.ID); err != nil {
+			return err
+		}
+		before, err := s.slaRepo.GetTeamSLA(ctx, team.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.slaRepo.DeleteTeamSLA(ctx, team.ID); err != nil {
+			return err
+		}
+		return audit(ctx, s.auditRepo, storage.AuditTeamSLADelete, AuditTargetTeam, team.TeamName, newAuditSLA(before), nil)
+	})
+}
+
+// currentSLA возвращает снимок срока ревью команды для журнала аудита; nil - срок не задан.
+func (s *SLAService) currentSLA(ctx context.Context, teamID int) (any, *apperrors.AppError) {
+	sla, err := s.slaRepo.GetTeamSLA(ctx, teamID)
+	switch {
+	case err == nil:
+		return newAuditSLA(sla), nil
+	case err.Code == apperrors.ErrNotFound:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// GetPRSLA возвращает SLA ревью PR по SLA команды автора.
+func (s *SLAService) GetPRSLA(ctx context.Context, prID string) (PRSLA, *apperrors.AppError) {
+	pr, err := s.prRepo.Get(ctx, prID)
+	if err != nil {
+		return PRSLA{}, err
+	}
+	author, err := s.userRepo.Get(ctx, pr.AuthorID)
+	if err != nil {
+		return PRSLA{}, err
+	}
+	reviews, err := s.slaRepo.GetReviews(ctx, prID)
+	if err != nil {
+		return PRSLA{}, err
+	}
+
+	team, err := s.teamRepo.GetByID(ctx, author.TeamID)
+	if err != nil {
+		return PRSLA{}, err
+	}
+
+	res := PRSLA{PR: pr, TeamName: team.TeamName, Reviewers: make([]ReviewerSLA, 0, len(reviews))}
+	sla, err := s.slaRepo.GetTeamSLA(ctx, author.TeamID)
+	switch {
+	case err == nil:
+		res.SLA = &sla
+	case err.Code != apperrors.ErrNotFound:
+		return PRSLA{}, err
+	}
+
+	now := s.now()
+	for _, rs := range reviews {
+		r := ReviewerSLA{ReviewState: rs}
+		if res.SLA != nil {
+			r.DueAt = rs.AssignedAt.Add(res.SLA.Within)
+			r.State = reviewState(pr, r, now)
+		}
+		res.Reviewers = append(res.Reviewers, r)
+	}
+	return res, nil
+}
+
+func reviewState(pr storage.PullRequest, r ReviewerSLA, now time.Time) ReviewSLAState {
+	switch {
+	case pr.Status != storage.StatusOpen:
+		return SLAFinished
+	case r.EscalatedAt != nil:
+		return SLAEscalated
+	case !r.DueAt.After(now):
+		return SLAOverdue
+	default:
+		return SLAOnTrack
+	}
+}
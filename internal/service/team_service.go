@@ -10,17 +10,61 @@ import (
 
 // TeamService - сервис для управления командами.
 type TeamService struct {
-	teamRepo storage.TeamRepository
+	txm       storage.TxManager
+	teamRepo  storage.TeamRepository
+	userRepo  storage.UserRepository
+	auditRepo storage.AuditLogRepository
+	policy    *Policy
 }
 
 // NewTeamService возвращает новый TeamService.
-func NewTeamService(teamRepo storage.TeamRepository) *TeamService {
-	return &TeamService{teamRepo: teamRepo}
+func NewTeamService(
+	txm storage.TxManager,
+	teamRepo storage.TeamRepository,
+	userRepo storage.UserRepository,
+	auditRepo storage.AuditLogRepository,
+	policy *Policy,
+) *TeamService {
+	return &TeamService{txm: txm, teamRepo: teamRepo, userRepo: userRepo, auditRepo: auditRepo, policy: policy}
 }
 
-// CreateTeam создаёт новую команду.
-func (t *TeamService) CreateTeam(ctx context.Context, team storage.Team) *apperrors.AppError {
-	return t.teamRepo.Create(ctx, team)
+// CreateTeam создаёт новую команду и возвращает её в сохранённом виде. Участники из
+// других команд переходят в новую, поэтому права проверяются и для их команд
+// (см. Policy.CanChangeTeam). В журнал аудита записываются прежние данные уже
+// существовавших участников и созданная команда. Версии команд, из которых ушли участники,
+// растут.
+func (t *TeamService) CreateTeam(ctx context.Context, team storage.Team) (storage.Team, *apperrors.AppError) {
+	var created storage.Team
+	err := t.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if err := t.policy.CanChangeTeam(ctx, team); err != nil {
+			return err
+		}
+		existing, err := t.existingMembers(ctx, team.Members)
+		if err != nil {
+			return err
+		}
+		if err := t.teamRepo.Create(ctx, team); err != nil {
+			return err
+		}
+		if err := t.touchTeams(ctx, existing); err != nil {
+			return err
+		}
+
+		created, err = t.teamRepo.GetByName(ctx, team.TeamName)
+		if err != nil {
+			return err
+		}
+
+		var before any
+		if len(existing) > 0 {
+			before = newAuditUsers(existing)
+		}
+		return audit(ctx, t.auditRepo, storage.AuditTeamCreate, AuditTargetTeam, created.TeamName, before, newAuditTeam(created))
+	})
+	if err != nil {
+		return storage.Team{}, err
+	}
+	return created, nil
 }
 
 // GetTeamByName возвращает команду по имени.
@@ -32,3 +76,34 @@ func (t *TeamService) GetTeamByName(ctx context.Context, teamName string) (stora
 func (t *TeamService) GetTeamByID(ctx context.Context, teamID int) (storage.Team, *apperrors.AppError) {
 	return t.teamRepo.GetByID(ctx, teamID)
 }
+
+// existingMembers возвращает уже существующих пользователей из members в их текущем виде.
+func (t *TeamService) existingMembers(ctx context.Context, members []storage.User) ([]storage.User, *apperrors.AppError) {
+	var existing []storage.User
+	for _, m := range members {
+		user, err := t.userRepo.Get(ctx, m.ID)
+		if err != nil {
+			if err.Code == apperrors.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		existing = append(existing, user)
+	}
+	return existing, nil
+}
+
+// touchTeams увеличивает версии прежних команд участников members.
+func (t *TeamService) touchTeams(ctx context.Context, members []storage.User) *apperrors.AppError {
+	touched := make(map[int]bool, len(members))
+	for _, m := range members {
+		if touched[m.TeamID] {
+			continue
+		}
+		touched[m.TeamID] = true
+		if _, err := t.teamRepo.Touch(ctx, m.TeamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"log"
+	"slices"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// tokenPrefix отличает API-токены сервиса от других секретов (например, в сканерах утечек).
+const tokenPrefix = "apr_"
+
+// TokenService выпускает, проверяет и отзывает API-токены.
+type TokenService struct {
+	tokenRepo storage.APITokenRepository
+}
+
+// NewTokenService создаёт новый TokenService.
+func NewTokenService(tokenRepo storage.APITokenRepository) *TokenService {
+	return &TokenService{tokenRepo: tokenRepo}
+}
+
+// Issue выпускает токен и возвращает его вместе с секретом. Секрет не хранится
+// и показывается только здесь.
+func (s *TokenService) Issue(ctx context.Context, name string, scopes []storage.TokenScope) (storage.APIToken, string, *apperrors.AppError) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("generate api token failed: %v", err)
+		return storage.APIToken{}, "", apperrors.New(apperrors.ErrInternalIssue)
+	}
+	secret := tokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	token, err := s.tokenRepo.Create(ctx, storage.APIToken{Name: name, Hash: hashToken(secret), Scopes: scopes})
+	if err != nil {
+		return storage.APIToken{}, "", err
+	}
+	return token, secret, nil
+}
+
+// Bootstrap регистрирует заданный администратором секрет как токен name со ScopeAdmin,
+// если такого токена ещё нет. Отозванный токен не восстанавливается.
+func (s *TokenService) Bootstrap(ctx context.Context, name, secret string) *apperrors.AppError {
+	hash := hashToken(secret)
+	token, err := s.tokenRepo.GetByHash(ctx, hash)
+	switch {
+	case err == nil:
+		if token.RevokedAt != nil {
+			log.Printf("bootstrap api token %q is revoked", token.Name)
+		}
+		return nil
+	case err.Code != apperrors.ErrNotFound:
+		return err
+	}
+
+	_, err = s.tokenRepo.Create(ctx, storage.APIToken{Name: name, Hash: hash, Scopes: []storage.TokenScope{storage.ScopeAdmin}})
+	return err
+}
+
+// Authenticate возвращает действующий токен по секрету. Неизвестный и отозванный
+// токены дают NOT_FOUND.
+func (s *TokenService) Authenticate(ctx context.Context, secret string) (storage.APIToken, *apperrors.AppError) {
+	token, err := s.tokenRepo.GetByHash(ctx, hashToken(secret))
+	if err != nil {
+		return storage.APIToken{}, err
+	}
+	if token.RevokedAt != nil {
+		return storage.APIToken{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return token, nil
+}
+
+// List возвращает все токены.
+func (s *TokenService) List(ctx context.Context) ([]storage.APIToken, *apperrors.AppError) {
+	return s.tokenRepo.List(ctx)
+}
+
+// Revoke отзывает токен.
+func (s *TokenService) Revoke(ctx context.Context, tokenID int64) *apperrors.AppError {
+	return s.tokenRepo.Revoke(ctx, tokenID)
+}
+
+// IsTokenScope сообщает, существует ли право scope.
+func IsTokenScope(scope string) bool {
+	return slices.Contains(storage.TokenScopes, storage.TokenScope(scope))
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -9,34 +9,97 @@ import (
 
 // UserService - сервис для управления пользователями.
 type UserService struct {
-	userRepo storage.UserRepository
-	prRepo   storage.PullRequestRepository
+	txm       storage.TxManager
+	userRepo  storage.UserRepository
+	teamRepo  storage.TeamRepository
+	prRepo    storage.PullRequestRepository
+	outbox    storage.OutboxRepository
+	auditRepo storage.AuditLogRepository
+	policy    *Policy
 }
 
 // NewUserService возвращает новый UserService.
-func NewUserService(userRepo storage.UserRepository, prRepo storage.PullRequestRepository) *UserService {
-	return &UserService{userRepo: userRepo, prRepo: prRepo}
+func NewUserService(
+	txm storage.TxManager,
+	userRepo storage.UserRepository,
+	teamRepo storage.TeamRepository,
+	prRepo storage.PullRequestRepository,
+	outbox storage.OutboxRepository,
+	auditRepo storage.AuditLogRepository,
+	policy *Policy,
+) *UserService {
+	return &UserService{txm: txm, userRepo: userRepo, teamRepo: teamRepo, prRepo: prRepo, outbox: outbox, auditRepo: auditRepo, policy: policy}
 }
 
-// SetActiveStatus устанавливает флаг активности у пользователя.
+// SetActiveStatus устанавливает флаг активности у пользователя и публикует
+// user.activity_changed и запись журнала аудита в той же транзакции. Активность других пользователей меняют
+// только лид их команды и администраторы (см. Policy.CanSetActive). Версии пользователя и его
+// команды растут; при If-Match (см. WithIfMatch) версия пользователя до изменения должна совпасть.
 func (u *UserService) SetActiveStatus(ctx context.Context, userID string, isActive bool) (storage.User, *apperrors.AppError) {
-	return u.userRepo.SetActive(ctx, userID, isActive)
+	var user storage.User
+	err := u.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if err := u.policy.CanSetActive(ctx, userID); err != nil {
+			return err
+		}
+
+		before, err := u.userRepo.Get(ctx, userID)
+		if err != nil {
+			return err
+		}
+		user, err = u.userRepo.SetActive(ctx, userID, isActive)
+		if err != nil {
+			return err
+		}
+		// Версия сверяется после UPDATE: строка уже заблокирована, и конкурентное изменение
+		// между чтением before и записью тоже даст конфликт.
+		if err := checkVersion(ctx, user.Version-1); err != nil {
+			return err
+		}
+		if _, err := u.teamRepo.Touch(ctx, user.TeamID); err != nil {
+			return err
+		}
+
+		err = audit(ctx, u.auditRepo, storage.AuditUserSetActive, AuditTargetUser, user.ID, newAuditUser(before), newAuditUser(user))
+		if err != nil {
+			return err
+		}
+		return publish(ctx, u.outbox, DomainUserActivityChanged, userDomainPayload{
+			UserID:   user.ID,
+			Username: user.Username,
+			TeamID:   user.TeamID,
+			IsActive: user.IsActive,
+			Actor:    ActorFrom(ctx),
+		})
+	})
+	if err != nil {
+		return storage.User{}, err
+	}
+	return user, nil
+}
+
+// GetUser возвращает пользователя по id.
+func (u *UserService) GetUser(ctx context.Context, userID string) (storage.User, *apperrors.AppError) {
+	return u.userRepo.Get(ctx, userID)
 }
 
 // GetUserReviews возвращает pr'ы, где пользователь ревьюер.
 func (u *UserService) GetUserReviews(ctx context.Context, userID string) ([]storage.PullRequest, *apperrors.AppError) {
-	exists, err := u.userRepo.Exists(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
-	if !exists {
-		return nil, &apperrors.AppError{
-			Code:    apperrors.ErrNotFound,
-			Message: apperrors.FromCode(apperrors.ErrNotFound),
+	var prs []storage.PullRequest
+	err := u.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		exists, err := u.userRepo.Exists(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return &apperrors.AppError{
+				Code:    apperrors.ErrNotFound,
+				Message: apperrors.FromCode(apperrors.ErrNotFound),
+			}
 		}
-	}
 
-	prs, err := u.prRepo.GetByReviewer(ctx, userID)
+		prs, err = u.prRepo.GetByReviewer(ctx, userID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ifMatchKey - ключ контекста с ожидаемой версией изменяемого ресурса.
+type ifMatchKey struct{}
+
+// WithIfMatch возвращает контекст, в котором изменяющая операция выполняется, только если
+// версия ресурса равна version (заголовок If-Match); иначе - VERSION_CONFLICT.
+func WithIfMatch(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, ifMatchKey{}, version)
+}
+
+// checkVersion сравнивает версию ресурса current с ожидаемой из ctx. Без WithIfMatch
+// проверки нет.
+func checkVersion(ctx context.Context, current int64) *apperrors.AppError {
+	expected, ok := ctx.Value(ifMatchKey{}).(int64)
+	if !ok || expected == current {
+		return nil
+	}
+	return apperrors.New(apperrors.ErrVersionConflict)
+}
+
+// touchTeam увеличивает версию команды teamID и сверяет прежнюю версию с If-Match из ctx.
+// Вызывается внутри транзакции до изменения настроек команды: UPDATE блокирует строку
+// команды, поэтому конкурентные изменения выполняются по очереди.
+func touchTeam(ctx context.Context, teamRepo storage.TeamRepository, teamID int) *apperrors.AppError {
+	version, err := teamRepo.Touch(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	return checkVersion(ctx, version-1)
+}
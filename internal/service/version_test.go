@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+func requireVersionConflict(t *testing.T, err *apperrors.AppError) {
+	t.Helper()
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrVersionConflict, err.Code)
+	require.Equal(t, 412, err.HTTPStatus())
+}
+
+func TestIfMatchOnPullRequest(t *testing.T) {
+	s := newServices(t)
+	ctx := context.Background()
+	_, err := s.teams.CreateTeam(ctx, storage.Team{TeamName: "infra", Members: []storage.User{
+		{ID: "i1", Username: "Ivan", IsActive: true},
+		{ID: "i2", Username: "Irina", IsActive: true},
+		{ID: "i3", Username: "Igor", IsActive: true},
+		{ID: "i4", Username: "Inna", IsActive: true},
+	}})
+	require.Nil(t, err)
+
+	pr, err := s.prs.CreatePR(ctx, "pr-1", "Change", "i1")
+	require.Nil(t, err)
+	require.EqualValues(t, 1, pr.Version)
+
+	_, _, err = s.prs.ReassignReviewer(service.WithIfMatch(ctx, 2), "pr-1", pr.AssignedReviewers[0])
+	requireVersionConflict(t, err)
+
+	pr, _, err = s.prs.ReassignReviewer(service.WithIfMatch(ctx, 1), "pr-1", pr.AssignedReviewers[0])
+	require.Nil(t, err)
+	require.EqualValues(t, 2, pr.Version)
+
+	_, err = s.prs.Merge(service.WithIfMatch(ctx, 1), "pr-1")
+	requireVersionConflict(t, err)
+	pr, err = s.prs.GetPR(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusOpen, pr.Status, "a stale If-Match changes nothing")
+
+	pr, err = s.prs.Merge(service.WithIfMatch(ctx, 2), "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusMerged, pr.Status)
+	require.EqualValues(t, 3, pr.Version)
+
+	pr, err = s.prs.Merge(ctx, "pr-1")
+	require.Nil(t, err, "without If-Match the version is not checked")
+	require.EqualValues(t, 3, pr.Version, "a repeated merge is not a write")
+}
+
+func TestIfMatchOnUserBumpsTeam(t *testing.T) {
+	s := newServices(t)
+	ctx := context.Background()
+
+	_, err := s.users.SetActiveStatus(service.WithIfMatch(ctx, 2), "u1", false)
+	requireVersionConflict(t, err)
+	user, err := s.users.GetUser(ctx, "u1")
+	require.Nil(t, err)
+	require.True(t, user.IsActive)
+
+	user, err = s.users.SetActiveStatus(service.WithIfMatch(ctx, 1), "u1", false)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, user.Version)
+
+	team, err := s.teams.GetTeamByName(ctx, "backend")
+	require.Nil(t, err)
+	require.EqualValues(t, 2, team.Version, "member activity is part of the team")
+}
+
+func TestCreateTeamBumpsPreviousTeams(t *testing.T) {
+	s := newServices(t)
+	ctx := context.Background()
+
+	_, err := s.teams.CreateTeam(ctx, storage.Team{TeamName: "infra", Members: []storage.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+	}})
+	require.Nil(t, err)
+
+	backend, err := s.teams.GetTeamByName(ctx, "backend")
+	require.Nil(t, err)
+	require.EqualValues(t, 2, backend.Version, "two members left, the team is bumped once")
+	frontend, err := s.teams.GetTeamByName(ctx, "frontend")
+	require.Nil(t, err)
+	require.EqualValues(t, 1, frontend.Version)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// secretBytes - длина генерируемого секрета подписи в байтах.
+const secretBytes = 32
+
+// WebhookService управляет подписчиками на доменные события.
+type WebhookService struct {
+	webhookRepo storage.WebhookRepository
+	outbox      storage.OutboxRepository
+}
+
+// NewWebhookService создаёт новый WebhookService.
+func NewWebhookService(webhookRepo storage.WebhookRepository, outbox storage.OutboxRepository) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo, outbox: outbox}
+}
+
+// Register регистрирует подписчика. Пустой eventTypes - подписка на все события;
+// пустой secret заменяется случайным, его нужно сохранить из ответа.
+func (s *WebhookService) Register(ctx context.Context, url, secret string, eventTypes []string) (storage.Webhook, *apperrors.AppError) {
+	if secret == "" {
+		buf := make([]byte, secretBytes)
+		if _, err := rand.Read(buf); err != nil {
+			log.Printf("generate webhook secret failed: %v", err)
+			return storage.Webhook{}, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	return s.webhookRepo.Create(ctx, storage.Webhook{URL: url, Secret: secret, EventTypes: eventTypes})
+}
+
+// List возвращает всех подписчиков.
+func (s *WebhookService) List(ctx context.Context) ([]storage.Webhook, *apperrors.AppError) {
+	return s.webhookRepo.List(ctx)
+}
+
+// ListDeliveries возвращает последние доставки с фильтром по подписчику и статусу.
+func (s *WebhookService) ListDeliveries(ctx context.Context, webhookID int64, status storage.DeliveryStatus, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	return s.outbox.ListDeliveries(ctx, webhookID, status, limit)
+}
+
+// IsDomainEventType сообщает, можно ли подписаться на событие typ.
+func IsDomainEventType(typ string) bool {
+	for _, t := range DomainEventTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+// Package sla следит за сроками ревью: напоминает ревьюерам, которые не уложились
+// в SLA команды, и эскалирует ревью, просроченные сверх срока эскалации.
+package sla
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Config - параметры проверки сроков.
+type Config struct {
+	// PollInterval - период проверки.
+	PollInterval time.Duration
+	// BatchSize - сколько ревью обрабатывается в одной транзакции.
+	BatchSize int
+}
+
+// Scheduler периодически находит просроченные ревью открытых PR. Отметка о напоминании
+// или эскалации и её последствия (уведомления, замена ревьюера) записываются одной
+// транзакцией, поэтому при нескольких экземплярах каждое ревью обрабатывается один раз.
+type Scheduler struct {
+	txm  storage.TxManager
+	repo storage.ReviewSLARepository
+	prs  *service.PRService
+	now  func() time.Time
+	cfg  Config
+}
+
+// NewScheduler создаёт новый Scheduler.
+func NewScheduler(txm storage.TxManager, repo storage.ReviewSLARepository, prs *service.PRService, cfg Config) *Scheduler {
+	return &Scheduler{txm: txm, repo: repo, prs: prs, now: time.Now, cfg: cfg}
+}
+
+// Run проверяет сроки до отмены ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for s.RemindOnce(ctx) == s.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+		for s.EscalateOnce(ctx) == s.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RemindOnce отправляет напоминания по одной пачке просроченных ревью и возвращает её размер.
+func (s *Scheduler) RemindOnce(ctx context.Context) int {
+	now := s.now()
+	var claimed int
+	appErr := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		overdue, err := s.repo.ClaimReminders(ctx, now, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		claimed = len(overdue)
+		return s.prs.RemindOverdue(ctx, overdue)
+	})
+	if appErr != nil {
+		log.Printf("review sla reminders failed: %v", appErr)
+		return 0
+	}
+	return claimed
+}
+
+// EscalateOnce эскалирует одну пачку ревью и возвращает её размер.
+func (s *Scheduler) EscalateOnce(ctx context.Context) int {
+	now := s.now()
+	var claimed int
+	appErr := s.txm.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		overdue, err := s.repo.ClaimEscalations(ctx, now, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		claimed = len(overdue)
+		for _, o := range overdue {
+			if err := s.prs.EscalateOverdue(ctx, o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if appErr != nil {
+		log.Printf("review sla escalations failed: %v", appErr)
+		return 0
+	}
+	return claimed
+}
@@ -0,0 +1,192 @@
+package sla
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/notify"
+	"github.com/VechkanovVV/assigner-pr/internal/service"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+type fixture struct {
+	scheduler *Scheduler
+	prService *service.PRService
+	slas      *service.SLAService
+	notify    *memory.NotificationRepository
+	prs       *memory.PullRequestRepository
+	clock     time.Time
+}
+
+// newFixture поднимает команду backend (u1-u4) с чатом и команду leads (boss)
+// поверх памяти и задаёт backend срок ревью sla.
+func newFixture(t *testing.T, sla storage.ReviewSLA) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	store := memory.NewStore()
+	teams := memory.NewTeamRepository(store)
+	users := memory.NewUserRepository(store)
+	f := &fixture{
+		notify: memory.NewNotificationRepository(store),
+		prs:    memory.NewPullRequestRepository(store),
+		clock:  time.Now(),
+	}
+
+	require.Nil(t, teams.Create(ctx, storage.Team{
+		TeamName: "backend",
+		Members: []storage.User{
+			{ID: "u1", Username: "Alice", IsActive: true},
+			{ID: "u2", Username: "Bob", IsActive: true},
+			{ID: "u3", Username: "Carol", IsActive: true},
+			{ID: "u4", Username: "Dave", IsActive: true},
+		},
+	}))
+	require.Nil(t, teams.Create(ctx, storage.Team{
+		TeamName: "leads",
+		Members:  []storage.User{{ID: "boss", Username: "Boss", IsActive: true}},
+	}))
+	_, err := service.NewNotificationService(memory.NewTxManager(store), teams, users, f.notify, memory.NewAuditLogRepository(store), service.NewPolicy(users)).SetTeamChat(ctx, "backend", "http://127.0.0.1:9/hooks/backend")
+	require.Nil(t, err)
+
+	txm := memory.NewTxManager(store)
+	slaRepo := memory.NewReviewSLARepository(store)
+	f.prService = service.NewPRService(
+		txm, users, f.prs, memory.NewAssignmentLogRepository(store), memory.NewPREventRepository(store),
+		memory.NewOutboxRepository(store), service.WithChatNotifications(f.notify, notify.DefaultTemplates()),
+	)
+	f.slas = service.NewSLAService(txm, teams, users, f.prs, slaRepo, memory.NewAuditLogRepository(store), service.NewPolicy(users))
+	_, err = f.slas.SetTeamSLA(ctx, "backend", sla)
+	require.Nil(t, err)
+
+	f.scheduler = NewScheduler(txm, slaRepo, f.prService, Config{PollInterval: time.Minute, BatchSize: 10})
+	f.scheduler.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *fixture) reminders(t *testing.T) []string {
+	t.Helper()
+	notifications, err := f.notify.List(context.Background(), "", 100)
+	require.Nil(t, err)
+	var texts []string
+	for _, n := range notifications {
+		if strings.Contains(n.Body, "всё ещё ждёт ревью") {
+			texts = append(texts, n.Body)
+		}
+	}
+	return texts
+}
+
+func (f *fixture) reviewers(t *testing.T, prID string) []string {
+	t.Helper()
+	pr, err := f.prs.Get(context.Background(), prID)
+	require.Nil(t, err)
+	return pr.AssignedReviewers
+}
+
+func TestReminderIsSentOnce(t *testing.T) {
+	f := newFixture(t, storage.ReviewSLA{Within: time.Hour, Escalation: storage.EscalateNone})
+	ctx := context.Background()
+
+	_, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+
+	f.clock = time.Now().Add(30 * time.Minute)
+	require.Equal(t, 0, f.scheduler.RemindOnce(ctx), "not overdue yet")
+
+	f.clock = time.Now().Add(2 * time.Hour)
+	require.Equal(t, 2, f.scheduler.RemindOnce(ctx))
+	require.Len(t, f.reminders(t), 1, "one reminder per pull request")
+	require.Equal(t, 0, f.scheduler.RemindOnce(ctx), "already reminded")
+	require.Equal(t, 0, f.scheduler.EscalateOnce(ctx), "escalation is disabled")
+}
+
+func TestPRSLAState(t *testing.T) {
+	f := newFixture(t, storage.ReviewSLA{Within: time.Hour, Escalation: storage.EscalateNone})
+	ctx := context.Background()
+
+	pr, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+
+	prSLA, err := f.slas.GetPRSLA(ctx, "pr-1")
+	require.Nil(t, err)
+	require.NotNil(t, prSLA.SLA)
+	require.Equal(t, "backend", prSLA.TeamName)
+	require.Len(t, prSLA.Reviewers, len(pr.AssignedReviewers))
+	for _, r := range prSLA.Reviewers {
+		require.Equal(t, service.SLAOnTrack, r.State)
+		require.True(t, r.DueAt.Equal(r.AssignedAt.Add(time.Hour)))
+	}
+
+	_, err = f.prService.Merge(ctx, "pr-1")
+	require.Nil(t, err)
+	f.clock = time.Now().Add(2 * time.Hour)
+	require.Equal(t, 0, f.scheduler.RemindOnce(ctx), "merged pull requests are not tracked")
+
+	prSLA, err = f.slas.GetPRSLA(ctx, "pr-1")
+	require.Nil(t, err)
+	for _, r := range prSLA.Reviewers {
+		require.Equal(t, service.SLAFinished, r.State)
+	}
+}
+
+func TestReassignEscalation(t *testing.T) {
+	f := newFixture(t, storage.ReviewSLA{Within: time.Hour, Escalation: storage.EscalateReassign, EscalateAfter: 4 * time.Hour})
+	ctx := context.Background()
+
+	pr, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	f.clock = time.Now().Add(3 * time.Hour)
+	require.Equal(t, 0, f.scheduler.EscalateOnce(ctx), "not past escalate_after yet")
+
+	f.clock = time.Now().Add(5 * time.Hour)
+	require.Equal(t, 2, f.scheduler.RemindOnce(ctx))
+	require.Equal(t, 2, f.scheduler.EscalateOnce(ctx))
+
+	reviewers := f.reviewers(t, "pr-1")
+	require.Len(t, reviewers, 2)
+	require.NotEqual(t, pr.AssignedReviewers, reviewers)
+
+	// Отсчёт для новых ревьюеров начинается заново.
+	f.clock = time.Now().Add(2 * time.Hour)
+	require.Equal(t, 0, f.scheduler.EscalateOnce(ctx))
+	require.Equal(t, 2, f.scheduler.RemindOnce(ctx))
+}
+
+func TestLeadEscalation(t *testing.T) {
+	f := newFixture(t, storage.ReviewSLA{
+		Within: time.Hour, Escalation: storage.EscalateLead, EscalateAfter: 4 * time.Hour, LeadID: "boss",
+	})
+	ctx := context.Background()
+
+	pr, err := f.prService.CreatePR(ctx, "pr-1", "Add search", "u1")
+	require.Nil(t, err)
+
+	f.clock = time.Now().Add(5 * time.Hour)
+	require.Equal(t, 2, f.scheduler.EscalateOnce(ctx))
+
+	reviewers := f.reviewers(t, "pr-1")
+	require.Len(t, reviewers, 3, "the lead is added once")
+	require.True(t, slices.Contains(reviewers, "boss"))
+	for _, id := range pr.AssignedReviewers {
+		require.Contains(t, reviewers, id)
+	}
+
+	prSLA, err := f.slas.GetPRSLA(ctx, "pr-1")
+	require.Nil(t, err)
+	for _, r := range prSLA.Reviewers {
+		if r.ReviewerID == "boss" {
+			require.Equal(t, service.SLAOnTrack, r.State)
+		} else {
+			require.Equal(t, service.SLAEscalated, r.State)
+		}
+	}
+}
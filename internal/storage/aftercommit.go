@@ -0,0 +1,43 @@
+package storage
+
+import "context"
+
+// TxHooks - функции, отложенные до фиксации внешней транзакции (см. AfterCommit).
+// TxManager создаёт их для каждой попытки внешней транзакции и вызывает Run после
+// успешной фиксации; при откате они отбрасываются.
+type TxHooks struct {
+	fns []func()
+}
+
+// txHooksKey - ключ контекста с TxHooks внешней транзакции.
+type txHooksKey struct{}
+
+// WithTxHooks возвращает контекст внешней транзакции с новыми TxHooks. Если ctx уже
+// внутри транзакции, возвращает его без изменений и nil: функции достанутся внешней.
+func WithTxHooks(ctx context.Context) (context.Context, *TxHooks) {
+	if _, ok := ctx.Value(txHooksKey{}).(*TxHooks); ok {
+		return ctx, nil
+	}
+	hooks := &TxHooks{}
+	return context.WithValue(ctx, txHooksKey{}, hooks), hooks
+}
+
+// Run вызывает отложенные функции в порядке регистрации; для nil ничего не делает.
+func (h *TxHooks) Run() {
+	if h == nil {
+		return
+	}
+	for _, fn := range h.fns {
+		fn()
+	}
+}
+
+// AfterCommit откладывает fn до фиксации внешней транзакции ctx; вне транзакции fn
+// вызывается сразу. Если транзакция откатится, fn не вызывается.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(txHooksKey{}).(*TxHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
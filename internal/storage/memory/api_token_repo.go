@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// APITokenRepository - API-токены в памяти.
+type APITokenRepository struct {
+	store *Store
+}
+
+// NewAPITokenRepository создаёт экземпляр *APITokenRepository.
+func NewAPITokenRepository(store *Store) *APITokenRepository {
+	return &APITokenRepository{store: store}
+}
+
+// Create сохраняет токен организации из контекста и возвращает его с присвоенным id.
+func (a *APITokenRepository) Create(ctx context.Context, token storage.APIToken) (storage.APIToken, *apperrors.AppError) {
+	defer a.store.write(ctx)()
+
+	for _, t := range a.store.apiTokens {
+		if t.Hash == token.Hash {
+			return storage.APIToken{}, apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+	token.ID = int64(len(a.store.apiTokens)) + 1
+	token.OrgID = storage.OrgFrom(ctx)
+	token.CreatedAt = time.Now().UTC()
+	token.RevokedAt = nil
+	token.Scopes = append([]storage.TokenScope{}, token.Scopes...)
+	a.store.apiTokens = append(a.store.apiTokens, token)
+	return copyAPIToken(token), nil
+}
+
+// GetByHash возвращает токен по хешу секрета в любой организации: по токену
+// организация запроса и определяется.
+func (a *APITokenRepository) GetByHash(ctx context.Context, hash string) (storage.APIToken, *apperrors.AppError) {
+	defer a.store.read(ctx)()
+
+	for _, t := range a.store.apiTokens {
+		if t.Hash == hash {
+			return copyAPIToken(t), nil
+		}
+	}
+	return storage.APIToken{}, apperrors.New(apperrors.ErrNotFound)
+}
+
+// List возвращает токены организации в порядке выпуска.
+func (a *APITokenRepository) List(ctx context.Context) ([]storage.APIToken, *apperrors.AppError) {
+	defer a.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	tokens := make([]storage.APIToken, 0)
+	for _, t := range a.store.apiTokens {
+		if t.OrgID == org {
+			tokens = append(tokens, copyAPIToken(t))
+		}
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен.
+func (a *APITokenRepository) Revoke(ctx context.Context, tokenID int64) *apperrors.AppError {
+	defer a.store.write(ctx)()
+
+	for i, t := range a.store.apiTokens {
+		if t.ID != tokenID || t.OrgID != storage.OrgFrom(ctx) {
+			continue
+		}
+		if t.RevokedAt == nil {
+			now := time.Now().UTC()
+			t.RevokedAt = &now
+			tokens := append([]storage.APIToken(nil), a.store.apiTokens...)
+			tokens[i] = t
+			a.store.apiTokens = tokens
+		}
+		return nil
+	}
+	return apperrors.New(apperrors.ErrNotFound)
+}
+
+func copyAPIToken(t storage.APIToken) storage.APIToken {
+	t.Scopes = append([]storage.TokenScope{}, t.Scopes...)
+	if t.RevokedAt != nil {
+		at := *t.RevokedAt
+		t.RevokedAt = &at
+	}
+	return t
+}
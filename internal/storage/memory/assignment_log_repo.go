@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// AssignmentLogRepository - журнал назначений ревьюеров в памяти.
+type AssignmentLogRepository struct {
+	store *Store
+}
+
+// NewAssignmentLogRepository создаёт экземпляр *AssignmentLogRepository.
+func NewAssignmentLogRepository(store *Store) *AssignmentLogRepository {
+	return &AssignmentLogRepository{store: store}
+}
+
+// Add сохраняет записи о назначениях.
+func (a *AssignmentLogRepository) Add(ctx context.Context, entries []storage.AssignmentLog) *apperrors.AppError {
+	defer a.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	for _, e := range entries {
+		if _, ok := a.store.prs[orgKey{org: org, id: e.PullRequestID}]; !ok {
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+		if !a.store.userIn(org, e.ReviewerID) {
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, e := range entries {
+		a.store.nextLogID++
+		e.ID = a.store.nextLogID
+		e.CreatedAt = now
+		e.Candidates = append([]string{}, e.Candidates...)
+		e.Excluded = append([]storage.ExcludedCandidate{}, e.Excluded...)
+		a.store.logs = append(a.store.logs, owned[storage.AssignmentLog]{value: e, org: org})
+	}
+	return nil
+}
+
+// GetByPR возвращает журнал назначений по pr в хронологическом порядке.
+func (a *AssignmentLogRepository) GetByPR(ctx context.Context, prID string) ([]storage.AssignmentLog, *apperrors.AppError) {
+	defer a.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	entries := make([]storage.AssignmentLog, 0)
+	for _, e := range a.store.logs {
+		if e.org == org && e.value.PullRequestID == prID {
+			entries = append(entries, e.value)
+		}
+	}
+	return entries, nil
+}
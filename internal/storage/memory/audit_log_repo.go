@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// AuditLogRepository - журнал аудита в памяти.
+type AuditLogRepository struct {
+	store *Store
+}
+
+// NewAuditLogRepository создаёт экземпляр *AuditLogRepository.
+func NewAuditLogRepository(store *Store) *AuditLogRepository {
+	return &AuditLogRepository{store: store}
+}
+
+// Add дописывает запись в журнал организации из контекста.
+func (a *AuditLogRepository) Add(ctx context.Context, entry storage.AuditEntry) *apperrors.AppError {
+	defer a.store.write(ctx)()
+
+	a.store.nextAuditID++
+	entry.ID = a.store.nextAuditID
+	entry.CreatedAt = time.Now().UTC()
+	entry.Before = append([]byte(nil), entry.Before...)
+	entry.After = append([]byte(nil), entry.After...)
+	if len(entry.Before) == 0 {
+		entry.Before = nil
+	}
+	if len(entry.After) == 0 {
+		entry.After = nil
+	}
+	a.store.audit = append(a.store.audit, owned[storage.AuditEntry]{value: entry, org: storage.OrgFrom(ctx)})
+	return nil
+}
+
+// List возвращает записи организации по фильтру, новые первыми.
+func (a *AuditLogRepository) List(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, *apperrors.AppError) {
+	defer a.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	entries := make([]storage.AuditEntry, 0)
+	for i := len(a.store.audit) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		e := a.store.audit[i]
+		if e.org == org && auditMatches(e.value, filter) {
+			entries = append(entries, e.value)
+		}
+	}
+	return entries, nil
+}
+
+// DeleteBefore удаляет записи всех организаций старше cutoff.
+func (a *AuditLogRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, *apperrors.AppError) {
+	defer a.store.write(ctx)()
+
+	kept := make([]owned[storage.AuditEntry], 0, len(a.store.audit))
+	for _, e := range a.store.audit {
+		if !e.value.CreatedAt.Before(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	deleted := int64(len(a.store.audit) - len(kept))
+	a.store.audit = kept
+	return deleted, nil
+}
+
+func auditMatches(e storage.AuditEntry, f storage.AuditFilter) bool {
+	switch {
+	case f.BeforeID > 0 && e.ID >= f.BeforeID:
+		return false
+	case !f.Since.IsZero() && e.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.CreatedAt.Before(f.Until):
+		return false
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	case f.Action != "" && e.Action != f.Action:
+		return false
+	case f.TargetType != "" && e.TargetType != f.TargetType:
+		return false
+	case f.TargetID != "" && e.TargetID != f.TargetID:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// idempotencyKey - ключ идемпотентности клиента организации.
+type idempotencyKey struct {
+	client string
+	key    string
+	org    int64
+}
+
+// IdempotencyRepository - ключи идемпотентности в памяти.
+type IdempotencyRepository struct {
+	store *Store
+}
+
+// NewIdempotencyRepository создаёт экземпляр *IdempotencyRepository.
+func NewIdempotencyRepository(store *Store) *IdempotencyRepository {
+	return &IdempotencyRepository{store: store}
+}
+
+// Claim сохраняет незавершённую запись ключа, если у него нет действующей записи.
+func (i *IdempotencyRepository) Claim(ctx context.Context, rec storage.IdempotencyRecord, now time.Time) (storage.IdempotencyRecord, bool, *apperrors.AppError) {
+	defer i.store.write(ctx)()
+
+	k := idempotencyKey{client: rec.Client, key: rec.Key, org: storage.OrgFrom(ctx)}
+	if existing, ok := i.store.idempotency[k]; ok && existing.ExpiresAt.After(now) {
+		existing.Headers = maps.Clone(existing.Headers)
+		existing.Response = append([]byte(nil), existing.Response...)
+		return existing, false, nil
+	}
+
+	rec.CreatedAt = time.Now().UTC()
+	rec.Status = 0
+	rec.Headers = nil
+	rec.Response = nil
+	i.store.idempotency[k] = rec
+	return rec, true, nil
+}
+
+// Complete сохраняет ответ на запрос с ключом.
+func (i *IdempotencyRepository) Complete(ctx context.Context, client, key string, status int, headers map[string]string, response []byte) *apperrors.AppError {
+	defer i.store.write(ctx)()
+
+	k := idempotencyKey{client: client, key: key, org: storage.OrgFrom(ctx)}
+	rec, ok := i.store.idempotency[k]
+	if !ok {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	rec.Status = status
+	rec.Headers = maps.Clone(headers)
+	rec.Response = append([]byte(nil), response...)
+	i.store.idempotency[k] = rec
+	return nil
+}
+
+// Release удаляет незавершённую запись ключа.
+func (i *IdempotencyRepository) Release(ctx context.Context, client, key string) *apperrors.AppError {
+	defer i.store.write(ctx)()
+
+	k := idempotencyKey{client: client, key: key, org: storage.OrgFrom(ctx)}
+	if rec, ok := i.store.idempotency[k]; ok && rec.Status == 0 {
+		delete(i.store.idempotency, k)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет просроченные записи всех организаций.
+func (i *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, *apperrors.AppError) {
+	defer i.store.write(ctx)()
+
+	var deleted int64
+	for k, rec := range i.store.idempotency {
+		if !rec.ExpiresAt.After(now) {
+			delete(i.store.idempotency, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// integrationKey - ключ логина, доставки или секрета (id пуст) в рамках провайдера и организации.
+type integrationKey struct {
+	provider string
+	id       string
+	org      int64
+}
+
+// IntegrationRepository - данные интеграций с код-хостингами в памяти.
+type IntegrationRepository struct {
+	store *Store
+}
+
+// NewIntegrationRepository создаёт экземпляр *IntegrationRepository.
+func NewIntegrationRepository(store *Store) *IntegrationRepository {
+	return &IntegrationRepository{store: store}
+}
+
+// LinkIdentity связывает логин провайдера с пользователем; существующая связь перезаписывается.
+func (i *IntegrationRepository) LinkIdentity(ctx context.Context, provider, login, userID string) *apperrors.AppError {
+	defer i.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	if !i.store.userIn(org, userID) {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	i.store.identities[integrationKey{org: org, provider: provider, id: login}] = userID
+	return nil
+}
+
+// ResolveIdentity возвращает user_id, связанный с логином провайдера.
+func (i *IntegrationRepository) ResolveIdentity(ctx context.Context, provider, login string) (string, *apperrors.AppError) {
+	defer i.store.read(ctx)()
+
+	userID, ok := i.store.identities[integrationKey{org: storage.OrgFrom(ctx), provider: provider, id: login}]
+	if !ok {
+		return "", apperrors.New(apperrors.ErrNotFound)
+	}
+	return userID, nil
+}
+
+// IdentityLogin возвращает логин провайдера, связанный с пользователем.
+func (i *IntegrationRepository) IdentityLogin(ctx context.Context, provider, userID string) (string, *apperrors.AppError) {
+	defer i.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	login := ""
+	for key, id := range i.store.identities {
+		if key.org == org && key.provider == provider && id == userID && (login == "" || key.id < login) {
+			login = key.id
+		}
+	}
+	if login == "" {
+		return "", apperrors.New(apperrors.ErrNotFound)
+	}
+	return login, nil
+}
+
+// RecordDelivery запоминает входящую доставку и возвращает false, если она уже была обработана.
+func (i *IntegrationRepository) RecordDelivery(ctx context.Context, provider, deliveryID string) (bool, *apperrors.AppError) {
+	defer i.store.write(ctx)()
+
+	key := integrationKey{org: storage.OrgFrom(ctx), provider: provider, id: deliveryID}
+	if _, ok := i.store.inbound[key]; ok {
+		return false, nil
+	}
+	i.store.inbound[key] = struct{}{}
+	return true, nil
+}
+
+// SetInboundSecret создаёт или заменяет секрет провайдера организации.
+func (i *IntegrationRepository) SetInboundSecret(ctx context.Context, provider, secret string) *apperrors.AppError {
+	defer i.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	i.store.inboundSecrets[integrationKey{org: org, provider: provider}] = storage.InboundSecret{
+		OrgID: org, Provider: provider, Secret: secret, CreatedAt: time.Now().UTC(),
+	}
+	return nil
+}
+
+// GetInboundSecret возвращает секрет провайдера организации.
+func (i *IntegrationRepository) GetInboundSecret(ctx context.Context, provider string) (storage.InboundSecret, *apperrors.AppError) {
+	defer i.store.read(ctx)()
+
+	s, ok := i.store.inboundSecrets[integrationKey{org: storage.OrgFrom(ctx), provider: provider}]
+	if !ok {
+		return storage.InboundSecret{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return s, nil
+}
+
+// DeleteInboundSecret удаляет секрет провайдера организации.
+func (i *IntegrationRepository) DeleteInboundSecret(ctx context.Context, provider string) *apperrors.AppError {
+	defer i.store.write(ctx)()
+
+	key := integrationKey{org: storage.OrgFrom(ctx), provider: provider}
+	if _, ok := i.store.inboundSecrets[key]; !ok {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	delete(i.store.inboundSecrets, key)
+	return nil
+}
+
+// InboundSecrets возвращает секреты провайдера всех организаций.
+func (i *IntegrationRepository) InboundSecrets(ctx context.Context, provider string) ([]storage.InboundSecret, *apperrors.AppError) {
+	defer i.store.read(ctx)()
+
+	secrets := make([]storage.InboundSecret, 0)
+	for key, s := range i.store.inboundSecrets {
+		if key.provider == provider {
+			secrets = append(secrets, s)
+		}
+	}
+	slices.SortFunc(secrets, func(a, b storage.InboundSecret) int { return cmp.Compare(a.OrgID, b.OrgID) })
+	return secrets, nil
+}
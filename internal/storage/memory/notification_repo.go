@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// NotificationRepository - настройки чат-уведомлений и очередь уведомлений в памяти.
+type NotificationRepository struct {
+	store *Store
+}
+
+// NewNotificationRepository создаёт экземпляр *NotificationRepository.
+func NewNotificationRepository(store *Store) *NotificationRepository {
+	return &NotificationRepository{store: store}
+}
+
+// SetTeamChat задаёт вебхук команды; существующая настройка перезаписывается. Команда
+// другой организации - NOT_FOUND.
+func (n *NotificationRepository) SetTeamChat(ctx context.Context, chat storage.TeamChat) *apperrors.AppError {
+	defer n.store.write(ctx)()
+
+	if !n.store.teamIn(storage.OrgFrom(ctx), chat.TeamID) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	chat.UpdatedAt = time.Now().UTC()
+	n.store.teamChats[chat.TeamID] = chat
+	return nil
+}
+
+// GetTeamChat возвращает вебхук команды.
+func (n *NotificationRepository) GetTeamChat(ctx context.Context, teamID int) (storage.TeamChat, *apperrors.AppError) {
+	defer n.store.read(ctx)()
+
+	chat, ok := n.store.teamChats[teamID]
+	if !ok || !n.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return storage.TeamChat{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return chat, nil
+}
+
+// DeleteTeamChat отключает чат-уведомления команды.
+func (n *NotificationRepository) DeleteTeamChat(ctx context.Context, teamID int) *apperrors.AppError {
+	defer n.store.write(ctx)()
+
+	if _, ok := n.store.teamChats[teamID]; !ok || !n.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	delete(n.store.teamChats, teamID)
+	return nil
+}
+
+// Enqueue ставит уведомления в очередь; они готовы к отправке сразу.
+func (n *NotificationRepository) Enqueue(ctx context.Context, notifications []storage.Notification) *apperrors.AppError {
+	defer n.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	now := time.Now().UTC()
+	for _, nt := range notifications {
+		n.store.notifications = append(n.store.notifications, owned[storage.Notification]{org: org, value: storage.Notification{
+			ID:            int64(len(n.store.notifications)) + 1,
+			Channel:       nt.Channel,
+			Target:        nt.Target,
+			Subject:       nt.Subject,
+			Body:          nt.Body,
+			Status:        storage.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}})
+	}
+	return nil
+}
+
+// ClaimDue выдаёт до limit уведомлений всех организаций, которым пора отправляться, и откладывает их на lease.
+func (n *NotificationRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.Notification, *apperrors.AppError) {
+	defer n.store.write(ctx)()
+
+	due := make([]int, 0)
+	for i, rec := range n.store.notifications {
+		if rec.value.Status == storage.DeliveryPending && !rec.value.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return n.store.notifications[due[a]].value.NextAttemptAt.Before(n.store.notifications[due[b]].value.NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	sort.Ints(due)
+
+	claimed := make([]storage.Notification, 0, len(due))
+	for _, i := range due {
+		nt := &n.store.notifications[i].value
+		nt.Attempts++
+		nt.NextAttemptAt = now.Add(lease).UTC()
+		nt.UpdatedAt = time.Now().UTC()
+		claimed = append(claimed, *nt)
+	}
+	return claimed, nil
+}
+
+// MarkDone помечает уведомление как отправленное.
+func (n *NotificationRepository) MarkDone(ctx context.Context, notificationID int64) *apperrors.AppError {
+	return n.update(ctx, notificationID, func(nt *storage.Notification) {
+		nt.Status = storage.DeliveryDelivered
+		nt.LastError = ""
+	})
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит уведомление в DEAD.
+func (n *NotificationRepository) MarkFailed(ctx context.Context, notificationID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	return n.update(ctx, notificationID, func(nt *storage.Notification) {
+		nt.Status = storage.DeliveryPending
+		if dead {
+			nt.Status = storage.DeliveryDead
+		}
+		nt.NextAttemptAt = nextAttemptAt.UTC()
+		nt.LastError = lastErr
+	})
+}
+
+func (n *NotificationRepository) update(ctx context.Context, notificationID int64, fn func(nt *storage.Notification)) *apperrors.AppError {
+	defer n.store.write(ctx)()
+
+	if notificationID < 1 || notificationID > int64(len(n.store.notifications)) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	nt := &n.store.notifications[notificationID-1].value
+	fn(nt)
+	nt.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// List возвращает последние уведомления организации, новые первыми. Пустой status означает «без фильтра».
+func (n *NotificationRepository) List(ctx context.Context, status storage.DeliveryStatus, limit int) ([]storage.Notification, *apperrors.AppError) {
+	defer n.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	notifications := make([]storage.Notification, 0)
+	for i := len(n.store.notifications) - 1; i >= 0 && len(notifications) < limit; i-- {
+		rec := n.store.notifications[i]
+		if rec.org != org || status != "" && rec.value.Status != status {
+			continue
+		}
+		notifications = append(notifications, rec.value)
+	}
+	return notifications, nil
+}
+
+// SetEmailPreference создаёт или заменяет настройку писем пользователя. Пользователь
+// другой организации - NOT_FOUND.
+func (n *NotificationRepository) SetEmailPreference(ctx context.Context, pref storage.EmailPreference) *apperrors.AppError {
+	defer n.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	if !n.store.userIn(org, pref.UserID) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	pref.OrgID = org
+	now := time.Now().UTC()
+	pref.LastDigestAt = now
+	key := orgKey{org: org, id: pref.UserID}
+	if old, ok := n.store.emailPrefs[key]; ok && old.Mode == pref.Mode {
+		pref.LastDigestAt = old.LastDigestAt
+	}
+	pref.UpdatedAt = now
+	n.store.emailPrefs[key] = pref
+	return nil
+}
+
+// GetEmailPreference возвращает настройку писем пользователя.
+func (n *NotificationRepository) GetEmailPreference(ctx context.Context, userID string) (storage.EmailPreference, *apperrors.AppError) {
+	defer n.store.read(ctx)()
+
+	pref, ok := n.store.emailPrefs[orgKey{org: storage.OrgFrom(ctx), id: userID}]
+	if !ok {
+		return storage.EmailPreference{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return pref, nil
+}
+
+// ClaimDigests отмечает сводку отправленной для пользователей всех организаций, которым
+// она положена.
+func (n *NotificationRepository) ClaimDigests(ctx context.Context, cutoff, now time.Time, limit int) ([]storage.EmailPreference, *apperrors.AppError) {
+	defer n.store.write(ctx)()
+
+	ids := make([]orgKey, 0)
+	for key, pref := range n.store.emailPrefs {
+		if pref.Mode == storage.EmailDigest && pref.LastDigestAt.Before(cutoff) {
+			ids = append(ids, key)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].org != ids[j].org {
+			return ids[i].org < ids[j].org
+		}
+		return ids[i].id < ids[j].id
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	prefs := make([]storage.EmailPreference, 0, len(ids))
+	for _, id := range ids {
+		pref := n.store.emailPrefs[id]
+		pref.LastDigestAt = now.UTC()
+		n.store.emailPrefs[id] = pref
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OrganizationRepository - организации в памяти.
+type OrganizationRepository struct {
+	store *Store
+}
+
+// NewOrganizationRepository создаёт экземпляр *OrganizationRepository.
+func NewOrganizationRepository(store *Store) *OrganizationRepository {
+	return &OrganizationRepository{store: store}
+}
+
+// Create сохраняет организацию и возвращает её с присвоенным id.
+func (o *OrganizationRepository) Create(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	defer o.store.write(ctx)()
+
+	for _, org := range o.store.orgs {
+		if org.Name == name {
+			return storage.Organization{}, apperrors.New(apperrors.ErrOrgExists)
+		}
+	}
+	org := storage.Organization{
+		ID:        o.store.orgs[len(o.store.orgs)-1].ID + 1,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+	o.store.orgs = append(o.store.orgs, org)
+	return org, nil
+}
+
+// GetByName возвращает организацию по имени.
+func (o *OrganizationRepository) GetByName(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	defer o.store.read(ctx)()
+
+	for _, org := range o.store.orgs {
+		if org.Name == name {
+			return org, nil
+		}
+	}
+	return storage.Organization{}, apperrors.New(apperrors.ErrNotFound)
+}
+
+// List возвращает все организации в порядке создания.
+func (o *OrganizationRepository) List(ctx context.Context) ([]storage.Organization, *apperrors.AppError) {
+	defer o.store.read(ctx)()
+
+	return append([]storage.Organization{}, o.store.orgs...), nil
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OutboxRepository - outbox доменных событий и очередь доставок вебхуков в памяти.
+type OutboxRepository struct {
+	store *Store
+}
+
+// NewOutboxRepository создаёт экземпляр *OutboxRepository.
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+// Add записывает события и создаёт доставки для подписчиков той же организации.
+func (o *OutboxRepository) Add(ctx context.Context, events []storage.OutboxEvent) *apperrors.AppError {
+	defer o.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	now := time.Now().UTC()
+	for _, ev := range events {
+		ev.ID = int64(len(o.store.outbox)) + 1
+		ev.CreatedAt = now
+		if len(ev.Payload) == 0 {
+			ev.Payload = []byte("{}")
+		} else {
+			ev.Payload = append([]byte(nil), ev.Payload...)
+		}
+		o.store.outbox = append(o.store.outbox, ev)
+
+		for _, rec := range o.store.webhooks {
+			wh := rec.value
+			if rec.org != org || len(wh.EventTypes) > 0 && !slices.Contains(wh.EventTypes, ev.Type) {
+				continue
+			}
+			o.store.deliveries = append(o.store.deliveries, storage.WebhookDelivery{
+				ID:            int64(len(o.store.deliveries)) + 1,
+				WebhookID:     wh.ID,
+				Event:         ev,
+				URL:           wh.URL,
+				Secret:        wh.Secret,
+				Status:        storage.DeliveryPending,
+				NextAttemptAt: now,
+				UpdatedAt:     now,
+			})
+		}
+	}
+	return nil
+}
+
+// ClaimDue выдаёт до limit доставок всех организаций, которым пора отправляться, и откладывает их на lease.
+func (o *OutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	defer o.store.write(ctx)()
+
+	due := make([]int, 0)
+	for i, d := range o.store.deliveries {
+		if d.Status == storage.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return o.store.deliveries[due[a]].NextAttemptAt.Before(o.store.deliveries[due[b]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	sort.Ints(due)
+
+	claimed := make([]storage.WebhookDelivery, 0, len(due))
+	for _, i := range due {
+		d := &o.store.deliveries[i]
+		d.Attempts++
+		d.NextAttemptAt = now.Add(lease).UTC()
+		d.UpdatedAt = time.Now().UTC()
+		claimed = append(claimed, *d)
+	}
+	return claimed, nil
+}
+
+// MarkDelivered помечает доставку как успешную.
+func (o *OutboxRepository) MarkDelivered(ctx context.Context, deliveryID int64) *apperrors.AppError {
+	return o.update(ctx, deliveryID, func(d *storage.WebhookDelivery) {
+		d.Status = storage.DeliveryDelivered
+		d.LastError = ""
+	})
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит доставку в DEAD.
+func (o *OutboxRepository) MarkFailed(ctx context.Context, deliveryID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	return o.update(ctx, deliveryID, func(d *storage.WebhookDelivery) {
+		d.Status = storage.DeliveryPending
+		if dead {
+			d.Status = storage.DeliveryDead
+		}
+		d.NextAttemptAt = nextAttemptAt.UTC()
+		d.LastError = lastErr
+	})
+}
+
+func (o *OutboxRepository) update(ctx context.Context, deliveryID int64, fn func(d *storage.WebhookDelivery)) *apperrors.AppError {
+	defer o.store.write(ctx)()
+
+	if deliveryID < 1 || deliveryID > int64(len(o.store.deliveries)) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	d := &o.store.deliveries[deliveryID-1]
+	fn(d)
+	d.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки организации, новые первыми. webhookID = 0 и пустой
+// status означают «без фильтра».
+func (o *OutboxRepository) ListDeliveries(ctx context.Context, webhookID int64, status storage.DeliveryStatus, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	defer o.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	deliveries := make([]storage.WebhookDelivery, 0)
+	for i := len(o.store.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := o.store.deliveries[i]
+		if o.store.webhooks[d.WebhookID-1].org != org {
+			continue
+		}
+		if webhookID != 0 && d.WebhookID != webhookID {
+			continue
+		}
+		if status != "" && d.Status != status {
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// PREventRepository - история событий PR в памяти.
+type PREventRepository struct {
+	store *Store
+}
+
+// NewPREventRepository создаёт экземпляр *PREventRepository.
+func NewPREventRepository(store *Store) *PREventRepository {
+	return &PREventRepository{store: store}
+}
+
+// Add дописывает события в историю.
+func (e *PREventRepository) Add(ctx context.Context, events []storage.PREvent) *apperrors.AppError {
+	defer e.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	for _, ev := range events {
+		if _, ok := e.store.prs[orgKey{org: org, id: ev.PullRequestID}]; !ok {
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, ev := range events {
+		e.store.nextEventID++
+		ev.ID = e.store.nextEventID
+		ev.CreatedAt = now
+		if len(ev.Payload) == 0 {
+			ev.Payload = []byte("{}")
+		} else {
+			ev.Payload = append([]byte(nil), ev.Payload...)
+		}
+		e.store.events = append(e.store.events, owned[storage.PREvent]{value: ev, org: org})
+	}
+	return nil
+}
+
+// GetByPR возвращает историю pr в хронологическом порядке.
+func (e *PREventRepository) GetByPR(ctx context.Context, prID string) ([]storage.PREvent, *apperrors.AppError) {
+	defer e.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	events := make([]storage.PREvent, 0)
+	for _, ev := range e.store.events {
+		if ev.org == org && ev.value.PullRequestID == prID {
+			events = append(events, ev.value)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// PullRequestRepository - репозиторий pr'ов в памяти.
+type PullRequestRepository struct {
+	store *Store
+}
+
+// NewPullRequestRepository создаёт экземпляр *PullRequestRepository.
+func NewPullRequestRepository(store *Store) *PullRequestRepository {
+	return &PullRequestRepository{store: store}
+}
+
+// Create создаёт pr с ревьюверами.
+func (p *PullRequestRepository) Create(ctx context.Context, pr storage.PullRequest) *apperrors.AppError {
+	defer p.store.write(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: pr.ID}
+	if _, ok := p.store.prs[key]; ok {
+		return apperrors.New(apperrors.ErrPRExists)
+	}
+
+	// Как и внешние ключи в Postgres: автор и ревьюеры должны существовать, ревьюеры - без повторов.
+	if !p.store.userIn(key.org, pr.AuthorID) {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	now := time.Now().UTC()
+	seen := make(map[string]bool, len(pr.AssignedReviewers))
+	reviewers := make([]review, 0, len(pr.AssignedReviewers))
+	for _, rev := range pr.AssignedReviewers {
+		if !p.store.userIn(key.org, rev) || seen[rev] {
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+		seen[rev] = true
+		reviewers = append(reviewers, review{reviewerID: rev, assignedAt: now})
+	}
+
+	stored := pr
+	stored.AssignedReviewers = nil
+	stored.MergedAt = nil
+	stored.Version = 1
+	p.store.prs[key] = pullRequest{pr: stored, reviewers: reviewers}
+	return nil
+}
+
+// Get возвращает pr по id.
+func (p *PullRequestRepository) Get(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	defer p.store.read(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: prID}
+	rec, ok := p.store.prs[key]
+	if !ok {
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return rec.withReviewers(), nil
+}
+
+// GetForUpdate возвращает pr по id. Внутри TxManager.Do хранилище и так заблокировано
+// целиком, поэтому отдельная блокировка строки не нужна.
+func (p *PullRequestRepository) GetForUpdate(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	return p.Get(ctx, prID)
+}
+
+// Exists проверяет существование pr.
+func (p *PullRequestRepository) Exists(ctx context.Context, prID string) (bool, *apperrors.AppError) {
+	defer p.store.read(ctx)()
+
+	_, ok := p.store.prs[orgKey{org: storage.OrgFrom(ctx), id: prID}]
+	return ok, nil
+}
+
+// MarkMerged помечает pr как MERGED. Повторный вызов не меняет merged_at.
+func (p *PullRequestRepository) MarkMerged(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	defer p.store.write(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: prID}
+	rec, ok := p.store.prs[key]
+	if !ok {
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrNotFound)
+	}
+
+	rec.pr.Status = storage.StatusMerged
+	if rec.pr.MergedAt == nil {
+		now := time.Now().UTC()
+		rec.pr.MergedAt = &now
+	}
+	rec.pr.Version++
+	p.store.prs[key] = rec
+	return rec.withReviewers(), nil
+}
+
+// SetStatus переводит pr в OPEN или CLOSED; для MERGED используется MarkMerged.
+func (p *PullRequestRepository) SetStatus(ctx context.Context, prID string, status storage.PRStatus) (storage.PullRequest, *apperrors.AppError) {
+	defer p.store.write(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: prID}
+	rec, ok := p.store.prs[key]
+	if !ok {
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrNotFound)
+	}
+
+	rec.pr.Status = status
+	rec.pr.Version++
+	p.store.prs[key] = rec
+	return rec.withReviewers(), nil
+}
+
+// ReplaceReviewer заменяет одного ревьюера на другого.
+func (p *PullRequestRepository) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) *apperrors.AppError {
+	defer p.store.write(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: prID}
+	rec, ok := p.store.prs[key]
+	if !ok {
+		return apperrors.New(apperrors.ErrNotAssigned)
+	}
+
+	idx := -1
+	for i, r := range rec.reviewers {
+		if r.reviewerID == oldReviewerID {
+			idx = i
+		}
+		if r.reviewerID == newReviewerID && newReviewerID != oldReviewerID {
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+	if idx < 0 {
+		return apperrors.New(apperrors.ErrNotAssigned)
+	}
+	if !p.store.userIn(key.org, newReviewerID) {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	reviewers := append([]review(nil), rec.reviewers...)
+	reviewers[idx] = review{reviewerID: newReviewerID, assignedAt: time.Now().UTC()}
+	rec.reviewers = reviewers
+	rec.pr.Version++
+	p.store.prs[key] = rec
+	return nil
+}
+
+// AddReviewer назначает на pr ещё одного ревьюера.
+func (p *PullRequestRepository) AddReviewer(ctx context.Context, prID, reviewerID string) *apperrors.AppError {
+	defer p.store.write(ctx)()
+
+	// Как и внешние ключи и первичный ключ reviews в Postgres.
+	key := orgKey{org: storage.OrgFrom(ctx), id: prID}
+	rec, ok := p.store.prs[key]
+	if !ok {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if !p.store.userIn(key.org, reviewerID) {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	for _, r := range rec.reviewers {
+		if r.reviewerID == reviewerID {
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+
+	reviewers := append([]review(nil), rec.reviewers...)
+	rec.reviewers = append(reviewers, review{reviewerID: reviewerID, assignedAt: time.Now().UTC()})
+	rec.pr.Version++
+	p.store.prs[key] = rec
+	return nil
+}
+
+// GetByReviewer возвращает все pr, где пользователь ревьюер (без списка ревьюеров).
+func (p *PullRequestRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]storage.PullRequest, *apperrors.AppError) {
+	defer p.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	var prs []storage.PullRequest
+	for key, rec := range p.store.prs {
+		if key.org == org && rec.hasReviewer(reviewerID) {
+			prs = append(prs, rec.pr)
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].ID < prs[j].ID })
+	return prs, nil
+}
+
+// IsReviewerAssigned проверяет, является ли пользователь ревьюером.
+func (p *PullRequestRepository) IsReviewerAssigned(ctx context.Context, reviewerID string) (bool, *apperrors.AppError) {
+	defer p.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	for key, rec := range p.store.prs {
+		if key.org == org && rec.hasReviewer(reviewerID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CountAssignmentsByUser возвращает количество назначений по каждому ревьюеру.
+func (p *PullRequestRepository) CountAssignmentsByUser(ctx context.Context) (map[string]int, *apperrors.AppError) {
+	defer p.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	res := make(map[string]int)
+	for key, rec := range p.store.prs {
+		if key.org != org {
+			continue
+		}
+		for _, r := range rec.reviewers {
+			res[r.reviewerID]++
+		}
+	}
+	return res, nil
+}
+
+// CountAssignmentsByPR возвращает количество назначений по каждому pr.
+func (p *PullRequestRepository) CountAssignmentsByPR(ctx context.Context) (map[string]int, *apperrors.AppError) {
+	defer p.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	res := make(map[string]int)
+	for key, rec := range p.store.prs {
+		if key.org == org && len(rec.reviewers) > 0 {
+			res[key.id] = len(rec.reviewers)
+		}
+	}
+	return res, nil
+}
+
+func (r pullRequest) withReviewers() storage.PullRequest {
+	pr := r.pr
+	for _, rev := range r.reviewers {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, rev.reviewerID)
+	}
+	return pr
+}
+
+func (r pullRequest) hasReviewer(reviewerID string) bool {
+	for _, rev := range r.reviewers {
+		if rev.reviewerID == reviewerID {
+			return true
+		}
+	}
+	return false
+}
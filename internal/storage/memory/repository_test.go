@@ -0,0 +1,33 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/storagetest"
+)
+
+func TestRepositories(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		store := memory.NewStore()
+		return storagetest.Backend{
+			Tx:       memory.NewTxManager(store),
+			Users:    memory.NewUserRepository(store),
+			Teams:    memory.NewTeamRepository(store),
+			PRs:      memory.NewPullRequestRepository(store),
+			Logs:     memory.NewAssignmentLogRepository(store),
+			Events:   memory.NewPREventRepository(store),
+			Webhooks: memory.NewWebhookRepository(store),
+			Outbox:   memory.NewOutboxRepository(store),
+
+			Integrations:  memory.NewIntegrationRepository(store),
+			ReviewerSyncs: memory.NewReviewerSyncRepository(store),
+			Notifications: memory.NewNotificationRepository(store),
+			ReviewSLAs:    memory.NewReviewSLARepository(store),
+			APITokens:     memory.NewAPITokenRepository(store),
+			Orgs:          memory.NewOrganizationRepository(store),
+			Audit:         memory.NewAuditLogRepository(store),
+			Idempotency:   memory.NewIdempotencyRepository(store),
+		}
+	})
+}
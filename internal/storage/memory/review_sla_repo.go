@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewSLARepository - сроки ревью команд и отметки SLA назначений в памяти.
+type ReviewSLARepository struct {
+	store *Store
+}
+
+// NewReviewSLARepository создаёт экземпляр *ReviewSLARepository.
+func NewReviewSLARepository(store *Store) *ReviewSLARepository {
+	return &ReviewSLARepository{store: store}
+}
+
+// SetTeamSLA задаёт срок ревью команды; существующая настройка перезаписывается. Команда
+// другой организации - NOT_FOUND.
+func (r *ReviewSLARepository) SetTeamSLA(ctx context.Context, sla storage.ReviewSLA) *apperrors.AppError {
+	defer r.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	if !r.store.teamIn(org, sla.TeamID) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	if sla.LeadID != "" && !r.store.userIn(org, sla.LeadID) {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	sla.UpdatedAt = time.Now().UTC()
+	r.store.reviewSLAs[sla.TeamID] = sla
+	return nil
+}
+
+// GetTeamSLA возвращает срок ревью команды.
+func (r *ReviewSLARepository) GetTeamSLA(ctx context.Context, teamID int) (storage.ReviewSLA, *apperrors.AppError) {
+	defer r.store.read(ctx)()
+
+	sla, ok := r.store.reviewSLAs[teamID]
+	if !ok || !r.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return storage.ReviewSLA{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return sla, nil
+}
+
+// DeleteTeamSLA снимает срок ревью с команды.
+func (r *ReviewSLARepository) DeleteTeamSLA(ctx context.Context, teamID int) *apperrors.AppError {
+	defer r.store.write(ctx)()
+
+	if _, ok := r.store.reviewSLAs[teamID]; !ok || !r.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	delete(r.store.reviewSLAs, teamID)
+	return nil
+}
+
+// GetReviews возвращает назначения ревьюеров pr.
+func (r *ReviewSLARepository) GetReviews(ctx context.Context, prID string) ([]storage.ReviewState, *apperrors.AppError) {
+	defer r.store.read(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: prID}
+	rec := r.store.prs[key]
+	reviews := make([]storage.ReviewState, 0, len(rec.reviewers))
+	for _, rv := range rec.reviewers {
+		reviews = append(reviews, rv.state(key))
+	}
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].ReviewerID < reviews[j].ReviewerID })
+	return reviews, nil
+}
+
+// ClaimReminders отмечает напоминание для ревью, срок которых истёк.
+func (r *ReviewSLARepository) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	return r.claim(ctx, now, limit,
+		func(rv review, sla storage.ReviewSLA) bool {
+			return rv.remindedAt == nil && !rv.assignedAt.Add(sla.Within).After(now)
+		},
+		func(rv *review, at *time.Time) { rv.remindedAt = at },
+	)
+}
+
+// ClaimEscalations отмечает эскалацию для ревью, просроченных сверх EscalateAfter.
+func (r *ReviewSLARepository) ClaimEscalations(ctx context.Context, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	return r.claim(ctx, now, limit,
+		func(rv review, sla storage.ReviewSLA) bool {
+			return rv.escalatedAt == nil && sla.Escalation != storage.EscalateNone &&
+				!rv.assignedAt.Add(sla.EscalateAfter).After(now)
+		},
+		func(rv *review, at *time.Time) { rv.escalatedAt = at },
+	)
+}
+
+func (r *ReviewSLARepository) claim(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	due func(rv review, sla storage.ReviewSLA) bool,
+	mark func(rv *review, at *time.Time),
+) ([]storage.OverdueReview, *apperrors.AppError) {
+	defer r.store.write(ctx)()
+
+	overdue := make([]storage.OverdueReview, 0)
+	for key, rec := range r.store.prs {
+		if rec.pr.Status != storage.StatusOpen {
+			continue
+		}
+		sla, ok := r.store.reviewSLAs[r.store.users[orgKey{org: key.org, id: rec.pr.AuthorID}].TeamID]
+		if !ok {
+			continue
+		}
+		for _, rv := range rec.reviewers {
+			if due(rv, sla) {
+				overdue = append(overdue, storage.OverdueReview{SLA: sla, ReviewState: rv.state(key)})
+			}
+		}
+	}
+	sort.Slice(overdue, func(i, j int) bool {
+		if overdue[i].OrgID != overdue[j].OrgID {
+			return overdue[i].OrgID < overdue[j].OrgID
+		}
+		if overdue[i].PullRequestID != overdue[j].PullRequestID {
+			return overdue[i].PullRequestID < overdue[j].PullRequestID
+		}
+		return overdue[i].ReviewerID < overdue[j].ReviewerID
+	})
+	if len(overdue) > limit {
+		overdue = overdue[:limit]
+	}
+
+	at := now.UTC()
+	for i, o := range overdue {
+		key := orgKey{org: o.OrgID, id: o.PullRequestID}
+		rec := r.store.prs[key]
+		reviewers := append([]review(nil), rec.reviewers...)
+		for j := range reviewers {
+			if reviewers[j].reviewerID == o.ReviewerID {
+				mark(&reviewers[j], &at)
+				overdue[i].ReviewState = reviewers[j].state(key)
+			}
+		}
+		rec.reviewers = reviewers
+		r.store.prs[key] = rec
+	}
+	return overdue, nil
+}
+
+func (rv review) state(key orgKey) storage.ReviewState {
+	return storage.ReviewState{
+		AssignedAt:    rv.assignedAt,
+		RemindedAt:    rv.remindedAt,
+		EscalatedAt:   rv.escalatedAt,
+		PullRequestID: key.id,
+		ReviewerID:    rv.reviewerID,
+		OrgID:         key.org,
+	}
+}
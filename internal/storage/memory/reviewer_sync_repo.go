@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewerSyncRepository - очередь передачи ревьюеров на код-хостинг в памяти.
+type ReviewerSyncRepository struct {
+	store *Store
+}
+
+// NewReviewerSyncRepository создаёт экземпляр *ReviewerSyncRepository.
+func NewReviewerSyncRepository(store *Store) *ReviewerSyncRepository {
+	return &ReviewerSyncRepository{store: store}
+}
+
+// Enqueue ставит задачу в очередь; она готова к отправке сразу.
+func (r *ReviewerSyncRepository) Enqueue(ctx context.Context, sync storage.ReviewerSync) *apperrors.AppError {
+	defer r.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	if _, ok := r.store.prs[orgKey{org: org, id: sync.PullRequestID}]; !ok {
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	now := time.Now().UTC()
+	r.store.reviewerSyncs = append(r.store.reviewerSyncs, storage.ReviewerSync{
+		ID:            int64(len(r.store.reviewerSyncs)) + 1,
+		OrgID:         org,
+		Provider:      sync.Provider,
+		PullRequestID: sync.PullRequestID,
+		Removed:       append([]string{}, sync.Removed...),
+		Status:        storage.DeliveryPending,
+		NextAttemptAt: now,
+		UpdatedAt:     now,
+	})
+	return nil
+}
+
+// ClaimDue выдаёт до limit задач всех организаций, которым пора выполняться, и откладывает их на lease.
+func (r *ReviewerSyncRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.ReviewerSync, *apperrors.AppError) {
+	defer r.store.write(ctx)()
+
+	due := make([]int, 0)
+	for i, s := range r.store.reviewerSyncs {
+		if s.Status == storage.DeliveryPending && !s.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		return r.store.reviewerSyncs[due[a]].NextAttemptAt.Before(r.store.reviewerSyncs[due[b]].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	sort.Ints(due)
+
+	claimed := make([]storage.ReviewerSync, 0, len(due))
+	for _, i := range due {
+		s := &r.store.reviewerSyncs[i]
+		s.Attempts++
+		s.NextAttemptAt = now.Add(lease).UTC()
+		s.UpdatedAt = time.Now().UTC()
+		claimed = append(claimed, *s)
+	}
+	return claimed, nil
+}
+
+// MarkDone помечает задачу как выполненную.
+func (r *ReviewerSyncRepository) MarkDone(ctx context.Context, syncID int64) *apperrors.AppError {
+	return r.update(ctx, syncID, func(s *storage.ReviewerSync) {
+		s.Status = storage.DeliveryDelivered
+		s.LastError = ""
+	})
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит задачу в DEAD.
+func (r *ReviewerSyncRepository) MarkFailed(ctx context.Context, syncID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	return r.update(ctx, syncID, func(s *storage.ReviewerSync) {
+		s.Status = storage.DeliveryPending
+		if dead {
+			s.Status = storage.DeliveryDead
+		}
+		s.NextAttemptAt = nextAttemptAt.UTC()
+		s.LastError = lastErr
+	})
+}
+
+func (r *ReviewerSyncRepository) update(ctx context.Context, syncID int64, fn func(s *storage.ReviewerSync)) *apperrors.AppError {
+	defer r.store.write(ctx)()
+
+	if syncID < 1 || syncID > int64(len(r.store.reviewerSyncs)) {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	s := &r.store.reviewerSyncs[syncID-1]
+	fn(s)
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// List возвращает задачи pr в порядке постановки.
+func (r *ReviewerSyncRepository) List(ctx context.Context, prID string) ([]storage.ReviewerSync, *apperrors.AppError) {
+	defer r.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	syncs := make([]storage.ReviewerSync, 0)
+	for _, s := range r.store.reviewerSyncs {
+		if s.OrgID == org && s.PullRequestID == prID {
+			syncs = append(syncs, s)
+		}
+	}
+	return syncs, nil
+}
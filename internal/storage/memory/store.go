@@ -0,0 +1,298 @@
+// Package memory - реализация репозиториев storage в памяти процесса.
+// Семантика и коды ошибок совпадают с internal/storage/postgres; используется в тестах
+// и для запуска без базы данных.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// txKey - ключ контекста, помечающий, что вызов идёт внутри транзакции хранилища.
+type txKey struct{}
+
+// orgKey - ключ записи, уникальной в пределах организации (имя команды, id PR, user_id).
+type orgKey struct {
+	id  string
+	org int64
+}
+
+// owned - запись, принадлежащая организации.
+type owned[T any] struct {
+	value T
+	org   int64
+}
+
+type team struct {
+	createdAt time.Time
+	name      string
+	id        int
+	org       int64
+	version   int64
+}
+
+type review struct {
+	assignedAt  time.Time
+	remindedAt  *time.Time
+	escalatedAt *time.Time
+	reviewerID  string
+}
+
+type pullRequest struct {
+	pr        storage.PullRequest
+	reviewers []review
+}
+
+// Store - общее состояние in-memory репозиториев. Все операции сериализуются через мьютекс,
+// транзакция TxManager держит его целиком.
+type Store struct {
+	teams          map[int]team
+	teamIDs        map[orgKey]int
+	users          map[orgKey]storage.User
+	prs            map[orgKey]pullRequest
+	orgs           []storage.Organization
+	logs           []owned[storage.AssignmentLog]
+	events         []owned[storage.PREvent]
+	webhooks       []owned[storage.Webhook]
+	outbox         []storage.OutboxEvent
+	deliveries     []storage.WebhookDelivery
+	reviewerSyncs  []storage.ReviewerSync
+	notifications  []owned[storage.Notification]
+	teamChats      map[int]storage.TeamChat
+	emailPrefs     map[orgKey]storage.EmailPreference
+	reviewSLAs     map[int]storage.ReviewSLA
+	apiTokens      []storage.APIToken
+	audit          []owned[storage.AuditEntry]
+	idempotency    map[idempotencyKey]storage.IdempotencyRecord
+	identities     map[integrationKey]string
+	inbound        map[integrationKey]struct{}
+	inboundSecrets map[integrationKey]storage.InboundSecret
+	nextTeamID     int
+	nextLogID      int64
+	nextEventID    int64
+	nextAuditID    int64
+	mu             sync.RWMutex
+}
+
+// NewStore создаёт пустое хранилище с организацией по умолчанию.
+func NewStore() *Store {
+	return &Store{
+		teams:   make(map[int]team),
+		teamIDs: make(map[orgKey]int),
+		users:   make(map[orgKey]storage.User),
+		prs:     make(map[orgKey]pullRequest),
+		orgs: []storage.Organization{
+			{ID: storage.DefaultOrgID, Name: storage.DefaultOrgName, CreatedAt: time.Now().UTC()},
+		},
+
+		identities:     make(map[integrationKey]string),
+		inbound:        make(map[integrationKey]struct{}),
+		inboundSecrets: make(map[integrationKey]storage.InboundSecret),
+		teamChats:      make(map[int]storage.TeamChat),
+		emailPrefs:     make(map[orgKey]storage.EmailPreference),
+		reviewSLAs:     make(map[int]storage.ReviewSLA),
+
+		idempotency: make(map[idempotencyKey]storage.IdempotencyRecord),
+	}
+}
+
+// inTx сообщает, выполняется ли вызов внутри транзакции этого хранилища.
+func (s *Store) inTx(ctx context.Context) bool {
+	st, _ := ctx.Value(txKey{}).(*Store)
+	return st == s
+}
+
+// teamIn сообщает, есть ли команда teamID в организации org.
+func (s *Store) teamIn(org int64, teamID int) bool {
+	tm, ok := s.teams[teamID]
+	return ok && tm.org == org
+}
+
+// userIn сообщает, есть ли пользователь userID в организации org.
+func (s *Store) userIn(org int64, userID string) bool {
+	_, ok := s.users[orgKey{org: org, id: userID}]
+	return ok
+}
+
+// read блокирует хранилище на чтение, если вызов не внутри транзакции.
+func (s *Store) read(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// write блокирует хранилище на запись, если вызов не внутри транзакции.
+func (s *Store) write(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// snapshot - копия состояния для отката транзакции.
+type snapshot struct {
+	teams          map[int]team
+	teamIDs        map[orgKey]int
+	users          map[orgKey]storage.User
+	prs            map[orgKey]pullRequest
+	orgs           []storage.Organization
+	logs           []owned[storage.AssignmentLog]
+	events         []owned[storage.PREvent]
+	webhooks       []owned[storage.Webhook]
+	outbox         []storage.OutboxEvent
+	deliveries     []storage.WebhookDelivery
+	reviewerSyncs  []storage.ReviewerSync
+	notifications  []owned[storage.Notification]
+	teamChats      map[int]storage.TeamChat
+	emailPrefs     map[orgKey]storage.EmailPreference
+	reviewSLAs     map[int]storage.ReviewSLA
+	apiTokens      []storage.APIToken
+	audit          []owned[storage.AuditEntry]
+	idempotency    map[idempotencyKey]storage.IdempotencyRecord
+	identities     map[integrationKey]string
+	inbound        map[integrationKey]struct{}
+	inboundSecrets map[integrationKey]storage.InboundSecret
+	nextTeamID     int
+	nextLogID      int64
+	nextEventID    int64
+	nextAuditID    int64
+}
+
+func (s *Store) snapshot() snapshot {
+	snap := snapshot{
+		teams:          make(map[int]team, len(s.teams)),
+		teamIDs:        make(map[orgKey]int, len(s.teamIDs)),
+		users:          make(map[orgKey]storage.User, len(s.users)),
+		prs:            make(map[orgKey]pullRequest, len(s.prs)),
+		orgs:           append([]storage.Organization(nil), s.orgs...),
+		logs:           append([]owned[storage.AssignmentLog](nil), s.logs...),
+		events:         append([]owned[storage.PREvent](nil), s.events...),
+		webhooks:       append([]owned[storage.Webhook](nil), s.webhooks...),
+		outbox:         append([]storage.OutboxEvent(nil), s.outbox...),
+		deliveries:     append([]storage.WebhookDelivery(nil), s.deliveries...),
+		reviewerSyncs:  append([]storage.ReviewerSync(nil), s.reviewerSyncs...),
+		notifications:  append([]owned[storage.Notification](nil), s.notifications...),
+		teamChats:      make(map[int]storage.TeamChat, len(s.teamChats)),
+		emailPrefs:     make(map[orgKey]storage.EmailPreference, len(s.emailPrefs)),
+		reviewSLAs:     make(map[int]storage.ReviewSLA, len(s.reviewSLAs)),
+		apiTokens:      append([]storage.APIToken(nil), s.apiTokens...),
+		audit:          append([]owned[storage.AuditEntry](nil), s.audit...),
+		nextTeamID:     s.nextTeamID,
+		nextLogID:      s.nextLogID,
+		nextEventID:    s.nextEventID,
+		nextAuditID:    s.nextAuditID,
+		idempotency:    make(map[idempotencyKey]storage.IdempotencyRecord, len(s.idempotency)),
+		identities:     make(map[integrationKey]string, len(s.identities)),
+		inbound:        make(map[integrationKey]struct{}, len(s.inbound)),
+		inboundSecrets: make(map[integrationKey]storage.InboundSecret, len(s.inboundSecrets)),
+	}
+	for k, v := range s.identities {
+		snap.identities[k] = v
+	}
+	for k, v := range s.idempotency {
+		snap.idempotency[k] = v
+	}
+	for k, v := range s.teamChats {
+		snap.teamChats[k] = v
+	}
+	for k, v := range s.emailPrefs {
+		snap.emailPrefs[k] = v
+	}
+	for k, v := range s.reviewSLAs {
+		snap.reviewSLAs[k] = v
+	}
+	for k, v := range s.inbound {
+		snap.inbound[k] = v
+	}
+	for k, v := range s.inboundSecrets {
+		snap.inboundSecrets[k] = v
+	}
+	for k, v := range s.teams {
+		snap.teams[k] = v
+	}
+	for k, v := range s.teamIDs {
+		snap.teamIDs[k] = v
+	}
+	for k, v := range s.users {
+		snap.users[k] = v
+	}
+	for k, v := range s.prs {
+		v.reviewers = append([]review(nil), v.reviewers...)
+		snap.prs[k] = v
+	}
+	return snap
+}
+
+func (s *Store) restore(snap snapshot) {
+	s.teams = snap.teams
+	s.teamIDs = snap.teamIDs
+	s.users = snap.users
+	s.prs = snap.prs
+	s.orgs = snap.orgs
+	s.logs = snap.logs
+	s.events = snap.events
+	s.webhooks = snap.webhooks
+	s.outbox = snap.outbox
+	s.deliveries = snap.deliveries
+	s.reviewerSyncs = snap.reviewerSyncs
+	s.notifications = snap.notifications
+	s.teamChats = snap.teamChats
+	s.emailPrefs = snap.emailPrefs
+	s.reviewSLAs = snap.reviewSLAs
+	s.apiTokens = snap.apiTokens
+	s.audit = snap.audit
+	s.idempotency = snap.idempotency
+	s.identities = snap.identities
+	s.inbound = snap.inbound
+	s.inboundSecrets = snap.inboundSecrets
+	s.nextTeamID = snap.nextTeamID
+	s.nextLogID = snap.nextLogID
+	s.nextEventID = snap.nextEventID
+	s.nextAuditID = snap.nextAuditID
+}
+
+// TxManager - реализация storage.TxManager для in-memory хранилища.
+type TxManager struct {
+	store *Store
+}
+
+// NewTxManager создаёт экземпляр *TxManager.
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+// Do выполняет fn под эксклюзивной блокировкой хранилища; если fn вернула ошибку,
+// состояние откатывается к моменту начала транзакции. Вложенный Do присоединяется к внешнему.
+// Функции storage.AfterCommit вызываются после внешнего Do, когда блокировка уже снята.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) *apperrors.AppError) *apperrors.AppError {
+	if m.store.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	ctx, hooks := storage.WithTxHooks(ctx)
+	if err := m.run(ctx, fn); err != nil {
+		return err
+	}
+	hooks.Run()
+	return nil
+}
+
+// run выполняет fn внешней транзакции под блокировкой хранилища.
+func (m *TxManager) run(ctx context.Context, fn func(ctx context.Context) *apperrors.AppError) *apperrors.AppError {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	snap := m.store.snapshot()
+	if err := fn(context.WithValue(ctx, txKey{}, m.store)); err != nil {
+		m.store.restore(snap)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// TeamRepository - репозиторий команд в памяти.
+type TeamRepository struct {
+	store *Store
+}
+
+// NewTeamRepository создаёт экземпляр *TeamRepository.
+func NewTeamRepository(store *Store) *TeamRepository {
+	return &TeamRepository{store: store}
+}
+
+// Create создаёт новую команду. Существующие пользователи организации переносятся в неё;
+// user_id уникальны в пределах организации.
+func (t *TeamRepository) Create(ctx context.Context, tm storage.Team) *apperrors.AppError {
+	defer t.store.write(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	key := orgKey{org: org, id: tm.TeamName}
+	if _, ok := t.store.teamIDs[key]; ok {
+		return apperrors.New(apperrors.ErrTeamExists)
+	}
+
+	now := time.Now().UTC()
+	t.store.nextTeamID++
+	id := t.store.nextTeamID
+	t.store.teams[id] = team{id: id, org: org, name: tm.TeamName, createdAt: now, version: 1}
+	t.store.teamIDs[key] = id
+
+	for _, m := range tm.Members {
+		userKey := orgKey{org: org, id: m.ID}
+		t.store.users[userKey] = storage.User{
+			ID:        m.ID,
+			Username:  m.Username,
+			TeamID:    id,
+			IsActive:  m.IsActive,
+			UpdatedAt: now,
+			Version:   t.store.users[userKey].Version + 1,
+		}
+	}
+	return nil
+}
+
+// GetByName возвращает команду и её участников по имени.
+func (t *TeamRepository) GetByName(ctx context.Context, teamName string) (storage.Team, *apperrors.AppError) {
+	defer t.store.read(ctx)()
+
+	id, ok := t.store.teamIDs[orgKey{org: storage.OrgFrom(ctx), id: teamName}]
+	if !ok {
+		return storage.Team{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return t.store.team(id), nil
+}
+
+// Exists проверяет существует ли команда по её имени.
+func (t *TeamRepository) Exists(ctx context.Context, teamName string) (bool, *apperrors.AppError) {
+	defer t.store.read(ctx)()
+
+	_, ok := t.store.teamIDs[orgKey{org: storage.OrgFrom(ctx), id: teamName}]
+	return ok, nil
+}
+
+// GetByID возвращает команду по её ID.
+func (t *TeamRepository) GetByID(ctx context.Context, teamID int) (storage.Team, *apperrors.AppError) {
+	defer t.store.read(ctx)()
+
+	if !t.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return storage.Team{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return t.store.team(teamID), nil
+}
+
+// team собирает storage.Team с участниками. Вызывающий должен держать блокировку хранилища.
+func (s *Store) team(id int) storage.Team {
+	tm := s.teams[id]
+	members := s.teamMembers(id)
+	if members == nil {
+		members = make([]storage.User, 0)
+	}
+	return storage.Team{
+		ID:        tm.id,
+		TeamName:  tm.name,
+		CreatedAt: tm.createdAt,
+		Members:   members,
+		Version:   tm.version,
+	}
+}
+
+// Touch увеличивает версию команды и возвращает новую.
+func (t *TeamRepository) Touch(ctx context.Context, teamID int) (int64, *apperrors.AppError) {
+	defer t.store.write(ctx)()
+
+	if !t.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return 0, apperrors.New(apperrors.ErrNotFound)
+	}
+	tm := t.store.teams[teamID]
+	tm.version++
+	t.store.teams[teamID] = tm
+	return tm.version, nil
+}
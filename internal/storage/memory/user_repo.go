@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// UserRepository - репозиторий пользователей в памяти.
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository создаёт экземпляр *UserRepository.
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// Get возвращает пользователя по id.
+func (u *UserRepository) Get(ctx context.Context, userID string) (storage.User, *apperrors.AppError) {
+	defer u.store.read(ctx)()
+
+	user, ok := u.store.users[orgKey{org: storage.OrgFrom(ctx), id: userID}]
+	if !ok {
+		return storage.User{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	return user, nil
+}
+
+// SetActive обновляет флаг активности пользователя.
+func (u *UserRepository) SetActive(ctx context.Context, userID string, isActive bool) (storage.User, *apperrors.AppError) {
+	defer u.store.write(ctx)()
+
+	key := orgKey{org: storage.OrgFrom(ctx), id: userID}
+	user, ok := u.store.users[key]
+	if !ok {
+		return storage.User{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	user.IsActive = isActive
+	user.UpdatedAt = time.Now().UTC()
+	user.Version++
+	u.store.users[key] = user
+	return user, nil
+}
+
+// GetActiveTeammates возвращает активных участников команды по teamID, исключая excludedID.
+func (u *UserRepository) GetActiveTeammates(ctx context.Context, teamID int, excludedID string) ([]storage.User, *apperrors.AppError) {
+	defer u.store.read(ctx)()
+
+	if !u.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return nil, nil
+	}
+	var users []storage.User
+	for _, user := range u.store.teamMembers(teamID) {
+		if user.IsActive && user.ID != excludedID {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// GetByTeam возвращает всех участников команды по teamID, включая неактивных.
+func (u *UserRepository) GetByTeam(ctx context.Context, teamID int) ([]storage.User, *apperrors.AppError) {
+	defer u.store.read(ctx)()
+
+	if !u.store.teamIn(storage.OrgFrom(ctx), teamID) {
+		return nil, nil
+	}
+	return u.store.teamMembers(teamID), nil
+}
+
+// Exists проверяет существует ли пользователь по его ID.
+func (u *UserRepository) Exists(ctx context.Context, userID string) (bool, *apperrors.AppError) {
+	defer u.store.read(ctx)()
+
+	return u.store.userIn(storage.OrgFrom(ctx), userID), nil
+}
+
+// teamMembers возвращает участников команды, отсортированных по user_id.
+// Вызывающий должен держать блокировку хранилища.
+func (s *Store) teamMembers(teamID int) []storage.User {
+	var users []storage.User
+	for key, user := range s.users {
+		if user.TeamID == teamID && key.org == s.teams[teamID].org {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
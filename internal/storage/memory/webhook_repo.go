@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// WebhookRepository - подписчики на доменные события в памяти.
+type WebhookRepository struct {
+	store *Store
+}
+
+// NewWebhookRepository создаёт экземпляр *WebhookRepository.
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+// Create регистрирует подписчика и возвращает его с присвоенным id.
+func (w *WebhookRepository) Create(ctx context.Context, webhook storage.Webhook) (storage.Webhook, *apperrors.AppError) {
+	defer w.store.write(ctx)()
+
+	webhook.ID = int64(len(w.store.webhooks)) + 1
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.EventTypes = append([]string{}, webhook.EventTypes...)
+	w.store.webhooks = append(w.store.webhooks, owned[storage.Webhook]{value: webhook, org: storage.OrgFrom(ctx)})
+	return webhook, nil
+}
+
+// List возвращает подписчиков организации в порядке регистрации.
+func (w *WebhookRepository) List(ctx context.Context) ([]storage.Webhook, *apperrors.AppError) {
+	defer w.store.read(ctx)()
+
+	org := storage.OrgFrom(ctx)
+	webhooks := make([]storage.Webhook, 0)
+	for _, rec := range w.store.webhooks {
+		if rec.org != org {
+			continue
+		}
+		wh := rec.value
+		wh.EventTypes = append([]string{}, wh.EventTypes...)
+		webhooks = append(webhooks, wh)
+	}
+	return webhooks, nil
+}
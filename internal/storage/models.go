@@ -1,4 +1,9 @@
 // Package storage содержит модели данных и интерфейсы репозиториев.
+//
+// Данные принадлежат организации (Organization): репозитории читают и пишут только данные
+// организации из контекста (см. WithOrg). Исключение - выборки фоновых обработчиков
+// (ClaimDue, ClaimDigests, ClaimReminders, ClaimEscalations): они идут по всем
+// организациям, а записи несут OrgID, от имени которого их нужно обработать.
 package storage
 
 import "time"
@@ -11,6 +16,8 @@ const (
 	StatusOpen PRStatus = "OPEN"
 	// StatusMerged - PR смержен.
 	StatusMerged PRStatus = "MERGED"
+	// StatusClosed - PR закрыт на стороне код-хостинга без слияния.
+	StatusClosed PRStatus = "CLOSED"
 )
 
 // User - пользователь, участник команды.
@@ -20,6 +27,8 @@ type User struct {
 	Username  string
 	TeamID    int
 	IsActive  bool
+	// Version растёт при каждом изменении пользователя (оптимистичная блокировка).
+	Version int64
 }
 
 // Team - команда разработчиков.
@@ -28,6 +37,8 @@ type Team struct {
 	CreatedAt time.Time
 	Members   []User
 	ID        int
+	// Version растёт при каждом изменении команды и её настроек.
+	Version int64
 }
 
 // PullRequest - PR с ревьюверами.
@@ -39,4 +50,368 @@ type PullRequest struct {
 	CreatedAt         time.Time
 	MergedAt          *time.Time
 	AssignedReviewers []string
+	// Version растёт при каждом изменении PR, включая смену ревьюеров.
+	Version int64
+}
+
+// AssignmentStrategy - способ, которым был выбран ревьюер.
+type AssignmentStrategy string
+
+const (
+	// StrategyRandom - ревьюер выбран случайно из пула кандидатов.
+	StrategyRandom AssignmentStrategy = "RANDOM"
+	// StrategyWholePool - кандидатов не больше, чем нужно ревьюеров, назначены все.
+	StrategyWholePool AssignmentStrategy = "WHOLE_POOL"
+)
+
+// ExclusionReason - причина, по которой участник команды не попал в пул кандидатов.
+type ExclusionReason string
+
+const (
+	// ExcludedAuthor - участник является автором PR.
+	ExcludedAuthor ExclusionReason = "AUTHOR"
+	// ExcludedInactive - участник неактивен.
+	ExcludedInactive ExclusionReason = "INACTIVE"
+	// ExcludedAlreadyAssigned - участник уже назначен ревьюером на этот PR.
+	ExcludedAlreadyAssigned ExclusionReason = "ALREADY_ASSIGNED"
+	// ExcludedReplaced - участник снимается с ревью при переназначении.
+	ExcludedReplaced ExclusionReason = "REPLACED_REVIEWER"
+)
+
+// ExcludedCandidate - участник команды, исключённый из пула кандидатов.
+type ExcludedCandidate struct {
+	UserID string          `json:"user_id"`
+	Reason ExclusionReason `json:"reason"`
+}
+
+// AssignmentLog - запись о назначении ревьюера: кого, как и из кого выбрали.
+type AssignmentLog struct {
+	CreatedAt time.Time
+	Seed      *int64
+	// Draw - случайный индекс выбранного кандидата; nil, если ревьюер назначен без
+	// розыгрыша (StrategyWholePool).
+	Draw               *int
+	PullRequestID      string
+	ReviewerID         string
+	ReplacedReviewerID string
+	Strategy           AssignmentStrategy
+	Candidates         []string
+	Excluded           []ExcludedCandidate
+	ID                 int64
+	PoolSize           int
+}
+
+// PREventType - тип события в истории PR.
+type PREventType string
+
+const (
+	// EventCreated - PR создан.
+	EventCreated PREventType = "created"
+	// EventReviewerAssigned - ревьюер назначен при создании PR.
+	EventReviewerAssigned PREventType = "reviewer_assigned"
+	// EventReviewerReplaced - ревьюер заменён другим.
+	EventReviewerReplaced PREventType = "reviewer_replaced"
+	// EventReviewOverdue - ревьюер не уложился в SLA команды, ему отправлено напоминание.
+	EventReviewOverdue PREventType = "review_overdue"
+	// EventReviewSubmitted - ревьюер оставил ревью.
+	EventReviewSubmitted PREventType = "review_submitted"
+	// EventMerged - PR смержен.
+	EventMerged PREventType = "merged"
+	// EventClosed - PR закрыт без merge.
+	EventClosed PREventType = "closed"
+	// EventReopened - закрытый PR открыт снова.
+	EventReopened PREventType = "reopened"
+)
+
+// ActorSystem - инициатор события, если вызывающий не известен.
+const ActorSystem = "system"
+
+// PREvent - неизменяемая запись истории PR. Payload - JSON-объект с деталями события.
+type PREvent struct {
+	CreatedAt     time.Time
+	PullRequestID string
+	Type          PREventType
+	Actor         string
+	Payload       []byte
+	ID            int64
+}
+
+// Webhook - подписчик на доменные события. Пустой EventTypes - подписка на все события.
+type Webhook struct {
+	CreatedAt  time.Time
+	URL        string
+	Secret     string
+	EventTypes []string
+	ID         int64
+}
+
+// OutboxEvent - доменное событие, записанное в outbox в транзакции бизнес-операции.
+type OutboxEvent struct {
+	CreatedAt time.Time
+	Type      string
+	Payload   []byte
+	ID        int64
+}
+
+// DeliveryStatus - состояние доставки события подписчику.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending - доставка ожидает очередной попытки.
+	DeliveryPending DeliveryStatus = "PENDING"
+	// DeliveryDelivered - подписчик подтвердил получение (2xx).
+	DeliveryDelivered DeliveryStatus = "DELIVERED"
+	// DeliveryDead - попытки исчерпаны, доставка больше не повторяется (dead letter).
+	DeliveryDead DeliveryStatus = "DEAD"
+)
+
+// WebhookDelivery - доставка одного события одному подписчику.
+type WebhookDelivery struct {
+	NextAttemptAt time.Time
+	UpdatedAt     time.Time
+	Event         OutboxEvent
+	Status        DeliveryStatus
+	URL           string
+	Secret        string
+	LastError     string
+	ID            int64
+	WebhookID     int64
+	Attempts      int
+}
+
+// Провайдеры код-хостинга, от которых принимаются входящие вебхуки.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// InboundSecret - секрет входящих вебхуков провайдера в организации: ключ HMAC-подписи
+// GitHub или токен GitLab. По нему определяется организация доставки, поэтому он
+// хранится открыто (подпись GitHub проверяется самим ключом).
+type InboundSecret struct {
+	CreatedAt time.Time
+	Provider  string
+	Secret    string
+	OrgID     int64
+}
+
+// ReviewerSync - задача передать текущих ревьюеров PR на код-хостинг. Ставится в
+// транзакции операции, которая изменила ревьюеров; Removed - ревьюеры, с которых нужно
+// снять запрос ревью.
+type ReviewerSync struct {
+	NextAttemptAt time.Time
+	UpdatedAt     time.Time
+	Provider      string
+	PullRequestID string
+	LastError     string
+	Status        DeliveryStatus
+	Removed       []string
+	ID            int64
+	OrgID         int64
+	Attempts      int
+}
+
+// NotificationChannel - канал доставки уведомления.
+type NotificationChannel string
+
+const (
+	// ChannelChat - incoming webhook Slack/Mattermost; Target - URL вебхука.
+	ChannelChat NotificationChannel = "chat"
+	// ChannelEmail - письмо; Target - адрес получателя.
+	ChannelEmail NotificationChannel = "email"
+)
+
+// Notification - готовое к отправке уведомление. Текст формируется в транзакции
+// операции, отправка выполняется позже и на результат операции не влияет.
+type Notification struct {
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	UpdatedAt     time.Time
+	Channel       NotificationChannel
+	Target        string
+	Subject       string
+	Body          string
+	LastError     string
+	Status        DeliveryStatus
+	ID            int64
+	Attempts      int
+}
+
+// TeamChat - incoming webhook, в который отправляются уведомления команды.
+type TeamChat struct {
+	UpdatedAt  time.Time
+	WebhookURL string
+	TeamID     int
+}
+
+// EmailMode - как пользователь получает письма о ревью.
+type EmailMode string
+
+const (
+	// EmailImmediate - письмо при каждом назначении, замене и напоминании.
+	EmailImmediate EmailMode = "immediate"
+	// EmailDigest - раз в день сводка открытых PR, ожидающих ревью.
+	EmailDigest EmailMode = "digest"
+	// EmailNone - писем нет.
+	EmailNone EmailMode = "none"
+)
+
+// EmailPreference - адрес и режим писем пользователя.
+type EmailPreference struct {
+	// LastDigestAt - когда пользователю последний раз формировалась сводка
+	// (для новой настройки и при смене режима - момент изменения).
+	LastDigestAt time.Time
+	UpdatedAt    time.Time
+	UserID       string
+	Email        string
+	Mode         EmailMode
+	OrgID        int64
+}
+
+// SLAEscalation - что делать с ревью, просроченным сверх ReviewSLA.EscalateAfter.
+type SLAEscalation string
+
+const (
+	// EscalateNone - только напоминание.
+	EscalateNone SLAEscalation = "none"
+	// EscalateReassign - просроченный ревьюер заменяется другим участником команды.
+	EscalateReassign SLAEscalation = "reassign"
+	// EscalateLead - на PR дополнительно назначается лид команды.
+	EscalateLead SLAEscalation = "lead"
+)
+
+// ReviewSLA - срок ревью команды. Отсчёт идёт от назначения ревьюера
+// (reviews.assigned_at); команда определяется по автору PR.
+type ReviewSLA struct {
+	UpdatedAt time.Time
+	// LeadID - лид команды для EscalateLead.
+	LeadID     string
+	Escalation SLAEscalation
+	TeamID     int
+	// Within - срок ревью; после него ревьюеру отправляется напоминание.
+	Within time.Duration
+	// EscalateAfter - через сколько после назначения ревью эскалируется; больше Within.
+	EscalateAfter time.Duration
+}
+
+// ReviewState - назначение ревьюера на PR с отметками о напоминании и эскалации.
+type ReviewState struct {
+	AssignedAt    time.Time
+	RemindedAt    *time.Time
+	EscalatedAt   *time.Time
+	PullRequestID string
+	ReviewerID    string
+	OrgID         int64
+}
+
+// OverdueReview - ревью открытого PR, просроченное по SLA команды автора.
+type OverdueReview struct {
+	SLA ReviewSLA
+	ReviewState
+}
+
+// TokenScope - право API-токена.
+type TokenScope string
+
+const (
+	// ScopeRead - чтение команд, пользователей и PR.
+	ScopeRead TokenScope = "read"
+	// ScopePRWrite - создание, merge и переназначение ревьюеров PR.
+	ScopePRWrite TokenScope = "pr:write"
+	// ScopeTeamAdmin - управление командами, участниками и их настройками.
+	ScopeTeamAdmin TokenScope = "team:admin"
+	// ScopeStatsRead - статистика назначений.
+	ScopeStatsRead TokenScope = "stats:read"
+	// ScopeAdmin - все права, включая вебхуки, очередь уведомлений и выпуск токенов.
+	ScopeAdmin TokenScope = "admin"
+)
+
+// TokenScopes - все права API-токенов.
+var TokenScopes = []TokenScope{ScopeRead, ScopePRWrite, ScopeTeamAdmin, ScopeStatsRead, ScopeAdmin}
+
+// APIToken - API-токен. Секрет не хранится, только его SHA-256 (Hash).
+type APIToken struct {
+	CreatedAt time.Time
+	// RevokedAt - когда токен отозван; nil - действует.
+	RevokedAt *time.Time
+	Name      string
+	Hash      string
+	Scopes    []TokenScope
+	ID        int64
+	// OrgID - организация, к данным которой токен даёт доступ.
+	OrgID int64
+}
+
+// AuditAction - административное действие в журнале аудита.
+type AuditAction string
+
+const (
+	// AuditTeamCreate - команда создана, участники из других команд перешли в неё.
+	AuditTeamCreate AuditAction = "team.create"
+	// AuditUserSetActive - изменена активность пользователя.
+	AuditUserSetActive AuditAction = "user.set_active"
+	// AuditTeamSLASet - задан срок ревью команды.
+	AuditTeamSLASet AuditAction = "team.sla.set"
+	// AuditTeamSLADelete - срок ревью снят с команды.
+	AuditTeamSLADelete AuditAction = "team.sla.delete"
+	// AuditTeamChatSet - команда подключена к чату или сменила вебхук чата.
+	AuditTeamChatSet AuditAction = "team.chat.set"
+	// AuditTeamChatDelete - чат-уведомления команды отключены.
+	AuditTeamChatDelete AuditAction = "team.chat.delete"
+	// AuditUserEmailSet - изменены адрес или режим писем пользователя.
+	AuditUserEmailSet AuditAction = "user.email.set"
+	// AuditInboundSecretRotate - выпущен новый секрет входящих вебхуков провайдера.
+	AuditInboundSecretRotate AuditAction = "integration.secret.rotate"
+	// AuditInboundSecretDelete - секрет входящих вебхуков провайдера удалён.
+	AuditInboundSecretDelete AuditAction = "integration.secret.delete"
+)
+
+// AuditEntry - запись журнала административных действий. Before и After - JSON-снимки
+// объекта до и после изменения; nil - объекта не было (или не стало).
+type AuditEntry struct {
+	CreatedAt time.Time
+	Action    AuditAction
+	// Actor - инициатор в формате истории PR: user_id, "token:<имя>" или ActorSystem.
+	Actor string
+	// RequestID и SourceIP - HTTP-запрос, в котором выполнено действие; пусто для
+	// фоновых обработчиков.
+	RequestID  string
+	SourceIP   string
+	TargetType string
+	TargetID   string
+	Before     []byte
+	After      []byte
+	ID         int64
+}
+
+// AuditFilter - выборка из журнала аудита. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	// Since и Until - полуинтервал [Since, Until) по времени записи.
+	Since      time.Time
+	Until      time.Time
+	Actor      string
+	Action     AuditAction
+	TargetType string
+	TargetID   string
+	// BeforeID - курсор страницы: только записи с id меньше BeforeID.
+	BeforeID int64
+	Limit    int
+}
+
+// IdempotencyRecord - запрос с ключом идемпотентности (заголовок Idempotency-Key) и ответ
+// на него. Ключ уникален в пределах организации и клиента.
+type IdempotencyRecord struct {
+	CreatedAt time.Time
+	// ExpiresAt - после этого момента ключ можно использовать для нового запроса.
+	ExpiresAt time.Time
+	// Client - вызывающий, которому принадлежит ключ (API-токен, пользователь или адрес).
+	Client string
+	Key    string
+	// Fingerprint - хеш метода, пути и тела запроса.
+	Fingerprint string
+	// Headers - заголовки ответа, которые повторяются вместе с ним (ETag, Location).
+	Headers  map[string]string
+	Response []byte
+	// Status - HTTP-статус ответа; 0 - запрос ещё выполняется.
+	Status int
 }
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// APITokenRepository - репозиторий API-токенов в Postgres.
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPITokenRepository создаёт экземпляр *APITokenRepository.
+func NewAPITokenRepository(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{pool: pool}
+}
+
+// Create сохраняет токен организации из контекста и возвращает его с присвоенным id.
+func (a *APITokenRepository) Create(ctx context.Context, token storage.APIToken) (storage.APIToken, *apperrors.AppError) {
+	const query = `
+		INSERT INTO api_tokens (org_id, name, token_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	token.OrgID = storage.OrgFrom(ctx)
+	err := conn(ctx, a.pool).QueryRow(ctx, query, token.OrgID, token.Name, token.Hash, scopesToStrings(token.Scopes)).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		log.Printf("insert api token failed: %v", err)
+		return storage.APIToken{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	token.RevokedAt = nil
+	return token, nil
+}
+
+// GetByHash возвращает токен по хешу секрета в любой организации: по токену
+// организация запроса и определяется.
+func (a *APITokenRepository) GetByHash(ctx context.Context, hash string) (storage.APIToken, *apperrors.AppError) {
+	const query = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+
+	token, err := scanAPIToken(conn(ctx, a.pool).QueryRow(ctx, query, hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.APIToken{}, &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query api token failed: %v", err)
+		return storage.APIToken{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return token, nil
+}
+
+// List возвращает токены организации в порядке выпуска.
+func (a *APITokenRepository) List(ctx context.Context) ([]storage.APIToken, *apperrors.AppError) {
+	const query = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE org_id = $1 ORDER BY id`
+
+	rows, err := conn(ctx, a.pool).Query(ctx, query, storage.OrgFrom(ctx))
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	tokens := make([]storage.APIToken, 0)
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен.
+func (a *APITokenRepository) Revoke(ctx context.Context, tokenID int64) *apperrors.AppError {
+	const query = `UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, NOW()) WHERE org_id = $1 AND id = $2`
+
+	tag, err := conn(ctx, a.pool).Exec(ctx, query, storage.OrgFrom(ctx), tokenID)
+	if err != nil {
+		log.Printf("revoke api token failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if tag.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+const apiTokenColumns = `id, org_id, name, token_hash, scopes, created_at, revoked_at`
+
+func scanAPIToken(row pgx.Row) (storage.APIToken, error) {
+	var token storage.APIToken
+	var scopes []string
+	if err := row.Scan(&token.ID, &token.OrgID, &token.Name, &token.Hash, &scopes, &token.CreatedAt, &token.RevokedAt); err != nil {
+		return storage.APIToken{}, err
+	}
+	token.Scopes = make([]storage.TokenScope, 0, len(scopes))
+	for _, s := range scopes {
+		token.Scopes = append(token.Scopes, storage.TokenScope(s))
+	}
+	return token, nil
+}
+
+func scopesToStrings(scopes []storage.TokenScope) []string {
+	res := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		res = append(res, string(s))
+	}
+	return res
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// AssignmentLogRepository - репозиторий журнала назначений ревьюеров в Postgres.
+type AssignmentLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAssignmentLogRepository создаёт экземпляр *AssignmentLogRepository.
+func NewAssignmentLogRepository(pool *pgxpool.Pool) *AssignmentLogRepository {
+	return &AssignmentLogRepository{pool: pool}
+}
+
+// Add сохраняет записи о назначениях одной транзакцией.
+func (a *AssignmentLogRepository) Add(ctx context.Context, entries []storage.AssignmentLog) *apperrors.AppError {
+	const query = `
+		INSERT INTO assignment_log
+			(org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy, pool_size, candidates, excluded, draw, seed)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10)
+	`
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, a.pool, func(ctx context.Context) error {
+		for _, e := range entries {
+			candidates := e.Candidates
+			if candidates == nil {
+				candidates = []string{}
+			}
+			excluded := e.Excluded
+			if excluded == nil {
+				excluded = []storage.ExcludedCandidate{}
+			}
+
+			_, err := conn(ctx, a.pool).Exec(ctx, query, orgID, e.PullRequestID, e.ReviewerID, e.ReplacedReviewerID,
+				e.Strategy, e.PoolSize, candidates, excluded, e.Draw, e.Seed)
+			if err != nil {
+				return fmt.Errorf("insert assignment log failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByPR возвращает журнал назначений по pr в хронологическом порядке.
+func (a *AssignmentLogRepository) GetByPR(ctx context.Context, prID string) ([]storage.AssignmentLog, *apperrors.AppError) {
+	const query = `
+		SELECT id, pull_request_id, reviewer_id, COALESCE(replaced_reviewer_id, ''), strategy,
+			pool_size, candidates, excluded, draw, seed, created_at
+		FROM assignment_log
+		WHERE org_id = $1 AND pull_request_id = $2
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, a.pool).Query(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	entries := make([]storage.AssignmentLog, 0)
+	for rows.Next() {
+		var e storage.AssignmentLog
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.ReviewerID, &e.ReplacedReviewerID, &e.Strategy,
+			&e.PoolSize, &e.Candidates, &e.Excluded, &e.Draw, &e.Seed, &e.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// AuditLogRepository - репозиторий журнала аудита в Postgres.
+type AuditLogRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditLogRepository создаёт экземпляр *AuditLogRepository.
+func NewAuditLogRepository(pool *pgxpool.Pool) *AuditLogRepository {
+	return &AuditLogRepository{pool: pool}
+}
+
+// Add дописывает запись в журнал организации из контекста.
+func (a *AuditLogRepository) Add(ctx context.Context, entry storage.AuditEntry) *apperrors.AppError {
+	const query = `
+		INSERT INTO audit_log (org_id, action, actor, request_id, source_ip, target_type, target_id,
+			before_state, after_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := conn(ctx, a.pool).Exec(ctx, query, storage.OrgFrom(ctx), entry.Action, entry.Actor,
+		entry.RequestID, entry.SourceIP, entry.TargetType, entry.TargetID, nullJSON(entry.Before), nullJSON(entry.After))
+	if err != nil {
+		log.Printf("insert audit entry failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return nil
+}
+
+// List возвращает записи организации по фильтру, новые первыми.
+func (a *AuditLogRepository) List(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, *apperrors.AppError) {
+	const query = `
+		SELECT id, action, actor, request_id, source_ip, target_type, target_id,
+			before_state, after_state, created_at
+		FROM audit_log
+		WHERE org_id = $1
+			AND ($2::BIGINT = 0 OR id < $2)
+			AND ($3::TIMESTAMPTZ IS NULL OR created_at >= $3)
+			AND ($4::TIMESTAMPTZ IS NULL OR created_at < $4)
+			AND ($5 = '' OR actor = $5)
+			AND ($6 = '' OR action = $6)
+			AND ($7 = '' OR target_type = $7)
+			AND ($8 = '' OR target_id = $8)
+		ORDER BY id DESC
+		LIMIT $9
+	`
+
+	rows, err := conn(ctx, a.pool).Query(ctx, query, storage.OrgFrom(ctx), filter.BeforeID,
+		nullTime(filter.Since), nullTime(filter.Until), filter.Actor, string(filter.Action),
+		filter.TargetType, filter.TargetID, filter.Limit)
+	if err != nil {
+		log.Printf("query audit log failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	entries := make([]storage.AuditEntry, 0)
+	for rows.Next() {
+		var e storage.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Action, &e.Actor, &e.RequestID, &e.SourceIP, &e.TargetType, &e.TargetID,
+			&e.Before, &e.After, &e.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return entries, nil
+}
+
+// DeleteBefore удаляет записи всех организаций старше cutoff.
+func (a *AuditLogRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, *apperrors.AppError) {
+	const query = `DELETE FROM audit_log WHERE created_at < $1`
+
+	tag, err := conn(ctx, a.pool).Exec(ctx, query, cutoff)
+	if err != nil {
+		log.Printf("delete audit entries failed: %v", err)
+		return 0, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return tag.RowsAffected(), nil
+}
+
+// nullJSON возвращает nil (NULL) для пустого снимка.
+func nullJSON(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// nullTime возвращает nil (NULL) для нулевого t.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
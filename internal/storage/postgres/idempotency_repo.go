@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// IdempotencyRepository - репозиторий ключей идемпотентности в Postgres.
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIdempotencyRepository создаёт экземпляр *IdempotencyRepository.
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+// Claim сохраняет незавершённую запись ключа, если у него нет действующей записи.
+// Одновременные запросы с одним ключом разрешает ON CONFLICT: запись получает один из них.
+func (i *IdempotencyRepository) Claim(ctx context.Context, rec storage.IdempotencyRecord, now time.Time) (storage.IdempotencyRecord, bool, *apperrors.AppError) {
+	const claimQuery = `
+		INSERT INTO idempotency_keys (org_id, client, idempotency_key, fingerprint, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (org_id, client, idempotency_key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status = 0,
+			response = NULL,
+			response_headers = NULL,
+			created_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $6
+		RETURNING created_at
+	`
+	const existingQuery = `
+		SELECT fingerprint, status, response_headers, response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE org_id = $1 AND client = $2 AND idempotency_key = $3
+	`
+
+	org := storage.OrgFrom(ctx)
+	err := conn(ctx, i.pool).QueryRow(ctx, claimQuery, org, rec.Client, rec.Key, rec.Fingerprint, rec.ExpiresAt, now).
+		Scan(&rec.CreatedAt)
+	if err == nil {
+		rec.Status = 0
+		rec.Headers = nil
+		rec.Response = nil
+		return rec, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("claim idempotency key failed: %v", err)
+		return storage.IdempotencyRecord{}, false, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+
+	existing := storage.IdempotencyRecord{Client: rec.Client, Key: rec.Key}
+	err = conn(ctx, i.pool).QueryRow(ctx, existingQuery, org, rec.Client, rec.Key).
+		Scan(&existing.Fingerprint, &existing.Status, &existing.Headers, &existing.Response, &existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		log.Printf("query idempotency key failed: %v", err)
+		return storage.IdempotencyRecord{}, false, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return existing, false, nil
+}
+
+// Complete сохраняет ответ на запрос с ключом.
+func (i *IdempotencyRepository) Complete(ctx context.Context, client, key string, status int, headers map[string]string, response []byte) *apperrors.AppError {
+	const query = `
+		UPDATE idempotency_keys SET status = $4, response_headers = $5, response = $6
+		WHERE org_id = $1 AND client = $2 AND idempotency_key = $3
+	`
+
+	tag, err := conn(ctx, i.pool).Exec(ctx, query, storage.OrgFrom(ctx), client, key, status, headers, response)
+	if err != nil {
+		log.Printf("complete idempotency key failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if tag.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// Release удаляет незавершённую запись ключа.
+func (i *IdempotencyRepository) Release(ctx context.Context, client, key string) *apperrors.AppError {
+	const query = `
+		DELETE FROM idempotency_keys
+		WHERE org_id = $1 AND client = $2 AND idempotency_key = $3 AND status = 0
+	`
+
+	if _, err := conn(ctx, i.pool).Exec(ctx, query, storage.OrgFrom(ctx), client, key); err != nil {
+		log.Printf("release idempotency key failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return nil
+}
+
+// DeleteExpired удаляет просроченные записи всех организаций.
+func (i *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, *apperrors.AppError) {
+	const query = `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+
+	tag, err := conn(ctx, i.pool).Exec(ctx, query, now)
+	if err != nil {
+		log.Printf("delete expired idempotency keys failed: %v", err)
+		return 0, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return tag.RowsAffected(), nil
+}
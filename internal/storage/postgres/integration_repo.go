@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// IntegrationRepository - данные интеграций с код-хостингами в Postgres.
+type IntegrationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewIntegrationRepository создаёт экземпляр *IntegrationRepository.
+func NewIntegrationRepository(pool *pgxpool.Pool) *IntegrationRepository {
+	return &IntegrationRepository{pool: pool}
+}
+
+// LinkIdentity связывает логин провайдера с пользователем; существующая связь перезаписывается.
+func (i *IntegrationRepository) LinkIdentity(ctx context.Context, provider, login, userID string) *apperrors.AppError {
+	const query = `
+		INSERT INTO user_identities (org_id, provider, login, user_id) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id, provider, login) DO UPDATE SET user_id = EXCLUDED.user_id
+	`
+
+	if _, err := conn(ctx, i.pool).Exec(ctx, query, storage.OrgFrom(ctx), provider, login, userID); err != nil {
+		log.Printf("upsert identity failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return nil
+}
+
+// ResolveIdentity возвращает user_id, связанный с логином провайдера.
+func (i *IntegrationRepository) ResolveIdentity(ctx context.Context, provider, login string) (string, *apperrors.AppError) {
+	const query = `SELECT user_id FROM user_identities WHERE org_id = $1 AND provider = $2 AND login = $3`
+
+	var userID string
+	err := conn(ctx, i.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), provider, login).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query identity failed: %v", err)
+		return "", &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return userID, nil
+}
+
+// IdentityLogin возвращает логин провайдера, связанный с пользователем.
+func (i *IntegrationRepository) IdentityLogin(ctx context.Context, provider, userID string) (string, *apperrors.AppError) {
+	const query = `
+		SELECT login FROM user_identities
+		WHERE org_id = $1 AND provider = $2 AND user_id = $3
+		ORDER BY login LIMIT 1
+	`
+
+	var login string
+	err := conn(ctx, i.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), provider, userID).Scan(&login)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query identity login failed: %v", err)
+		return "", &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return login, nil
+}
+
+// RecordDelivery запоминает входящую доставку и возвращает false, если она уже была
+// обработана. Вызывается в транзакции обработки, поэтому при её откате запись исчезает
+// и повторная доставка будет обработана заново.
+func (i *IntegrationRepository) RecordDelivery(ctx context.Context, provider, deliveryID string) (bool, *apperrors.AppError) {
+	const query = `
+		INSERT INTO inbound_deliveries (org_id, provider, delivery_id) VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, provider, delivery_id) DO NOTHING
+	`
+
+	ct, err := conn(ctx, i.pool).Exec(ctx, query, storage.OrgFrom(ctx), provider, deliveryID)
+	if err != nil {
+		log.Printf("insert delivery failed: %v", err)
+		return false, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return ct.RowsAffected() == 1, nil
+}
+
+// SetInboundSecret создаёт или заменяет секрет провайдера организации.
+func (i *IntegrationRepository) SetInboundSecret(ctx context.Context, provider, secret string) *apperrors.AppError {
+	const query = `
+		INSERT INTO inbound_secrets (org_id, provider, secret) VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, provider) DO UPDATE SET secret = EXCLUDED.secret, created_at = NOW()
+	`
+
+	if _, err := conn(ctx, i.pool).Exec(ctx, query, storage.OrgFrom(ctx), provider, secret); err != nil {
+		log.Printf("upsert inbound secret failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return nil
+}
+
+// GetInboundSecret возвращает секрет провайдера организации.
+func (i *IntegrationRepository) GetInboundSecret(ctx context.Context, provider string) (storage.InboundSecret, *apperrors.AppError) {
+	const query = `
+		SELECT org_id, provider, secret, created_at FROM inbound_secrets
+		WHERE org_id = $1 AND provider = $2
+	`
+
+	var s storage.InboundSecret
+	err := conn(ctx, i.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), provider).Scan(&s.OrgID, &s.Provider, &s.Secret, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.InboundSecret{}, &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query inbound secret failed: %v", err)
+		return storage.InboundSecret{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return s, nil
+}
+
+// DeleteInboundSecret удаляет секрет провайдера организации.
+func (i *IntegrationRepository) DeleteInboundSecret(ctx context.Context, provider string) *apperrors.AppError {
+	const query = `DELETE FROM inbound_secrets WHERE org_id = $1 AND provider = $2`
+
+	ct, err := conn(ctx, i.pool).Exec(ctx, query, storage.OrgFrom(ctx), provider)
+	if err != nil {
+		log.Printf("delete inbound secret failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// InboundSecrets возвращает секреты провайдера всех организаций.
+func (i *IntegrationRepository) InboundSecrets(ctx context.Context, provider string) ([]storage.InboundSecret, *apperrors.AppError) {
+	const query = `
+		SELECT org_id, provider, secret, created_at FROM inbound_secrets
+		WHERE provider = $1
+		ORDER BY org_id
+	`
+
+	rows, err := conn(ctx, i.pool).Query(ctx, query, provider)
+	if err != nil {
+		log.Printf("query inbound secrets failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	secrets := make([]storage.InboundSecret, 0)
+	for rows.Next() {
+		var s storage.InboundSecret
+		if err := rows.Scan(&s.OrgID, &s.Provider, &s.Secret, &s.CreatedAt); err != nil {
+			log.Printf("scan inbound secret failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		secrets = append(secrets, s)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("iterate inbound secrets failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return secrets, nil
+}
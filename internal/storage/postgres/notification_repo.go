@@ -0,0 +1,312 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// NotificationRepository - настройки чат-уведомлений и очередь уведомлений в Postgres.
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationRepository создаёт экземпляр *NotificationRepository.
+func NewNotificationRepository(pool *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{pool: pool}
+}
+
+const notificationColumns = `
+	id, channel, target, subject, body, status, attempts, next_attempt_at, last_error, created_at, updated_at
+`
+
+// SetTeamChat задаёт вебхук команды; существующая настройка перезаписывается. Команда
+// другой организации - NOT_FOUND.
+func (n *NotificationRepository) SetTeamChat(ctx context.Context, chat storage.TeamChat) *apperrors.AppError {
+	const query = `
+		INSERT INTO team_chats (team_id, webhook_url)
+		SELECT id, $3 FROM teams WHERE org_id = $1 AND id = $2
+		ON CONFLICT (team_id) DO UPDATE SET webhook_url = EXCLUDED.webhook_url, updated_at = NOW()
+	`
+
+	return n.update(ctx, query, storage.OrgFrom(ctx), chat.TeamID, chat.WebhookURL)
+}
+
+// GetTeamChat возвращает вебхук команды.
+func (n *NotificationRepository) GetTeamChat(ctx context.Context, teamID int) (storage.TeamChat, *apperrors.AppError) {
+	const query = `
+		SELECT c.team_id, c.webhook_url, c.updated_at
+		FROM team_chats c JOIN teams t ON t.id = c.team_id
+		WHERE t.org_id = $1 AND c.team_id = $2
+	`
+
+	var chat storage.TeamChat
+	err := conn(ctx, n.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), teamID).Scan(&chat.TeamID, &chat.WebhookURL, &chat.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.TeamChat{}, &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query team chat failed: %v", err)
+		return storage.TeamChat{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return chat, nil
+}
+
+// DeleteTeamChat отключает чат-уведомления команды.
+func (n *NotificationRepository) DeleteTeamChat(ctx context.Context, teamID int) *apperrors.AppError {
+	const query = `
+		DELETE FROM team_chats
+		WHERE team_id = $2 AND EXISTS (SELECT 1 FROM teams WHERE org_id = $1 AND id = $2)
+	`
+	return n.update(ctx, query, storage.OrgFrom(ctx), teamID)
+}
+
+// Enqueue ставит уведомления в очередь; они готовы к отправке сразу.
+func (n *NotificationRepository) Enqueue(ctx context.Context, notifications []storage.Notification) *apperrors.AppError {
+	const query = `
+		INSERT INTO notifications (org_id, channel, target, subject, body) VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, n.pool, func(ctx context.Context) error {
+		for _, nt := range notifications {
+			if _, err := conn(ctx, n.pool).Exec(ctx, query, orgID, nt.Channel, nt.Target, nt.Subject, nt.Body); err != nil {
+				return fmt.Errorf("insert notification failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDue выдаёт до limit уведомлений всех организаций, которым пора отправляться.
+// Строки, занятые другим обработчиком, пропускаются (SKIP LOCKED).
+func (n *NotificationRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.Notification, *apperrors.AppError) {
+	const query = `
+		UPDATE notifications
+		SET attempts = attempts + 1, next_attempt_at = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationColumns
+
+	rows, err := conn(ctx, n.pool).Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		log.Printf("claim notifications failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	notifications, appErr := scanNotifications(rows)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// RETURNING не гарантирует порядок подзапроса.
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].ID < notifications[j].ID })
+	return notifications, nil
+}
+
+// MarkDone помечает уведомление как отправленное.
+func (n *NotificationRepository) MarkDone(ctx context.Context, notificationID int64) *apperrors.AppError {
+	const query = `
+		UPDATE notifications
+		SET status = 'DELIVERED', last_error = '', updated_at = NOW()
+		WHERE id = $1
+	`
+	return n.update(ctx, query, notificationID)
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит уведомление в DEAD.
+func (n *NotificationRepository) MarkFailed(ctx context.Context, notificationID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	const query = `
+		UPDATE notifications
+		SET status = CASE WHEN $4 THEN 'DEAD' ELSE 'PENDING' END,
+			next_attempt_at = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	return n.update(ctx, query, notificationID, nextAttemptAt, lastErr, dead)
+}
+
+func (n *NotificationRepository) update(ctx context.Context, query string, args ...any) *apperrors.AppError {
+	ct, err := conn(ctx, n.pool).Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("update notification failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// List возвращает последние уведомления организации, новые первыми. Пустой status означает «без фильтра».
+func (n *NotificationRepository) List(ctx context.Context, status storage.DeliveryStatus, limit int) ([]storage.Notification, *apperrors.AppError) {
+	const query = `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE org_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := conn(ctx, n.pool).Query(ctx, query, storage.OrgFrom(ctx), string(status), limit)
+	if err != nil {
+		log.Printf("query notifications failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return scanNotifications(rows)
+}
+
+func scanNotifications(rows pgx.Rows) ([]storage.Notification, *apperrors.AppError) {
+	defer rows.Close()
+
+	notifications := make([]storage.Notification, 0)
+	for rows.Next() {
+		var nt storage.Notification
+		if err := rows.Scan(&nt.ID, &nt.Channel, &nt.Target, &nt.Subject, &nt.Body, &nt.Status, &nt.Attempts,
+			&nt.NextAttemptAt, &nt.LastError, &nt.CreatedAt, &nt.UpdatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		notifications = append(notifications, nt)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return notifications, nil
+}
+
+// SetEmailPreference создаёт или заменяет настройку писем пользователя. Пользователь
+// другой организации - NOT_FOUND.
+func (n *NotificationRepository) SetEmailPreference(ctx context.Context, pref storage.EmailPreference) *apperrors.AppError {
+	const query = `
+		INSERT INTO email_preferences (org_id, user_id, email, mode)
+		SELECT org_id, user_id, $3, $4 FROM users WHERE org_id = $1 AND user_id = $2
+		ON CONFLICT (org_id, user_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			mode = EXCLUDED.mode,
+			last_digest_at = CASE WHEN email_preferences.mode <> EXCLUDED.mode
+				THEN NOW() ELSE email_preferences.last_digest_at END,
+			updated_at = NOW()
+	`
+
+	return n.update(ctx, query, storage.OrgFrom(ctx), pref.UserID, pref.Email, pref.Mode)
+}
+
+// GetEmailPreference возвращает настройку писем пользователя.
+func (n *NotificationRepository) GetEmailPreference(ctx context.Context, userID string) (storage.EmailPreference, *apperrors.AppError) {
+	const query = `
+		SELECT user_id, org_id, email, mode, last_digest_at, updated_at
+		FROM email_preferences
+		WHERE org_id = $1 AND user_id = $2
+	`
+
+	var pref storage.EmailPreference
+	err := conn(ctx, n.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), userID).
+		Scan(&pref.UserID, &pref.OrgID, &pref.Email, &pref.Mode, &pref.LastDigestAt, &pref.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.EmailPreference{}, &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query email preference failed: %v", err)
+		return storage.EmailPreference{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return pref, nil
+}
+
+// ClaimDigests отмечает сводку отправленной для пользователей всех организаций, которым
+// она положена. Строки, занятые другим экземпляром, пропускаются (SKIP LOCKED).
+func (n *NotificationRepository) ClaimDigests(ctx context.Context, cutoff, now time.Time, limit int) ([]storage.EmailPreference, *apperrors.AppError) {
+	const query = `
+		UPDATE email_preferences
+		SET last_digest_at = $2
+		WHERE (org_id, user_id) IN (
+			SELECT org_id, user_id FROM email_preferences
+			WHERE mode = 'digest' AND last_digest_at < $1
+			ORDER BY org_id, user_id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING user_id, org_id, email, mode, last_digest_at, updated_at
+	`
+
+	rows, err := conn(ctx, n.pool).Query(ctx, query, cutoff, now, limit)
+	if err != nil {
+		log.Printf("claim digests failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	prefs := make([]storage.EmailPreference, 0)
+	for rows.Next() {
+		var pref storage.EmailPreference
+		if err := rows.Scan(&pref.UserID, &pref.OrgID, &pref.Email, &pref.Mode, &pref.LastDigestAt, &pref.UpdatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		prefs = append(prefs, pref)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+
+	// RETURNING не гарантирует порядок подзапроса.
+	sort.Slice(prefs, func(i, j int) bool { return prefs[i].UserID < prefs[j].UserID })
+	return prefs, nil
+}
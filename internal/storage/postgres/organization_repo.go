@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OrganizationRepository - репозиторий организаций в Postgres.
+type OrganizationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOrganizationRepository создаёт экземпляр *OrganizationRepository.
+func NewOrganizationRepository(pool *pgxpool.Pool) *OrganizationRepository {
+	return &OrganizationRepository{pool: pool}
+}
+
+// Create сохраняет организацию и возвращает её с присвоенным id.
+func (o *OrganizationRepository) Create(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	const query = `INSERT INTO organizations (name) VALUES ($1) RETURNING id, name, created_at`
+
+	var org storage.Organization
+	err := conn(ctx, o.pool).QueryRow(ctx, query, name).Scan(&org.ID, &org.Name, &org.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return storage.Organization{}, &apperrors.AppError{
+				Code:    apperrors.ErrOrgExists,
+				Message: apperrors.FromCode(apperrors.ErrOrgExists),
+			}
+		}
+		log.Printf("insert organization failed: %v", err)
+		return storage.Organization{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return org, nil
+}
+
+// GetByName возвращает организацию по имени.
+func (o *OrganizationRepository) GetByName(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	const query = `SELECT id, name, created_at FROM organizations WHERE name = $1`
+
+	var org storage.Organization
+	err := conn(ctx, o.pool).QueryRow(ctx, query, name).Scan(&org.ID, &org.Name, &org.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.Organization{}, &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query organization failed: %v", err)
+		return storage.Organization{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return org, nil
+}
+
+// List возвращает все организации в порядке создания.
+func (o *OrganizationRepository) List(ctx context.Context) ([]storage.Organization, *apperrors.AppError) {
+	const query = `SELECT id, name, created_at FROM organizations ORDER BY id`
+
+	rows, err := conn(ctx, o.pool).Query(ctx, query)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	orgs := make([]storage.Organization, 0)
+	for rows.Next() {
+		var org storage.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		orgs = append(orgs, org)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return orgs, nil
+}
@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OutboxRepository - outbox доменных событий и очередь доставок вебхуков в Postgres.
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository создаёт экземпляр *OutboxRepository.
+func NewOutboxRepository(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+// Add записывает события и создаёт доставки для подписчиков той же организации одной
+// транзакцией.
+func (o *OutboxRepository) Add(ctx context.Context, events []storage.OutboxEvent) *apperrors.AppError {
+	const eventInsert = `INSERT INTO outbox_events (org_id, event_type, payload) VALUES ($1, $2, $3) RETURNING id`
+	const deliveriesInsert = `
+		INSERT INTO webhook_deliveries (event_id, webhook_id)
+		SELECT $1, id FROM webhooks
+		WHERE org_id = $2 AND (cardinality(event_types) = 0 OR $3 = ANY(event_types))
+	`
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, o.pool, func(ctx context.Context) error {
+		for _, ev := range events {
+			payload := ev.Payload
+			if len(payload) == 0 {
+				payload = []byte("{}")
+			}
+
+			var id int64
+			if err := conn(ctx, o.pool).QueryRow(ctx, eventInsert, orgID, ev.Type, payload).Scan(&id); err != nil {
+				return fmt.Errorf("insert outbox event failed: %w", err)
+			}
+			if _, err := conn(ctx, o.pool).Exec(ctx, deliveriesInsert, id, orgID, ev.Type); err != nil {
+				return fmt.Errorf("insert webhook deliveries failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDue выдаёт до limit доставок всех организаций, которым пора отправляться. Строки,
+// занятые другим обработчиком, пропускаются (SKIP LOCKED).
+func (o *OutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	const query = `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = $2, updated_at = NOW()
+		FROM outbox_events e, webhooks w
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		AND e.id = d.event_id AND w.id = d.webhook_id
+		RETURNING d.id, d.webhook_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.updated_at,
+			e.id, e.event_type, e.payload, e.created_at, w.url, w.secret
+	`
+
+	rows, err := conn(ctx, o.pool).Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		log.Printf("claim deliveries failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	deliveries, appErr := scanDeliveries(rows)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// RETURNING не гарантирует порядок подзапроса.
+	sortDeliveries(deliveries)
+	return deliveries, nil
+}
+
+// MarkDelivered помечает доставку как успешную.
+func (o *OutboxRepository) MarkDelivered(ctx context.Context, deliveryID int64) *apperrors.AppError {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'DELIVERED', last_error = '', updated_at = NOW()
+		WHERE id = $1
+	`
+	return o.update(ctx, query, deliveryID)
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит доставку в DEAD.
+func (o *OutboxRepository) MarkFailed(ctx context.Context, deliveryID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $4 THEN 'DEAD' ELSE 'PENDING' END,
+			next_attempt_at = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	return o.update(ctx, query, deliveryID, nextAttemptAt, lastErr, dead)
+}
+
+func (o *OutboxRepository) update(ctx context.Context, query string, args ...any) *apperrors.AppError {
+	ct, err := conn(ctx, o.pool).Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("update delivery failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки организации, новые первыми. webhookID = 0 и пустой
+// status означают «без фильтра».
+func (o *OutboxRepository) ListDeliveries(ctx context.Context, webhookID int64, status storage.DeliveryStatus, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	const query = `
+		SELECT d.id, d.webhook_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.updated_at,
+			e.id, e.event_type, e.payload, e.created_at, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.event_id
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE w.org_id = $1 AND ($2 = 0 OR d.webhook_id = $2) AND ($3 = '' OR d.status = $3)
+		ORDER BY d.id DESC
+		LIMIT $4
+	`
+
+	rows, err := conn(ctx, o.pool).Query(ctx, query, storage.OrgFrom(ctx), webhookID, string(status), limit)
+	if err != nil {
+		log.Printf("query deliveries failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return scanDeliveries(rows)
+}
+
+func scanDeliveries(rows pgx.Rows) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	defer rows.Close()
+
+	deliveries := make([]storage.WebhookDelivery, 0)
+	for rows.Next() {
+		var d storage.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastError, &d.UpdatedAt,
+			&d.Event.ID, &d.Event.Type, &d.Event.Payload, &d.Event.CreatedAt, &d.URL, &d.Secret); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return deliveries, nil
+}
+
+func sortDeliveries(deliveries []storage.WebhookDelivery) {
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+}
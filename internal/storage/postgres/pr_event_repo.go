@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// PREventRepository - репозиторий истории событий PR в Postgres. Таблица pr_events
+// защищена триггером от UPDATE и DELETE.
+type PREventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPREventRepository создаёт экземпляр *PREventRepository.
+func NewPREventRepository(pool *pgxpool.Pool) *PREventRepository {
+	return &PREventRepository{pool: pool}
+}
+
+// Add дописывает события в историю одной транзакцией.
+func (e *PREventRepository) Add(ctx context.Context, events []storage.PREvent) *apperrors.AppError {
+	const query = `
+		INSERT INTO pr_events (org_id, pull_request_id, event_type, actor, payload)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, e.pool, func(ctx context.Context) error {
+		for _, ev := range events {
+			payload := ev.Payload
+			if len(payload) == 0 {
+				payload = []byte("{}")
+			}
+
+			if _, err := conn(ctx, e.pool).Exec(ctx, query, orgID, ev.PullRequestID, ev.Type, ev.Actor, payload); err != nil {
+				return fmt.Errorf("insert pr event failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByPR возвращает историю pr в хронологическом порядке.
+func (e *PREventRepository) GetByPR(ctx context.Context, prID string) ([]storage.PREvent, *apperrors.AppError) {
+	const query = `
+		SELECT id, pull_request_id, event_type, actor, payload, created_at
+		FROM pr_events
+		WHERE org_id = $1 AND pull_request_id = $2
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, e.pool).Query(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	events := make([]storage.PREvent, 0)
+	for rows.Next() {
+		var ev storage.PREvent
+		if err := rows.Scan(&ev.ID, &ev.PullRequestID, &ev.Type, &ev.Actor, &ev.Payload, &ev.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		events = append(events, ev)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return events, nil
+}
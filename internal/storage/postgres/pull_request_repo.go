@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/jackc/pgx/v5"
@@ -26,74 +27,59 @@ func NewPullRequestRepository(pool *pgxpool.Pool) *PullRequestRepository {
 // Create создаёт pr с ревьюверами.
 func (p *PullRequestRepository) Create(ctx context.Context, pr storage.PullRequest) *apperrors.AppError {
 	const prInsertQuery = `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at)
-        VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO pull_requests (org_id, pull_request_id, pull_request_name, author_id, status, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	const reviewInsertQuery = `INSERT INTO reviews (pull_request_id, reviewer_id) VALUES ($1, $2)`
+	const reviewInsertQuery = `INSERT INTO reviews (org_id, pull_request_id, reviewer_id) VALUES ($1, $2, $3)`
 
-	tx, err := p.pool.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		log.Printf("begin tx failed: %v", err)
-		return &apperrors.AppError{
-			Code:    apperrors.ErrInternalIssue,
-			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
-		}
-	}
-
-	defer func() {
-		if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) {
-			log.Printf("tx rollback failed: %v", rerr)
-		}
-	}()
-
-	_, err = tx.Exec(ctx, prInsertQuery, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return &apperrors.AppError{
-				Code:    apperrors.ErrPRExists,
-				Message: apperrors.FromCode(apperrors.ErrPRExists),
-			}
-		}
-		log.Printf("inserting pr failed: %v", err)
-		return &apperrors.AppError{
-			Code:    apperrors.ErrInternalIssue,
-			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
-		}
-	}
-
-	for _, rev := range pr.AssignedReviewers {
-		_, err := tx.Exec(ctx, reviewInsertQuery, pr.ID, rev)
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, p.pool, func(ctx context.Context) error {
+		_, err := conn(ctx, p.pool).Exec(ctx, prInsertQuery, orgID, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt)
 		if err != nil {
-			log.Printf("insert reviewer failed: %v", err)
-			return &apperrors.AppError{
-				Code:    apperrors.ErrInternalIssue,
-				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return &apperrors.AppError{
+					Code:    apperrors.ErrPRExists,
+					Message: apperrors.FromCode(apperrors.ErrPRExists),
+				}
 			}
+			return fmt.Errorf("inserting pr failed: %w", err)
 		}
-	}
 
-	if err := tx.Commit(ctx); err != nil {
-		log.Printf("commit failed: %v", err)
-		return &apperrors.AppError{
-			Code:    apperrors.ErrInternalIssue,
-			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		for _, rev := range pr.AssignedReviewers {
+			if _, err := conn(ctx, p.pool).Exec(ctx, reviewInsertQuery, orgID, pr.ID, rev); err != nil {
+				return fmt.Errorf("insert reviewer failed: %w", err)
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // Get возвращает pr по id.
 func (p *PullRequestRepository) Get(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
-	const prQuery = `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
-        FROM pull_requests WHERE pull_request_id = $1
+	return p.get(ctx, prID, false)
+}
+
+// GetForUpdate возвращает pr по id и блокирует его строку до конца транзакции
+// (SELECT ... FOR UPDATE). Имеет смысл только внутри TxManager.Do.
+func (p *PullRequestRepository) GetForUpdate(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	return p.get(ctx, prID, true)
+}
+
+func (p *PullRequestRepository) get(ctx context.Context, prID string, forUpdate bool) (storage.PullRequest, *apperrors.AppError) {
+	prQuery := `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+        FROM pull_requests WHERE org_id = $1 AND pull_request_id = $2
 	`
-	const revQuery = `SELECT reviewer_id FROM reviews WHERE pull_request_id = $1`
+	if forUpdate {
+		prQuery += " FOR UPDATE"
+	}
+	const revQuery = `SELECT reviewer_id FROM reviews WHERE org_id = $1 AND pull_request_id = $2`
 
+	orgID := storage.OrgFrom(ctx)
 	var pr storage.PullRequest
 
-	err := p.pool.QueryRow(ctx, prQuery, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt)
+	err := conn(ctx, p.pool).QueryRow(ctx, prQuery, orgID, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Version)
 	if err != nil {
 		var appErr *apperrors.AppError
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -111,7 +97,7 @@ func (p *PullRequestRepository) Get(ctx context.Context, prID string) (storage.P
 		return pr, appErr
 	}
 
-	rows, err := p.pool.Query(ctx, revQuery, prID)
+	rows, err := conn(ctx, p.pool).Query(ctx, revQuery, orgID, prID)
 	if err != nil {
 		log.Printf("query reviewer failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -150,9 +136,9 @@ func (p *PullRequestRepository) Get(ctx context.Context, prID string) (storage.P
 
 // Exists проверяет существование pr.
 func (p *PullRequestRepository) Exists(ctx context.Context, prID string) (bool, *apperrors.AppError) {
-	const query = `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`
+	const query = `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE org_id = $1 AND pull_request_id = $2)`
 	var exists bool
-	err := p.pool.QueryRow(ctx, query, prID).Scan(&exists)
+	err := conn(ctx, p.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), prID).Scan(&exists)
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -169,10 +155,35 @@ func (p *PullRequestRepository) Exists(ctx context.Context, prID string) (bool,
 func (p *PullRequestRepository) MarkMerged(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
 	const query = `
 		UPDATE pull_requests
-		SET status = 'MERGED', merged_at = COALESCE(merged_at, NOW())
-		WHERE pull_request_id = $1
+		SET status = 'MERGED', merged_at = COALESCE(merged_at, NOW()), version = version + 1
+		WHERE org_id = $1 AND pull_request_id = $2
 	`
-	ct, err := p.pool.Exec(ctx, query, prID)
+	ct, err := conn(ctx, p.pool).Exec(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("update failed: %v", err)
+		appErr := &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+		return storage.PullRequest{}, appErr
+	}
+
+	if ct.RowsAffected() == 0 {
+		appErr := &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+		return storage.PullRequest{}, appErr
+	}
+
+	return p.Get(ctx, prID)
+}
+
+// SetStatus переводит pr в OPEN или CLOSED; для MERGED используется MarkMerged.
+func (p *PullRequestRepository) SetStatus(ctx context.Context, prID string, status storage.PRStatus) (storage.PullRequest, *apperrors.AppError) {
+	const query = `UPDATE pull_requests SET status = $3::text::pr_status, version = version + 1 WHERE org_id = $1 AND pull_request_id = $2`
+
+	ct, err := conn(ctx, p.pool).Exec(ctx, query, storage.OrgFrom(ctx), prID, string(status))
 	if err != nil {
 		log.Printf("update failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -196,11 +207,11 @@ func (p *PullRequestRepository) MarkMerged(ctx context.Context, prID string) (st
 // ReplaceReviewer заменяет одного ревьюера на другого.
 func (p *PullRequestRepository) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) *apperrors.AppError {
 	const query = `
-		UPDATE reviews SET reviewer_id = $3, assigned_at = NOW()
-		WHERE pull_request_id = $1 AND reviewer_id = $2
+		UPDATE reviews SET reviewer_id = $4, assigned_at = NOW(), reminded_at = NULL, escalated_at = NULL
+		WHERE org_id = $1 AND pull_request_id = $2 AND reviewer_id = $3
 	`
 
-	ct, err := p.pool.Exec(ctx, query, prID, oldReviewerID, newReviewerID)
+	ct, err := conn(ctx, p.pool).Exec(ctx, query, storage.OrgFrom(ctx), prID, oldReviewerID, newReviewerID)
 	if err != nil {
 		log.Printf("update rev failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -218,18 +229,46 @@ func (p *PullRequestRepository) ReplaceReviewer(ctx context.Context, prID, oldRe
 		return appErr
 	}
 
+	return p.bumpVersion(ctx, prID)
+}
+
+// AddReviewer назначает на pr ещё одного ревьюера.
+func (p *PullRequestRepository) AddReviewer(ctx context.Context, prID, reviewerID string) *apperrors.AppError {
+	const query = `INSERT INTO reviews (org_id, pull_request_id, reviewer_id) VALUES ($1, $2, $3)`
+
+	if _, err := conn(ctx, p.pool).Exec(ctx, query, storage.OrgFrom(ctx), prID, reviewerID); err != nil {
+		log.Printf("insert reviewer failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return p.bumpVersion(ctx, prID)
+}
+
+// bumpVersion увеличивает версию pr после изменения его ревьюеров.
+func (p *PullRequestRepository) bumpVersion(ctx context.Context, prID string) *apperrors.AppError {
+	const query = `UPDATE pull_requests SET version = version + 1 WHERE org_id = $1 AND pull_request_id = $2`
+
+	if _, err := conn(ctx, p.pool).Exec(ctx, query, storage.OrgFrom(ctx), prID); err != nil {
+		log.Printf("bump pr version failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
 	return nil
 }
 
 // GetByReviewer возвращет все pr пользоавтель. где он ревьюер.
 func (p *PullRequestRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]storage.PullRequest, *apperrors.AppError) {
 	const query = `
-		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.version
         FROM pull_requests pr
-        INNER JOIN reviews r ON r.pull_request_id = pr.pull_request_id
-        WHERE r.reviewer_id = $1 
+        INNER JOIN reviews r ON r.org_id = pr.org_id AND r.pull_request_id = pr.pull_request_id
+        WHERE pr.org_id = $1 AND r.reviewer_id = $2
 	`
-	rows, err := p.pool.Query(ctx, query, reviewerID)
+	rows, err := conn(ctx, p.pool).Query(ctx, query, storage.OrgFrom(ctx), reviewerID)
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -244,7 +283,7 @@ func (p *PullRequestRepository) GetByReviewer(ctx context.Context, reviewerID st
 	var prs []storage.PullRequest
 	for rows.Next() {
 		var pr storage.PullRequest
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Version); err != nil {
 			log.Printf("scan failed: %v", err)
 			appErr := &apperrors.AppError{
 				Code:    apperrors.ErrInternalIssue,
@@ -270,9 +309,9 @@ func (p *PullRequestRepository) GetByReviewer(ctx context.Context, reviewerID st
 
 // IsReviewerAssigned проверяет, является ли пользователь ревьюером.
 func (p *PullRequestRepository) IsReviewerAssigned(ctx context.Context, reviewerID string) (bool, *apperrors.AppError) {
-	const query = `SELECT EXISTS(SELECT 1 FROM reviews WHERE reviewer_id = $1)`
+	const query = `SELECT EXISTS(SELECT 1 FROM reviews WHERE org_id = $1 AND reviewer_id = $2)`
 	var exists bool
-	err := p.pool.QueryRow(ctx, query, reviewerID).Scan(&exists)
+	err := conn(ctx, p.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), reviewerID).Scan(&exists)
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -290,9 +329,10 @@ func (p *PullRequestRepository) CountAssignmentsByUser(ctx context.Context) (map
 	const query = `
         SELECT reviewer_id, COUNT(*)
         FROM reviews
+        WHERE org_id = $1
         GROUP BY reviewer_id
     `
-	rows, err := p.pool.Query(ctx, query)
+	rows, err := conn(ctx, p.pool).Query(ctx, query, storage.OrgFrom(ctx))
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		return nil, &apperrors.AppError{
@@ -334,10 +374,11 @@ func (p *PullRequestRepository) CountAssignmentsByPR(ctx context.Context) (map[s
 	const query = `
         SELECT pull_request_id, COUNT(reviewer_id)
         FROM reviews
+        WHERE org_id = $1
         GROUP BY pull_request_id
     `
 
-	rows, err := p.pool.Query(ctx, query)
+	rows, err := conn(ctx, p.pool).Query(ctx, query, storage.OrgFrom(ctx))
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		return nil, &apperrors.AppError{
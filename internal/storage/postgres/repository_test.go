@@ -0,0 +1,73 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pginfra "github.com/VechkanovVV/assigner-pr/internal/infra/postgres"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/postgres"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/storagetest"
+)
+
+// TestRepositories прогоняет общий набор тестов против Postgres. Требует поднятую БД
+// с применёнными миграциями (см. internal/integration/run_tests.sh).
+func TestRepositories(t *testing.T) {
+	host := os.Getenv("INTEGRATION_DB_HOST")
+	if testing.Short() || host == "" {
+		t.Skip("INTEGRATION_DB_HOST is not set")
+	}
+
+	port, err := strconv.Atoi(getenv("INTEGRATION_DB_PORT", "5432"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	pool, err := pginfra.NewPool(
+		ctx,
+		port,
+		host,
+		getenv("INTEGRATION_DB_USER", "admin"),
+		getenv("INTEGRATION_DB_PASSWORD", "admin"),
+		getenv("INTEGRATION_DB_NAME", "db"),
+		getenv("INTEGRATION_DB_SSLMODE", "disable"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		_, err := pool.Exec(ctx, `TRUNCATE idempotency_keys, audit_log, api_tokens, review_slas, email_preferences, notifications, team_chats, reviewer_syncs, inbound_deliveries, user_identities, webhook_deliveries, outbox_events, webhooks, pr_events, assignment_log, reviews, pull_requests, users, teams RESTART IDENTITY CASCADE`)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, `DELETE FROM organizations WHERE id <> 1`)
+		require.NoError(t, err)
+
+		return storagetest.Backend{
+			Tx:       postgres.NewTxManager(pool),
+			Users:    postgres.NewUserRepository(pool),
+			Teams:    postgres.NewTeamRepository(pool),
+			PRs:      postgres.NewPullRequestRepository(pool),
+			Logs:     postgres.NewAssignmentLogRepository(pool),
+			Events:   postgres.NewPREventRepository(pool),
+			Webhooks: postgres.NewWebhookRepository(pool),
+			Outbox:   postgres.NewOutboxRepository(pool),
+
+			Integrations:  postgres.NewIntegrationRepository(pool),
+			ReviewerSyncs: postgres.NewReviewerSyncRepository(pool),
+			Notifications: postgres.NewNotificationRepository(pool),
+			ReviewSLAs:    postgres.NewReviewSLARepository(pool),
+			APITokens:     postgres.NewAPITokenRepository(pool),
+			Orgs:          postgres.NewOrganizationRepository(pool),
+			Audit:         postgres.NewAuditLogRepository(pool),
+			Idempotency:   postgres.NewIdempotencyRepository(pool),
+		}
+	})
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
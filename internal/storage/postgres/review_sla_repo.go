@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewSLARepository - сроки ревью команд и отметки SLA назначений в Postgres.
+type ReviewSLARepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReviewSLARepository создаёт экземпляр *ReviewSLARepository.
+func NewReviewSLARepository(pool *pgxpool.Pool) *ReviewSLARepository {
+	return &ReviewSLARepository{pool: pool}
+}
+
+// SetTeamSLA задаёт срок ревью команды; существующая настройка перезаписывается. Команда
+// другой организации - NOT_FOUND.
+func (r *ReviewSLARepository) SetTeamSLA(ctx context.Context, sla storage.ReviewSLA) *apperrors.AppError {
+	const query = `
+		INSERT INTO review_slas (team_id, org_id, within_ms, escalation, escalate_after_ms, lead_id)
+		SELECT id, org_id, $3, $4, $5, NULLIF($6, '') FROM teams WHERE org_id = $1 AND id = $2
+		ON CONFLICT (team_id) DO UPDATE SET
+			within_ms = EXCLUDED.within_ms,
+			escalation = EXCLUDED.escalation,
+			escalate_after_ms = EXCLUDED.escalate_after_ms,
+			lead_id = EXCLUDED.lead_id,
+			updated_at = NOW()
+	`
+
+	ct, err := conn(ctx, r.pool).Exec(ctx, query, storage.OrgFrom(ctx),
+		sla.TeamID, sla.Within.Milliseconds(), sla.Escalation, sla.EscalateAfter.Milliseconds(), sla.LeadID)
+	if err != nil {
+		log.Printf("upsert review sla failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// GetTeamSLA возвращает срок ревью команды.
+func (r *ReviewSLARepository) GetTeamSLA(ctx context.Context, teamID int) (storage.ReviewSLA, *apperrors.AppError) {
+	const query = `
+		SELECT s.team_id, s.within_ms, s.escalation, s.escalate_after_ms, COALESCE(s.lead_id, ''), s.updated_at
+		FROM review_slas s JOIN teams t ON t.id = s.team_id
+		WHERE t.org_id = $1 AND s.team_id = $2
+	`
+
+	var sla storage.ReviewSLA
+	var withinMs, escalateAfterMs int64
+	err := conn(ctx, r.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), teamID).
+		Scan(&sla.TeamID, &withinMs, &sla.Escalation, &escalateAfterMs, &sla.LeadID, &sla.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return storage.ReviewSLA{}, &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	if err != nil {
+		log.Printf("query review sla failed: %v", err)
+		return storage.ReviewSLA{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	sla.Within = time.Duration(withinMs) * time.Millisecond
+	sla.EscalateAfter = time.Duration(escalateAfterMs) * time.Millisecond
+	return sla, nil
+}
+
+// DeleteTeamSLA снимает срок ревью с команды.
+func (r *ReviewSLARepository) DeleteTeamSLA(ctx context.Context, teamID int) *apperrors.AppError {
+	const query = `
+		DELETE FROM review_slas
+		WHERE team_id = $2 AND EXISTS (SELECT 1 FROM teams WHERE org_id = $1 AND id = $2)
+	`
+
+	ct, err := conn(ctx, r.pool).Exec(ctx, query, storage.OrgFrom(ctx), teamID)
+	if err != nil {
+		log.Printf("delete review sla failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// GetReviews возвращает назначения ревьюеров pr.
+func (r *ReviewSLARepository) GetReviews(ctx context.Context, prID string) ([]storage.ReviewState, *apperrors.AppError) {
+	const query = `
+		SELECT pull_request_id, reviewer_id, org_id, assigned_at, reminded_at, escalated_at
+		FROM reviews WHERE org_id = $1 AND pull_request_id = $2
+		ORDER BY reviewer_id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query reviews failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	reviews := make([]storage.ReviewState, 0)
+	for rows.Next() {
+		var rs storage.ReviewState
+		if err := rows.Scan(&rs.PullRequestID, &rs.ReviewerID, &rs.OrgID, &rs.AssignedAt, &rs.RemindedAt, &rs.EscalatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		reviews = append(reviews, rs)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return reviews, nil
+}
+
+// overdueQuery - отметка просроченных ревью всех организаций: %[1]s - колонка отметки,
+// %[2]s - условие просрочки над rv (reviews) и s (review_slas).
+const overdueQuery = `
+	UPDATE reviews SET %[1]s = $1
+	FROM (
+		SELECT rv.org_id, rv.pull_request_id, rv.reviewer_id,
+			s.team_id, s.within_ms, s.escalation, s.escalate_after_ms, COALESCE(s.lead_id, '') AS lead_id, s.updated_at
+		FROM reviews rv
+		JOIN pull_requests pr ON pr.org_id = rv.org_id AND pr.pull_request_id = rv.pull_request_id
+		JOIN users a ON a.org_id = pr.org_id AND a.user_id = pr.author_id
+		JOIN review_slas s ON s.team_id = a.team_id
+		WHERE pr.status = 'OPEN' AND rv.%[1]s IS NULL AND %[2]s
+		ORDER BY rv.org_id, rv.pull_request_id, rv.reviewer_id
+		LIMIT $2
+		FOR UPDATE OF rv SKIP LOCKED
+	) due
+	WHERE reviews.org_id = due.org_id AND reviews.pull_request_id = due.pull_request_id
+		AND reviews.reviewer_id = due.reviewer_id
+	RETURNING reviews.pull_request_id, reviews.reviewer_id, reviews.org_id, reviews.assigned_at, reviews.reminded_at, reviews.escalated_at,
+		due.team_id, due.within_ms, due.escalation, due.escalate_after_ms, due.lead_id, due.updated_at
+`
+
+// ClaimReminders отмечает напоминание для ревью, срок которых истёк.
+// Строки, занятые другим экземпляром, пропускаются (SKIP LOCKED).
+func (r *ReviewSLARepository) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	query := fmt.Sprintf(overdueQuery, "reminded_at",
+		"rv.assigned_at + s.within_ms * INTERVAL '1 millisecond' <= $1")
+	return r.claim(ctx, query, now, limit)
+}
+
+// ClaimEscalations отмечает эскалацию для ревью, просроченных сверх EscalateAfter.
+func (r *ReviewSLARepository) ClaimEscalations(ctx context.Context, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	query := fmt.Sprintf(overdueQuery, "escalated_at",
+		"s.escalation <> 'none' AND rv.assigned_at + s.escalate_after_ms * INTERVAL '1 millisecond' <= $1")
+	return r.claim(ctx, query, now, limit)
+}
+
+func (r *ReviewSLARepository) claim(ctx context.Context, query string, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, now, limit)
+	if err != nil {
+		log.Printf("claim overdue reviews failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	overdue := make([]storage.OverdueReview, 0)
+	for rows.Next() {
+		var o storage.OverdueReview
+		var withinMs, escalateAfterMs int64
+		err := rows.Scan(&o.PullRequestID, &o.ReviewerID, &o.OrgID, &o.AssignedAt, &o.RemindedAt, &o.EscalatedAt,
+			&o.SLA.TeamID, &withinMs, &o.SLA.Escalation, &escalateAfterMs, &o.SLA.LeadID, &o.SLA.UpdatedAt)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		o.SLA.Within = time.Duration(withinMs) * time.Millisecond
+		o.SLA.EscalateAfter = time.Duration(escalateAfterMs) * time.Millisecond
+		overdue = append(overdue, o)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+
+	// RETURNING не гарантирует порядок подзапроса.
+	sort.Slice(overdue, func(i, j int) bool {
+		if overdue[i].OrgID != overdue[j].OrgID {
+			return overdue[i].OrgID < overdue[j].OrgID
+		}
+		if overdue[i].PullRequestID != overdue[j].PullRequestID {
+			return overdue[i].PullRequestID < overdue[j].PullRequestID
+		}
+		return overdue[i].ReviewerID < overdue[j].ReviewerID
+	})
+	return overdue, nil
+}
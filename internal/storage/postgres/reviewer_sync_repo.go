@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewerSyncRepository - очередь передачи ревьюеров на код-хостинг в Postgres.
+type ReviewerSyncRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReviewerSyncRepository создаёт экземпляр *ReviewerSyncRepository.
+func NewReviewerSyncRepository(pool *pgxpool.Pool) *ReviewerSyncRepository {
+	return &ReviewerSyncRepository{pool: pool}
+}
+
+const reviewerSyncColumns = `
+	id, org_id, provider, pull_request_id, removed_reviewers, status, attempts, next_attempt_at, last_error, updated_at
+`
+
+// Enqueue ставит задачу в очередь; она готова к отправке сразу.
+func (r *ReviewerSyncRepository) Enqueue(ctx context.Context, sync storage.ReviewerSync) *apperrors.AppError {
+	const query = `
+		INSERT INTO reviewer_syncs (org_id, provider, pull_request_id, removed_reviewers)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	removed := sync.Removed
+	if removed == nil {
+		removed = []string{}
+	}
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, storage.OrgFrom(ctx), sync.Provider, sync.PullRequestID, removed); err != nil {
+		log.Printf("insert reviewer sync failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return nil
+}
+
+// ClaimDue выдаёт до limit задач всех организаций, которым пора выполняться. Строки,
+// занятые другим обработчиком, пропускаются (SKIP LOCKED).
+func (r *ReviewerSyncRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.ReviewerSync, *apperrors.AppError) {
+	const query = `
+		UPDATE reviewer_syncs
+		SET attempts = attempts + 1, next_attempt_at = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM reviewer_syncs
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + reviewerSyncColumns
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		log.Printf("claim reviewer syncs failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	syncs, appErr := scanReviewerSyncs(rows)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// RETURNING не гарантирует порядок подзапроса.
+	sort.Slice(syncs, func(i, j int) bool { return syncs[i].ID < syncs[j].ID })
+	return syncs, nil
+}
+
+// MarkDone помечает задачу как выполненную.
+func (r *ReviewerSyncRepository) MarkDone(ctx context.Context, syncID int64) *apperrors.AppError {
+	const query = `
+		UPDATE reviewer_syncs
+		SET status = 'DELIVERED', last_error = '', updated_at = NOW()
+		WHERE id = $1
+	`
+	return r.update(ctx, query, syncID)
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит задачу в DEAD.
+func (r *ReviewerSyncRepository) MarkFailed(ctx context.Context, syncID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	const query = `
+		UPDATE reviewer_syncs
+		SET status = CASE WHEN $4 THEN 'DEAD' ELSE 'PENDING' END,
+			next_attempt_at = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	return r.update(ctx, query, syncID, nextAttemptAt, lastErr, dead)
+}
+
+func (r *ReviewerSyncRepository) update(ctx context.Context, query string, args ...any) *apperrors.AppError {
+	ct, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		log.Printf("update reviewer sync failed: %v", err)
+		return &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	if ct.RowsAffected() == 0 {
+		return &apperrors.AppError{
+			Code:    apperrors.ErrNotFound,
+			Message: apperrors.FromCode(apperrors.ErrNotFound),
+		}
+	}
+	return nil
+}
+
+// List возвращает задачи pr в порядке постановки.
+func (r *ReviewerSyncRepository) List(ctx context.Context, prID string) ([]storage.ReviewerSync, *apperrors.AppError) {
+	const query = `SELECT ` + reviewerSyncColumns + ` FROM reviewer_syncs WHERE org_id = $1 AND pull_request_id = $2 ORDER BY id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query reviewer syncs failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return scanReviewerSyncs(rows)
+}
+
+func scanReviewerSyncs(rows pgx.Rows) ([]storage.ReviewerSync, *apperrors.AppError) {
+	defer rows.Close()
+
+	syncs := make([]storage.ReviewerSync, 0)
+	for rows.Next() {
+		var s storage.ReviewerSync
+		if err := rows.Scan(&s.ID, &s.OrgID, &s.Provider, &s.PullRequestID, &s.Removed, &s.Status, &s.Attempts,
+			&s.NextAttemptAt, &s.LastError, &s.UpdatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		syncs = append(syncs, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return syncs, nil
+}
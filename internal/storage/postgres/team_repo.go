@@ -4,6 +4,7 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"time"
 
@@ -25,87 +26,62 @@ func NewTeamRepository(pool *pgxpool.Pool) *TeamRepository {
 	return &TeamRepository{pool: pool}
 }
 
-// Create создаёт новую команду.
+// Create создаёт новую команду. Существующие пользователи организации переносятся в неё;
+// user_id уникальны в пределах организации.
 func (t *TeamRepository) Create(ctx context.Context, team storage.Team) *apperrors.AppError {
-	const queryTeamInsert = `INSERT INTO teams (team_name) VALUES ($1) RETURNING id, created_at`
+	const queryTeamInsert = `INSERT INTO teams (org_id, team_name) VALUES ($1, $2) RETURNING id, created_at`
 	const queryUserInsert = `
-        INSERT INTO users (user_id, username, team_id, is_active)
-            VALUES ($1, $2, $3, $4)
-            ON CONFLICT (user_id) DO UPDATE SET
+        INSERT INTO users (org_id, user_id, username, team_id, is_active)
+            VALUES ($1, $2, $3, $4, $5)
+            ON CONFLICT (org_id, user_id) DO UPDATE SET
             username = EXCLUDED.username,
             team_id = EXCLUDED.team_id,
             is_active = EXCLUDED.is_active,
-            updated_at = NOW()`
-
-	tx, err := t.pool.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		log.Printf("begin tx failed: %v", err)
-		return &apperrors.AppError{
-			Code:    apperrors.ErrInternalIssue,
-			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
-		}
-	}
-
-	defer func() {
-		if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) {
-			log.Printf("tx rollback error: %v", rerr)
-		}
-	}()
-
-	var teamID int
-	var createdAt time.Time
-	err = tx.QueryRow(ctx, queryTeamInsert, team.TeamName).Scan(&teamID, &createdAt)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return &apperrors.AppError{
-				Code:    apperrors.ErrTeamExists,
-				Message: apperrors.FromCode(apperrors.ErrTeamExists),
-			}
-		}
-		log.Printf("insert team failed: %v", err)
-		return &apperrors.AppError{
-			Code:    apperrors.ErrInternalIssue,
-			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
-		}
-	}
-
-	for _, user := range team.Members {
-		_, err := tx.Exec(ctx, queryUserInsert, user.ID, user.Username, teamID, user.IsActive)
+            updated_at = NOW(),
+            version = users.version + 1`
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, t.pool, func(ctx context.Context) error {
+		var teamID int
+		var createdAt time.Time
+		err := conn(ctx, t.pool).QueryRow(ctx, queryTeamInsert, orgID, team.TeamName).Scan(&teamID, &createdAt)
 		if err != nil {
-			log.Printf("failed insertion into users: %v", err)
-			return &apperrors.AppError{
-				Code:    apperrors.ErrInternalIssue,
-				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return &apperrors.AppError{
+					Code:    apperrors.ErrTeamExists,
+					Message: apperrors.FromCode(apperrors.ErrTeamExists),
+				}
 			}
+			return fmt.Errorf("insert team failed: %w", err)
 		}
-	}
 
-	if err := tx.Commit(ctx); err != nil {
-		log.Printf("commit failed: %v", err)
-		return &apperrors.AppError{
-			Code:    apperrors.ErrInternalIssue,
-			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		for _, user := range team.Members {
+			_, err := conn(ctx, t.pool).Exec(ctx, queryUserInsert, orgID, user.ID, user.Username, teamID, user.IsActive)
+			if err != nil {
+				return fmt.Errorf("failed insertion into users: %w", err)
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // GetByName осуществляет поиск в бд команды и её участников по имени команды.
 func (t *TeamRepository) GetByName(ctx context.Context, teamName string) (storage.Team, *apperrors.AppError) {
 	const selectTeamByName = `
-	SELECT t.id, t.team_name, t.created_at
+	SELECT t.id, t.team_name, t.created_at, t.version
 	FROM teams t
-	WHERE t.team_name = $1
+	WHERE t.org_id = $1 AND t.team_name = $2
 	`
 
 	const selectUsersByTeamID = `
-		SELECT u.user_id, u.username, u.team_id, u.is_active, u.updated_at
+		SELECT u.user_id, u.username, u.team_id, u.is_active, u.updated_at, u.version
 		FROM users u
-		WHERE u.team_id = $1
+		WHERE u.org_id = $1 AND u.team_id = $2
 	`
+	orgID := storage.OrgFrom(ctx)
 	var team storage.Team
-	err := t.pool.QueryRow(ctx, selectTeamByName, teamName).Scan(&team.ID, &team.TeamName, &team.CreatedAt)
+	err := conn(ctx, t.pool).QueryRow(ctx, selectTeamByName, orgID, teamName).Scan(&team.ID, &team.TeamName, &team.CreatedAt, &team.Version)
 	if err != nil {
 		var appErr *apperrors.AppError
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -123,7 +99,7 @@ func (t *TeamRepository) GetByName(ctx context.Context, teamName string) (storag
 		return team, appErr
 	}
 
-	rows, err := t.pool.Query(ctx, selectUsersByTeamID, team.ID)
+	rows, err := conn(ctx, t.pool).Query(ctx, selectUsersByTeamID, orgID, team.ID)
 	if err != nil {
 		log.Printf("query users failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -137,7 +113,7 @@ func (t *TeamRepository) GetByName(ctx context.Context, teamName string) (storag
 
 	for rows.Next() {
 		var user storage.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version); err != nil {
 			log.Printf("scan user failed: %v", err)
 			appErr := &apperrors.AppError{
 				Code:    apperrors.ErrInternalIssue,
@@ -161,9 +137,9 @@ func (t *TeamRepository) GetByName(ctx context.Context, teamName string) (storag
 
 // Exists проверяет существует ли команда по её имени.
 func (t *TeamRepository) Exists(ctx context.Context, teamName string) (bool, *apperrors.AppError) {
-	const query = `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`
+	const query = `SELECT EXISTS(SELECT 1 FROM teams WHERE org_id = $1 AND team_name = $2)`
 	var exists bool
-	err := t.pool.QueryRow(ctx, query, teamName).Scan(&exists)
+	err := conn(ctx, t.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), teamName).Scan(&exists)
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		return false, &apperrors.AppError{
@@ -177,11 +153,12 @@ func (t *TeamRepository) Exists(ctx context.Context, teamName string) (bool, *ap
 
 // GetByID получает команду по её ID.
 func (t *TeamRepository) GetByID(ctx context.Context, teamID int) (storage.Team, *apperrors.AppError) {
-	const teamQuery = `SELECT team_name FROM teams WHERE id = $1`
-	const membersQuery = `SELECT user_id, username, is_active FROM users WHERE team_id = $1`
+	const teamQuery = `SELECT team_name, version FROM teams WHERE org_id = $1 AND id = $2`
+	const membersQuery = `SELECT user_id, username, is_active, version FROM users WHERE org_id = $1 AND team_id = $2`
 
+	orgID := storage.OrgFrom(ctx)
 	var team storage.Team
-	err := t.pool.QueryRow(ctx, teamQuery, teamID).Scan(&team.TeamName)
+	err := conn(ctx, t.pool).QueryRow(ctx, teamQuery, orgID, teamID).Scan(&team.TeamName, &team.Version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return team, &apperrors.AppError{
@@ -196,7 +173,7 @@ func (t *TeamRepository) GetByID(ctx context.Context, teamID int) (storage.Team,
 		}
 	}
 
-	rows, err := t.pool.Query(ctx, membersQuery, teamID)
+	rows, err := conn(ctx, t.pool).Query(ctx, membersQuery, orgID, teamID)
 	if err != nil {
 		log.Printf("query failed:: %v", err)
 		return team, &apperrors.AppError{
@@ -209,7 +186,7 @@ func (t *TeamRepository) GetByID(ctx context.Context, teamID int) (storage.Team,
 	members := make([]storage.User, 0)
 	for rows.Next() {
 		var member storage.User
-		if err := rows.Scan(&member.ID, &member.Username, &member.IsActive); err != nil {
+		if err := rows.Scan(&member.ID, &member.Username, &member.IsActive, &member.Version); err != nil {
 			log.Printf("scan failed: %v", err)
 			return team, &apperrors.AppError{
 				Code:    apperrors.ErrInternalIssue,
@@ -230,3 +207,25 @@ func (t *TeamRepository) GetByID(ctx context.Context, teamID int) (storage.Team,
 	team.Members = members
 	return team, nil
 }
+
+// Touch увеличивает версию команды и возвращает новую.
+func (t *TeamRepository) Touch(ctx context.Context, teamID int) (int64, *apperrors.AppError) {
+	const query = `UPDATE teams SET version = version + 1 WHERE org_id = $1 AND id = $2 RETURNING version`
+
+	var version int64
+	err := conn(ctx, t.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), teamID).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, &apperrors.AppError{
+				Code:    apperrors.ErrNotFound,
+				Message: apperrors.FromCode(apperrors.ErrNotFound),
+			}
+		}
+		log.Printf("touch team failed: %v", err)
+		return 0, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+const (
+	// txMaxAttempts - сколько раз транзакция запускается при конфликтах сериализации.
+	txMaxAttempts = 5
+	// txRetryBackoff - базовая пауза перед повтором транзакции.
+	txRetryBackoff = 10 * time.Millisecond
+)
+
+// txKey - ключ контекста, под которым лежит текущая транзакция.
+type txKey struct{}
+
+// querier - общая часть pgxpool.Pool и pgx.Tx, которой пользуются репозитории.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// TxManager - реализация storage.TxManager поверх pgxpool. Транзакция передаётся
+// репозиториям через context.
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+// NewTxManager создаёт экземпляр *TxManager.
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{pool: pool}
+}
+
+// Do выполняет fn в транзакции. Если в ctx уже есть транзакция, fn выполняется в ней.
+// При serialization_failure и deadlock_detected транзакция повторяется целиком.
+// Функции storage.AfterCommit вызываются после фиксации внешней транзакции.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) *apperrors.AppError) *apperrors.AppError {
+	var hooks *storage.TxHooks
+	err := inTx(ctx, m.pool, func(ctx context.Context) error {
+		ctx, hooks = storage.WithTxHooks(ctx)
+		if appErr := fn(ctx); appErr != nil {
+			return appErr
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	hooks.Run()
+	return nil
+}
+
+// conn возвращает транзакцию из ctx, а если её нет - pool.
+func conn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if c, ok := ctx.Value(txKey{}).(*txConn); ok {
+		return c
+	}
+	return pool
+}
+
+// inTx выполняет fn в транзакции из ctx или, если её нет, в новой транзакции с повторами.
+// fn возвращает *apperrors.AppError для доменных ошибок или любую другую ошибку БД
+// (логируется и превращается в INTERNAL_ISSUE).
+func inTx(ctx context.Context, pool *pgxpool.Pool, fn func(ctx context.Context) error) *apperrors.AppError {
+	if _, ok := ctx.Value(txKey{}).(*txConn); ok {
+		return toAppError(fn(ctx))
+	}
+
+	var err error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		var retryable bool
+		retryable, err = runTxOnce(ctx, pool, fn)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || attempt == txMaxAttempts {
+			break
+		}
+
+		log.Printf("tx conflict, retrying (attempt %d/%d): %v", attempt, txMaxAttempts, err)
+		if werr := wait(ctx, txRetryBackoff*time.Duration(attempt)); werr != nil {
+			err = werr
+			break
+		}
+	}
+
+	return toAppError(err)
+}
+
+// runTxOnce выполняет одну попытку транзакции и сообщает, стоит ли её повторить.
+func runTxOnce(ctx context.Context, pool *pgxpool.Pool, fn func(ctx context.Context) error) (retryable bool, err error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) {
+			log.Printf("tx rollback failed: %v", rerr)
+		}
+	}()
+
+	c := &txConn{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, c)); err != nil {
+		return c.conflict != nil || isRetryable(err), err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return isRetryable(err), err
+	}
+	return false, nil
+}
+
+func toAppError(err error) *apperrors.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	log.Printf("tx failed: %v", err)
+	return &apperrors.AppError{
+		Code:    apperrors.ErrInternalIssue,
+		Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+	}
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// isRetryable сообщает, можно ли повторить транзакцию после ошибки.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// txConn - транзакция, запоминающая конфликты сериализации. Репозитории превращают
+// ошибки БД в AppError, поэтому повтор транзакции решается по этому флагу.
+type txConn struct {
+	tx       pgx.Tx
+	conflict error
+}
+
+func (c *txConn) track(err error) {
+	if c.conflict == nil && isRetryable(err) {
+		c.conflict = err
+	}
+}
+
+// Exec реализует querier.
+func (c *txConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	ct, err := c.tx.Exec(ctx, sql, args...)
+	c.track(err)
+	return ct, err
+}
+
+// Query реализует querier.
+func (c *txConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := c.tx.Query(ctx, sql, args...)
+	c.track(err)
+	if err != nil {
+		return rows, err
+	}
+	return &trackedRows{Rows: rows, c: c}, nil
+}
+
+// QueryRow реализует querier.
+func (c *txConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return trackedRow{row: c.tx.QueryRow(ctx, sql, args...), c: c}
+}
+
+type trackedRows struct {
+	pgx.Rows
+	c *txConn
+}
+
+func (r *trackedRows) Err() error {
+	err := r.Rows.Err()
+	r.c.track(err)
+	return err
+}
+
+type trackedRow struct {
+	row pgx.Row
+	c   *txConn
+}
+
+func (r trackedRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	r.c.track(err)
+	return err
+}
@@ -25,12 +25,12 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 // Get осуществляет поиск в бд пользователя(участника команды) по его id.
 func (u *UserRepository) Get(ctx context.Context, userID string) (storage.User, *apperrors.AppError) {
 	const query = `
-		SELECT user_id, username, team_id, is_active, updated_at
-        FROM users WHERE user_id = $1
+		SELECT user_id, username, team_id, is_active, updated_at, version
+        FROM users WHERE org_id = $1 AND user_id = $2
 	`
 
 	var user storage.User
-	err := u.pool.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt)
+	err := conn(ctx, u.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), userID).Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			appErr := &apperrors.AppError{
@@ -54,13 +54,13 @@ func (u *UserRepository) Get(ctx context.Context, userID string) (storage.User,
 func (u *UserRepository) SetActive(ctx context.Context, userID string, isActive bool) (storage.User, *apperrors.AppError) {
 	const query = `
 		UPDATE users
-		SET is_active = $2, updated_at = NOW()
-		WHERE user_id = $1
-		RETURNING user_id, username, team_id, is_active, updated_at
+		SET is_active = $3, updated_at = NOW(), version = version + 1
+		WHERE org_id = $1 AND user_id = $2
+		RETURNING user_id, username, team_id, is_active, updated_at, version
 	`
 
 	var user storage.User
-	err := u.pool.QueryRow(ctx, query, userID, isActive).Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt)
+	err := conn(ctx, u.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), userID, isActive).Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			appErr := &apperrors.AppError{
@@ -83,12 +83,12 @@ func (u *UserRepository) SetActive(ctx context.Context, userID string, isActive
 // GetActiveTeammates возвращает активных участников команды по teamID, исключая excludedID.
 func (u *UserRepository) GetActiveTeammates(ctx context.Context, teamID int, excludedID string) ([]storage.User, *apperrors.AppError) {
 	const query = `
-		SELECT user_id, username, team_id, is_active, updated_at
+		SELECT user_id, username, team_id, is_active, updated_at, version
 		FROM users
-		WHERE team_id = $1 AND is_active = true AND user_id != $2
+		WHERE org_id = $1 AND team_id = $2 AND is_active = true AND user_id != $3
 	`
 
-	rows, err := u.pool.Query(ctx, query, teamID, excludedID)
+	rows, err := conn(ctx, u.pool).Query(ctx, query, storage.OrgFrom(ctx), teamID, excludedID)
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		appErr := &apperrors.AppError{
@@ -102,7 +102,7 @@ func (u *UserRepository) GetActiveTeammates(ctx context.Context, teamID int, exc
 	var users []storage.User
 	for rows.Next() {
 		var user storage.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version); err != nil {
 			log.Printf("scan failed: %v", err)
 			appErr := &apperrors.AppError{
 				Code:    apperrors.ErrInternalIssue,
@@ -123,11 +123,53 @@ func (u *UserRepository) GetActiveTeammates(ctx context.Context, teamID int, exc
 	return users, nil
 }
 
+// GetByTeam возвращает всех участников команды по teamID, включая неактивных.
+func (u *UserRepository) GetByTeam(ctx context.Context, teamID int) ([]storage.User, *apperrors.AppError) {
+	const query = `
+		SELECT user_id, username, team_id, is_active, updated_at, version
+		FROM users
+		WHERE org_id = $1 AND team_id = $2
+		ORDER BY user_id
+	`
+
+	rows, err := conn(ctx, u.pool).Query(ctx, query, storage.OrgFrom(ctx), teamID)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	var users []storage.User
+	for rows.Next() {
+		var user storage.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("%v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return users, nil
+}
+
 // Exists проверяет существует ли пользователь по его ID(userID).
 func (u *UserRepository) Exists(ctx context.Context, userID string) (bool, *apperrors.AppError) {
-	const query = `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`
+	const query = `SELECT EXISTS(SELECT 1 FROM users WHERE org_id = $1 AND user_id = $2)`
 	var exists bool
-	err := u.pool.QueryRow(ctx, query, userID).Scan(&exists)
+	err := conn(ctx, u.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), userID).Scan(&exists)
 	if err != nil {
 		log.Printf("query failed: %v", err)
 		return false, &apperrors.AppError{
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// WebhookRepository - репозиторий подписчиков на доменные события в Postgres.
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookRepository создаёт экземпляр *WebhookRepository.
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+// Create регистрирует подписчика и возвращает его с присвоенным id.
+func (w *WebhookRepository) Create(ctx context.Context, webhook storage.Webhook) (storage.Webhook, *apperrors.AppError) {
+	const query = `
+		INSERT INTO webhooks (org_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	eventTypes := webhook.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	err := conn(ctx, w.pool).QueryRow(ctx, query, storage.OrgFrom(ctx), webhook.URL, webhook.Secret, eventTypes).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		log.Printf("insert webhook failed: %v", err)
+		return storage.Webhook{}, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	webhook.EventTypes = eventTypes
+	return webhook, nil
+}
+
+// List возвращает подписчиков организации в порядке регистрации.
+func (w *WebhookRepository) List(ctx context.Context) ([]storage.Webhook, *apperrors.AppError) {
+	const query = `SELECT id, url, secret, event_types, created_at FROM webhooks WHERE org_id = $1 ORDER BY id`
+
+	rows, err := conn(ctx, w.pool).Query(ctx, query, storage.OrgFrom(ctx))
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	defer rows.Close()
+
+	webhooks := make([]storage.Webhook, 0)
+	for rows.Next() {
+		var wh storage.Webhook
+		if err := rows.Scan(&wh.ID, &wh.URL, &wh.Secret, &wh.EventTypes, &wh.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, &apperrors.AppError{
+				Code:    apperrors.ErrInternalIssue,
+				Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+			}
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, &apperrors.AppError{
+			Code:    apperrors.ErrInternalIssue,
+			Message: apperrors.FromCode(apperrors.ErrInternalIssue),
+		}
+	}
+	return webhooks, nil
+}
@@ -2,15 +2,25 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
 )
 
+// TxManager выполняет несколько вызовов репозиториев в одной транзакции (unit of work).
+// Репозитории, вызванные с контекстом, переданным в fn, работают внутри этой транзакции;
+// вложенный Do присоединяется к внешней. fn может быть вызвана повторно, если
+// транзакция откатилась из-за конфликта сериализации.
+type TxManager interface {
+	Do(ctx context.Context, fn func(ctx context.Context) *apperrors.AppError) *apperrors.AppError
+}
+
 // UserRepository - репозиторий для упраления пользователями.
 type UserRepository interface {
 	Get(ctx context.Context, userID string) (User, *apperrors.AppError)
 	SetActive(ctx context.Context, userID string, isActive bool) (User, *apperrors.AppError)
 	GetActiveTeammates(ctx context.Context, teamID int, excludedID string) ([]User, *apperrors.AppError)
+	GetByTeam(ctx context.Context, teamID int) ([]User, *apperrors.AppError)
 	Exists(ctx context.Context, userID string) (bool, *apperrors.AppError)
 }
 
@@ -20,17 +30,167 @@ type TeamRepository interface {
 	Exists(ctx context.Context, teamName string) (bool, *apperrors.AppError)
 	GetByID(ctx context.Context, teamID int) (Team, *apperrors.AppError)
 	GetByName(ctx context.Context, teamName string) (Team, *apperrors.AppError)
+	// Touch увеличивает версию команды и возвращает новую: так помечаются изменения
+	// состава и настроек команды, хранящихся в других таблицах.
+	Touch(ctx context.Context, teamID int) (int64, *apperrors.AppError)
 }
 
 // PullRequestRepository - репозиторий для управления Pull Request'ами.
 type PullRequestRepository interface {
 	Create(ctx context.Context, pr PullRequest) *apperrors.AppError
 	Get(ctx context.Context, prID string) (PullRequest, *apperrors.AppError)
+	GetForUpdate(ctx context.Context, prID string) (PullRequest, *apperrors.AppError)
 	Exists(ctx context.Context, prID string) (bool, *apperrors.AppError)
 	MarkMerged(ctx context.Context, prID string) (PullRequest, *apperrors.AppError)
+	SetStatus(ctx context.Context, prID string, status PRStatus) (PullRequest, *apperrors.AppError)
 	ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) *apperrors.AppError
+	AddReviewer(ctx context.Context, prID, reviewerID string) *apperrors.AppError
 	GetByReviewer(ctx context.Context, reviewerID string) ([]PullRequest, *apperrors.AppError)
 	IsReviewerAssigned(ctx context.Context, reviewerID string) (bool, *apperrors.AppError)
 	CountAssignmentsByUser(ctx context.Context) (map[string]int, *apperrors.AppError)
 	CountAssignmentsByPR(ctx context.Context) (map[string]int, *apperrors.AppError)
 }
+
+// AssignmentLogRepository - репозиторий журнала назначений ревьюеров.
+type AssignmentLogRepository interface {
+	Add(ctx context.Context, entries []AssignmentLog) *apperrors.AppError
+	GetByPR(ctx context.Context, prID string) ([]AssignmentLog, *apperrors.AppError)
+}
+
+// PREventRepository - append-only история событий PR.
+type PREventRepository interface {
+	Add(ctx context.Context, events []PREvent) *apperrors.AppError
+	GetByPR(ctx context.Context, prID string) ([]PREvent, *apperrors.AppError)
+}
+
+// WebhookRepository - репозиторий подписчиков на доменные события.
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook Webhook) (Webhook, *apperrors.AppError)
+	List(ctx context.Context) ([]Webhook, *apperrors.AppError)
+}
+
+// OutboxRepository - transactional outbox доменных событий и очередь их доставки.
+// Add вызывается внутри транзакции бизнес-операции и сразу создаёт доставки для всех
+// подписчиков события. ClaimDue выдаёт доставки, которым пора отправляться, увеличивает
+// счётчик попыток и откладывает следующую попытку на lease, чтобы доставку не взял
+// другой обработчик; результат фиксируется MarkDelivered или MarkFailed.
+type OutboxRepository interface {
+	Add(ctx context.Context, events []OutboxEvent) *apperrors.AppError
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, *apperrors.AppError)
+	MarkDelivered(ctx context.Context, deliveryID int64) *apperrors.AppError
+	MarkFailed(ctx context.Context, deliveryID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError
+	ListDeliveries(ctx context.Context, webhookID int64, status DeliveryStatus, limit int) ([]WebhookDelivery, *apperrors.AppError)
+}
+
+// IntegrationRepository - данные интеграций с код-хостингами: соответствие логинов
+// провайдера пользователям, секреты входящих вебхуков и журнал уже обработанных входящих
+// доставок. IdentityLogin - обратный поиск; если у пользователя несколько логинов,
+// возвращается первый по алфавиту.
+type IntegrationRepository interface {
+	LinkIdentity(ctx context.Context, provider, login, userID string) *apperrors.AppError
+	ResolveIdentity(ctx context.Context, provider, login string) (string, *apperrors.AppError)
+	IdentityLogin(ctx context.Context, provider, userID string) (string, *apperrors.AppError)
+	RecordDelivery(ctx context.Context, provider, deliveryID string) (bool, *apperrors.AppError)
+	// SetInboundSecret создаёт или заменяет секрет провайдера организации.
+	SetInboundSecret(ctx context.Context, provider, secret string) *apperrors.AppError
+	GetInboundSecret(ctx context.Context, provider string) (InboundSecret, *apperrors.AppError)
+	DeleteInboundSecret(ctx context.Context, provider string) *apperrors.AppError
+	// InboundSecrets возвращает секреты провайдера всех организаций по возрастанию OrgID:
+	// организация входящей доставки определяется по секрету, подтвердившему её подпись.
+	InboundSecrets(ctx context.Context, provider string) ([]InboundSecret, *apperrors.AppError)
+}
+
+// ReviewerSyncRepository - очередь передачи ревьюеров на код-хостинг. Семантика
+// ClaimDue, MarkFailed и статусов совпадает с OutboxRepository.
+type ReviewerSyncRepository interface {
+	Enqueue(ctx context.Context, sync ReviewerSync) *apperrors.AppError
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]ReviewerSync, *apperrors.AppError)
+	MarkDone(ctx context.Context, syncID int64) *apperrors.AppError
+	MarkFailed(ctx context.Context, syncID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError
+	List(ctx context.Context, prID string) ([]ReviewerSync, *apperrors.AppError)
+}
+
+// NotificationRepository - настройки чат-уведомлений команд, настройки писем пользователей
+// и очередь уведомлений.
+// Семантика ClaimDue, MarkFailed и статусов совпадает с OutboxRepository.
+type NotificationRepository interface {
+	SetTeamChat(ctx context.Context, chat TeamChat) *apperrors.AppError
+	GetTeamChat(ctx context.Context, teamID int) (TeamChat, *apperrors.AppError)
+	DeleteTeamChat(ctx context.Context, teamID int) *apperrors.AppError
+	Enqueue(ctx context.Context, notifications []Notification) *apperrors.AppError
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Notification, *apperrors.AppError)
+	MarkDone(ctx context.Context, notificationID int64) *apperrors.AppError
+	MarkFailed(ctx context.Context, notificationID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError
+	List(ctx context.Context, status DeliveryStatus, limit int) ([]Notification, *apperrors.AppError)
+
+	// SetEmailPreference создаёт или заменяет настройку писем пользователя. При создании
+	// и смене режима LastDigestAt сбрасывается на текущий момент.
+	SetEmailPreference(ctx context.Context, pref EmailPreference) *apperrors.AppError
+	GetEmailPreference(ctx context.Context, userID string) (EmailPreference, *apperrors.AppError)
+	// ClaimDigests отмечает сводку отправленной в now для до limit пользователей в режиме
+	// EmailDigest, у которых LastDigestAt раньше cutoff, и возвращает их.
+	ClaimDigests(ctx context.Context, cutoff, now time.Time, limit int) ([]EmailPreference, *apperrors.AppError)
+}
+
+// ReviewSLARepository - сроки ревью команд и отметки о напоминаниях и эскалациях
+// назначений. ClaimReminders и ClaimEscalations рассматривают только открытые PR, ставят
+// отметку now не больше чем limit ревью, срок которых истёк, и возвращают их,
+// упорядоченные по PR и ревьюеру. Замена ревьюера (ReplaceReviewer) начинает отсчёт заново.
+type ReviewSLARepository interface {
+	SetTeamSLA(ctx context.Context, sla ReviewSLA) *apperrors.AppError
+	GetTeamSLA(ctx context.Context, teamID int) (ReviewSLA, *apperrors.AppError)
+	DeleteTeamSLA(ctx context.Context, teamID int) *apperrors.AppError
+	// GetReviews возвращает назначения ревьюеров PR, упорядоченные по ревьюеру.
+	GetReviews(ctx context.Context, prID string) ([]ReviewState, *apperrors.AppError)
+	// ClaimReminders - ревью без напоминания, назначенные раньше now - Within.
+	ClaimReminders(ctx context.Context, now time.Time, limit int) ([]OverdueReview, *apperrors.AppError)
+	// ClaimEscalations - ревью без эскалации в командах с Escalation, отличной от
+	// EscalateNone, назначенные раньше now - EscalateAfter.
+	ClaimEscalations(ctx context.Context, now time.Time, limit int) ([]OverdueReview, *apperrors.AppError)
+}
+
+// OrganizationRepository - хранилище организаций. В отличие от остальных репозиториев
+// не зависит от организации в контексте.
+type OrganizationRepository interface {
+	// Create сохраняет организацию; занятое имя - ORG_EXISTS.
+	Create(ctx context.Context, name string) (Organization, *apperrors.AppError)
+	GetByName(ctx context.Context, name string) (Organization, *apperrors.AppError)
+	// List возвращает все организации в порядке создания.
+	List(ctx context.Context) ([]Organization, *apperrors.AppError)
+}
+
+// APITokenRepository - хранилище API-токенов.
+type APITokenRepository interface {
+	// Create сохраняет токен и возвращает его с присвоенным id.
+	Create(ctx context.Context, token APIToken) (APIToken, *apperrors.AppError)
+	// GetByHash возвращает токен по хешу секрета, в том числе отозванный.
+	GetByHash(ctx context.Context, hash string) (APIToken, *apperrors.AppError)
+	// List возвращает все токены в порядке выпуска.
+	List(ctx context.Context) ([]APIToken, *apperrors.AppError)
+	// Revoke отзывает токен; повторный отзыв не меняет RevokedAt.
+	Revoke(ctx context.Context, tokenID int64) *apperrors.AppError
+}
+
+// AuditLogRepository - журнал административных действий организации.
+type AuditLogRepository interface {
+	// Add дописывает запись; CreatedAt и ID присваивает хранилище.
+	Add(ctx context.Context, entry AuditEntry) *apperrors.AppError
+	// List возвращает до filter.Limit записей, новые первыми.
+	List(ctx context.Context, filter AuditFilter) ([]AuditEntry, *apperrors.AppError)
+	// DeleteBefore удаляет записи всех организаций старше cutoff и возвращает их число.
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, *apperrors.AppError)
+}
+
+// IdempotencyRepository - ключи идемпотентности запросов и сохранённые ответы.
+type IdempotencyRepository interface {
+	// Claim сохраняет незавершённую запись rec (Client, Key, Fingerprint, ExpiresAt), если у
+	// ключа нет записи, действующей на момент now (просроченная заменяется), и возвращает её
+	// с true. Иначе возвращает действующую запись и false.
+	Claim(ctx context.Context, rec IdempotencyRecord, now time.Time) (IdempotencyRecord, bool, *apperrors.AppError)
+	// Complete сохраняет ответ на запрос с ключом и его заголовки headers.
+	Complete(ctx context.Context, client, key string, status int, headers map[string]string, response []byte) *apperrors.AppError
+	// Release удаляет незавершённую запись ключа, чтобы запрос можно было повторить.
+	Release(ctx context.Context, client, key string) *apperrors.AppError
+	// DeleteExpired удаляет записи всех организаций, просроченные на момент now, и возвращает их число.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, *apperrors.AppError)
+}
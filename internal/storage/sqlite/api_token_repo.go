@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// APITokenRepository - репозиторий API-токенов в SQLite.
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+// NewAPITokenRepository создаёт экземпляр *APITokenRepository.
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create сохраняет токен организации из контекста и возвращает его с присвоенным id.
+func (a *APITokenRepository) Create(ctx context.Context, token storage.APIToken) (storage.APIToken, *apperrors.AppError) {
+	const query = `INSERT INTO api_tokens (org_id, name, token_hash, scopes, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`
+
+	if token.Scopes == nil {
+		token.Scopes = []storage.TokenScope{}
+	}
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		log.Printf("marshal scopes failed: %v", err)
+		return storage.APIToken{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	token.OrgID = storage.OrgFrom(ctx)
+	token.CreatedAt = time.Now().UTC()
+	token.RevokedAt = nil
+	err = conn(ctx, a.db).QueryRowContext(ctx, query, token.OrgID, token.Name, token.Hash, string(scopes), token.CreatedAt).Scan(&token.ID)
+	if err != nil {
+		log.Printf("insert api token failed: %v", err)
+		return storage.APIToken{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return token, nil
+}
+
+// GetByHash возвращает токен по хешу секрета в любой организации: по токену
+// организация запроса и определяется.
+func (a *APITokenRepository) GetByHash(ctx context.Context, hash string) (storage.APIToken, *apperrors.AppError) {
+	const query = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+
+	token, err := scanAPIToken(conn(ctx, a.db).QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.APIToken{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query api token failed: %v", err)
+		return storage.APIToken{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return token, nil
+}
+
+// List возвращает токены организации в порядке выпуска.
+func (a *APITokenRepository) List(ctx context.Context) ([]storage.APIToken, *apperrors.AppError) {
+	const query = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE org_id = ? ORDER BY id`
+
+	rows, err := conn(ctx, a.db).QueryContext(ctx, query, storage.OrgFrom(ctx))
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	tokens := make([]storage.APIToken, 0)
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return tokens, nil
+}
+
+// Revoke отзывает токен.
+func (a *APITokenRepository) Revoke(ctx context.Context, tokenID int64) *apperrors.AppError {
+	const query = `UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, ?) WHERE org_id = ? AND id = ?`
+
+	res, err := conn(ctx, a.db).ExecContext(ctx, query, time.Now().UnixMilli(), storage.OrgFrom(ctx), tokenID)
+	if err != nil {
+		log.Printf("revoke api token failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+const apiTokenColumns = `id, org_id, name, token_hash, scopes, created_at, revoked_at`
+
+func scanAPIToken(row scanner) (storage.APIToken, error) {
+	var token storage.APIToken
+	var scopes string
+	var revokedAt sql.NullInt64
+	if err := row.Scan(&token.ID, &token.OrgID, &token.Name, &token.Hash, &scopes, &token.CreatedAt, &revokedAt); err != nil {
+		return storage.APIToken{}, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &token.Scopes); err != nil {
+		return storage.APIToken{}, err
+	}
+	token.RevokedAt = fromNullMilli(revokedAt)
+	return token, nil
+}
@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// AssignmentLogRepository - репозиторий журнала назначений ревьюеров в SQLite.
+type AssignmentLogRepository struct {
+	db *sql.DB
+}
+
+// NewAssignmentLogRepository создаёт экземпляр *AssignmentLogRepository.
+func NewAssignmentLogRepository(db *sql.DB) *AssignmentLogRepository {
+	return &AssignmentLogRepository{db: db}
+}
+
+// Add сохраняет записи о назначениях одной транзакцией. Списки кандидатов
+// и исключённых хранятся как JSON-текст.
+func (a *AssignmentLogRepository) Add(ctx context.Context, entries []storage.AssignmentLog) *apperrors.AppError {
+	const query = `
+		INSERT INTO assignment_log
+			(org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy, pool_size, candidates, excluded, draw, seed, created_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, a.db, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		for _, e := range entries {
+			candidates := e.Candidates
+			if candidates == nil {
+				candidates = []string{}
+			}
+			excluded := e.Excluded
+			if excluded == nil {
+				excluded = []storage.ExcludedCandidate{}
+			}
+
+			candidatesJSON, err := json.Marshal(candidates)
+			if err != nil {
+				return fmt.Errorf("marshal candidates failed: %w", err)
+			}
+			excludedJSON, err := json.Marshal(excluded)
+			if err != nil {
+				return fmt.Errorf("marshal excluded failed: %w", err)
+			}
+
+			_, err = conn(ctx, a.db).ExecContext(ctx, query, orgID, e.PullRequestID, e.ReviewerID, e.ReplacedReviewerID,
+				e.Strategy, e.PoolSize, string(candidatesJSON), string(excludedJSON), e.Draw, e.Seed, now)
+			if err != nil {
+				return fmt.Errorf("insert assignment log failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByPR возвращает журнал назначений по pr в хронологическом порядке.
+func (a *AssignmentLogRepository) GetByPR(ctx context.Context, prID string) ([]storage.AssignmentLog, *apperrors.AppError) {
+	const query = `
+		SELECT id, pull_request_id, reviewer_id, COALESCE(replaced_reviewer_id, ''), strategy,
+			pool_size, candidates, excluded, draw, seed, created_at
+		FROM assignment_log
+		WHERE org_id = ? AND pull_request_id = ?
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, a.db).QueryContext(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	entries := make([]storage.AssignmentLog, 0)
+	for rows.Next() {
+		var e storage.AssignmentLog
+		var candidates, excluded string
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.ReviewerID, &e.ReplacedReviewerID, &e.Strategy,
+			&e.PoolSize, &candidates, &excluded, &e.Draw, &e.Seed, &e.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		if err := json.Unmarshal([]byte(candidates), &e.Candidates); err != nil {
+			log.Printf("unmarshal candidates failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		if err := json.Unmarshal([]byte(excluded), &e.Excluded); err != nil {
+			log.Printf("unmarshal excluded failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return entries, nil
+}
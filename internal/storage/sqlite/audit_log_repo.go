@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// AuditLogRepository - репозиторий журнала аудита в SQLite.
+type AuditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository создаёт экземпляр *AuditLogRepository.
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Add дописывает запись в журнал организации из контекста.
+func (a *AuditLogRepository) Add(ctx context.Context, entry storage.AuditEntry) *apperrors.AppError {
+	const query = `
+		INSERT INTO audit_log (org_id, action, actor, request_id, source_ip, target_type, target_id,
+			before_state, after_state, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := conn(ctx, a.db).ExecContext(ctx, query, storage.OrgFrom(ctx), entry.Action, entry.Actor,
+		entry.RequestID, entry.SourceIP, entry.TargetType, entry.TargetID,
+		nullJSON(entry.Before), nullJSON(entry.After), time.Now().UnixMilli())
+	if err != nil {
+		log.Printf("insert audit entry failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return nil
+}
+
+// List возвращает записи организации по фильтру, новые первыми.
+func (a *AuditLogRepository) List(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, *apperrors.AppError) {
+	const query = `
+		SELECT id, action, actor, request_id, source_ip, target_type, target_id,
+			before_state, after_state, created_at
+		FROM audit_log
+		WHERE org_id = ?
+			AND (? = 0 OR id < ?)
+			AND (? = 0 OR created_at >= ?)
+			AND (? = 0 OR created_at < ?)
+			AND (? = '' OR actor = ?)
+			AND (? = '' OR action = ?)
+			AND (? = '' OR target_type = ?)
+			AND (? = '' OR target_id = ?)
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	since, until := unixMilliOrZero(filter.Since), unixMilliOrZero(filter.Until)
+	rows, err := conn(ctx, a.db).QueryContext(ctx, query, storage.OrgFrom(ctx),
+		filter.BeforeID, filter.BeforeID, since, since, until, until,
+		filter.Actor, filter.Actor, string(filter.Action), string(filter.Action),
+		filter.TargetType, filter.TargetType, filter.TargetID, filter.TargetID, filter.Limit)
+	if err != nil {
+		log.Printf("query audit log failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	entries := make([]storage.AuditEntry, 0)
+	for rows.Next() {
+		var e storage.AuditEntry
+		var before, after sql.NullString
+		var createdAt int64
+		if err := rows.Scan(&e.ID, &e.Action, &e.Actor, &e.RequestID, &e.SourceIP, &e.TargetType, &e.TargetID,
+			&before, &after, &createdAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		e.CreatedAt = time.UnixMilli(createdAt).UTC()
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return entries, nil
+}
+
+// DeleteBefore удаляет записи всех организаций старше cutoff.
+func (a *AuditLogRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, *apperrors.AppError) {
+	const query = `DELETE FROM audit_log WHERE created_at < ?`
+
+	res, err := conn(ctx, a.db).ExecContext(ctx, query, cutoff.UnixMilli())
+	if err != nil {
+		log.Printf("delete audit entries failed: %v", err)
+		return 0, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return 0, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return deleted, nil
+}
+
+// nullJSON возвращает NULL для пустого снимка.
+func nullJSON(b []byte) sql.NullString {
+	return sql.NullString{String: string(b), Valid: len(b) > 0}
+}
+
+// unixMilliOrZero возвращает unix-время в миллисекундах или 0 для нулевого t.
+func unixMilliOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// IdempotencyRepository - репозиторий ключей идемпотентности в SQLite.
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository создаёт экземпляр *IdempotencyRepository.
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim сохраняет незавершённую запись ключа, если у него нет действующей записи.
+func (i *IdempotencyRepository) Claim(ctx context.Context, rec storage.IdempotencyRecord, now time.Time) (storage.IdempotencyRecord, bool, *apperrors.AppError) {
+	const claimQuery = `
+		INSERT INTO idempotency_keys (org_id, client, idempotency_key, fingerprint, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (org_id, client, idempotency_key) DO UPDATE SET
+			fingerprint = excluded.fingerprint,
+			status = 0,
+			response = NULL,
+			response_headers = NULL,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= ?
+		RETURNING created_at
+	`
+	const existingQuery = `
+		SELECT fingerprint, status, response_headers, response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE org_id = ? AND client = ? AND idempotency_key = ?
+	`
+
+	org := storage.OrgFrom(ctx)
+	var createdAt int64
+	err := conn(ctx, i.db).QueryRowContext(ctx, claimQuery, org, rec.Client, rec.Key, rec.Fingerprint,
+		time.Now().UnixMilli(), rec.ExpiresAt.UnixMilli(), now.UnixMilli()).Scan(&createdAt)
+	if err == nil {
+		rec.CreatedAt = time.UnixMilli(createdAt).UTC()
+		rec.Status = 0
+		rec.Headers = nil
+		rec.Response = nil
+		return rec, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("claim idempotency key failed: %v", err)
+		return storage.IdempotencyRecord{}, false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	existing := storage.IdempotencyRecord{Client: rec.Client, Key: rec.Key}
+	var expiresAt int64
+	var headers sql.NullString
+	err = conn(ctx, i.db).QueryRowContext(ctx, existingQuery, org, rec.Client, rec.Key).
+		Scan(&existing.Fingerprint, &existing.Status, &headers, &existing.Response, &createdAt, &expiresAt)
+	if err != nil {
+		log.Printf("query idempotency key failed: %v", err)
+		return storage.IdempotencyRecord{}, false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if headers.Valid {
+		if err := json.Unmarshal([]byte(headers.String), &existing.Headers); err != nil {
+			log.Printf("decode idempotency response headers failed: %v", err)
+			return storage.IdempotencyRecord{}, false, apperrors.New(apperrors.ErrInternalIssue)
+		}
+	}
+	existing.CreatedAt = time.UnixMilli(createdAt).UTC()
+	existing.ExpiresAt = time.UnixMilli(expiresAt).UTC()
+	return existing, false, nil
+}
+
+// Complete сохраняет ответ на запрос с ключом.
+func (i *IdempotencyRepository) Complete(ctx context.Context, client, key string, status int, headers map[string]string, response []byte) *apperrors.AppError {
+	const query = `
+		UPDATE idempotency_keys SET status = ?, response_headers = ?, response = ?
+		WHERE org_id = ? AND client = ? AND idempotency_key = ?
+	`
+
+	var headersJSON sql.NullString
+	if headers != nil {
+		data, err := json.Marshal(headers)
+		if err != nil {
+			log.Printf("encode idempotency response headers failed: %v", err)
+			return apperrors.New(apperrors.ErrInternalIssue)
+		}
+		headersJSON = sql.NullString{String: string(data), Valid: true}
+	}
+
+	res, err := conn(ctx, i.db).ExecContext(ctx, query, status, headersJSON, response, storage.OrgFrom(ctx), client, key)
+	if err != nil {
+		log.Printf("complete idempotency key failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// Release удаляет незавершённую запись ключа.
+func (i *IdempotencyRepository) Release(ctx context.Context, client, key string) *apperrors.AppError {
+	const query = `
+		DELETE FROM idempotency_keys
+		WHERE org_id = ? AND client = ? AND idempotency_key = ? AND status = 0
+	`
+
+	if _, err := conn(ctx, i.db).ExecContext(ctx, query, storage.OrgFrom(ctx), client, key); err != nil {
+		log.Printf("release idempotency key failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return nil
+}
+
+// DeleteExpired удаляет просроченные записи всех организаций.
+func (i *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, *apperrors.AppError) {
+	const query = `DELETE FROM idempotency_keys WHERE expires_at <= ?`
+
+	res, err := conn(ctx, i.db).ExecContext(ctx, query, now.UnixMilli())
+	if err != nil {
+		log.Printf("delete expired idempotency keys failed: %v", err)
+		return 0, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return 0, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,176 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// IntegrationRepository - данные интеграций с код-хостингами в SQLite.
+type IntegrationRepository struct {
+	db *sql.DB
+}
+
+// NewIntegrationRepository создаёт экземпляр *IntegrationRepository.
+func NewIntegrationRepository(db *sql.DB) *IntegrationRepository {
+	return &IntegrationRepository{db: db}
+}
+
+// LinkIdentity связывает логин провайдера с пользователем; существующая связь перезаписывается.
+func (i *IntegrationRepository) LinkIdentity(ctx context.Context, provider, login, userID string) *apperrors.AppError {
+	const query = `
+		INSERT INTO user_identities (org_id, provider, login, user_id, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (org_id, provider, login) DO UPDATE SET user_id = excluded.user_id
+	`
+
+	if _, err := conn(ctx, i.db).ExecContext(ctx, query, storage.OrgFrom(ctx), provider, login, userID, time.Now().UTC()); err != nil {
+		log.Printf("upsert identity failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return nil
+}
+
+// ResolveIdentity возвращает user_id, связанный с логином провайдера.
+func (i *IntegrationRepository) ResolveIdentity(ctx context.Context, provider, login string) (string, *apperrors.AppError) {
+	const query = `SELECT user_id FROM user_identities WHERE org_id = ? AND provider = ? AND login = ?`
+
+	var userID string
+	err := conn(ctx, i.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), provider, login).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query identity failed: %v", err)
+		return "", apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return userID, nil
+}
+
+// IdentityLogin возвращает логин провайдера, связанный с пользователем.
+func (i *IntegrationRepository) IdentityLogin(ctx context.Context, provider, userID string) (string, *apperrors.AppError) {
+	const query = `
+		SELECT login FROM user_identities
+		WHERE org_id = ? AND provider = ? AND user_id = ?
+		ORDER BY login LIMIT 1
+	`
+
+	var login string
+	err := conn(ctx, i.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), provider, userID).Scan(&login)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query identity login failed: %v", err)
+		return "", apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return login, nil
+}
+
+// RecordDelivery запоминает входящую доставку и возвращает false, если она уже была обработана.
+func (i *IntegrationRepository) RecordDelivery(ctx context.Context, provider, deliveryID string) (bool, *apperrors.AppError) {
+	const query = `
+		INSERT INTO inbound_deliveries (org_id, provider, delivery_id, received_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (org_id, provider, delivery_id) DO NOTHING
+	`
+
+	res, err := conn(ctx, i.db).ExecContext(ctx, query, storage.OrgFrom(ctx), provider, deliveryID, time.Now().UTC())
+	if err != nil {
+		log.Printf("insert delivery failed: %v", err)
+		return false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return affected == 1, nil
+}
+
+// SetInboundSecret создаёт или заменяет секрет провайдера организации.
+func (i *IntegrationRepository) SetInboundSecret(ctx context.Context, provider, secret string) *apperrors.AppError {
+	const query = `
+		INSERT INTO inbound_secrets (org_id, provider, secret, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (org_id, provider) DO UPDATE SET secret = excluded.secret, created_at = excluded.created_at
+	`
+
+	if _, err := conn(ctx, i.db).ExecContext(ctx, query, storage.OrgFrom(ctx), provider, secret, time.Now().UTC()); err != nil {
+		log.Printf("upsert inbound secret failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return nil
+}
+
+// GetInboundSecret возвращает секрет провайдера организации.
+func (i *IntegrationRepository) GetInboundSecret(ctx context.Context, provider string) (storage.InboundSecret, *apperrors.AppError) {
+	const query = `
+		SELECT org_id, provider, secret, created_at FROM inbound_secrets
+		WHERE org_id = ? AND provider = ?
+	`
+
+	var s storage.InboundSecret
+	err := conn(ctx, i.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), provider).Scan(&s.OrgID, &s.Provider, &s.Secret, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.InboundSecret{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query inbound secret failed: %v", err)
+		return storage.InboundSecret{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return s, nil
+}
+
+// DeleteInboundSecret удаляет секрет провайдера организации.
+func (i *IntegrationRepository) DeleteInboundSecret(ctx context.Context, provider string) *apperrors.AppError {
+	const query = `DELETE FROM inbound_secrets WHERE org_id = ? AND provider = ?`
+
+	res, err := conn(ctx, i.db).ExecContext(ctx, query, storage.OrgFrom(ctx), provider)
+	if err != nil {
+		log.Printf("delete inbound secret failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// InboundSecrets возвращает секреты провайдера всех организаций.
+func (i *IntegrationRepository) InboundSecrets(ctx context.Context, provider string) ([]storage.InboundSecret, *apperrors.AppError) {
+	const query = `
+		SELECT org_id, provider, secret, created_at FROM inbound_secrets
+		WHERE provider = ?
+		ORDER BY org_id
+	`
+
+	rows, err := conn(ctx, i.db).QueryContext(ctx, query, provider)
+	if err != nil {
+		log.Printf("query inbound secrets failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	secrets := make([]storage.InboundSecret, 0)
+	for rows.Next() {
+		var s storage.InboundSecret
+		if err := rows.Scan(&s.OrgID, &s.Provider, &s.Secret, &s.CreatedAt); err != nil {
+			log.Printf("scan inbound secret failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		secrets = append(secrets, s)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("iterate inbound secrets failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return secrets, nil
+}
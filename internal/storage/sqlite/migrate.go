@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.up.sql
+var migrationsFS embed.FS
+
+// Migrate применяет к db ещё не применённые миграции из migrations/. Номер последней
+// применённой миграции хранится в schema_migrations, как у golang-migrate.
+//
+// Миграции выполняются на отдельном соединении с выключенными внешними ключами, чтобы
+// можно было пересоздавать таблицы (так в SQLite меняются CHECK и прочие ограничения);
+// целостность ссылок проверяется foreign_key_check перед фиксацией каждой миграции.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return fmt.Errorf("disable foreign keys failed: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), `PRAGMA foreign_keys = ON`); err != nil {
+			log.Printf("enable foreign keys failed: %v", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("create schema_migrations failed: %w", err)
+	}
+
+	var current int64
+	err = conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("read schema version failed: %w", err)
+	}
+
+	files, err := fs.Glob(migrationsFS, "migrations/*.up.sql")
+	if err != nil {
+		return fmt.Errorf("list migrations failed: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, name := range files {
+		version, err := migrationVersion(name)
+		if err != nil {
+			return err
+		}
+		if version <= current {
+			continue
+		}
+
+		body, err := migrationsFS.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("read migration %s failed: %w", name, err)
+		}
+
+		if err := applyMigration(ctx, conn, version, string(body)); err != nil {
+			return fmt.Errorf("apply migration %s failed: %w", name, err)
+		}
+		log.Printf("sqlite migration %d applied", version)
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, version int64, body string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if err := foreignKeyCheck(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES (?, FALSE)`, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// foreignKeyCheck возвращает ошибку, если после миграции остались висячие ссылки.
+func foreignKeyCheck(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return fmt.Errorf("foreign key check failed: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int64
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return fmt.Errorf("foreign key check failed: %w", err)
+		}
+		return fmt.Errorf("foreign key violation: %s row %d references missing %s", table, rowid.Int64, parent)
+	}
+	return rows.Err()
+}
+
+// migrationVersion извлекает номер из имени файла вида NNN_name.up.sql.
+func migrationVersion(name string) (int64, error) {
+	base := strings.TrimPrefix(name, "migrations/")
+	prefix, _, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, fmt.Errorf("invalid migration name %q", name)
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration name %q: %w", name, err)
+	}
+	return version, nil
+}
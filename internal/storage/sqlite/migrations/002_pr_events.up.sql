@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS pr_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id),
+    event_type TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pull_request_id ON pr_events(pull_request_id);
+
+CREATE TRIGGER IF NOT EXISTS pr_events_no_update BEFORE UPDATE ON pr_events
+BEGIN
+    SELECT RAISE(ABORT, 'pr_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS pr_events_no_delete BEFORE DELETE ON pr_events
+BEGIN
+    SELECT RAISE(ABORT, 'pr_events is append-only');
+END;
@@ -0,0 +1,35 @@
+-- CHECK в SQLite не изменить через ALTER TABLE, поэтому pull_requests пересоздаётся
+-- с новым допустимым статусом CLOSED. Migrate выполняет миграции с выключенными
+-- внешними ключами, так что ссылки из reviews, assignment_log и pr_events сохраняются.
+CREATE TABLE pull_requests_new (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED', 'CLOSED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    merged_at TIMESTAMP
+);
+
+INSERT INTO pull_requests_new (pull_request_id, pull_request_name, author_id, status, created_at, merged_at)
+SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at FROM pull_requests;
+
+DROP TABLE pull_requests;
+
+ALTER TABLE pull_requests_new RENAME TO pull_requests;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_status ON pull_requests(status);
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, login)
+);
+
+CREATE TABLE IF NOT EXISTS inbound_deliveries (
+    provider TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, delivery_id)
+);
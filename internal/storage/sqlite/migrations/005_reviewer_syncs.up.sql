@@ -0,0 +1,18 @@
+-- next_attempt_at хранится как unix-время в миллисекундах, как в webhook_deliveries.
+CREATE TABLE IF NOT EXISTS reviewer_syncs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    removed_reviewers TEXT NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_syncs_due ON reviewer_syncs(status, next_attempt_at);
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_syncs_pull_request_id ON reviewer_syncs(pull_request_id);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
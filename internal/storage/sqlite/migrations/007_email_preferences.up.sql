@@ -0,0 +1,10 @@
+-- last_digest_at хранится как unix-время в миллисекундах.
+CREATE TABLE IF NOT EXISTS email_preferences (
+    user_id TEXT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    mode TEXT NOT NULL CHECK (mode IN ('immediate', 'digest', 'none')),
+    last_digest_at INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_preferences_digest ON email_preferences(mode, last_digest_at);
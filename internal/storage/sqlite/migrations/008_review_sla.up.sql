@@ -0,0 +1,12 @@
+-- reminded_at и escalated_at хранятся как unix-время в миллисекундах.
+CREATE TABLE IF NOT EXISTS review_slas (
+    team_id INTEGER PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    within_ms INTEGER NOT NULL CHECK (within_ms > 0),
+    escalation TEXT NOT NULL DEFAULT 'none' CHECK (escalation IN ('none', 'reassign', 'lead')),
+    escalate_after_ms INTEGER NOT NULL DEFAULT 0,
+    lead_id TEXT REFERENCES users(user_id),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE reviews ADD COLUMN reminded_at INTEGER;
+ALTER TABLE reviews ADD COLUMN escalated_at INTEGER;
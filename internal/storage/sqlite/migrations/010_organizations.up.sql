@@ -0,0 +1,196 @@
+-- Организации изолируют данные подразделений; существующие данные переходят в организацию
+-- default (id = 1). Имена команд и id PR уникальны в пределах организации. Ключи и
+-- ограничения UNIQUE в SQLite не изменить через ALTER TABLE, поэтому такие таблицы
+-- пересоздаются (внешние ключи при миграции выключены, см. 004_integrations).
+CREATE TABLE IF NOT EXISTS organizations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO organizations (id, name) VALUES (1, 'default');
+
+CREATE TABLE teams_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    team_name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (org_id, team_name)
+);
+
+INSERT INTO teams_new (id, org_id, team_name, created_at)
+SELECT id, 1, team_name, created_at FROM teams;
+
+DROP TABLE teams;
+
+ALTER TABLE teams_new RENAME TO teams;
+
+CREATE TABLE pull_requests_new (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    pull_request_id TEXT NOT NULL,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id),
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED', 'CLOSED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    merged_at TIMESTAMP,
+    PRIMARY KEY (org_id, pull_request_id)
+);
+
+INSERT INTO pull_requests_new (org_id, pull_request_id, pull_request_name, author_id, status, created_at, merged_at)
+SELECT 1, pull_request_id, pull_request_name, author_id, status, created_at, merged_at FROM pull_requests;
+
+DROP TABLE pull_requests;
+
+ALTER TABLE pull_requests_new RENAME TO pull_requests;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_status ON pull_requests(status);
+
+CREATE TABLE reviews_new (
+    org_id INTEGER NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reminded_at INTEGER,
+    escalated_at INTEGER,
+    PRIMARY KEY (org_id, pull_request_id, reviewer_id),
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE
+);
+
+INSERT INTO reviews_new (org_id, pull_request_id, reviewer_id, assigned_at, reminded_at, escalated_at)
+SELECT 1, pull_request_id, reviewer_id, assigned_at, reminded_at, escalated_at FROM reviews;
+
+DROP TABLE reviews;
+
+ALTER TABLE reviews_new RENAME TO reviews;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_reviewer_id ON reviews(reviewer_id);
+
+CREATE TABLE assignment_log_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    replaced_reviewer_id TEXT REFERENCES users(user_id),
+    strategy TEXT NOT NULL,
+    pool_size INTEGER NOT NULL,
+    candidates TEXT NOT NULL DEFAULT '[]',
+    excluded TEXT NOT NULL DEFAULT '[]',
+    draw INTEGER NOT NULL,
+    seed INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE
+);
+
+INSERT INTO assignment_log_new (id, org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy,
+    pool_size, candidates, excluded, draw, seed, created_at)
+SELECT id, 1, pull_request_id, reviewer_id, replaced_reviewer_id, strategy,
+    pool_size, candidates, excluded, draw, seed, created_at FROM assignment_log;
+
+DROP TABLE assignment_log;
+
+ALTER TABLE assignment_log_new RENAME TO assignment_log;
+
+CREATE INDEX IF NOT EXISTS idx_assignment_log_pull_request_id ON assignment_log(org_id, pull_request_id);
+
+-- Триггеры pr_events удаляются вместе с таблицей и создаются заново.
+CREATE TABLE pr_events_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id)
+);
+
+INSERT INTO pr_events_new (id, org_id, pull_request_id, event_type, actor, payload, created_at)
+SELECT id, 1, pull_request_id, event_type, actor, payload, created_at FROM pr_events;
+
+DROP TABLE pr_events;
+
+ALTER TABLE pr_events_new RENAME TO pr_events;
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pull_request_id ON pr_events(org_id, pull_request_id);
+
+CREATE TRIGGER IF NOT EXISTS pr_events_no_update BEFORE UPDATE ON pr_events
+BEGIN
+    SELECT RAISE(ABORT, 'pr_events is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS pr_events_no_delete BEFORE DELETE ON pr_events
+BEGIN
+    SELECT RAISE(ABORT, 'pr_events is append-only');
+END;
+
+CREATE TABLE reviewer_syncs_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL,
+    provider TEXT NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    removed_reviewers TEXT NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at INTEGER NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE
+);
+
+INSERT INTO reviewer_syncs_new (id, org_id, provider, pull_request_id, removed_reviewers, status, attempts,
+    next_attempt_at, last_error, updated_at)
+SELECT id, 1, provider, pull_request_id, removed_reviewers, status, attempts,
+    next_attempt_at, last_error, updated_at FROM reviewer_syncs;
+
+DROP TABLE reviewer_syncs;
+
+ALTER TABLE reviewer_syncs_new RENAME TO reviewer_syncs;
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_syncs_due ON reviewer_syncs(status, next_attempt_at);
+
+CREATE INDEX IF NOT EXISTS idx_reviewer_syncs_pull_request_id ON reviewer_syncs(org_id, pull_request_id);
+
+CREATE TABLE user_identities_new (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, provider, login)
+);
+
+INSERT INTO user_identities_new (org_id, provider, login, user_id, created_at)
+SELECT 1, provider, login, user_id, created_at FROM user_identities;
+
+DROP TABLE user_identities;
+
+ALTER TABLE user_identities_new RENAME TO user_identities;
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+CREATE TABLE inbound_deliveries_new (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    provider TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, provider, delivery_id)
+);
+
+INSERT INTO inbound_deliveries_new (org_id, provider, delivery_id, received_at)
+SELECT 1, provider, delivery_id, received_at FROM inbound_deliveries;
+
+DROP TABLE inbound_deliveries;
+
+ALTER TABLE inbound_deliveries_new RENAME TO inbound_deliveries;
+
+-- В остальные таблицы колонка добавляется; DEFAULT 1 заполняет существующие строки,
+-- а новые строки репозитории всегда пишут с явной организацией.
+ALTER TABLE users ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE webhooks ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE outbox_events ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE notifications ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE api_tokens ADD COLUMN org_id INTEGER NOT NULL DEFAULT 1 REFERENCES organizations(id);
+
+CREATE INDEX IF NOT EXISTS idx_users_org_id ON users(org_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_org_id ON webhooks(org_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_org_id ON notifications(org_id, id);
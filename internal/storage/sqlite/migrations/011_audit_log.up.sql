@@ -0,0 +1,18 @@
+-- created_at хранится как unix-время в миллисекундах, чтобы фильтровать по интервалу.
+-- before_state и after_state - JSON-снимки объекта, NULL - объекта не было.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    source_ip TEXT NOT NULL DEFAULT '',
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    before_state TEXT,
+    after_state TEXT,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_org_id ON audit_log(org_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
@@ -0,0 +1,15 @@
+-- Ключи идемпотентности запросов. Времена хранятся как unix-время в миллисекундах;
+-- status = 0 - запрос ещё выполняется, ответа нет.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    client TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    response BLOB,
+    created_at INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL,
+    PRIMARY KEY (org_id, client, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,30 @@
+-- Ревьюеры стратегии WHOLE_POOL назначаются без розыгрыша, поэтому выпавшего значения
+-- у них нет; раньше в draw записывался порядковый номер кандидата. NOT NULL в SQLite
+-- не снять через ALTER TABLE, поэтому assignment_log пересоздаётся.
+CREATE TABLE assignment_log_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    replaced_reviewer_id TEXT REFERENCES users(user_id),
+    strategy TEXT NOT NULL,
+    pool_size INTEGER NOT NULL,
+    candidates TEXT NOT NULL DEFAULT '[]',
+    excluded TEXT NOT NULL DEFAULT '[]',
+    draw INTEGER,
+    seed INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE
+);
+
+INSERT INTO assignment_log_new (id, org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy,
+    pool_size, candidates, excluded, draw, seed, created_at)
+SELECT id, org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy,
+    pool_size, candidates, excluded, CASE WHEN strategy = 'WHOLE_POOL' THEN NULL ELSE draw END, seed, created_at
+FROM assignment_log;
+
+DROP TABLE assignment_log;
+
+ALTER TABLE assignment_log_new RENAME TO assignment_log;
+
+CREATE INDEX IF NOT EXISTS idx_assignment_log_pull_request_id ON assignment_log(org_id, pull_request_id);
@@ -0,0 +1,11 @@
+-- Секреты входящих вебхуков код-хостингов по организациям: организация доставки
+-- определяется по секрету, подтвердившему подпись, а не по параметрам запроса.
+CREATE TABLE IF NOT EXISTS inbound_secrets (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    provider TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbound_secrets_provider ON inbound_secrets(provider);
@@ -0,0 +1,2 @@
+-- Заголовки сохранённого ответа (ETag, Location) в JSON, которые повторяются вместе с ним.
+ALTER TABLE idempotency_keys ADD COLUMN response_headers TEXT;
@@ -0,0 +1,158 @@
+-- user_id уникален в пределах организации, а не глобально (см. 010_organizations).
+-- Ссылки на users становятся составными (org_id, user_id), поэтому users и все
+-- ссылающиеся на неё таблицы пересоздаются; email_preferences и review_slas получают
+-- колонку org_id.
+CREATE TABLE users_new (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    user_id TEXT NOT NULL,
+    username TEXT NOT NULL,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    version INTEGER NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, user_id)
+);
+
+INSERT INTO users_new (org_id, user_id, username, team_id, is_active, version, updated_at)
+SELECT org_id, user_id, username, team_id, is_active, version, updated_at FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX IF NOT EXISTS idx_users_team_id_is_active ON users(team_id, is_active);
+
+CREATE TABLE pull_requests_new (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    pull_request_id TEXT NOT NULL,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED', 'CLOSED')),
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    merged_at TIMESTAMP,
+    PRIMARY KEY (org_id, pull_request_id),
+    FOREIGN KEY (org_id, author_id) REFERENCES users(org_id, user_id)
+);
+
+INSERT INTO pull_requests_new (org_id, pull_request_id, pull_request_name, author_id, status, version,
+    created_at, merged_at)
+SELECT org_id, pull_request_id, pull_request_name, author_id, status, version, created_at, merged_at
+FROM pull_requests;
+
+DROP TABLE pull_requests;
+
+ALTER TABLE pull_requests_new RENAME TO pull_requests;
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_status ON pull_requests(status);
+
+CREATE TABLE reviews_new (
+    org_id INTEGER NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    assigned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reminded_at INTEGER,
+    escalated_at INTEGER,
+    PRIMARY KEY (org_id, pull_request_id, reviewer_id),
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE,
+    FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id)
+);
+
+INSERT INTO reviews_new (org_id, pull_request_id, reviewer_id, assigned_at, reminded_at, escalated_at)
+SELECT org_id, pull_request_id, reviewer_id, assigned_at, reminded_at, escalated_at FROM reviews;
+
+DROP TABLE reviews;
+
+ALTER TABLE reviews_new RENAME TO reviews;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_reviewer_id ON reviews(org_id, reviewer_id);
+
+CREATE TABLE assignment_log_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    org_id INTEGER NOT NULL,
+    pull_request_id TEXT NOT NULL,
+    reviewer_id TEXT NOT NULL,
+    replaced_reviewer_id TEXT,
+    strategy TEXT NOT NULL,
+    pool_size INTEGER NOT NULL,
+    candidates TEXT NOT NULL DEFAULT '[]',
+    excluded TEXT NOT NULL DEFAULT '[]',
+    draw INTEGER,
+    seed INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE,
+    FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id),
+    FOREIGN KEY (org_id, replaced_reviewer_id) REFERENCES users(org_id, user_id)
+);
+
+INSERT INTO assignment_log_new (id, org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy,
+    pool_size, candidates, excluded, draw, seed, created_at)
+SELECT id, org_id, pull_request_id, reviewer_id, replaced_reviewer_id, strategy,
+    pool_size, candidates, excluded, draw, seed, created_at FROM assignment_log;
+
+DROP TABLE assignment_log;
+
+ALTER TABLE assignment_log_new RENAME TO assignment_log;
+
+CREATE INDEX IF NOT EXISTS idx_assignment_log_pull_request_id ON assignment_log(org_id, pull_request_id);
+
+CREATE TABLE user_identities_new (
+    org_id INTEGER NOT NULL REFERENCES organizations(id),
+    provider TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, provider, login),
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE
+);
+
+INSERT INTO user_identities_new (org_id, provider, login, user_id, created_at)
+SELECT org_id, provider, login, user_id, created_at FROM user_identities;
+
+DROP TABLE user_identities;
+
+ALTER TABLE user_identities_new RENAME TO user_identities;
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(org_id, user_id);
+
+CREATE TABLE email_preferences_new (
+    org_id INTEGER NOT NULL,
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    mode TEXT NOT NULL CHECK (mode IN ('immediate', 'digest', 'none')),
+    last_digest_at INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, user_id),
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE
+);
+
+INSERT INTO email_preferences_new (org_id, user_id, email, mode, last_digest_at, updated_at)
+SELECT u.org_id, p.user_id, p.email, p.mode, p.last_digest_at, p.updated_at
+FROM email_preferences p
+JOIN users u ON u.user_id = p.user_id;
+
+DROP TABLE email_preferences;
+
+ALTER TABLE email_preferences_new RENAME TO email_preferences;
+
+CREATE INDEX IF NOT EXISTS idx_email_preferences_digest ON email_preferences(mode, last_digest_at);
+
+CREATE TABLE review_slas_new (
+    team_id INTEGER PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    org_id INTEGER NOT NULL,
+    within_ms INTEGER NOT NULL CHECK (within_ms > 0),
+    escalation TEXT NOT NULL DEFAULT 'none' CHECK (escalation IN ('none', 'reassign', 'lead')),
+    escalate_after_ms INTEGER NOT NULL DEFAULT 0,
+    lead_id TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (org_id, lead_id) REFERENCES users(org_id, user_id)
+);
+
+INSERT INTO review_slas_new (team_id, org_id, within_ms, escalation, escalate_after_ms, lead_id, updated_at)
+SELECT s.team_id, t.org_id, s.within_ms, s.escalation, s.escalate_after_ms, s.lead_id, s.updated_at
+FROM review_slas s
+JOIN teams t ON t.id = s.team_id;
+
+DROP TABLE review_slas;
+
+ALTER TABLE review_slas_new RENAME TO review_slas;
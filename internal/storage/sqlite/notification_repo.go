@@ -0,0 +1,317 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// NotificationRepository - настройки чат-уведомлений и очередь уведомлений в SQLite.
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository создаёт экземпляр *NotificationRepository.
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const notificationColumns = `
+	id, channel, target, subject, body, status, attempts, next_attempt_at, last_error, created_at, updated_at
+`
+
+// SetTeamChat задаёт вебхук команды; существующая настройка перезаписывается. Команда
+// другой организации - NOT_FOUND.
+func (n *NotificationRepository) SetTeamChat(ctx context.Context, chat storage.TeamChat) *apperrors.AppError {
+	const query = `
+		INSERT INTO team_chats (team_id, webhook_url, updated_at)
+		SELECT id, ?, ? FROM teams WHERE org_id = ? AND id = ?
+		ON CONFLICT (team_id) DO UPDATE SET webhook_url = excluded.webhook_url, updated_at = excluded.updated_at
+	`
+	return n.update(ctx, query, chat.WebhookURL, time.Now().UTC(), storage.OrgFrom(ctx), chat.TeamID)
+}
+
+// GetTeamChat возвращает вебхук команды.
+func (n *NotificationRepository) GetTeamChat(ctx context.Context, teamID int) (storage.TeamChat, *apperrors.AppError) {
+	const query = `
+		SELECT c.team_id, c.webhook_url, c.updated_at
+		FROM team_chats c JOIN teams t ON t.id = c.team_id
+		WHERE t.org_id = ? AND c.team_id = ?
+	`
+
+	var chat storage.TeamChat
+	err := conn(ctx, n.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), teamID).Scan(&chat.TeamID, &chat.WebhookURL, &chat.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.TeamChat{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query team chat failed: %v", err)
+		return storage.TeamChat{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return chat, nil
+}
+
+// DeleteTeamChat отключает чат-уведомления команды.
+func (n *NotificationRepository) DeleteTeamChat(ctx context.Context, teamID int) *apperrors.AppError {
+	const query = `
+		DELETE FROM team_chats
+		WHERE team_id = ? AND team_id IN (SELECT id FROM teams WHERE org_id = ?)
+	`
+	return n.update(ctx, query, teamID, storage.OrgFrom(ctx))
+}
+
+// Enqueue ставит уведомления в очередь; они готовы к отправке сразу.
+func (n *NotificationRepository) Enqueue(ctx context.Context, notifications []storage.Notification) *apperrors.AppError {
+	const query = `
+		INSERT INTO notifications (org_id, channel, target, subject, body, next_attempt_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, n.db, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		for _, nt := range notifications {
+			if _, err := conn(ctx, n.db).ExecContext(ctx, query, orgID, nt.Channel, nt.Target, nt.Subject, nt.Body,
+				now.UnixMilli(), now, now); err != nil {
+				return fmt.Errorf("insert notification failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDue выдаёт до limit уведомлений всех организаций, которым пора отправляться. Транзакция SQLite
+// эксклюзивна, поэтому выборка и обновление не пересекаются с другими обработчиками.
+func (n *NotificationRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.Notification, *apperrors.AppError) {
+	const selectDue = `
+		SELECT id FROM notifications
+		WHERE status = 'PENDING' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`
+	const claim = `UPDATE notifications SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ? WHERE id = ?`
+	const selectClaimed = `SELECT ` + notificationColumns + ` FROM notifications WHERE id = ?`
+
+	var notifications []storage.Notification
+	appErr := inTx(ctx, n.db, func(ctx context.Context) error {
+		rows, err := conn(ctx, n.db).QueryContext(ctx, selectDue, now.UnixMilli(), limit)
+		if err != nil {
+			return fmt.Errorf("select due notifications failed: %w", err)
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan notification id failed: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows error: %w", err)
+		}
+
+		notifications = make([]storage.Notification, 0, len(ids))
+		for _, id := range ids {
+			if _, err := conn(ctx, n.db).ExecContext(ctx, claim, now.Add(lease).UnixMilli(), time.Now().UTC(), id); err != nil {
+				return fmt.Errorf("claim notification failed: %w", err)
+			}
+			nt, err := scanNotification(conn(ctx, n.db).QueryRowContext(ctx, selectClaimed, id))
+			if err != nil {
+				return fmt.Errorf("read claimed notification failed: %w", err)
+			}
+			notifications = append(notifications, nt)
+		}
+		return nil
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	return notifications, nil
+}
+
+// MarkDone помечает уведомление как отправленное.
+func (n *NotificationRepository) MarkDone(ctx context.Context, notificationID int64) *apperrors.AppError {
+	const query = `UPDATE notifications SET status = 'DELIVERED', last_error = '', updated_at = ? WHERE id = ?`
+	return n.update(ctx, query, time.Now().UTC(), notificationID)
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит уведомление в DEAD.
+func (n *NotificationRepository) MarkFailed(ctx context.Context, notificationID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	const query = `
+		UPDATE notifications
+		SET status = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`
+	status := storage.DeliveryPending
+	if dead {
+		status = storage.DeliveryDead
+	}
+	return n.update(ctx, query, status, nextAttemptAt.UnixMilli(), lastErr, time.Now().UTC(), notificationID)
+}
+
+func (n *NotificationRepository) update(ctx context.Context, query string, args ...any) *apperrors.AppError {
+	res, err := conn(ctx, n.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("update notification failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// List возвращает последние уведомления организации, новые первыми. Пустой status означает «без фильтра».
+func (n *NotificationRepository) List(ctx context.Context, status storage.DeliveryStatus, limit int) ([]storage.Notification, *apperrors.AppError) {
+	const query = `SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE org_id = ? AND (? = '' OR status = ?)
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := conn(ctx, n.db).QueryContext(ctx, query, storage.OrgFrom(ctx), string(status), string(status), limit)
+	if err != nil {
+		log.Printf("query notifications failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	notifications := make([]storage.Notification, 0)
+	for rows.Next() {
+		nt, err := scanNotification(rows)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		notifications = append(notifications, nt)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return notifications, nil
+}
+
+func scanNotification(row scanner) (storage.Notification, error) {
+	var nt storage.Notification
+	var nextAttemptAt int64
+	err := row.Scan(&nt.ID, &nt.Channel, &nt.Target, &nt.Subject, &nt.Body, &nt.Status, &nt.Attempts,
+		&nextAttemptAt, &nt.LastError, &nt.CreatedAt, &nt.UpdatedAt)
+	if err != nil {
+		return storage.Notification{}, err
+	}
+	nt.NextAttemptAt = time.UnixMilli(nextAttemptAt).UTC()
+	return nt, nil
+}
+
+// SetEmailPreference создаёт или заменяет настройку писем пользователя. Пользователь
+// другой организации - NOT_FOUND.
+func (n *NotificationRepository) SetEmailPreference(ctx context.Context, pref storage.EmailPreference) *apperrors.AppError {
+	const query = `
+		INSERT INTO email_preferences (org_id, user_id, email, mode, last_digest_at, updated_at)
+		SELECT org_id, user_id, ?, ?, ?, ? FROM users WHERE org_id = ? AND user_id = ?
+		ON CONFLICT (org_id, user_id) DO UPDATE SET
+			email = excluded.email,
+			mode = excluded.mode,
+			last_digest_at = CASE WHEN email_preferences.mode <> excluded.mode
+				THEN excluded.last_digest_at ELSE email_preferences.last_digest_at END,
+			updated_at = excluded.updated_at
+	`
+
+	now := time.Now().UTC()
+	return n.update(ctx, query, pref.Email, pref.Mode, now.UnixMilli(), now, storage.OrgFrom(ctx), pref.UserID)
+}
+
+// GetEmailPreference возвращает настройку писем пользователя.
+func (n *NotificationRepository) GetEmailPreference(ctx context.Context, userID string) (storage.EmailPreference, *apperrors.AppError) {
+	const query = `
+		SELECT user_id, org_id, email, mode, last_digest_at, updated_at
+		FROM email_preferences
+		WHERE org_id = ? AND user_id = ?
+	`
+
+	pref, err := scanEmailPreference(conn(ctx, n.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.EmailPreference{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query email preference failed: %v", err)
+		return storage.EmailPreference{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return pref, nil
+}
+
+// ClaimDigests отмечает сводку отправленной для пользователей всех организаций, которым
+// она положена.
+func (n *NotificationRepository) ClaimDigests(ctx context.Context, cutoff, now time.Time, limit int) ([]storage.EmailPreference, *apperrors.AppError) {
+	const selectDue = `
+		SELECT user_id, org_id, email, mode, last_digest_at, updated_at
+		FROM email_preferences
+		WHERE mode = 'digest' AND last_digest_at < ?
+		ORDER BY org_id, user_id
+		LIMIT ?
+	`
+	const claim = `UPDATE email_preferences SET last_digest_at = ? WHERE org_id = ? AND user_id = ?`
+
+	var prefs []storage.EmailPreference
+	appErr := inTx(ctx, n.db, func(ctx context.Context) error {
+		rows, err := conn(ctx, n.db).QueryContext(ctx, selectDue, cutoff.UnixMilli(), limit)
+		if err != nil {
+			return fmt.Errorf("select due digests failed: %w", err)
+		}
+		prefs = make([]storage.EmailPreference, 0)
+		for rows.Next() {
+			pref, err := scanEmailPreference(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("scan email preference failed: %w", err)
+			}
+			prefs = append(prefs, pref)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows error: %w", err)
+		}
+
+		for i := range prefs {
+			if _, err := conn(ctx, n.db).ExecContext(ctx, claim, now.UnixMilli(), prefs[i].OrgID, prefs[i].UserID); err != nil {
+				return fmt.Errorf("claim digest failed: %w", err)
+			}
+			prefs[i].LastDigestAt = time.UnixMilli(now.UnixMilli()).UTC()
+		}
+		return nil
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	return prefs, nil
+}
+
+func scanEmailPreference(row scanner) (storage.EmailPreference, error) {
+	var pref storage.EmailPreference
+	var lastDigestAt int64
+	if err := row.Scan(&pref.UserID, &pref.OrgID, &pref.Email, &pref.Mode, &lastDigestAt, &pref.UpdatedAt); err != nil {
+		return storage.EmailPreference{}, err
+	}
+	pref.LastDigestAt = time.UnixMilli(lastDigestAt).UTC()
+	return pref, nil
+}
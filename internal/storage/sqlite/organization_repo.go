@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OrganizationRepository - репозиторий организаций в SQLite.
+type OrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewOrganizationRepository создаёт экземпляр *OrganizationRepository.
+func NewOrganizationRepository(db *sql.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create сохраняет организацию и возвращает её с присвоенным id.
+func (o *OrganizationRepository) Create(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	const query = `INSERT INTO organizations (name, created_at) VALUES (?, ?) RETURNING id`
+
+	org := storage.Organization{Name: name, CreatedAt: time.Now().UTC()}
+	if err := conn(ctx, o.db).QueryRowContext(ctx, query, org.Name, org.CreatedAt).Scan(&org.ID); err != nil {
+		if isUniqueViolation(err) {
+			return storage.Organization{}, apperrors.New(apperrors.ErrOrgExists)
+		}
+		log.Printf("insert organization failed: %v", err)
+		return storage.Organization{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return org, nil
+}
+
+// GetByName возвращает организацию по имени.
+func (o *OrganizationRepository) GetByName(ctx context.Context, name string) (storage.Organization, *apperrors.AppError) {
+	const query = `SELECT id, name, created_at FROM organizations WHERE name = ?`
+
+	var org storage.Organization
+	err := conn(ctx, o.db).QueryRowContext(ctx, query, name).Scan(&org.ID, &org.Name, &org.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Organization{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query organization failed: %v", err)
+		return storage.Organization{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return org, nil
+}
+
+// List возвращает все организации в порядке создания.
+func (o *OrganizationRepository) List(ctx context.Context) ([]storage.Organization, *apperrors.AppError) {
+	const query = `SELECT id, name, created_at FROM organizations ORDER BY id`
+
+	rows, err := conn(ctx, o.db).QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	orgs := make([]storage.Organization, 0)
+	for rows.Next() {
+		var org storage.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		orgs = append(orgs, org)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return orgs, nil
+}
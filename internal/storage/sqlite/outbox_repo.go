@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// OutboxRepository - outbox доменных событий и очередь доставок вебхуков в SQLite.
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository создаёт экземпляр *OutboxRepository.
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+const deliveryColumns = `
+	d.id, d.webhook_id, d.status, d.attempts, d.next_attempt_at, d.last_error, d.updated_at,
+	e.id, e.event_type, e.payload, e.created_at, w.url, w.secret
+`
+
+// Add записывает события и создаёт доставки для подписчиков той же организации одной
+// транзакцией.
+func (o *OutboxRepository) Add(ctx context.Context, events []storage.OutboxEvent) *apperrors.AppError {
+	const eventInsert = `
+		INSERT INTO outbox_events (org_id, event_type, payload, created_at) VALUES (?, ?, ?, ?) RETURNING id
+	`
+	const deliveriesInsert = `
+		INSERT INTO webhook_deliveries (event_id, webhook_id, next_attempt_at, updated_at)
+		SELECT ?, w.id, ?, ? FROM webhooks w
+		WHERE w.org_id = ? AND (json_array_length(w.event_types) = 0
+			OR EXISTS (SELECT 1 FROM json_each(w.event_types) WHERE value = ?))
+	`
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, o.db, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		for _, ev := range events {
+			payload := ev.Payload
+			if len(payload) == 0 {
+				payload = []byte("{}")
+			}
+
+			var id int64
+			if err := conn(ctx, o.db).QueryRowContext(ctx, eventInsert, orgID, ev.Type, string(payload), now).Scan(&id); err != nil {
+				return fmt.Errorf("insert outbox event failed: %w", err)
+			}
+			if _, err := conn(ctx, o.db).ExecContext(ctx, deliveriesInsert, id, now.UnixMilli(), now, orgID, ev.Type); err != nil {
+				return fmt.Errorf("insert webhook deliveries failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ClaimDue выдаёт до limit доставок всех организаций, которым пора отправляться. Транзакция SQLite
+// эксклюзивна, поэтому выборка и обновление не пересекаются с другими обработчиками.
+func (o *OutboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	const selectDue = `
+		SELECT id FROM webhook_deliveries
+		WHERE status = 'PENDING' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`
+	const claim = `UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ? WHERE id = ?`
+	const selectClaimed = `SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.event_id
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = ?
+	`
+
+	var deliveries []storage.WebhookDelivery
+	appErr := inTx(ctx, o.db, func(ctx context.Context) error {
+		rows, err := conn(ctx, o.db).QueryContext(ctx, selectDue, now.UnixMilli(), limit)
+		if err != nil {
+			return fmt.Errorf("select due deliveries failed: %w", err)
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan delivery id failed: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows error: %w", err)
+		}
+
+		deliveries = make([]storage.WebhookDelivery, 0, len(ids))
+		for _, id := range ids {
+			if _, err := conn(ctx, o.db).ExecContext(ctx, claim, now.Add(lease).UnixMilli(), time.Now().UTC(), id); err != nil {
+				return fmt.Errorf("claim delivery failed: %w", err)
+			}
+			d, err := scanDelivery(conn(ctx, o.db).QueryRowContext(ctx, selectClaimed, id))
+			if err != nil {
+				return fmt.Errorf("read claimed delivery failed: %w", err)
+			}
+			deliveries = append(deliveries, d)
+		}
+		return nil
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	return deliveries, nil
+}
+
+// MarkDelivered помечает доставку как успешную.
+func (o *OutboxRepository) MarkDelivered(ctx context.Context, deliveryID int64) *apperrors.AppError {
+	const query = `UPDATE webhook_deliveries SET status = 'DELIVERED', last_error = '', updated_at = ? WHERE id = ?`
+	return o.update(ctx, query, time.Now().UTC(), deliveryID)
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит доставку в DEAD.
+func (o *OutboxRepository) MarkFailed(ctx context.Context, deliveryID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`
+	status := storage.DeliveryPending
+	if dead {
+		status = storage.DeliveryDead
+	}
+	return o.update(ctx, query, status, nextAttemptAt.UnixMilli(), lastErr, time.Now().UTC(), deliveryID)
+}
+
+func (o *OutboxRepository) update(ctx context.Context, query string, args ...any) *apperrors.AppError {
+	res, err := conn(ctx, o.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("update delivery failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// ListDeliveries возвращает последние доставки организации, новые первыми. webhookID = 0 и пустой
+// status означают «без фильтра».
+func (o *OutboxRepository) ListDeliveries(ctx context.Context, webhookID int64, status storage.DeliveryStatus, limit int) ([]storage.WebhookDelivery, *apperrors.AppError) {
+	const query = `SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN outbox_events e ON e.id = d.event_id
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE w.org_id = ? AND (? = 0 OR d.webhook_id = ?) AND (? = '' OR d.status = ?)
+		ORDER BY d.id DESC
+		LIMIT ?
+	`
+
+	rows, err := conn(ctx, o.db).QueryContext(ctx, query, storage.OrgFrom(ctx), webhookID, webhookID, string(status), string(status), limit)
+	if err != nil {
+		log.Printf("query deliveries failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	deliveries := make([]storage.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return deliveries, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDelivery(row scanner) (storage.WebhookDelivery, error) {
+	var d storage.WebhookDelivery
+	var nextAttemptAt int64
+	var payload string
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Status, &d.Attempts, &nextAttemptAt, &d.LastError, &d.UpdatedAt,
+		&d.Event.ID, &d.Event.Type, &payload, &d.Event.CreatedAt, &d.URL, &d.Secret)
+	if err != nil {
+		return storage.WebhookDelivery{}, err
+	}
+	d.NextAttemptAt = time.UnixMilli(nextAttemptAt).UTC()
+	d.Event.Payload = []byte(payload)
+	return d, nil
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// PREventRepository - репозиторий истории событий PR в SQLite. Таблица pr_events
+// защищена триггерами от UPDATE и DELETE.
+type PREventRepository struct {
+	db *sql.DB
+}
+
+// NewPREventRepository создаёт экземпляр *PREventRepository.
+func NewPREventRepository(db *sql.DB) *PREventRepository {
+	return &PREventRepository{db: db}
+}
+
+// Add дописывает события в историю одной транзакцией.
+func (e *PREventRepository) Add(ctx context.Context, events []storage.PREvent) *apperrors.AppError {
+	const query = `
+		INSERT INTO pr_events (org_id, pull_request_id, event_type, actor, payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, e.db, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		for _, ev := range events {
+			payload := ev.Payload
+			if len(payload) == 0 {
+				payload = []byte("{}")
+			}
+
+			if _, err := conn(ctx, e.db).ExecContext(ctx, query, orgID, ev.PullRequestID, ev.Type, ev.Actor, string(payload), now); err != nil {
+				return fmt.Errorf("insert pr event failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByPR возвращает историю pr в хронологическом порядке.
+func (e *PREventRepository) GetByPR(ctx context.Context, prID string) ([]storage.PREvent, *apperrors.AppError) {
+	const query = `
+		SELECT id, pull_request_id, event_type, actor, payload, created_at
+		FROM pr_events
+		WHERE org_id = ? AND pull_request_id = ?
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, e.db).QueryContext(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	events := make([]storage.PREvent, 0)
+	for rows.Next() {
+		var ev storage.PREvent
+		var payload string
+		if err := rows.Scan(&ev.ID, &ev.PullRequestID, &ev.Type, &ev.Actor, &payload, &ev.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		ev.Payload = []byte(payload)
+		events = append(events, ev)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return events, nil
+}
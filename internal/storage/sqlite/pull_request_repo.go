@@ -0,0 +1,288 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// PullRequestRepository - репозиторий для управления pr'ами в SQLite.
+type PullRequestRepository struct {
+	db *sql.DB
+}
+
+// NewPullRequestRepository создаёт экземпляр *PullRequestRepository.
+func NewPullRequestRepository(db *sql.DB) *PullRequestRepository {
+	return &PullRequestRepository{db: db}
+}
+
+// Create создаёт pr с ревьюерами.
+func (p *PullRequestRepository) Create(ctx context.Context, pr storage.PullRequest) *apperrors.AppError {
+	const prInsertQuery = `
+		INSERT INTO pull_requests (org_id, pull_request_id, pull_request_name, author_id, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	const reviewInsertQuery = `INSERT INTO reviews (org_id, pull_request_id, reviewer_id, assigned_at) VALUES (?, ?, ?, ?)`
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, p.db, func(ctx context.Context) error {
+		_, err := conn(ctx, p.db).ExecContext(ctx, prInsertQuery, orgID, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return apperrors.New(apperrors.ErrPRExists)
+			}
+			return fmt.Errorf("inserting pr failed: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, rev := range pr.AssignedReviewers {
+			if _, err := conn(ctx, p.db).ExecContext(ctx, reviewInsertQuery, orgID, pr.ID, rev, now); err != nil {
+				return fmt.Errorf("insert reviewer failed: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Get возвращает pr по id.
+func (p *PullRequestRepository) Get(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	const prQuery = `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+		FROM pull_requests WHERE org_id = ? AND pull_request_id = ?
+	`
+	const revQuery = `SELECT reviewer_id FROM reviews WHERE org_id = ? AND pull_request_id = ?`
+
+	orgID := storage.OrgFrom(ctx)
+	var pr storage.PullRequest
+	err := conn(ctx, p.db).QueryRowContext(ctx, prQuery, orgID, prID).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pr, apperrors.New(apperrors.ErrNotFound)
+		}
+		log.Printf("query pr failed: %v", err)
+		return pr, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	rows, err := conn(ctx, p.db).QueryContext(ctx, revQuery, orgID, prID)
+	if err != nil {
+		log.Printf("query reviewer failed: %v", err)
+		return pr, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rev string
+		if err := rows.Scan(&rev); err != nil {
+			log.Printf("reviewer scan failed: %v", err)
+			return pr, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		pr.AssignedReviewers = append(pr.AssignedReviewers, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return pr, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return pr, nil
+}
+
+// GetForUpdate возвращает pr по id. В SQLite транзакция TxManager.Do держит блокировку
+// записи на всю базу (BEGIN IMMEDIATE), поэтому отдельная блокировка строки не нужна.
+func (p *PullRequestRepository) GetForUpdate(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	return p.Get(ctx, prID)
+}
+
+// Exists проверяет существование pr.
+func (p *PullRequestRepository) Exists(ctx context.Context, prID string) (bool, *apperrors.AppError) {
+	const query = `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE org_id = ? AND pull_request_id = ?)`
+
+	var exists bool
+	if err := conn(ctx, p.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), prID).Scan(&exists); err != nil {
+		log.Printf("query failed: %v", err)
+		return false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return exists, nil
+}
+
+// MarkMerged помечает pr как MERGED.
+func (p *PullRequestRepository) MarkMerged(ctx context.Context, prID string) (storage.PullRequest, *apperrors.AppError) {
+	const query = `
+		UPDATE pull_requests
+		SET status = 'MERGED', merged_at = COALESCE(merged_at, ?), version = version + 1
+		WHERE org_id = ? AND pull_request_id = ?
+	`
+
+	res, err := conn(ctx, p.db).ExecContext(ctx, query, time.Now().UTC(), storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("update failed: %v", err)
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrNotFound)
+	}
+
+	return p.Get(ctx, prID)
+}
+
+// SetStatus переводит pr в OPEN или CLOSED; для MERGED используется MarkMerged.
+func (p *PullRequestRepository) SetStatus(ctx context.Context, prID string, status storage.PRStatus) (storage.PullRequest, *apperrors.AppError) {
+	const query = `UPDATE pull_requests SET status = ?, version = version + 1 WHERE org_id = ? AND pull_request_id = ?`
+
+	res, err := conn(ctx, p.db).ExecContext(ctx, query, string(status), storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("update failed: %v", err)
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return storage.PullRequest{}, apperrors.New(apperrors.ErrNotFound)
+	}
+
+	return p.Get(ctx, prID)
+}
+
+// ReplaceReviewer заменяет одного ревьюера на другого.
+func (p *PullRequestRepository) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) *apperrors.AppError {
+	const query = `
+		UPDATE reviews SET reviewer_id = ?, assigned_at = ?, reminded_at = NULL, escalated_at = NULL
+		WHERE org_id = ? AND pull_request_id = ? AND reviewer_id = ?
+	`
+
+	res, err := conn(ctx, p.db).ExecContext(ctx, query, newReviewerID, time.Now().UTC(), storage.OrgFrom(ctx), prID, oldReviewerID)
+	if err != nil {
+		log.Printf("update rev failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotAssigned)
+	}
+	return p.bumpVersion(ctx, prID)
+}
+
+// AddReviewer назначает на pr ещё одного ревьюера.
+func (p *PullRequestRepository) AddReviewer(ctx context.Context, prID, reviewerID string) *apperrors.AppError {
+	const query = `INSERT INTO reviews (org_id, pull_request_id, reviewer_id, assigned_at) VALUES (?, ?, ?, ?)`
+
+	if _, err := conn(ctx, p.db).ExecContext(ctx, query, storage.OrgFrom(ctx), prID, reviewerID, time.Now().UTC()); err != nil {
+		log.Printf("insert reviewer failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return p.bumpVersion(ctx, prID)
+}
+
+// bumpVersion увеличивает версию pr после изменения его ревьюеров.
+func (p *PullRequestRepository) bumpVersion(ctx context.Context, prID string) *apperrors.AppError {
+	const query = `UPDATE pull_requests SET version = version + 1 WHERE org_id = ? AND pull_request_id = ?`
+
+	if _, err := conn(ctx, p.db).ExecContext(ctx, query, storage.OrgFrom(ctx), prID); err != nil {
+		log.Printf("bump pr version failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return nil
+}
+
+// GetByReviewer возвращает все pr, где пользователь назначен ревьюером.
+func (p *PullRequestRepository) GetByReviewer(ctx context.Context, reviewerID string) ([]storage.PullRequest, *apperrors.AppError) {
+	const query = `
+		SELECT DISTINCT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.version
+		FROM pull_requests pr
+		INNER JOIN reviews r ON r.org_id = pr.org_id AND r.pull_request_id = pr.pull_request_id
+		WHERE pr.org_id = ? AND r.reviewer_id = ?
+	`
+
+	rows, err := conn(ctx, p.db).QueryContext(ctx, query, storage.OrgFrom(ctx), reviewerID)
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	var prs []storage.PullRequest
+	for rows.Next() {
+		var pr storage.PullRequest
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Version); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		prs = append(prs, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return prs, nil
+}
+
+// IsReviewerAssigned проверяет, является ли пользователь ревьюером.
+func (p *PullRequestRepository) IsReviewerAssigned(ctx context.Context, reviewerID string) (bool, *apperrors.AppError) {
+	const query = `SELECT EXISTS(SELECT 1 FROM reviews WHERE org_id = ? AND reviewer_id = ?)`
+
+	var exists bool
+	if err := conn(ctx, p.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), reviewerID).Scan(&exists); err != nil {
+		log.Printf("query failed: %v", err)
+		return false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return exists, nil
+}
+
+// CountAssignmentsByUser возвращает количество назначений по каждому ревьюеру.
+func (p *PullRequestRepository) CountAssignmentsByUser(ctx context.Context) (map[string]int, *apperrors.AppError) {
+	const query = `SELECT reviewer_id, COUNT(*) FROM reviews WHERE org_id = ? GROUP BY reviewer_id`
+	return p.countBy(ctx, query)
+}
+
+// CountAssignmentsByPR возвращает количество назначений по каждому pr.
+func (p *PullRequestRepository) CountAssignmentsByPR(ctx context.Context) (map[string]int, *apperrors.AppError) {
+	const query = `SELECT pull_request_id, COUNT(reviewer_id) FROM reviews WHERE org_id = ? GROUP BY pull_request_id`
+	return p.countBy(ctx, query)
+}
+
+func (p *PullRequestRepository) countBy(ctx context.Context, query string) (map[string]int, *apperrors.AppError) {
+	rows, err := conn(ctx, p.db).QueryContext(ctx, query, storage.OrgFrom(ctx))
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	res := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var cnt int
+		if err := rows.Scan(&key, &cnt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		res[key] = cnt
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return res, nil
+}
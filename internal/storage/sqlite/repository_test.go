@@ -0,0 +1,45 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqliteinfra "github.com/VechkanovVV/assigner-pr/internal/infra/sqlite"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/sqlite"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/storagetest"
+)
+
+func TestRepositories(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Backend {
+		ctx := context.Background()
+		db, err := sqliteinfra.NewDB(ctx, filepath.Join(t.TempDir(), "assigner.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		require.NoError(t, sqlite.Migrate(ctx, db))
+		require.NoError(t, sqlite.Migrate(ctx, db), "migrations are applied once")
+
+		return storagetest.Backend{
+			Tx:       sqlite.NewTxManager(db),
+			Users:    sqlite.NewUserRepository(db),
+			Teams:    sqlite.NewTeamRepository(db),
+			PRs:      sqlite.NewPullRequestRepository(db),
+			Logs:     sqlite.NewAssignmentLogRepository(db),
+			Events:   sqlite.NewPREventRepository(db),
+			Webhooks: sqlite.NewWebhookRepository(db),
+			Outbox:   sqlite.NewOutboxRepository(db),
+
+			Integrations:  sqlite.NewIntegrationRepository(db),
+			ReviewerSyncs: sqlite.NewReviewerSyncRepository(db),
+			Notifications: sqlite.NewNotificationRepository(db),
+			ReviewSLAs:    sqlite.NewReviewSLARepository(db),
+			APITokens:     sqlite.NewAPITokenRepository(db),
+			Orgs:          sqlite.NewOrganizationRepository(db),
+			Audit:         sqlite.NewAuditLogRepository(db),
+			Idempotency:   sqlite.NewIdempotencyRepository(db),
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewSLARepository - сроки ревью команд и отметки SLA назначений в SQLite.
+type ReviewSLARepository struct {
+	db *sql.DB
+}
+
+// NewReviewSLARepository создаёт экземпляр *ReviewSLARepository.
+func NewReviewSLARepository(db *sql.DB) *ReviewSLARepository {
+	return &ReviewSLARepository{db: db}
+}
+
+// SetTeamSLA задаёт срок ревью команды; существующая настройка перезаписывается. Команда
+// другой организации - NOT_FOUND.
+func (r *ReviewSLARepository) SetTeamSLA(ctx context.Context, sla storage.ReviewSLA) *apperrors.AppError {
+	const query = `
+		INSERT INTO review_slas (team_id, org_id, within_ms, escalation, escalate_after_ms, lead_id, updated_at)
+		SELECT id, org_id, ?, ?, ?, NULLIF(?, ''), ? FROM teams WHERE org_id = ? AND id = ?
+		ON CONFLICT (team_id) DO UPDATE SET
+			within_ms = excluded.within_ms,
+			escalation = excluded.escalation,
+			escalate_after_ms = excluded.escalate_after_ms,
+			lead_id = excluded.lead_id,
+			updated_at = excluded.updated_at
+	`
+
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, sla.Within.Milliseconds(), sla.Escalation,
+		sla.EscalateAfter.Milliseconds(), sla.LeadID, time.Now().UTC(), storage.OrgFrom(ctx), sla.TeamID)
+	if err != nil {
+		log.Printf("upsert review sla failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// GetTeamSLA возвращает срок ревью команды.
+func (r *ReviewSLARepository) GetTeamSLA(ctx context.Context, teamID int) (storage.ReviewSLA, *apperrors.AppError) {
+	const query = `
+		SELECT s.team_id, s.within_ms, s.escalation, s.escalate_after_ms, COALESCE(s.lead_id, ''), s.updated_at
+		FROM review_slas s JOIN teams t ON t.id = s.team_id
+		WHERE t.org_id = ? AND s.team_id = ?
+	`
+
+	sla, err := scanReviewSLA(conn(ctx, r.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), teamID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ReviewSLA{}, apperrors.New(apperrors.ErrNotFound)
+	}
+	if err != nil {
+		log.Printf("query review sla failed: %v", err)
+		return storage.ReviewSLA{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return sla, nil
+}
+
+// DeleteTeamSLA снимает срок ревью с команды.
+func (r *ReviewSLARepository) DeleteTeamSLA(ctx context.Context, teamID int) *apperrors.AppError {
+	const query = `
+		DELETE FROM review_slas
+		WHERE team_id = ? AND team_id IN (SELECT id FROM teams WHERE org_id = ?)
+	`
+
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, teamID, storage.OrgFrom(ctx))
+	if err != nil {
+		log.Printf("delete review sla failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// GetReviews возвращает назначения ревьюеров pr.
+func (r *ReviewSLARepository) GetReviews(ctx context.Context, prID string) ([]storage.ReviewState, *apperrors.AppError) {
+	const query = `
+		SELECT pull_request_id, reviewer_id, org_id, assigned_at, reminded_at, escalated_at
+		FROM reviews WHERE org_id = ? AND pull_request_id = ?
+		ORDER BY reviewer_id
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query reviews failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	reviews := make([]storage.ReviewState, 0)
+	for rows.Next() {
+		rs, err := scanReviewState(rows)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		reviews = append(reviews, rs)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return reviews, nil
+}
+
+// ClaimReminders отмечает напоминание для ревью, срок которых истёк.
+func (r *ReviewSLARepository) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	return r.claim(ctx, "reminded_at", now, limit, func(o storage.OverdueReview) bool {
+		return !o.AssignedAt.Add(o.SLA.Within).After(now)
+	})
+}
+
+// ClaimEscalations отмечает эскалацию для ревью, просроченных сверх EscalateAfter.
+func (r *ReviewSLARepository) ClaimEscalations(ctx context.Context, now time.Time, limit int) ([]storage.OverdueReview, *apperrors.AppError) {
+	return r.claim(ctx, "escalated_at", now, limit, func(o storage.OverdueReview) bool {
+		return o.SLA.Escalation != storage.EscalateNone && !o.AssignedAt.Add(o.SLA.EscalateAfter).After(now)
+	})
+}
+
+// claim отмечает в column ревью открытых PR всех организаций без отметки, для которых
+// выполняется due.
+// assigned_at хранится драйвером как текст, поэтому срок сравнивается в Go.
+func (r *ReviewSLARepository) claim(
+	ctx context.Context,
+	column string,
+	now time.Time,
+	limit int,
+	due func(storage.OverdueReview) bool,
+) ([]storage.OverdueReview, *apperrors.AppError) {
+	selectPending := fmt.Sprintf(`
+		SELECT rv.pull_request_id, rv.reviewer_id, rv.org_id, rv.assigned_at, rv.reminded_at, rv.escalated_at,
+			s.team_id, s.within_ms, s.escalation, s.escalate_after_ms, COALESCE(s.lead_id, ''), s.updated_at
+		FROM reviews rv
+		JOIN pull_requests pr ON pr.org_id = rv.org_id AND pr.pull_request_id = rv.pull_request_id
+		JOIN users a ON a.org_id = pr.org_id AND a.user_id = pr.author_id
+		JOIN review_slas s ON s.team_id = a.team_id
+		WHERE pr.status = 'OPEN' AND rv.%[1]s IS NULL
+	`, column)
+	mark := fmt.Sprintf(`UPDATE reviews SET %s = ? WHERE org_id = ? AND pull_request_id = ? AND reviewer_id = ?`, column)
+
+	var overdue []storage.OverdueReview
+	appErr := inTx(ctx, r.db, func(ctx context.Context) error {
+		rows, err := conn(ctx, r.db).QueryContext(ctx, selectPending)
+		if err != nil {
+			return fmt.Errorf("select pending reviews failed: %w", err)
+		}
+		overdue = make([]storage.OverdueReview, 0)
+		for rows.Next() {
+			o, err := scanOverdueReview(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("scan review failed: %w", err)
+			}
+			if due(o) {
+				overdue = append(overdue, o)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows error: %w", err)
+		}
+
+		sort.Slice(overdue, func(i, j int) bool {
+			if overdue[i].OrgID != overdue[j].OrgID {
+				return overdue[i].OrgID < overdue[j].OrgID
+			}
+			if overdue[i].PullRequestID != overdue[j].PullRequestID {
+				return overdue[i].PullRequestID < overdue[j].PullRequestID
+			}
+			return overdue[i].ReviewerID < overdue[j].ReviewerID
+		})
+		if len(overdue) > limit {
+			overdue = overdue[:limit]
+		}
+
+		marked := time.UnixMilli(now.UnixMilli()).UTC()
+		for i := range overdue {
+			o := &overdue[i]
+			if _, err := conn(ctx, r.db).ExecContext(ctx, mark, marked.UnixMilli(), o.OrgID, o.PullRequestID, o.ReviewerID); err != nil {
+				return fmt.Errorf("mark review failed: %w", err)
+			}
+			if column == "reminded_at" {
+				o.RemindedAt = &marked
+			} else {
+				o.EscalatedAt = &marked
+			}
+		}
+		return nil
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	return overdue, nil
+}
+
+func scanReviewSLA(row scanner) (storage.ReviewSLA, error) {
+	var sla storage.ReviewSLA
+	var withinMs, escalateAfterMs int64
+	if err := row.Scan(&sla.TeamID, &withinMs, &sla.Escalation, &escalateAfterMs, &sla.LeadID, &sla.UpdatedAt); err != nil {
+		return storage.ReviewSLA{}, err
+	}
+	sla.Within = time.Duration(withinMs) * time.Millisecond
+	sla.EscalateAfter = time.Duration(escalateAfterMs) * time.Millisecond
+	return sla, nil
+}
+
+func scanReviewState(row scanner) (storage.ReviewState, error) {
+	var rs storage.ReviewState
+	var remindedAt, escalatedAt sql.NullInt64
+	if err := row.Scan(&rs.PullRequestID, &rs.ReviewerID, &rs.OrgID, &rs.AssignedAt, &remindedAt, &escalatedAt); err != nil {
+		return storage.ReviewState{}, err
+	}
+	rs.RemindedAt, rs.EscalatedAt = fromNullMilli(remindedAt), fromNullMilli(escalatedAt)
+	return rs, nil
+}
+
+func scanOverdueReview(row scanner) (storage.OverdueReview, error) {
+	var o storage.OverdueReview
+	var remindedAt, escalatedAt sql.NullInt64
+	var withinMs, escalateAfterMs int64
+	err := row.Scan(&o.PullRequestID, &o.ReviewerID, &o.OrgID, &o.AssignedAt, &remindedAt, &escalatedAt,
+		&o.SLA.TeamID, &withinMs, &o.SLA.Escalation, &escalateAfterMs, &o.SLA.LeadID, &o.SLA.UpdatedAt)
+	if err != nil {
+		return storage.OverdueReview{}, err
+	}
+	o.RemindedAt, o.EscalatedAt = fromNullMilli(remindedAt), fromNullMilli(escalatedAt)
+	o.SLA.Within = time.Duration(withinMs) * time.Millisecond
+	o.SLA.EscalateAfter = time.Duration(escalateAfterMs) * time.Millisecond
+	return o, nil
+}
+
+func fromNullMilli(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.UnixMilli(v.Int64).UTC()
+	return &t
+}
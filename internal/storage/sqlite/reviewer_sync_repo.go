@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// ReviewerSyncRepository - очередь передачи ревьюеров на код-хостинг в SQLite.
+type ReviewerSyncRepository struct {
+	db *sql.DB
+}
+
+// NewReviewerSyncRepository создаёт экземпляр *ReviewerSyncRepository.
+func NewReviewerSyncRepository(db *sql.DB) *ReviewerSyncRepository {
+	return &ReviewerSyncRepository{db: db}
+}
+
+const reviewerSyncColumns = `
+	id, org_id, provider, pull_request_id, removed_reviewers, status, attempts, next_attempt_at, last_error, updated_at
+`
+
+// Enqueue ставит задачу в очередь; она готова к отправке сразу.
+func (r *ReviewerSyncRepository) Enqueue(ctx context.Context, sync storage.ReviewerSync) *apperrors.AppError {
+	const query = `
+		INSERT INTO reviewer_syncs (org_id, provider, pull_request_id, removed_reviewers, next_attempt_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	removed := sync.Removed
+	if removed == nil {
+		removed = []string{}
+	}
+	data, err := json.Marshal(removed)
+	if err != nil {
+		log.Printf("marshal removed reviewers failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	now := time.Now().UTC()
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, storage.OrgFrom(ctx), sync.Provider, sync.PullRequestID, string(data), now.UnixMilli(), now); err != nil {
+		log.Printf("insert reviewer sync failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return nil
+}
+
+// ClaimDue выдаёт до limit задач всех организаций, которым пора выполняться. Транзакция SQLite
+// эксклюзивна, поэтому выборка и обновление не пересекаются с другими обработчиками.
+func (r *ReviewerSyncRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]storage.ReviewerSync, *apperrors.AppError) {
+	const selectDue = `
+		SELECT id FROM reviewer_syncs
+		WHERE status = 'PENDING' AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?
+	`
+	const claim = `UPDATE reviewer_syncs SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ? WHERE id = ?`
+	const selectClaimed = `SELECT ` + reviewerSyncColumns + ` FROM reviewer_syncs WHERE id = ?`
+
+	var syncs []storage.ReviewerSync
+	appErr := inTx(ctx, r.db, func(ctx context.Context) error {
+		rows, err := conn(ctx, r.db).QueryContext(ctx, selectDue, now.UnixMilli(), limit)
+		if err != nil {
+			return fmt.Errorf("select due reviewer syncs failed: %w", err)
+		}
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan reviewer sync id failed: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("rows error: %w", err)
+		}
+
+		syncs = make([]storage.ReviewerSync, 0, len(ids))
+		for _, id := range ids {
+			if _, err := conn(ctx, r.db).ExecContext(ctx, claim, now.Add(lease).UnixMilli(), time.Now().UTC(), id); err != nil {
+				return fmt.Errorf("claim reviewer sync failed: %w", err)
+			}
+			s, err := scanReviewerSync(conn(ctx, r.db).QueryRowContext(ctx, selectClaimed, id))
+			if err != nil {
+				return fmt.Errorf("read claimed reviewer sync failed: %w", err)
+			}
+			syncs = append(syncs, s)
+		}
+		return nil
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+	return syncs, nil
+}
+
+// MarkDone помечает задачу как выполненную.
+func (r *ReviewerSyncRepository) MarkDone(ctx context.Context, syncID int64) *apperrors.AppError {
+	const query = `UPDATE reviewer_syncs SET status = 'DELIVERED', last_error = '', updated_at = ? WHERE id = ?`
+	return r.update(ctx, query, time.Now().UTC(), syncID)
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей; dead переводит задачу в DEAD.
+func (r *ReviewerSyncRepository) MarkFailed(ctx context.Context, syncID int64, nextAttemptAt time.Time, lastErr string, dead bool) *apperrors.AppError {
+	const query = `
+		UPDATE reviewer_syncs
+		SET status = ?, next_attempt_at = ?, last_error = ?, updated_at = ?
+		WHERE id = ?
+	`
+	status := storage.DeliveryPending
+	if dead {
+		status = storage.DeliveryDead
+	}
+	return r.update(ctx, query, status, nextAttemptAt.UnixMilli(), lastErr, time.Now().UTC(), syncID)
+}
+
+func (r *ReviewerSyncRepository) update(ctx context.Context, query string, args ...any) *apperrors.AppError {
+	res, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("update reviewer sync failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows affected failed: %v", err)
+		return apperrors.New(apperrors.ErrInternalIssue)
+	}
+	if affected == 0 {
+		return apperrors.New(apperrors.ErrNotFound)
+	}
+	return nil
+}
+
+// List возвращает задачи pr в порядке постановки.
+func (r *ReviewerSyncRepository) List(ctx context.Context, prID string) ([]storage.ReviewerSync, *apperrors.AppError) {
+	const query = `SELECT ` + reviewerSyncColumns + ` FROM reviewer_syncs WHERE org_id = ? AND pull_request_id = ? ORDER BY id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, storage.OrgFrom(ctx), prID)
+	if err != nil {
+		log.Printf("query reviewer syncs failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	syncs := make([]storage.ReviewerSync, 0)
+	for rows.Next() {
+		s, err := scanReviewerSync(rows)
+		if err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		syncs = append(syncs, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return syncs, nil
+}
+
+func scanReviewerSync(row scanner) (storage.ReviewerSync, error) {
+	var s storage.ReviewerSync
+	var removed string
+	var nextAttemptAt int64
+	err := row.Scan(&s.ID, &s.OrgID, &s.Provider, &s.PullRequestID, &removed, &s.Status, &s.Attempts,
+		&nextAttemptAt, &s.LastError, &s.UpdatedAt)
+	if err != nil {
+		return storage.ReviewerSync{}, err
+	}
+	if err := json.Unmarshal([]byte(removed), &s.Removed); err != nil {
+		return storage.ReviewerSync{}, fmt.Errorf("decode removed reviewers: %w", err)
+	}
+	s.NextAttemptAt = time.UnixMilli(nextAttemptAt).UTC()
+	return s, nil
+}
@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// TeamRepository - репозиторий для управления командами в SQLite.
+type TeamRepository struct {
+	db *sql.DB
+}
+
+// NewTeamRepository создаёт экземпляр *TeamRepository.
+func NewTeamRepository(db *sql.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Create создаёт новую команду. Существующие пользователи организации переносятся в неё;
+// user_id уникальны в пределах организации.
+func (t *TeamRepository) Create(ctx context.Context, team storage.Team) *apperrors.AppError {
+	const queryTeamInsert = `INSERT INTO teams (org_id, team_name, created_at) VALUES (?, ?, ?) RETURNING id`
+	const queryUserInsert = `
+		INSERT INTO users (org_id, user_id, username, team_id, is_active, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (org_id, user_id) DO UPDATE SET
+			username = excluded.username,
+			team_id = excluded.team_id,
+			is_active = excluded.is_active,
+			updated_at = excluded.updated_at,
+			version = users.version + 1`
+
+	orgID := storage.OrgFrom(ctx)
+	return inTx(ctx, t.db, func(ctx context.Context) error {
+		now := time.Now().UTC()
+
+		var teamID int
+		err := conn(ctx, t.db).QueryRowContext(ctx, queryTeamInsert, orgID, team.TeamName, now).Scan(&teamID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return apperrors.New(apperrors.ErrTeamExists)
+			}
+			return fmt.Errorf("insert team failed: %w", err)
+		}
+
+		for _, user := range team.Members {
+			_, err := conn(ctx, t.db).ExecContext(ctx, queryUserInsert, orgID, user.ID, user.Username, teamID, user.IsActive, now)
+			if err != nil {
+				return fmt.Errorf("failed insertion into users: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetByName осуществляет поиск команды и её участников по имени команды.
+func (t *TeamRepository) GetByName(ctx context.Context, teamName string) (storage.Team, *apperrors.AppError) {
+	const selectTeamByName = `SELECT id, team_name, created_at, version FROM teams WHERE org_id = ? AND team_name = ?`
+
+	orgID := storage.OrgFrom(ctx)
+	var team storage.Team
+	err := conn(ctx, t.db).QueryRowContext(ctx, selectTeamByName, orgID, teamName).Scan(&team.ID, &team.TeamName, &team.CreatedAt, &team.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.Team{}, apperrors.New(apperrors.ErrNotFound)
+		}
+		log.Printf("query team failed: %v", err)
+		return storage.Team{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	members, appErr := queryUsers(ctx, conn(ctx, t.db), `
+		SELECT user_id, username, team_id, is_active, updated_at, version
+		FROM users
+		WHERE org_id = ? AND team_id = ?
+	`, orgID, team.ID)
+	if appErr != nil {
+		return storage.Team{}, appErr
+	}
+	team.Members = members
+
+	return team, nil
+}
+
+// Exists проверяет существует ли команда по её имени.
+func (t *TeamRepository) Exists(ctx context.Context, teamName string) (bool, *apperrors.AppError) {
+	const query = `SELECT EXISTS(SELECT 1 FROM teams WHERE org_id = ? AND team_name = ?)`
+
+	var exists bool
+	if err := conn(ctx, t.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), teamName).Scan(&exists); err != nil {
+		log.Printf("query failed: %v", err)
+		return false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return exists, nil
+}
+
+// GetByID получает команду по её ID.
+func (t *TeamRepository) GetByID(ctx context.Context, teamID int) (storage.Team, *apperrors.AppError) {
+	const teamQuery = `SELECT team_name, version FROM teams WHERE org_id = ? AND id = ?`
+
+	orgID := storage.OrgFrom(ctx)
+	var team storage.Team
+	err := conn(ctx, t.db).QueryRowContext(ctx, teamQuery, orgID, teamID).Scan(&team.TeamName, &team.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return team, apperrors.New(apperrors.ErrNotFound)
+		}
+		log.Printf("query failed: %v", err)
+		return team, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	members, appErr := queryUsers(ctx, conn(ctx, t.db), `
+		SELECT user_id, username, team_id, is_active, updated_at, version
+		FROM users
+		WHERE org_id = ? AND team_id = ?
+	`, orgID, teamID)
+	if appErr != nil {
+		return team, appErr
+	}
+	if members == nil {
+		members = make([]storage.User, 0)
+	}
+	team.Members = members
+
+	return team, nil
+}
+
+// Touch увеличивает версию команды и возвращает новую.
+func (t *TeamRepository) Touch(ctx context.Context, teamID int) (int64, *apperrors.AppError) {
+	const query = `UPDATE teams SET version = version + 1 WHERE org_id = ? AND id = ? RETURNING version`
+
+	var version int64
+	err := conn(ctx, t.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), teamID).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, apperrors.New(apperrors.ErrNotFound)
+		}
+		log.Printf("touch team failed: %v", err)
+		return 0, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return version, nil
+}
@@ -0,0 +1,113 @@
+// Package sqlite - реализация репозиториев storage поверх SQLite для однонодовых
+// установок. Семантика и коды ошибок совпадают с internal/storage/postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// txKey - ключ контекста, под которым лежит текущая транзакция.
+type txKey struct{}
+
+// querier - общая часть *sql.DB и *sql.Tx, которой пользуются репозитории.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TxManager - реализация storage.TxManager поверх SQLite. Транзакция передаётся
+// репозиториям через context.
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager создаёт экземпляр *TxManager.
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// Do выполняет fn в транзакции. Если в ctx уже есть транзакция, fn выполняется в ней.
+// Транзакции SQLite открываются как BEGIN IMMEDIATE и сериализуются целиком, поэтому
+// повторы при конфликтах не нужны.
+// Функции storage.AfterCommit вызываются после фиксации внешней транзакции.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) *apperrors.AppError) *apperrors.AppError {
+	var hooks *storage.TxHooks
+	err := inTx(ctx, m.db, func(ctx context.Context) error {
+		ctx, hooks = storage.WithTxHooks(ctx)
+		if appErr := fn(ctx); appErr != nil {
+			return appErr
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	hooks.Run()
+	return nil
+}
+
+// conn возвращает транзакцию из ctx, а если её нет - db.
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// inTx выполняет fn в транзакции из ctx или, если её нет, в новой транзакции.
+// fn возвращает *apperrors.AppError для доменных ошибок или любую другую ошибку БД
+// (логируется и превращается в INTERNAL_ISSUE).
+func inTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) *apperrors.AppError {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return toAppError(fn(ctx))
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return toAppError(err)
+	}
+
+	defer func() {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+			log.Printf("tx rollback failed: %v", rerr)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return toAppError(err)
+	}
+	return toAppError(tx.Commit())
+}
+
+func toAppError(err error) *apperrors.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	log.Printf("tx failed: %v", err)
+	return apperrors.New(apperrors.ErrInternalIssue)
+}
+
+// isUniqueViolation сообщает, нарушено ли ограничение UNIQUE или PRIMARY KEY.
+func isUniqueViolation(err error) bool {
+	var sqlErr *sqlite.Error
+	if !errors.As(err, &sqlErr) {
+		return false
+	}
+	return sqlErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqlErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
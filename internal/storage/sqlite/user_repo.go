@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// UserRepository - репозиторий для управления пользователями (участниками команд) в SQLite.
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository создаёт экземпляр *UserRepository.
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Get осуществляет поиск пользователя (участника команды) по его id.
+func (u *UserRepository) Get(ctx context.Context, userID string) (storage.User, *apperrors.AppError) {
+	const query = `
+		SELECT user_id, username, team_id, is_active, updated_at, version
+		FROM users WHERE org_id = ? AND user_id = ?
+	`
+
+	var user storage.User
+	err := conn(ctx, u.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), userID).Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, apperrors.New(apperrors.ErrNotFound)
+		}
+		log.Printf("query failed: %v", err)
+		return user, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return user, nil
+}
+
+// SetActive обновляет флаг активности пользователя.
+func (u *UserRepository) SetActive(ctx context.Context, userID string, isActive bool) (storage.User, *apperrors.AppError) {
+	const query = `
+		UPDATE users
+		SET is_active = ?, updated_at = ?, version = version + 1
+		WHERE org_id = ? AND user_id = ?
+		RETURNING user_id, username, team_id, is_active, updated_at, version
+	`
+
+	var user storage.User
+	err := conn(ctx, u.db).QueryRowContext(ctx, query, isActive, time.Now().UTC(), storage.OrgFrom(ctx), userID).
+		Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return user, apperrors.New(apperrors.ErrNotFound)
+		}
+		log.Printf("set active failed: %v", err)
+		return user, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return user, nil
+}
+
+// GetActiveTeammates возвращает активных участников команды по teamID, исключая excludedID.
+func (u *UserRepository) GetActiveTeammates(ctx context.Context, teamID int, excludedID string) ([]storage.User, *apperrors.AppError) {
+	const query = `
+		SELECT user_id, username, team_id, is_active, updated_at, version
+		FROM users
+		WHERE org_id = ? AND team_id = ? AND is_active = TRUE AND user_id != ?
+	`
+	return queryUsers(ctx, conn(ctx, u.db), query, storage.OrgFrom(ctx), teamID, excludedID)
+}
+
+// GetByTeam возвращает всех участников команды по teamID, включая неактивных.
+func (u *UserRepository) GetByTeam(ctx context.Context, teamID int) ([]storage.User, *apperrors.AppError) {
+	const query = `
+		SELECT user_id, username, team_id, is_active, updated_at, version
+		FROM users
+		WHERE org_id = ? AND team_id = ?
+		ORDER BY user_id
+	`
+	return queryUsers(ctx, conn(ctx, u.db), query, storage.OrgFrom(ctx), teamID)
+}
+
+// Exists проверяет существует ли пользователь по его ID (userID).
+func (u *UserRepository) Exists(ctx context.Context, userID string) (bool, *apperrors.AppError) {
+	const query = `SELECT EXISTS(SELECT 1 FROM users WHERE org_id = ? AND user_id = ?)`
+
+	var exists bool
+	if err := conn(ctx, u.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), userID).Scan(&exists); err != nil {
+		log.Printf("query failed: %v", err)
+		return false, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return exists, nil
+}
+
+// queryUsers выполняет запрос, возвращающий колонки user_id, username, team_id, is_active, updated_at, version.
+func queryUsers(ctx context.Context, q querier, query string, args ...any) ([]storage.User, *apperrors.AppError) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("query users failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	var users []storage.User
+	for rows.Next() {
+		var user storage.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.TeamID, &user.IsActive, &user.UpdatedAt, &user.Version); err != nil {
+			log.Printf("scan user failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return users, nil
+}
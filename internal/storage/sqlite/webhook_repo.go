@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// WebhookRepository - репозиторий подписчиков на доменные события в SQLite.
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository создаёт экземпляр *WebhookRepository.
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create регистрирует подписчика и возвращает его с присвоенным id.
+func (w *WebhookRepository) Create(ctx context.Context, webhook storage.Webhook) (storage.Webhook, *apperrors.AppError) {
+	const query = `
+		INSERT INTO webhooks (org_id, url, secret, event_types, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id
+	`
+
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []string{}
+	}
+	eventTypes, err := json.Marshal(webhook.EventTypes)
+	if err != nil {
+		log.Printf("marshal event types failed: %v", err)
+		return storage.Webhook{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+
+	webhook.CreatedAt = time.Now().UTC()
+	err = conn(ctx, w.db).QueryRowContext(ctx, query, storage.OrgFrom(ctx), webhook.URL, webhook.Secret, string(eventTypes), webhook.CreatedAt).Scan(&webhook.ID)
+	if err != nil {
+		log.Printf("insert webhook failed: %v", err)
+		return storage.Webhook{}, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return webhook, nil
+}
+
+// List возвращает подписчиков организации в порядке регистрации.
+func (w *WebhookRepository) List(ctx context.Context) ([]storage.Webhook, *apperrors.AppError) {
+	const query = `SELECT id, url, secret, event_types, created_at FROM webhooks WHERE org_id = ? ORDER BY id`
+
+	rows, err := conn(ctx, w.db).QueryContext(ctx, query, storage.OrgFrom(ctx))
+	if err != nil {
+		log.Printf("query failed: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	defer rows.Close()
+
+	webhooks := make([]storage.Webhook, 0)
+	for rows.Next() {
+		var wh storage.Webhook
+		var eventTypes string
+		if err := rows.Scan(&wh.ID, &wh.URL, &wh.Secret, &eventTypes, &wh.CreatedAt); err != nil {
+			log.Printf("scan failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		if err := json.Unmarshal([]byte(eventTypes), &wh.EventTypes); err != nil {
+			log.Printf("unmarshal event types failed: %v", err)
+			return nil, apperrors.New(apperrors.ErrInternalIssue)
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("rows error: %v", err)
+		return nil, apperrors.New(apperrors.ErrInternalIssue)
+	}
+	return webhooks, nil
+}
@@ -0,0 +1,1599 @@
+// Package storagetest содержит общий набор тестов для реализаций интерфейсов storage.
+// Один и тот же набор прогоняется против каждого хранилища, чтобы их семантика
+// и коды ошибок не расходились.
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/apperrors"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Backend - набор репозиториев одного хранилища.
+type Backend struct {
+	Tx       storage.TxManager
+	Users    storage.UserRepository
+	Teams    storage.TeamRepository
+	PRs      storage.PullRequestRepository
+	Logs     storage.AssignmentLogRepository
+	Events   storage.PREventRepository
+	Webhooks storage.WebhookRepository
+	Outbox   storage.OutboxRepository
+
+	Integrations  storage.IntegrationRepository
+	ReviewerSyncs storage.ReviewerSyncRepository
+	Notifications storage.NotificationRepository
+	ReviewSLAs    storage.ReviewSLARepository
+	APITokens     storage.APITokenRepository
+	Orgs          storage.OrganizationRepository
+	Audit         storage.AuditLogRepository
+	Idempotency   storage.IdempotencyRepository
+}
+
+// Run прогоняет общие тесты репозиториев. newBackend вызывается для каждого подтеста
+// и должен возвращать пустое хранилище.
+func Run(t *testing.T, newBackend func(t *testing.T) Backend) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, b Backend)
+	}{
+		{"TeamCreateAndGet", testTeamCreateAndGet},
+		{"TeamDuplicate", testTeamDuplicate},
+		{"TeamNotFound", testTeamNotFound},
+		{"TeamCreateMovesExistingUsers", testTeamCreateMovesExistingUsers},
+		{"UserGetAndSetActive", testUserGetAndSetActive},
+		{"UserTeammates", testUserTeammates},
+		{"PRCreateAndGet", testPRCreateAndGet},
+		{"PRDuplicate", testPRDuplicate},
+		{"PRMerge", testPRMerge},
+		{"PRSetStatus", testPRSetStatus},
+		{"PRReplaceReviewer", testPRReplaceReviewer},
+		{"PRByReviewerAndStats", testPRByReviewerAndStats},
+		{"AssignmentLog", testAssignmentLog},
+		{"PREvents", testPREvents},
+		{"OutboxFanOut", testOutboxFanOut},
+		{"OutboxClaimAndRetry", testOutboxClaimAndRetry},
+		{"OutboxRollback", testOutboxRollback},
+		{"IntegrationIdentities", testIntegrationIdentities},
+		{"IntegrationDeliveries", testIntegrationDeliveries},
+		{"InboundSecrets", testInboundSecrets},
+		{"ReviewerSyncQueue", testReviewerSyncQueue},
+		{"TeamChats", testTeamChats},
+		{"NotificationQueue", testNotificationQueue},
+		{"EmailPreferences", testEmailPreferences},
+		{"ReviewSLA", testReviewSLA},
+		{"PRAddReviewer", testPRAddReviewer},
+		{"APITokens", testAPITokens},
+		{"Organizations", testOrganizations},
+		{"TenantIsolation", testTenantIsolation},
+		{"TenantQueues", testTenantQueues},
+		{"AuditLog", testAuditLog},
+		{"IdempotencyKeys", testIdempotencyKeys},
+		{"Versions", testVersions},
+		{"TxCommitAndRollback", testTxCommitAndRollback},
+		{"TxAfterCommit", testTxAfterCommit},
+		{"ConcurrentWrites", testConcurrentWrites},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newBackend(t))
+		})
+	}
+}
+
+func requireCode(t *testing.T, want apperrors.Code, err *apperrors.AppError) {
+	t.Helper()
+	require.NotNil(t, err, "expected %s", want)
+	require.Equal(t, want, err.Code)
+}
+
+func createTeam(t *testing.T, b Backend, name string, members ...storage.User) storage.Team {
+	t.Helper()
+	ctx := context.Background()
+	require.Nil(t, b.Teams.Create(ctx, storage.Team{TeamName: name, Members: members}))
+	team, err := b.Teams.GetByName(ctx, name)
+	require.Nil(t, err)
+	return team
+}
+
+func member(id string, active bool) storage.User {
+	return storage.User{ID: id, Username: "name-" + id, IsActive: active}
+}
+
+func memberIDs(users []storage.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func createPR(t *testing.T, b Backend, id, author string, reviewers ...string) {
+	t.Helper()
+	require.Nil(t, b.PRs.Create(context.Background(), storage.PullRequest{
+		ID:                id,
+		Name:              "name-" + id,
+		AuthorID:          author,
+		Status:            storage.StatusOpen,
+		CreatedAt:         time.Now().UTC(),
+		AssignedReviewers: reviewers,
+	}))
+}
+
+func testTeamCreateAndGet(t *testing.T, b Backend) {
+	ctx := context.Background()
+	team := createTeam(t, b, "backend", member("u1", true), member("u2", false))
+
+	require.Equal(t, "backend", team.TeamName)
+	require.NotZero(t, team.ID)
+	require.ElementsMatch(t, []string{"u1", "u2"}, memberIDs(team.Members))
+	for _, m := range team.Members {
+		require.Equal(t, "name-"+m.ID, m.Username)
+		require.Equal(t, m.ID == "u1", m.IsActive)
+	}
+
+	byID, err := b.Teams.GetByID(ctx, team.ID)
+	require.Nil(t, err)
+	require.Equal(t, "backend", byID.TeamName)
+	require.ElementsMatch(t, []string{"u1", "u2"}, memberIDs(byID.Members))
+
+	exists, err := b.Teams.Exists(ctx, "backend")
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	exists, err = b.Teams.Exists(ctx, "frontend")
+	require.Nil(t, err)
+	require.False(t, exists)
+}
+
+func testTeamDuplicate(t *testing.T, b Backend) {
+	createTeam(t, b, "backend", member("u1", true))
+
+	err := b.Teams.Create(context.Background(), storage.Team{TeamName: "backend", Members: []storage.User{member("u2", true)}})
+	requireCode(t, apperrors.ErrTeamExists, err)
+
+	exists, appErr := b.Users.Exists(context.Background(), "u2")
+	require.Nil(t, appErr)
+	require.False(t, exists, "failed team creation must not leave users behind")
+}
+
+func testTeamNotFound(t *testing.T, b Backend) {
+	_, err := b.Teams.GetByName(context.Background(), "missing")
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	_, err = b.Teams.GetByID(context.Background(), 424242)
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testTeamCreateMovesExistingUsers(t *testing.T, b Backend) {
+	ctx := context.Background()
+	first := createTeam(t, b, "first", member("u1", true), member("u2", true))
+	second := createTeam(t, b, "second", storage.User{ID: "u2", Username: "renamed", IsActive: false})
+
+	u2, err := b.Users.Get(ctx, "u2")
+	require.Nil(t, err)
+	require.Equal(t, second.ID, u2.TeamID)
+	require.Equal(t, "renamed", u2.Username)
+	require.False(t, u2.IsActive)
+
+	first, err = b.Teams.GetByName(ctx, "first")
+	require.Nil(t, err)
+	require.Equal(t, []string{"u1"}, memberIDs(first.Members))
+}
+
+func testUserGetAndSetActive(t *testing.T, b Backend) {
+	ctx := context.Background()
+	team := createTeam(t, b, "backend", member("u1", true))
+
+	u, err := b.Users.Get(ctx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, "u1", u.ID)
+	require.Equal(t, team.ID, u.TeamID)
+	require.True(t, u.IsActive)
+
+	updated, err := b.Users.SetActive(ctx, "u1", false)
+	require.Nil(t, err)
+	require.False(t, updated.IsActive)
+	require.Equal(t, team.ID, updated.TeamID)
+	require.False(t, updated.UpdatedAt.Before(u.UpdatedAt))
+
+	u, err = b.Users.Get(ctx, "u1")
+	require.Nil(t, err)
+	require.False(t, u.IsActive)
+
+	_, err = b.Users.Get(ctx, "ghost")
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	_, err = b.Users.SetActive(ctx, "ghost", true)
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	exists, err := b.Users.Exists(ctx, "u1")
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	exists, err = b.Users.Exists(ctx, "ghost")
+	require.Nil(t, err)
+	require.False(t, exists)
+}
+
+func testUserTeammates(t *testing.T, b Backend) {
+	ctx := context.Background()
+	team := createTeam(t, b, "backend", member("u1", true), member("u2", true), member("u3", false))
+	createTeam(t, b, "other", member("x1", true))
+
+	active, err := b.Users.GetActiveTeammates(ctx, team.ID, "u1")
+	require.Nil(t, err)
+	require.Equal(t, []string{"u2"}, memberIDs(active))
+
+	all, err := b.Users.GetByTeam(ctx, team.ID)
+	require.Nil(t, err)
+	require.Equal(t, []string{"u1", "u2", "u3"}, memberIDs(all), "GetByTeam is ordered by user_id")
+}
+
+func testPRCreateAndGet(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true), member("r2", true))
+	createPR(t, b, "pr-1", "a", "r1", "r2")
+
+	pr, err := b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, "pr-1", pr.ID)
+	require.Equal(t, "name-pr-1", pr.Name)
+	require.Equal(t, "a", pr.AuthorID)
+	require.Equal(t, storage.StatusOpen, pr.Status)
+	require.Nil(t, pr.MergedAt)
+	require.WithinDuration(t, time.Now(), pr.CreatedAt, time.Minute)
+	require.ElementsMatch(t, []string{"r1", "r2"}, pr.AssignedReviewers)
+
+	locked, err := b.PRs.GetForUpdate(ctx, "pr-1")
+	require.Nil(t, err)
+	require.ElementsMatch(t, pr.AssignedReviewers, locked.AssignedReviewers)
+
+	exists, err := b.PRs.Exists(ctx, "pr-1")
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	exists, err = b.PRs.Exists(ctx, "pr-2")
+	require.Nil(t, err)
+	require.False(t, exists)
+
+	_, err = b.PRs.Get(ctx, "pr-2")
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testPRDuplicate(t *testing.T, b Backend) {
+	createTeam(t, b, "backend", member("a", true), member("r1", true))
+	createPR(t, b, "pr-1", "a", "r1")
+
+	err := b.PRs.Create(context.Background(), storage.PullRequest{
+		ID:        "pr-1",
+		Name:      "again",
+		AuthorID:  "a",
+		Status:    storage.StatusOpen,
+		CreatedAt: time.Now().UTC(),
+	})
+	requireCode(t, apperrors.ErrPRExists, err)
+}
+
+func testPRMerge(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true))
+	createPR(t, b, "pr-1", "a", "r1")
+
+	merged, err := b.PRs.MarkMerged(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusMerged, merged.Status)
+	require.NotNil(t, merged.MergedAt)
+	require.Equal(t, []string{"r1"}, merged.AssignedReviewers)
+
+	again, err := b.PRs.MarkMerged(ctx, "pr-1")
+	require.Nil(t, err)
+	require.True(t, merged.MergedAt.Equal(*again.MergedAt), "merge is idempotent")
+
+	_, err = b.PRs.MarkMerged(ctx, "missing")
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testPRSetStatus(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true))
+	createPR(t, b, "pr-1", "a", "r1")
+
+	closed, err := b.PRs.SetStatus(ctx, "pr-1", storage.StatusClosed)
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusClosed, closed.Status)
+	require.Nil(t, closed.MergedAt)
+	require.Equal(t, []string{"r1"}, closed.AssignedReviewers)
+
+	got, err := b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusClosed, got.Status)
+
+	reopened, err := b.PRs.SetStatus(ctx, "pr-1", storage.StatusOpen)
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusOpen, reopened.Status)
+
+	_, err = b.PRs.SetStatus(ctx, "missing", storage.StatusClosed)
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testPRReplaceReviewer(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true), member("r2", true), member("r3", true))
+	createPR(t, b, "pr-1", "a", "r1", "r2")
+
+	require.Nil(t, b.PRs.ReplaceReviewer(ctx, "pr-1", "r1", "r3"))
+
+	pr, err := b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"r2", "r3"}, pr.AssignedReviewers)
+
+	requireCode(t, apperrors.ErrNotAssigned, b.PRs.ReplaceReviewer(ctx, "pr-1", "r1", "a"))
+	requireCode(t, apperrors.ErrNotAssigned, b.PRs.ReplaceReviewer(ctx, "missing", "r1", "a"))
+}
+
+func testPRByReviewerAndStats(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true), member("r2", true))
+	createPR(t, b, "pr-1", "a", "r1", "r2")
+	createPR(t, b, "pr-2", "a", "r1")
+	createPR(t, b, "pr-3", "a")
+
+	prs, err := b.PRs.GetByReviewer(ctx, "r1")
+	require.Nil(t, err)
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.ID)
+		require.Equal(t, "a", pr.AuthorID)
+		require.Equal(t, storage.StatusOpen, pr.Status)
+	}
+	require.ElementsMatch(t, []string{"pr-1", "pr-2"}, ids)
+
+	prs, err = b.PRs.GetByReviewer(ctx, "a")
+	require.Nil(t, err)
+	require.Empty(t, prs)
+
+	assigned, err := b.PRs.IsReviewerAssigned(ctx, "r2")
+	require.Nil(t, err)
+	require.True(t, assigned)
+
+	assigned, err = b.PRs.IsReviewerAssigned(ctx, "a")
+	require.Nil(t, err)
+	require.False(t, assigned)
+
+	byUser, err := b.PRs.CountAssignmentsByUser(ctx)
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"r1": 2, "r2": 1}, byUser)
+
+	byPR, err := b.PRs.CountAssignmentsByPR(ctx)
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"pr-1": 2, "pr-2": 1}, byPR)
+}
+
+func testAssignmentLog(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true), member("r2", true), member("r3", false))
+	createPR(t, b, "pr-1", "a", "r1")
+
+	seed := int64(-42)
+	draw := 1
+	require.Nil(t, b.Logs.Add(ctx, nil))
+	require.Nil(t, b.Logs.Add(ctx, []storage.AssignmentLog{
+		{
+			PullRequestID: "pr-1",
+			ReviewerID:    "r1",
+			Strategy:      storage.StrategyRandom,
+			PoolSize:      2,
+			Candidates:    []string{"r1", "r2"},
+			Excluded: []storage.ExcludedCandidate{
+				{UserID: "a", Reason: storage.ExcludedAuthor},
+				{UserID: "r3", Reason: storage.ExcludedInactive},
+			},
+			Draw: &draw,
+			Seed: &seed,
+		},
+	}))
+	require.Nil(t, b.Logs.Add(ctx, []storage.AssignmentLog{
+		{
+			PullRequestID:      "pr-1",
+			ReviewerID:         "r2",
+			ReplacedReviewerID: "r1",
+			Strategy:           storage.StrategyWholePool,
+			PoolSize:           1,
+			Candidates:         []string{"r2"},
+		},
+	}))
+
+	entries, err := b.Logs.GetByPR(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+
+	first := entries[0]
+	require.NotZero(t, first.ID)
+	require.Equal(t, "r1", first.ReviewerID)
+	require.Empty(t, first.ReplacedReviewerID)
+	require.Equal(t, storage.StrategyRandom, first.Strategy)
+	require.Equal(t, 2, first.PoolSize)
+	require.Equal(t, []string{"r1", "r2"}, first.Candidates)
+	require.Equal(t, []storage.ExcludedCandidate{
+		{UserID: "a", Reason: storage.ExcludedAuthor},
+		{UserID: "r3", Reason: storage.ExcludedInactive},
+	}, first.Excluded)
+	require.NotNil(t, first.Seed)
+	require.Equal(t, seed, *first.Seed)
+	require.NotNil(t, first.Draw)
+	require.Equal(t, draw, *first.Draw)
+	require.WithinDuration(t, time.Now(), first.CreatedAt, time.Minute)
+
+	second := entries[1]
+	require.Greater(t, second.ID, first.ID)
+	require.Equal(t, "r1", second.ReplacedReviewerID)
+	require.Equal(t, storage.StrategyWholePool, second.Strategy)
+	require.Empty(t, second.Excluded)
+	require.Nil(t, second.Seed)
+	require.Nil(t, second.Draw)
+
+	entries, err = b.Logs.GetByPR(ctx, "pr-2")
+	require.Nil(t, err)
+	require.Empty(t, entries)
+}
+
+func testPREvents(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("a", true), member("r1", true))
+	createPR(t, b, "pr-1", "a", "r1")
+
+	require.Nil(t, b.Events.Add(ctx, nil))
+	require.Nil(t, b.Events.Add(ctx, []storage.PREvent{
+		{PullRequestID: "pr-1", Type: storage.EventCreated, Actor: "a", Payload: []byte(`{"author_id":"a"}`)},
+		{PullRequestID: "pr-1", Type: storage.EventReviewerAssigned, Actor: "a", Payload: []byte(`{"reviewer_id":"r1"}`)},
+	}))
+	require.Nil(t, b.Events.Add(ctx, []storage.PREvent{
+		{PullRequestID: "pr-1", Type: storage.EventMerged, Actor: storage.ActorSystem},
+	}))
+
+	events, err := b.Events.GetByPR(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Len(t, events, 3)
+
+	require.Equal(t, storage.EventCreated, events[0].Type)
+	require.Equal(t, "a", events[0].Actor)
+	require.JSONEq(t, `{"author_id":"a"}`, string(events[0].Payload))
+	require.WithinDuration(t, time.Now(), events[0].CreatedAt, time.Minute)
+
+	require.Equal(t, storage.EventReviewerAssigned, events[1].Type)
+	require.Greater(t, events[1].ID, events[0].ID)
+
+	require.Equal(t, storage.EventMerged, events[2].Type)
+	require.Equal(t, storage.ActorSystem, events[2].Actor)
+	require.JSONEq(t, `{}`, string(events[2].Payload))
+
+	events, err = b.Events.GetByPR(ctx, "pr-2")
+	require.Nil(t, err)
+	require.Empty(t, events)
+
+	err = b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if err := b.Events.Add(ctx, []storage.PREvent{{PullRequestID: "pr-1", Type: storage.EventClosed, Actor: "a"}}); err != nil {
+			return err
+		}
+		return apperrors.New(apperrors.ErrPRMerged)
+	})
+	requireCode(t, apperrors.ErrPRMerged, err)
+
+	events, err = b.Events.GetByPR(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Len(t, events, 3, "events are rolled back with the transaction")
+}
+
+func testOutboxFanOut(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	all, err := b.Webhooks.Create(ctx, storage.Webhook{URL: "http://all.example", Secret: "s1"})
+	require.Nil(t, err)
+	require.NotZero(t, all.ID)
+	require.Empty(t, all.EventTypes)
+	merged, err := b.Webhooks.Create(ctx, storage.Webhook{URL: "http://merged.example", Secret: "s2", EventTypes: []string{"pr.merged"}})
+	require.Nil(t, err)
+
+	webhooks, err := b.Webhooks.List(ctx)
+	require.Nil(t, err)
+	require.Len(t, webhooks, 2)
+	require.Equal(t, all.ID, webhooks[0].ID)
+	require.Equal(t, []string{"pr.merged"}, webhooks[1].EventTypes)
+	require.Equal(t, "s2", webhooks[1].Secret)
+
+	require.Nil(t, b.Outbox.Add(ctx, nil))
+	require.Nil(t, b.Outbox.Add(ctx, []storage.OutboxEvent{
+		{Type: "pr.created", Payload: []byte(`{"id":"pr-1"}`)},
+		{Type: "pr.merged", Payload: []byte(`{"id":"pr-1"}`)},
+	}))
+
+	deliveries, err := b.Outbox.ListDeliveries(ctx, 0, "", 10)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 3, "pr.created goes to one subscriber, pr.merged to both")
+	require.Equal(t, "pr.merged", deliveries[0].Event.Type, "newest first")
+
+	deliveries, err = b.Outbox.ListDeliveries(ctx, merged.ID, "", 10)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, "http://merged.example", deliveries[0].URL)
+	require.Equal(t, "s2", deliveries[0].Secret)
+	require.Equal(t, storage.DeliveryPending, deliveries[0].Status)
+	require.Zero(t, deliveries[0].Attempts)
+	require.JSONEq(t, `{"id":"pr-1"}`, string(deliveries[0].Event.Payload))
+	require.WithinDuration(t, time.Now(), deliveries[0].Event.CreatedAt, time.Minute)
+
+	deliveries, err = b.Outbox.ListDeliveries(ctx, 0, storage.DeliveryPending, 2)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 2)
+}
+
+func testOutboxClaimAndRetry(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	_, err := b.Webhooks.Create(ctx, storage.Webhook{URL: "http://hook.example", Secret: "s"})
+	require.Nil(t, err)
+	require.Nil(t, b.Outbox.Add(ctx, []storage.OutboxEvent{
+		{Type: "pr.created", Payload: []byte(`{}`)},
+		{Type: "pr.merged", Payload: []byte(`{}`)},
+		{Type: "user.activity_changed", Payload: []byte(`{}`)},
+	}))
+
+	now := time.Now().Add(time.Second)
+	claimed, err := b.Outbox.ClaimDue(ctx, now, time.Minute, 2)
+	require.Nil(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, "pr.created", claimed[0].Event.Type)
+	require.Equal(t, 1, claimed[0].Attempts)
+	require.Equal(t, "http://hook.example", claimed[0].URL)
+
+	claimed2, err := b.Outbox.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, claimed2, 1, "leased deliveries are not claimed again")
+	require.Equal(t, "user.activity_changed", claimed2[0].Event.Type)
+
+	require.Nil(t, b.Outbox.MarkDelivered(ctx, claimed[0].ID))
+	require.Nil(t, b.Outbox.MarkFailed(ctx, claimed[1].ID, now, "status 500", false))
+	require.Nil(t, b.Outbox.MarkFailed(ctx, claimed2[0].ID, now, "status 410", true))
+	requireCode(t, apperrors.ErrNotFound, b.Outbox.MarkDelivered(ctx, 999))
+
+	retry, err := b.Outbox.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, retry, 1, "only the failed pending delivery is due again")
+	require.Equal(t, claimed[1].ID, retry[0].ID)
+	require.Equal(t, 2, retry[0].Attempts)
+	require.Equal(t, "status 500", retry[0].LastError)
+
+	empty, err := b.Outbox.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Empty(t, empty)
+
+	expired, err := b.Outbox.ClaimDue(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, expired, 1, "delivery is claimable again once the lease expires")
+
+	delivered, err := b.Outbox.ListDeliveries(ctx, 0, storage.DeliveryDelivered, 10)
+	require.Nil(t, err)
+	require.Len(t, delivered, 1)
+	require.Equal(t, claimed[0].ID, delivered[0].ID)
+
+	dead, err := b.Outbox.ListDeliveries(ctx, 0, storage.DeliveryDead, 10)
+	require.Nil(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "status 410", dead[0].LastError)
+}
+
+func testOutboxRollback(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	_, err := b.Webhooks.Create(ctx, storage.Webhook{URL: "http://hook.example", Secret: "s"})
+	require.Nil(t, err)
+
+	err = b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if err := b.Outbox.Add(ctx, []storage.OutboxEvent{{Type: "pr.created"}}); err != nil {
+			return err
+		}
+		return apperrors.New(apperrors.ErrPRExists)
+	})
+	requireCode(t, apperrors.ErrPRExists, err)
+
+	deliveries, err := b.Outbox.ListDeliveries(ctx, 0, "", 10)
+	require.Nil(t, err)
+	require.Empty(t, deliveries, "outbox writes are rolled back with the transaction")
+
+	err = b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		return b.Outbox.Add(ctx, []storage.OutboxEvent{{Type: "pr.created"}})
+	})
+	require.Nil(t, err)
+
+	deliveries, err = b.Outbox.ListDeliveries(ctx, 0, "", 10)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1)
+	require.JSONEq(t, `{}`, string(deliveries[0].Event.Payload))
+}
+
+func testIntegrationIdentities(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("u1", true), member("u2", true))
+
+	_, err := b.Integrations.ResolveIdentity(ctx, storage.ProviderGitHub, "octocat")
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	require.Nil(t, b.Integrations.LinkIdentity(ctx, storage.ProviderGitHub, "octocat", "u1"))
+	userID, err := b.Integrations.ResolveIdentity(ctx, storage.ProviderGitHub, "octocat")
+	require.Nil(t, err)
+	require.Equal(t, "u1", userID)
+
+	_, err = b.Integrations.ResolveIdentity(ctx, storage.ProviderGitLab, "octocat")
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	require.Nil(t, b.Integrations.LinkIdentity(ctx, storage.ProviderGitHub, "octocat", "u2"))
+	userID, err = b.Integrations.ResolveIdentity(ctx, storage.ProviderGitHub, "octocat")
+	require.Nil(t, err)
+	require.Equal(t, "u2", userID, "link overwrites the previous user")
+
+	_, err = b.Integrations.IdentityLogin(ctx, storage.ProviderGitHub, "u1")
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	require.Nil(t, b.Integrations.LinkIdentity(ctx, storage.ProviderGitHub, "cat-u2", "u2"))
+	login, err := b.Integrations.IdentityLogin(ctx, storage.ProviderGitHub, "u2")
+	require.Nil(t, err)
+	require.Equal(t, "cat-u2", login, "the alphabetically first login wins")
+
+	_, err = b.Integrations.IdentityLogin(ctx, storage.ProviderGitLab, "u2")
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testIntegrationDeliveries(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	fresh, err := b.Integrations.RecordDelivery(ctx, storage.ProviderGitHub, "d-1")
+	require.Nil(t, err)
+	require.True(t, fresh)
+
+	fresh, err = b.Integrations.RecordDelivery(ctx, storage.ProviderGitHub, "d-1")
+	require.Nil(t, err)
+	require.False(t, fresh, "redelivery is detected")
+
+	fresh, err = b.Integrations.RecordDelivery(ctx, storage.ProviderGitLab, "d-1")
+	require.Nil(t, err)
+	require.True(t, fresh, "delivery ids are scoped by provider")
+
+	appErr := b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		fresh, err := b.Integrations.RecordDelivery(ctx, storage.ProviderGitHub, "d-2")
+		if err != nil {
+			return err
+		}
+		require.True(t, fresh)
+		return apperrors.New(apperrors.ErrNotFound)
+	})
+	requireCode(t, apperrors.ErrNotFound, appErr)
+
+	fresh, err = b.Integrations.RecordDelivery(ctx, storage.ProviderGitHub, "d-2")
+	require.Nil(t, err)
+	require.True(t, fresh, "a rolled back delivery is processed again")
+}
+
+func testInboundSecrets(t *testing.T, b Backend) {
+	ctx := context.Background()
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	actx := storage.WithOrg(ctx, acme.ID)
+
+	_, err = b.Integrations.GetInboundSecret(ctx, storage.ProviderGitHub)
+	requireCode(t, apperrors.ErrNotFound, err)
+	requireCode(t, apperrors.ErrNotFound, b.Integrations.DeleteInboundSecret(ctx, storage.ProviderGitHub))
+
+	require.Nil(t, b.Integrations.SetInboundSecret(actx, storage.ProviderGitHub, "acme-old"))
+	require.Nil(t, b.Integrations.SetInboundSecret(actx, storage.ProviderGitHub, "acme-new"))
+	require.Nil(t, b.Integrations.SetInboundSecret(ctx, storage.ProviderGitHub, "default"))
+	require.Nil(t, b.Integrations.SetInboundSecret(actx, storage.ProviderGitLab, "acme-gitlab"))
+
+	secret, err := b.Integrations.GetInboundSecret(actx, storage.ProviderGitHub)
+	require.Nil(t, err)
+	require.Equal(t, "acme-new", secret.Secret, "setting a secret replaces the previous one")
+	require.Equal(t, acme.ID, secret.OrgID)
+	require.WithinDuration(t, time.Now(), secret.CreatedAt, time.Minute)
+
+	secrets, err := b.Integrations.InboundSecrets(ctx, storage.ProviderGitHub)
+	require.Nil(t, err)
+	require.Len(t, secrets, 2, "secrets of all organizations are returned")
+	require.Equal(t, storage.DefaultOrgID, secrets[0].OrgID)
+	require.Equal(t, "default", secrets[0].Secret)
+	require.Equal(t, acme.ID, secrets[1].OrgID)
+	require.Equal(t, "acme-new", secrets[1].Secret)
+
+	require.Nil(t, b.Integrations.DeleteInboundSecret(actx, storage.ProviderGitHub))
+	_, err = b.Integrations.GetInboundSecret(actx, storage.ProviderGitHub)
+	requireCode(t, apperrors.ErrNotFound, err)
+	secrets, err = b.Integrations.InboundSecrets(ctx, storage.ProviderGitHub)
+	require.Nil(t, err)
+	require.Len(t, secrets, 1)
+	require.Equal(t, storage.DefaultOrgID, secrets[0].OrgID)
+}
+
+func testReviewerSyncQueue(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("u1", true), member("u2", true), member("u3", true))
+	createPR(t, b, "github:acme/widgets#1", "u1", "u2")
+	createPR(t, b, "github:acme/widgets#2", "u1", "u3")
+
+	require.Nil(t, b.ReviewerSyncs.Enqueue(ctx, storage.ReviewerSync{Provider: storage.ProviderGitHub, PullRequestID: "github:acme/widgets#1"}))
+	require.Nil(t, b.ReviewerSyncs.Enqueue(ctx, storage.ReviewerSync{
+		Provider:      storage.ProviderGitHub,
+		PullRequestID: "github:acme/widgets#1",
+		Removed:       []string{"u3"},
+	}))
+	require.Nil(t, b.ReviewerSyncs.Enqueue(ctx, storage.ReviewerSync{Provider: storage.ProviderGitHub, PullRequestID: "github:acme/widgets#2"}))
+
+	rollback := b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		require.Nil(t, b.ReviewerSyncs.Enqueue(ctx, storage.ReviewerSync{Provider: storage.ProviderGitHub, PullRequestID: "github:acme/widgets#2"}))
+		return apperrors.New(apperrors.ErrNoCandidate)
+	})
+	requireCode(t, apperrors.ErrNoCandidate, rollback)
+
+	now := time.Now().Add(time.Second)
+	claimed, err := b.ReviewerSyncs.ClaimDue(ctx, now, time.Minute, 2)
+	require.Nil(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, "github:acme/widgets#1", claimed[0].PullRequestID)
+	require.Equal(t, storage.ProviderGitHub, claimed[0].Provider)
+	require.Empty(t, claimed[0].Removed)
+	require.Equal(t, []string{"u3"}, claimed[1].Removed)
+	require.Equal(t, 1, claimed[0].Attempts)
+
+	claimed2, err := b.ReviewerSyncs.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, claimed2, 1, "leased and rolled back syncs are not claimed")
+	require.Equal(t, "github:acme/widgets#2", claimed2[0].PullRequestID)
+
+	require.Nil(t, b.ReviewerSyncs.MarkDone(ctx, claimed[0].ID))
+	require.Nil(t, b.ReviewerSyncs.MarkFailed(ctx, claimed[1].ID, now, "status 502", false))
+	require.Nil(t, b.ReviewerSyncs.MarkFailed(ctx, claimed2[0].ID, now, "status 422", true))
+	requireCode(t, apperrors.ErrNotFound, b.ReviewerSyncs.MarkDone(ctx, 999))
+
+	retry, err := b.ReviewerSyncs.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, retry, 1, "only the failed pending sync is due again")
+	require.Equal(t, claimed[1].ID, retry[0].ID)
+	require.Equal(t, 2, retry[0].Attempts)
+	require.Equal(t, "status 502", retry[0].LastError)
+
+	syncs, err := b.ReviewerSyncs.List(ctx, "github:acme/widgets#1")
+	require.Nil(t, err)
+	require.Len(t, syncs, 2)
+	require.Equal(t, storage.DeliveryDelivered, syncs[0].Status)
+	require.Equal(t, storage.DeliveryPending, syncs[1].Status)
+
+	syncs, err = b.ReviewerSyncs.List(ctx, "github:acme/widgets#2")
+	require.Nil(t, err)
+	require.Len(t, syncs, 1)
+	require.Equal(t, storage.DeliveryDead, syncs[0].Status)
+	require.Equal(t, "status 422", syncs[0].LastError)
+}
+
+func testTeamChats(t *testing.T, b Backend) {
+	ctx := context.Background()
+	team := createTeam(t, b, "backend", member("u1", true))
+
+	_, err := b.Notifications.GetTeamChat(ctx, team.ID)
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	require.Nil(t, b.Notifications.SetTeamChat(ctx, storage.TeamChat{TeamID: team.ID, WebhookURL: "https://chat.example/hooks/1"}))
+	require.Nil(t, b.Notifications.SetTeamChat(ctx, storage.TeamChat{TeamID: team.ID, WebhookURL: "https://chat.example/hooks/2"}))
+
+	chat, err := b.Notifications.GetTeamChat(ctx, team.ID)
+	require.Nil(t, err)
+	require.Equal(t, team.ID, chat.TeamID)
+	require.Equal(t, "https://chat.example/hooks/2", chat.WebhookURL)
+	require.False(t, chat.UpdatedAt.IsZero())
+
+	requireCode(t, apperrors.ErrNotFound, b.Notifications.SetTeamChat(ctx, storage.TeamChat{TeamID: 999, WebhookURL: "https://chat.example"}))
+
+	require.Nil(t, b.Notifications.DeleteTeamChat(ctx, team.ID))
+	requireCode(t, apperrors.ErrNotFound, b.Notifications.DeleteTeamChat(ctx, team.ID))
+	_, err = b.Notifications.GetTeamChat(ctx, team.ID)
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testNotificationQueue(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	require.Nil(t, b.Notifications.Enqueue(ctx, []storage.Notification{
+		{Channel: storage.ChannelChat, Target: "https://chat.example/a", Body: "first"},
+		{Channel: storage.ChannelEmail, Target: "bob@example.com", Subject: "Review", Body: "second"},
+	}))
+	require.Nil(t, b.Notifications.Enqueue(ctx, []storage.Notification{
+		{Channel: storage.ChannelChat, Target: "https://chat.example/b", Body: "third"},
+	}))
+	require.Nil(t, b.Notifications.Enqueue(ctx, nil))
+
+	rollback := b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		require.Nil(t, b.Notifications.Enqueue(ctx, []storage.Notification{{Channel: storage.ChannelChat, Target: "x", Body: "rolled back"}}))
+		return apperrors.New(apperrors.ErrNoCandidate)
+	})
+	requireCode(t, apperrors.ErrNoCandidate, rollback)
+
+	now := time.Now().Add(time.Second)
+	claimed, err := b.Notifications.ClaimDue(ctx, now, time.Minute, 2)
+	require.Nil(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, "first", claimed[0].Body)
+	require.Equal(t, storage.ChannelEmail, claimed[1].Channel)
+	require.Equal(t, "bob@example.com", claimed[1].Target)
+	require.Equal(t, "Review", claimed[1].Subject)
+	require.Equal(t, 1, claimed[0].Attempts)
+
+	claimed2, err := b.Notifications.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, claimed2, 1, "leased and rolled back notifications are not claimed")
+	require.Equal(t, "third", claimed2[0].Body)
+
+	require.Nil(t, b.Notifications.MarkDone(ctx, claimed[0].ID))
+	require.Nil(t, b.Notifications.MarkFailed(ctx, claimed[1].ID, now, "status 502", false))
+	require.Nil(t, b.Notifications.MarkFailed(ctx, claimed2[0].ID, now, "status 404", true))
+	requireCode(t, apperrors.ErrNotFound, b.Notifications.MarkDone(ctx, 999))
+
+	retry, err := b.Notifications.ClaimDue(ctx, now, time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, retry, 1, "only the failed pending notification is due again")
+	require.Equal(t, claimed[1].ID, retry[0].ID)
+	require.Equal(t, 2, retry[0].Attempts)
+	require.Equal(t, "status 502", retry[0].LastError)
+
+	all, err := b.Notifications.List(ctx, "", 10)
+	require.Nil(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, "third", all[0].Body, "newest first")
+
+	dead, err := b.Notifications.List(ctx, storage.DeliveryDead, 10)
+	require.Nil(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "status 404", dead[0].LastError)
+
+	limited, err := b.Notifications.List(ctx, "", 1)
+	require.Nil(t, err)
+	require.Len(t, limited, 1)
+}
+
+func testEmailPreferences(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("u1", true), member("u2", true), member("u3", true))
+
+	_, err := b.Notifications.GetEmailPreference(ctx, "u1")
+	requireCode(t, apperrors.ErrNotFound, err)
+	requireCode(t, apperrors.ErrNotFound, b.Notifications.SetEmailPreference(ctx,
+		storage.EmailPreference{UserID: "ghost", Email: "ghost@example.com", Mode: storage.EmailDigest}))
+
+	for _, pref := range []storage.EmailPreference{
+		{UserID: "u1", Email: "u1@example.com", Mode: storage.EmailDigest},
+		{UserID: "u2", Email: "u2@example.com", Mode: storage.EmailImmediate},
+		{UserID: "u3", Email: "u3@example.com", Mode: storage.EmailDigest},
+	} {
+		require.Nil(t, b.Notifications.SetEmailPreference(ctx, pref))
+	}
+
+	pref, err := b.Notifications.GetEmailPreference(ctx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, "u1@example.com", pref.Email)
+	require.Equal(t, storage.EmailDigest, pref.Mode)
+	require.WithinDuration(t, time.Now(), pref.LastDigestAt, time.Minute)
+
+	cutoff := time.Now().Add(time.Second)
+	sentAt := cutoff.Add(time.Second).Truncate(time.Millisecond)
+	claimed, err := b.Notifications.ClaimDigests(ctx, cutoff, sentAt, 1)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "u1", claimed[0].UserID)
+	require.True(t, sentAt.Equal(claimed[0].LastDigestAt))
+
+	claimed, err = b.Notifications.ClaimDigests(ctx, cutoff, sentAt, 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1, "immediate mode and already sent digests are skipped")
+	require.Equal(t, "u3", claimed[0].UserID)
+
+	require.Nil(t, b.Notifications.SetEmailPreference(ctx, storage.EmailPreference{UserID: "u1", Email: "new@example.com", Mode: storage.EmailDigest}))
+	pref, err = b.Notifications.GetEmailPreference(ctx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, "new@example.com", pref.Email)
+	require.True(t, sentAt.Equal(pref.LastDigestAt), "same mode keeps the digest time")
+
+	require.Nil(t, b.Notifications.SetEmailPreference(ctx, storage.EmailPreference{UserID: "u1", Email: "new@example.com", Mode: storage.EmailNone}))
+	pref, err = b.Notifications.GetEmailPreference(ctx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, storage.EmailNone, pref.Mode)
+	require.True(t, pref.LastDigestAt.Before(sentAt), "mode change resets the digest time")
+
+	claimed, err = b.Notifications.ClaimDigests(ctx, sentAt.Add(time.Hour), sentAt.Add(time.Hour), 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "u3", claimed[0].UserID)
+}
+
+func testPRAddReviewer(t *testing.T, b Backend) {
+	ctx := context.Background()
+	createTeam(t, b, "backend", member("u1", true), member("u2", true), member("u3", true))
+	createPR(t, b, "pr-1", "u1", "u2")
+
+	require.Nil(t, b.PRs.AddReviewer(ctx, "pr-1", "u3"))
+	requireCode(t, apperrors.ErrInternalIssue, b.PRs.AddReviewer(ctx, "pr-1", "u3"))
+	requireCode(t, apperrors.ErrInternalIssue, b.PRs.AddReviewer(ctx, "pr-1", "ghost"))
+
+	pr, err := b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+}
+
+func testReviewSLA(t *testing.T, b Backend) {
+	ctx := context.Background()
+	team := createTeam(t, b, "backend", member("u1", true), member("u2", true), member("u3", true), member("u4", true))
+	other := createTeam(t, b, "frontend", member("f1", true), member("f2", true))
+	createPR(t, b, "pr-1", "u1", "u2", "u3")
+	createPR(t, b, "pr-2", "u1", "u2")
+	createPR(t, b, "pr-3", "f1", "f2")
+	_, err := b.PRs.MarkMerged(ctx, "pr-2")
+	require.Nil(t, err)
+
+	_, err = b.ReviewSLAs.GetTeamSLA(ctx, team.ID)
+	requireCode(t, apperrors.ErrNotFound, err)
+	requireCode(t, apperrors.ErrNotFound, b.ReviewSLAs.DeleteTeamSLA(ctx, team.ID))
+
+	require.Nil(t, b.ReviewSLAs.SetTeamSLA(ctx, storage.ReviewSLA{TeamID: team.ID, Within: time.Hour, Escalation: storage.EscalateNone}))
+	want := storage.ReviewSLA{TeamID: team.ID, Within: time.Hour, Escalation: storage.EscalateLead, EscalateAfter: 2 * time.Hour, LeadID: "u4"}
+	require.Nil(t, b.ReviewSLAs.SetTeamSLA(ctx, want))
+	sla, err := b.ReviewSLAs.GetTeamSLA(ctx, team.ID)
+	require.Nil(t, err)
+	require.WithinDuration(t, time.Now(), sla.UpdatedAt, time.Minute)
+	sla.UpdatedAt = time.Time{}
+	require.Equal(t, want, sla)
+
+	now := time.Now()
+	claimed, err := b.ReviewSLAs.ClaimReminders(ctx, now, 10)
+	require.Nil(t, err)
+	require.Empty(t, claimed, "not overdue yet")
+
+	later := now.Add(time.Hour + time.Minute).Truncate(time.Millisecond)
+	claimed, err = b.ReviewSLAs.ClaimReminders(ctx, later, 1)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "pr-1", claimed[0].PullRequestID)
+	require.Equal(t, "u2", claimed[0].ReviewerID)
+	require.NotNil(t, claimed[0].RemindedAt)
+	require.True(t, later.Equal(*claimed[0].RemindedAt))
+	require.Equal(t, "u4", claimed[0].SLA.LeadID)
+	require.Equal(t, time.Hour, claimed[0].SLA.Within)
+
+	claimed, err = b.ReviewSLAs.ClaimReminders(ctx, later, 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1, "merged PRs, teams without SLA and reminded reviews are skipped")
+	require.Equal(t, "u3", claimed[0].ReviewerID)
+
+	claimed, err = b.ReviewSLAs.ClaimEscalations(ctx, later, 10)
+	require.Nil(t, err)
+	require.Empty(t, claimed)
+	claimed, err = b.ReviewSLAs.ClaimEscalations(ctx, later.Add(time.Hour), 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, storage.EscalateLead, claimed[0].SLA.Escalation)
+	claimed, err = b.ReviewSLAs.ClaimEscalations(ctx, later.Add(time.Hour), 10)
+	require.Nil(t, err)
+	require.Empty(t, claimed, "escalated once")
+
+	require.Nil(t, b.PRs.ReplaceReviewer(ctx, "pr-1", "u2", "u4"))
+	reviews, err := b.ReviewSLAs.GetReviews(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Len(t, reviews, 2)
+	require.Equal(t, "u3", reviews[0].ReviewerID)
+	require.NotNil(t, reviews[0].RemindedAt)
+	require.NotNil(t, reviews[0].EscalatedAt)
+	require.Equal(t, "u4", reviews[1].ReviewerID)
+	require.Nil(t, reviews[1].RemindedAt, "replacement starts the SLA over")
+	require.Nil(t, reviews[1].EscalatedAt)
+	require.WithinDuration(t, time.Now(), reviews[1].AssignedAt, time.Minute)
+
+	claimed, err = b.ReviewSLAs.ClaimReminders(ctx, time.Now().Add(2*time.Hour), 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "u4", claimed[0].ReviewerID)
+
+	require.Nil(t, b.ReviewSLAs.SetTeamSLA(ctx, storage.ReviewSLA{TeamID: other.ID, Within: time.Hour, Escalation: storage.EscalateNone}))
+	claimed, err = b.ReviewSLAs.ClaimEscalations(ctx, time.Now().Add(24*time.Hour), 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1, "no escalation without an escalation mode")
+	require.Equal(t, "u4", claimed[0].ReviewerID)
+
+	require.Nil(t, b.ReviewSLAs.DeleteTeamSLA(ctx, other.ID))
+	claimed, err = b.ReviewSLAs.ClaimReminders(ctx, time.Now().Add(24*time.Hour), 10)
+	require.Nil(t, err)
+	require.Empty(t, claimed)
+
+	reviews, err = b.ReviewSLAs.GetReviews(ctx, "missing")
+	require.Nil(t, err)
+	require.Empty(t, reviews)
+}
+
+func testAPITokens(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	_, err := b.APITokens.GetByHash(ctx, "missing")
+	requireCode(t, apperrors.ErrNotFound, err)
+	requireCode(t, apperrors.ErrNotFound, b.APITokens.Revoke(ctx, 42))
+
+	ci, err := b.APITokens.Create(ctx, storage.APIToken{Name: "ci", Hash: "h1", Scopes: []storage.TokenScope{storage.ScopeRead, storage.ScopePRWrite}})
+	require.Nil(t, err)
+	require.NotZero(t, ci.ID)
+	require.False(t, ci.CreatedAt.IsZero())
+	admin, err := b.APITokens.Create(ctx, storage.APIToken{Name: "admin", Hash: "h2", Scopes: []storage.TokenScope{storage.ScopeAdmin}})
+	require.Nil(t, err)
+	require.Greater(t, admin.ID, ci.ID)
+
+	_, err = b.APITokens.Create(ctx, storage.APIToken{Name: "dup", Hash: "h1", Scopes: []storage.TokenScope{storage.ScopeRead}})
+	requireCode(t, apperrors.ErrInternalIssue, err)
+
+	got, err := b.APITokens.GetByHash(ctx, "h1")
+	require.Nil(t, err)
+	require.Equal(t, ci.ID, got.ID)
+	require.Equal(t, "ci", got.Name)
+	require.Equal(t, []storage.TokenScope{storage.ScopeRead, storage.ScopePRWrite}, got.Scopes)
+	require.Nil(t, got.RevokedAt)
+
+	require.Nil(t, b.APITokens.Revoke(ctx, ci.ID))
+	got, err = b.APITokens.GetByHash(ctx, "h1")
+	require.Nil(t, err)
+	require.NotNil(t, got.RevokedAt)
+	revokedAt := *got.RevokedAt
+
+	require.Nil(t, b.APITokens.Revoke(ctx, ci.ID), "revoking twice is idempotent")
+	got, err = b.APITokens.GetByHash(ctx, "h1")
+	require.Nil(t, err)
+	require.True(t, revokedAt.Equal(*got.RevokedAt))
+
+	tokens, err := b.APITokens.List(ctx)
+	require.Nil(t, err)
+	require.Len(t, tokens, 2)
+	require.Equal(t, "ci", tokens[0].Name)
+	require.NotNil(t, tokens[0].RevokedAt)
+	require.Equal(t, "admin", tokens[1].Name)
+	require.Nil(t, tokens[1].RevokedAt)
+}
+
+func testOrganizations(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	def, err := b.Orgs.GetByName(ctx, storage.DefaultOrgName)
+	require.Nil(t, err)
+	require.Equal(t, storage.DefaultOrgID, def.ID)
+	_, err = b.Orgs.GetByName(ctx, "acme")
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	require.Greater(t, acme.ID, storage.DefaultOrgID)
+	require.Equal(t, "acme", acme.Name)
+	require.False(t, acme.CreatedAt.IsZero())
+	_, err = b.Orgs.Create(ctx, "acme")
+	requireCode(t, apperrors.ErrOrgExists, err)
+
+	got, err := b.Orgs.GetByName(ctx, "acme")
+	require.Nil(t, err)
+	require.Equal(t, acme.ID, got.ID)
+
+	orgs, err := b.Orgs.List(ctx)
+	require.Nil(t, err)
+	require.Len(t, orgs, 2)
+	require.Equal(t, storage.DefaultOrgName, orgs[0].Name)
+	require.Equal(t, "acme", orgs[1].Name)
+}
+
+func testTenantIsolation(t *testing.T, b Backend) {
+	ctx := context.Background()
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	actx := storage.WithOrg(ctx, acme.ID)
+
+	team := createTeam(t, b, "backend", member("u1", true), member("u2", true))
+	createPR(t, b, "pr-1", "u1", "u2")
+
+	require.Nil(t, b.Teams.Create(actx, storage.Team{TeamName: "backend", Members: []storage.User{member("a1", true), member("a2", true)}}),
+		"team names are unique per organization")
+	requireCode(t, apperrors.ErrTeamExists, b.Teams.Create(actx, storage.Team{TeamName: "backend"}))
+
+	acmeTeam, err := b.Teams.GetByName(actx, "backend")
+	require.Nil(t, err)
+	require.NotEqual(t, team.ID, acmeTeam.ID)
+	require.Equal(t, []string{"a1", "a2"}, memberIDs(acmeTeam.Members))
+	_, err = b.Teams.GetByID(actx, team.ID)
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	_, err = b.Users.Get(actx, "u1")
+	requireCode(t, apperrors.ErrNotFound, err)
+	_, err = b.Users.SetActive(actx, "u1", false)
+	requireCode(t, apperrors.ErrNotFound, err)
+	teammates, err := b.Users.GetActiveTeammates(actx, team.ID, "")
+	require.Nil(t, err)
+	require.Empty(t, teammates)
+
+	require.Nil(t, b.PRs.Create(actx, storage.PullRequest{ID: "pr-1", Name: "acme", AuthorID: "a1", Status: storage.StatusOpen,
+		CreatedAt: time.Now().UTC(), AssignedReviewers: []string{"a2"}}), "pr ids are unique per organization")
+	pr, err := b.PRs.Get(actx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, "acme", pr.Name)
+	require.Equal(t, []string{"a2"}, pr.AssignedReviewers)
+	pr, err = b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+	_, err = b.PRs.MarkMerged(actx, "pr-1")
+	require.Nil(t, err)
+	pr, err = b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Equal(t, storage.StatusOpen, pr.Status, "merging in one organization does not touch the other")
+
+	prs, err := b.PRs.GetByReviewer(actx, "u2")
+	require.Nil(t, err)
+	require.Empty(t, prs)
+	counts, err := b.PRs.CountAssignmentsByUser(actx)
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"a2": 1}, counts)
+
+	requireCode(t, apperrors.ErrNotFound, b.Notifications.SetTeamChat(actx, storage.TeamChat{TeamID: team.ID, WebhookURL: "https://chat.example"}))
+	requireCode(t, apperrors.ErrNotFound, b.ReviewSLAs.SetTeamSLA(actx, storage.ReviewSLA{TeamID: team.ID, Within: time.Hour, Escalation: storage.EscalateNone}))
+	requireCode(t, apperrors.ErrNotFound, b.Notifications.SetEmailPreference(actx,
+		storage.EmailPreference{UserID: "u1", Email: "u1@example.com", Mode: storage.EmailImmediate}))
+
+	require.Nil(t, b.Integrations.LinkIdentity(ctx, "github", "octocat", "u1"))
+	_, err = b.Integrations.ResolveIdentity(actx, "github", "octocat")
+	requireCode(t, apperrors.ErrNotFound, err)
+	fresh, err := b.Integrations.RecordDelivery(ctx, "github", "d-1")
+	require.Nil(t, err)
+	require.True(t, fresh)
+	fresh, err = b.Integrations.RecordDelivery(actx, "github", "d-1")
+	require.Nil(t, err)
+	require.True(t, fresh, "delivery ids are tracked per organization")
+
+	require.Nil(t, b.Teams.Create(actx, storage.Team{TeamName: "frontend", Members: []storage.User{member("u1", false)}}),
+		"user ids are unique per organization")
+	frontend, err := b.Teams.GetByName(actx, "frontend")
+	require.Nil(t, err)
+	u1, err := b.Users.Get(actx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, frontend.ID, u1.TeamID)
+	require.False(t, u1.IsActive)
+	u1, err = b.Users.Get(ctx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, team.ID, u1.TeamID, "the user of the other organization is unchanged")
+	require.True(t, u1.IsActive)
+
+	require.Nil(t, b.Notifications.SetEmailPreference(ctx,
+		storage.EmailPreference{UserID: "u1", Email: "u1@default.example", Mode: storage.EmailImmediate}))
+	require.Nil(t, b.Notifications.SetEmailPreference(actx,
+		storage.EmailPreference{UserID: "u1", Email: "u1@acme.example", Mode: storage.EmailDigest}))
+	pref, err := b.Notifications.GetEmailPreference(ctx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, "u1@default.example", pref.Email)
+	require.Equal(t, storage.DefaultOrgID, pref.OrgID)
+	pref, err = b.Notifications.GetEmailPreference(actx, "u1")
+	require.Nil(t, err)
+	require.Equal(t, "u1@acme.example", pref.Email)
+	require.Equal(t, acme.ID, pref.OrgID)
+}
+
+func testTenantQueues(t *testing.T, b Backend) {
+	ctx := context.Background()
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	actx := storage.WithOrg(ctx, acme.ID)
+
+	_, err = b.Webhooks.Create(ctx, storage.Webhook{URL: "http://default.example", Secret: "s1"})
+	require.Nil(t, err)
+	acmeHook, err := b.Webhooks.Create(actx, storage.Webhook{URL: "http://acme.example", Secret: "s2"})
+	require.Nil(t, err)
+
+	webhooks, err := b.Webhooks.List(actx)
+	require.Nil(t, err)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, acmeHook.ID, webhooks[0].ID)
+
+	require.Nil(t, b.Outbox.Add(actx, []storage.OutboxEvent{{Type: "pr.created", Payload: []byte(`{}`)}}))
+	deliveries, err := b.Outbox.ListDeliveries(actx, 0, "", 10)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1, "events fan out only to subscribers of the same organization")
+	require.Equal(t, "http://acme.example", deliveries[0].URL)
+	deliveries, err = b.Outbox.ListDeliveries(ctx, 0, "", 10)
+	require.Nil(t, err)
+	require.Empty(t, deliveries)
+
+	claimed, err := b.Outbox.ClaimDue(ctx, time.Now().Add(time.Second), time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, claimed, 1, "workers claim deliveries of every organization")
+
+	require.Nil(t, b.Notifications.Enqueue(actx, []storage.Notification{{Channel: storage.ChannelChat, Target: "https://chat.example", Body: "hi"}}))
+	notifications, err := b.Notifications.List(ctx, "", 10)
+	require.Nil(t, err)
+	require.Empty(t, notifications)
+	notifications, err = b.Notifications.List(actx, "", 10)
+	require.Nil(t, err)
+	require.Len(t, notifications, 1)
+
+	createTeam(t, b, "backend", member("u1", true), member("u2", true))
+	require.Nil(t, b.Teams.Create(actx, storage.Team{TeamName: "backend", Members: []storage.User{member("a1", true), member("a2", true)}}))
+	acmeTeam, err := b.Teams.GetByName(actx, "backend")
+	require.Nil(t, err)
+	require.Nil(t, b.PRs.Create(actx, storage.PullRequest{ID: "pr-1", Name: "acme", AuthorID: "a1", Status: storage.StatusOpen,
+		CreatedAt: time.Now().UTC(), AssignedReviewers: []string{"a2"}}))
+	require.Nil(t, b.ReviewSLAs.SetTeamSLA(actx, storage.ReviewSLA{TeamID: acmeTeam.ID, Within: time.Millisecond, Escalation: storage.EscalateNone}))
+	require.Nil(t, b.ReviewerSyncs.Enqueue(actx, storage.ReviewerSync{Provider: "github", PullRequestID: "pr-1"}))
+
+	reminders, err := b.ReviewSLAs.ClaimReminders(ctx, time.Now().Add(time.Minute), 10)
+	require.Nil(t, err)
+	require.Len(t, reminders, 1)
+	require.Equal(t, acme.ID, reminders[0].OrgID)
+	require.Equal(t, "a2", reminders[0].ReviewerID)
+
+	syncs, err := b.ReviewerSyncs.ClaimDue(ctx, time.Now().Add(time.Second), time.Minute, 10)
+	require.Nil(t, err)
+	require.Len(t, syncs, 1)
+	require.Equal(t, acme.ID, syncs[0].OrgID)
+	list, err := b.ReviewerSyncs.List(ctx, "pr-1")
+	require.Nil(t, err)
+	require.Empty(t, list)
+
+	require.Nil(t, b.Notifications.SetEmailPreference(actx, storage.EmailPreference{UserID: "a1", Email: "a1@example.com", Mode: storage.EmailDigest}))
+	digests, err := b.Notifications.ClaimDigests(ctx, time.Now().Add(time.Minute), time.Now(), 10)
+	require.Nil(t, err)
+	require.Len(t, digests, 1)
+	require.Equal(t, acme.ID, digests[0].OrgID)
+
+	token, err := b.APITokens.Create(actx, storage.APIToken{Name: "ci", Hash: "h1", Scopes: []storage.TokenScope{storage.ScopeRead}})
+	require.Nil(t, err)
+	require.Equal(t, acme.ID, token.OrgID)
+	got, err := b.APITokens.GetByHash(ctx, "h1")
+	require.Nil(t, err)
+	require.Equal(t, acme.ID, got.OrgID, "tokens are looked up across organizations")
+	tokens, err := b.APITokens.List(ctx)
+	require.Nil(t, err)
+	require.Empty(t, tokens)
+	requireCode(t, apperrors.ErrNotFound, b.APITokens.Revoke(ctx, token.ID))
+}
+
+func testAuditLog(t *testing.T, b Backend) {
+	ctx := context.Background()
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	acmeCtx := storage.WithOrg(ctx, acme.ID)
+
+	start := time.Now().Add(-time.Second)
+	require.Nil(t, b.Audit.Add(ctx, storage.AuditEntry{
+		Action: storage.AuditTeamCreate, Actor: "admin", RequestID: "req-1", SourceIP: "10.0.0.1",
+		TargetType: "team", TargetID: "backend", After: []byte(`{"team_name":"backend"}`),
+	}))
+	require.Nil(t, b.Audit.Add(ctx, storage.AuditEntry{
+		Action: storage.AuditUserSetActive, Actor: "lead", TargetType: "user", TargetID: "u1",
+		Before: []byte(`{"is_active":true}`), After: []byte(`{"is_active":false}`),
+	}))
+	require.Nil(t, b.Audit.Add(ctx, storage.AuditEntry{
+		Action: storage.AuditUserSetActive, Actor: "admin", TargetType: "user", TargetID: "u2",
+		Before: []byte(`{"is_active":false}`), After: []byte(`{"is_active":true}`),
+	}))
+	require.Nil(t, b.Audit.Add(acmeCtx, storage.AuditEntry{
+		Action: storage.AuditTeamCreate, Actor: "token:acme", TargetType: "team", TargetID: "backend",
+	}))
+
+	all, err := b.Audit.List(ctx, storage.AuditFilter{Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, all, 3, "entries of other organizations are not listed")
+	require.Equal(t, "u2", all[0].TargetID, "newest first")
+	require.Equal(t, "backend", all[2].TargetID)
+	require.Equal(t, storage.AuditTeamCreate, all[2].Action)
+	require.Equal(t, "admin", all[2].Actor)
+	require.Equal(t, "req-1", all[2].RequestID)
+	require.Equal(t, "10.0.0.1", all[2].SourceIP)
+	require.Equal(t, "team", all[2].TargetType)
+	require.Nil(t, all[2].Before)
+	require.JSONEq(t, `{"team_name":"backend"}`, string(all[2].After))
+	require.JSONEq(t, `{"is_active":true}`, string(all[1].Before))
+	require.False(t, all[2].CreatedAt.Before(start))
+
+	page, err := b.Audit.List(ctx, storage.AuditFilter{Limit: 2})
+	require.Nil(t, err)
+	require.Len(t, page, 2)
+	page, err = b.Audit.List(ctx, storage.AuditFilter{Limit: 2, BeforeID: page[1].ID})
+	require.Nil(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, all[2].ID, page[0].ID)
+
+	byActor, err := b.Audit.List(ctx, storage.AuditFilter{Actor: "admin", Action: storage.AuditUserSetActive, Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, byActor, 1)
+	require.Equal(t, "u2", byActor[0].TargetID)
+	byTarget, err := b.Audit.List(ctx, storage.AuditFilter{TargetType: "user", TargetID: "u1", Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, byTarget, 1)
+	require.Equal(t, "lead", byTarget[0].Actor)
+
+	inRange, err := b.Audit.List(ctx, storage.AuditFilter{Since: start, Until: time.Now().Add(time.Second), Limit: 10})
+	require.Nil(t, err)
+	require.Len(t, inRange, 3)
+	future, err := b.Audit.List(ctx, storage.AuditFilter{Since: time.Now().Add(time.Hour), Limit: 10})
+	require.Nil(t, err)
+	require.Empty(t, future)
+
+	deleted, err := b.Audit.DeleteBefore(ctx, start)
+	require.Nil(t, err)
+	require.Zero(t, deleted)
+	deleted, err = b.Audit.DeleteBefore(ctx, time.Now().Add(time.Second))
+	require.Nil(t, err)
+	require.EqualValues(t, 4, deleted, "retention applies to every organization")
+	acmeEntries, err := b.Audit.List(acmeCtx, storage.AuditFilter{Limit: 10})
+	require.Nil(t, err)
+	require.Empty(t, acmeEntries)
+}
+
+func testIdempotencyKeys(t *testing.T, b Backend) {
+	ctx := context.Background()
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	acmeCtx := storage.WithOrg(ctx, acme.ID)
+
+	now := time.Now()
+	rec := storage.IdempotencyRecord{Client: "token:ci", Key: "k1", Fingerprint: "fp1", ExpiresAt: now.Add(time.Hour)}
+	claimed, ok, err := b.Idempotency.Claim(ctx, rec, now)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Zero(t, claimed.Status)
+
+	existing, ok, err := b.Idempotency.Claim(ctx, storage.IdempotencyRecord{
+		Client: "token:ci", Key: "k1", Fingerprint: "fp2", ExpiresAt: now.Add(time.Hour),
+	}, now)
+	require.Nil(t, err)
+	require.False(t, ok, "a live key is not claimed again")
+	require.Equal(t, "fp1", existing.Fingerprint)
+	require.Zero(t, existing.Status, "the request is still in progress")
+
+	_, ok, err = b.Idempotency.Claim(acmeCtx, rec, now)
+	require.Nil(t, err)
+	require.True(t, ok, "keys are scoped by organization")
+	_, ok, err = b.Idempotency.Claim(ctx, storage.IdempotencyRecord{
+		Client: "token:other", Key: "k1", Fingerprint: "fp1", ExpiresAt: now.Add(time.Hour),
+	}, now)
+	require.Nil(t, err)
+	require.True(t, ok, "keys are scoped by client")
+
+	headers := map[string]string{"ETag": `"3"`, "Location": "/team/get?team_name=backend"}
+	require.Nil(t, b.Idempotency.Complete(ctx, "token:ci", "k1", 201, headers, []byte(`{"ok":true}`)))
+	existing, ok, err = b.Idempotency.Claim(ctx, rec, now)
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.Equal(t, 201, existing.Status)
+	require.Equal(t, headers, existing.Headers)
+	require.JSONEq(t, `{"ok":true}`, string(existing.Response))
+	require.WithinDuration(t, now.Add(time.Hour), existing.ExpiresAt, time.Second)
+
+	err = b.Idempotency.Complete(ctx, "token:ci", "missing", 200, nil, nil)
+	require.NotNil(t, err)
+	require.Equal(t, apperrors.ErrNotFound, err.Code)
+
+	require.Nil(t, b.Idempotency.Release(ctx, "token:ci", "k1"))
+	_, ok, err = b.Idempotency.Claim(ctx, rec, now)
+	require.Nil(t, err)
+	require.False(t, ok, "completed keys are not released")
+
+	require.Nil(t, b.Idempotency.Release(ctx, "token:other", "k1"))
+	_, ok, err = b.Idempotency.Claim(ctx, storage.IdempotencyRecord{
+		Client: "token:other", Key: "k1", Fingerprint: "fp3", ExpiresAt: now.Add(time.Hour),
+	}, now)
+	require.Nil(t, err)
+	require.True(t, ok, "a released key can be claimed again")
+
+	later := now.Add(2 * time.Hour)
+	claimed, ok, err = b.Idempotency.Claim(ctx, storage.IdempotencyRecord{
+		Client: "token:ci", Key: "k1", Fingerprint: "fp4", ExpiresAt: later.Add(time.Hour),
+	}, later)
+	require.Nil(t, err)
+	require.True(t, ok, "an expired key is claimed anew")
+	require.Zero(t, claimed.Status)
+	existing, _, err = b.Idempotency.Claim(ctx, rec, later)
+	require.Nil(t, err)
+	require.Equal(t, "fp4", existing.Fingerprint)
+	require.Zero(t, existing.Status)
+	require.Empty(t, existing.Headers)
+	require.Empty(t, existing.Response)
+
+	deleted, err := b.Idempotency.DeleteExpired(ctx, later)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, deleted, "expired keys of every organization are deleted")
+	_, ok, err = b.Idempotency.Claim(acmeCtx, rec, now)
+	require.Nil(t, err)
+	require.True(t, ok)
+}
+
+func testVersions(t *testing.T, b Backend) {
+	ctx := context.Background()
+	team := createTeam(t, b, "backend", member("u1", true), member("u2", true), member("u3", true))
+	require.EqualValues(t, 1, team.Version)
+	for _, m := range team.Members {
+		require.EqualValues(t, 1, m.Version, m.ID)
+	}
+
+	version, err := b.Teams.Touch(ctx, team.ID)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, version)
+	team, err = b.Teams.GetByName(ctx, "backend")
+	require.Nil(t, err)
+	require.EqualValues(t, 2, team.Version)
+	byID, err := b.Teams.GetByID(ctx, team.ID)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, byID.Version)
+	_, err = b.Teams.Touch(ctx, team.ID+100)
+	requireCode(t, apperrors.ErrNotFound, err)
+
+	user, err := b.Users.SetActive(ctx, "u1", false)
+	require.Nil(t, err)
+	require.EqualValues(t, 2, user.Version)
+	user, err = b.Users.Get(ctx, "u1")
+	require.Nil(t, err)
+	require.EqualValues(t, 2, user.Version)
+
+	createTeam(t, b, "frontend", member("u3", true), member("f1", true))
+	moved, err := b.Users.Get(ctx, "u3")
+	require.Nil(t, err)
+	require.EqualValues(t, 2, moved.Version, "moving a user to another team is a write")
+	created, err := b.Users.Get(ctx, "f1")
+	require.Nil(t, err)
+	require.EqualValues(t, 1, created.Version)
+
+	createPR(t, b, "pr-1", "u1", "u2")
+	pr, err := b.PRs.Get(ctx, "pr-1")
+	require.Nil(t, err)
+	require.EqualValues(t, 1, pr.Version)
+
+	require.Nil(t, b.PRs.ReplaceReviewer(ctx, "pr-1", "u2", "u3"))
+	require.Nil(t, b.PRs.AddReviewer(ctx, "pr-1", "u2"))
+	pr, err = b.PRs.GetForUpdate(ctx, "pr-1")
+	require.Nil(t, err)
+	require.EqualValues(t, 3, pr.Version, "reviewer changes bump the PR version")
+
+	pr, err = b.PRs.SetStatus(ctx, "pr-1", storage.StatusClosed)
+	require.Nil(t, err)
+	require.EqualValues(t, 4, pr.Version)
+	pr, err = b.PRs.MarkMerged(ctx, "pr-1")
+	require.Nil(t, err)
+	require.EqualValues(t, 5, pr.Version)
+
+	prs, err := b.PRs.GetByReviewer(ctx, "u2")
+	require.Nil(t, err)
+	require.Len(t, prs, 1)
+	require.EqualValues(t, 5, prs[0].Version)
+
+	acme, err := b.Orgs.Create(ctx, "acme")
+	require.Nil(t, err)
+	_, err = b.Teams.Touch(storage.WithOrg(ctx, acme.ID), team.ID)
+	requireCode(t, apperrors.ErrNotFound, err)
+}
+
+func testTxCommitAndRollback(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	err := b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if err := b.Teams.Create(ctx, storage.Team{TeamName: "committed", Members: []storage.User{member("u1", true)}}); err != nil {
+			return err
+		}
+		return b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+			_, err := b.Users.SetActive(ctx, "u1", false)
+			return err
+		})
+	})
+	require.Nil(t, err)
+
+	u1, appErr := b.Users.Get(ctx, "u1")
+	require.Nil(t, appErr)
+	require.False(t, u1.IsActive)
+
+	err = b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		if err := b.Teams.Create(ctx, storage.Team{TeamName: "rolled-back", Members: []storage.User{member("u2", true)}}); err != nil {
+			return err
+		}
+		if _, err := b.Users.SetActive(ctx, "u1", true); err != nil {
+			return err
+		}
+		return apperrors.New(apperrors.ErrNoCandidate)
+	})
+	requireCode(t, apperrors.ErrNoCandidate, err)
+
+	exists, appErr := b.Teams.Exists(ctx, "rolled-back")
+	require.Nil(t, appErr)
+	require.False(t, exists)
+
+	exists, appErr = b.Users.Exists(ctx, "u2")
+	require.Nil(t, appErr)
+	require.False(t, exists)
+
+	u1, appErr = b.Users.Get(ctx, "u1")
+	require.Nil(t, appErr)
+	require.False(t, u1.IsActive)
+}
+
+func testTxAfterCommit(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	var ran []string
+	err := b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		storage.AfterCommit(ctx, func() { ran = append(ran, "outer") })
+		err := b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+			storage.AfterCommit(ctx, func() { ran = append(ran, "nested") })
+			return nil
+		})
+		require.Empty(t, ran, "a nested commit does not run the hooks")
+		return err
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"outer", "nested"}, ran)
+
+	ran = nil
+	err = b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+		_ = b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+			storage.AfterCommit(ctx, func() { ran = append(ran, "nested") })
+			return nil
+		})
+		return apperrors.New(apperrors.ErrNoCandidate)
+	})
+	requireCode(t, apperrors.ErrNoCandidate, err)
+	require.Empty(t, ran, "hooks of a rolled back transaction are dropped")
+
+	storage.AfterCommit(ctx, func() { ran = append(ran, "no tx") })
+	require.Equal(t, []string{"no tx"}, ran)
+}
+
+func testConcurrentWrites(t *testing.T, b Backend) {
+	ctx := context.Background()
+	members := []storage.User{member("a", true)}
+	for i := 0; i < 4; i++ {
+		members = append(members, member(fmt.Sprintf("r%d", i), true))
+	}
+	createTeam(t, b, "backend", members...)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan *apperrors.AppError, workers*2)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prID := fmt.Sprintf("pr-%d", i)
+			errs <- b.Tx.Do(ctx, func(ctx context.Context) *apperrors.AppError {
+				return b.PRs.Create(ctx, storage.PullRequest{
+					ID:                prID,
+					Name:              prID,
+					AuthorID:          "a",
+					Status:            storage.StatusOpen,
+					CreatedAt:         time.Now().UTC(),
+					AssignedReviewers: []string{"r0", "r1"},
+				})
+			})
+			_, err := b.Users.SetActive(ctx, fmt.Sprintf("r%d", i%4), i%2 == 0)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.Nil(t, err)
+	}
+
+	byPR, err := b.PRs.CountAssignmentsByPR(ctx)
+	require.Nil(t, err)
+	require.Len(t, byPR, workers)
+
+	byUser, err := b.PRs.CountAssignmentsByUser(ctx)
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"r0": workers, "r1": workers}, byUser)
+}
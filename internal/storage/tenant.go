@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultOrgID - организация, в которую попадают данные, созданные до появления
+// организаций, и запросы без явно выбранной организации.
+const DefaultOrgID int64 = 1
+
+// DefaultOrgName - имя организации DefaultOrgID.
+const DefaultOrgName = "default"
+
+// Organization - организация (подразделение) со своими командами, пользователями и PR.
+type Organization struct {
+	CreatedAt time.Time
+	Name      string
+	ID        int64
+}
+
+// orgKey - ключ контекста с организацией запроса.
+type orgKey struct{}
+
+// WithOrg возвращает контекст, запросы к хранилищу в котором видят только данные
+// организации orgID.
+func WithOrg(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgFrom возвращает организацию из ctx или DefaultOrgID, если она не задана.
+func OrgFrom(ctx context.Context) int64 {
+	if orgID, ok := ctx.Value(orgKey{}).(int64); ok && orgID != 0 {
+		return orgID
+	}
+	return DefaultOrgID
+}
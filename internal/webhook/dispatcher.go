@@ -0,0 +1,175 @@
+// Package webhook доставляет доменные события из outbox подписчикам по HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VechkanovVV/assigner-pr/internal/retry"
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+)
+
+// Заголовки исходящего запроса.
+const (
+	HeaderEvent     = "X-Assigner-Event"
+	HeaderDelivery  = "X-Assigner-Delivery"
+	HeaderSignature = "X-Assigner-Signature"
+)
+
+// Sign возвращает подпись тела запроса в формате "sha256=<hex HMAC-SHA256>".
+// Получатель вычисляет её по сырому телу и своему секрету и сравнивает с заголовком.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Envelope - тело запроса к подписчику.
+type Envelope struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ID        int64           `json:"id"`
+}
+
+// Config - параметры доставки.
+type Config struct {
+	// PollInterval - период опроса outbox.
+	PollInterval time.Duration
+	// BatchSize - сколько доставок берётся за один опрос.
+	BatchSize int
+	// MaxAttempts - после стольких неудачных попыток доставка переходит в DEAD.
+	MaxAttempts int
+	// BaseBackoff и MaxBackoff - задержка перед повтором: BaseBackoff*2^(попытка-1), не больше MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout - таймаут одного HTTP-запроса; он же срок аренды доставки.
+	Timeout time.Duration
+}
+
+// Dispatcher периодически забирает из outbox доставки, которым пора отправляться,
+// и отправляет их подписчикам. Несколько экземпляров могут работать параллельно:
+// аренда в ClaimDue не даёт двум обработчикам взять одну доставку.
+type Dispatcher struct {
+	repo   storage.OutboxRepository
+	client *http.Client
+	now    func() time.Time
+	cfg    Config
+}
+
+// NewDispatcher создаёт новый Dispatcher.
+func NewDispatcher(repo storage.OutboxRepository, client *http.Client, cfg Config) *Dispatcher {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Dispatcher{repo: repo, client: client, now: time.Now, cfg: cfg}
+}
+
+// Run обрабатывает outbox до отмены ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for d.DispatchOnce(ctx) == d.cfg.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce отправляет одну пачку доставок и возвращает её размер.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) int {
+	deliveries, appErr := d.repo.ClaimDue(ctx, d.now(), d.lease(), d.cfg.BatchSize)
+	if appErr != nil {
+		log.Printf("claim webhook deliveries failed: %v", appErr)
+		return 0
+	}
+
+	for _, dl := range deliveries {
+		d.deliver(ctx, dl)
+	}
+	return len(deliveries)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, dl storage.WebhookDelivery) {
+	err := d.send(ctx, dl)
+	if err == nil {
+		if appErr := d.repo.MarkDelivered(ctx, dl.ID); appErr != nil {
+			log.Printf("mark delivery %d delivered failed: %v", dl.ID, appErr)
+		}
+		return
+	}
+
+	dead := dl.Attempts >= d.cfg.MaxAttempts
+	msg := retry.TruncateError(err)
+	if dead {
+		log.Printf("webhook delivery %d to %s is dead after %d attempts: %s", dl.ID, dl.URL, dl.Attempts, msg)
+	}
+	if appErr := d.repo.MarkFailed(ctx, dl.ID, d.now().Add(d.backoff(dl.Attempts)), msg, dead); appErr != nil {
+		log.Printf("mark delivery %d failed failed: %v", dl.ID, appErr)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl storage.WebhookDelivery) error {
+	body, err := json.Marshal(Envelope{
+		ID:        dl.Event.ID,
+		Type:      dl.Event.Type,
+		CreatedAt: dl.Event.CreatedAt,
+		Payload:   dl.Event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, dl.Event.Type)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(dl.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(dl.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff возвращает задержку перед попыткой, следующей за attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	return retry.Backoff(d.cfg.BaseBackoff, d.cfg.MaxBackoff, attempt)
+}
+
+// lease - на сколько откладывается выданная доставка: запас сверх таймаута запроса.
+func (d *Dispatcher) lease() time.Duration {
+	return 2 * d.cfg.Timeout
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/VechkanovVV/assigner-pr/internal/storage"
+	"github.com/VechkanovVV/assigner-pr/internal/storage/memory"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	requests []received
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, received{header: req.Header.Clone(), body: body})
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+type fixture struct {
+	dispatcher *Dispatcher
+	outbox     *memory.OutboxRepository
+	receiver   *receiver
+	clock      time.Time
+}
+
+func newFixture(t *testing.T, statuses ...int) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	rcv := &receiver{statuses: statuses}
+	srv := httptest.NewServer(rcv)
+	t.Cleanup(srv.Close)
+
+	store := memory.NewStore()
+	_, err := memory.NewWebhookRepository(store).Create(ctx, storage.Webhook{URL: srv.URL, Secret: "top-secret"})
+	require.Nil(t, err)
+
+	f := &fixture{outbox: memory.NewOutboxRepository(store), receiver: rcv}
+	f.dispatcher = NewDispatcher(f.outbox, srv.Client(), Config{
+		PollInterval: time.Second,
+		BatchSize:    10,
+		MaxAttempts:  3,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   time.Minute,
+		Timeout:      time.Second,
+	})
+	f.dispatcher.now = func() time.Time { return f.clock }
+
+	require.Nil(t, f.outbox.Add(ctx, []storage.OutboxEvent{{Type: "pr.merged", Payload: []byte(`{"pull_request_id":"pr-1"}`)}}))
+	f.clock = time.Now()
+	return f
+}
+
+func (f *fixture) deliveries(t *testing.T) []storage.WebhookDelivery {
+	t.Helper()
+	deliveries, err := f.outbox.ListDeliveries(context.Background(), 0, "", 10)
+	require.Nil(t, err)
+	return deliveries
+}
+
+func TestDispatchSignsRequest(t *testing.T) {
+	f := newFixture(t)
+
+	require.Equal(t, 1, f.dispatcher.DispatchOnce(context.Background()))
+	require.Len(t, f.receiver.requests, 1)
+
+	req := f.receiver.requests[0]
+	require.Equal(t, Sign("top-secret", req.body), req.header.Get(HeaderSignature))
+	require.NotEqual(t, Sign("other-secret", req.body), req.header.Get(HeaderSignature))
+	require.Equal(t, "pr.merged", req.header.Get(HeaderEvent))
+	require.Equal(t, "1", req.header.Get(HeaderDelivery))
+	require.Equal(t, "application/json", req.header.Get("Content-Type"))
+
+	var env Envelope
+	require.NoError(t, json.Unmarshal(req.body, &env))
+	require.Equal(t, "pr.merged", env.Type)
+	require.JSONEq(t, `{"pull_request_id":"pr-1"}`, string(env.Payload))
+
+	deliveries := f.deliveries(t)
+	require.Equal(t, storage.DeliveryDelivered, deliveries[0].Status)
+	require.Equal(t, 1, deliveries[0].Attempts)
+
+	require.Zero(t, f.dispatcher.DispatchOnce(context.Background()), "delivered events are not resent")
+}
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	f := newFixture(t, http.StatusInternalServerError, http.StatusBadGateway)
+	ctx := context.Background()
+
+	require.Equal(t, 1, f.dispatcher.DispatchOnce(ctx))
+	d := f.deliveries(t)[0]
+	require.Equal(t, storage.DeliveryPending, d.Status)
+	require.Equal(t, "unexpected status 500", d.LastError)
+	require.WithinDuration(t, f.clock.Add(time.Second), d.NextAttemptAt, time.Millisecond)
+
+	require.Zero(t, f.dispatcher.DispatchOnce(ctx), "retry waits for backoff")
+
+	f.clock = f.clock.Add(time.Second)
+	require.Equal(t, 1, f.dispatcher.DispatchOnce(ctx))
+	d = f.deliveries(t)[0]
+	require.Equal(t, "unexpected status 502", d.LastError)
+	require.WithinDuration(t, f.clock.Add(2*time.Second), d.NextAttemptAt, time.Millisecond)
+
+	f.clock = f.clock.Add(2 * time.Second)
+	require.Equal(t, 1, f.dispatcher.DispatchOnce(ctx))
+	d = f.deliveries(t)[0]
+	require.Equal(t, storage.DeliveryDelivered, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Len(t, f.receiver.requests, 3)
+}
+
+func TestDispatchDeadLetter(t *testing.T) {
+	f := newFixture(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, 1, f.dispatcher.DispatchOnce(ctx))
+		f.clock = f.clock.Add(time.Hour)
+	}
+
+	d := f.deliveries(t)[0]
+	require.Equal(t, storage.DeliveryDead, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Zero(t, f.dispatcher.DispatchOnce(ctx), "dead deliveries are not retried")
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, nil, Config{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	require.Equal(t, time.Second, d.backoff(1))
+	require.Equal(t, 2*time.Second, d.backoff(2))
+	require.Equal(t, 8*time.Second, d.backoff(4))
+	require.Equal(t, 10*time.Second, d.backoff(5))
+	require.Equal(t, 10*time.Second, d.backoff(60))
+}
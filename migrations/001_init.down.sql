@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS reviews;
+
+DROP TABLE IF EXISTS pull_requests;
+
+DROP TYPE IF EXISTS pr_status;
+
+DROP TABLE IF EXISTS users;
+
+DROP TABLE IF EXISTS teams;
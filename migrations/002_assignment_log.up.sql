@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS assignment_log (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    replaced_reviewer_id TEXT REFERENCES users(user_id),
+    strategy TEXT NOT NULL,
+    pool_size INTEGER NOT NULL,
+    candidates JSONB NOT NULL DEFAULT '[]',
+    excluded JSONB NOT NULL DEFAULT '[]',
+    draw INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_log_pull_request_id ON assignment_log(pull_request_id);
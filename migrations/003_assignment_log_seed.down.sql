@@ -0,0 +1 @@
+ALTER TABLE assignment_log DROP COLUMN IF EXISTS seed;
@@ -0,0 +1 @@
+ALTER TABLE assignment_log ADD COLUMN IF NOT EXISTS seed BIGINT;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS outbox_events;
+
+DROP TABLE IF EXISTS webhooks;
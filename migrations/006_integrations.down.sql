@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS inbound_deliveries;
+
+DROP TABLE IF EXISTS user_identities;
+
+-- Значение из enum удалить нельзя, поэтому тип пересоздаётся без CLOSED.
+UPDATE pull_requests SET status = 'OPEN' WHERE status::text = 'CLOSED';
+
+ALTER TABLE pull_requests ALTER COLUMN status DROP DEFAULT;
+
+ALTER TYPE pr_status RENAME TO pr_status_old;
+
+CREATE TYPE pr_status AS ENUM ('OPEN', 'MERGED');
+
+ALTER TABLE pull_requests ALTER COLUMN status TYPE pr_status USING status::text::pr_status;
+
+ALTER TABLE pull_requests ALTER COLUMN status SET DEFAULT 'OPEN';
+
+DROP TYPE pr_status_old;
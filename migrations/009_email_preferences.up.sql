@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS email_preferences (
+    user_id TEXT PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    mode TEXT NOT NULL CHECK (mode IN ('immediate', 'digest', 'none')),
+    last_digest_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_preferences_digest
+    ON email_preferences(last_digest_at) WHERE mode = 'digest';
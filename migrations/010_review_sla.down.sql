@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_reviews_sla_pending;
+ALTER TABLE reviews DROP COLUMN IF EXISTS escalated_at;
+ALTER TABLE reviews DROP COLUMN IF EXISTS reminded_at;
+DROP TABLE IF EXISTS review_slas;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS review_slas (
+    team_id INTEGER PRIMARY KEY REFERENCES teams(id) ON DELETE CASCADE,
+    within_ms BIGINT NOT NULL CHECK (within_ms > 0),
+    escalation TEXT NOT NULL DEFAULT 'none' CHECK (escalation IN ('none', 'reassign', 'lead')),
+    escalate_after_ms BIGINT NOT NULL DEFAULT 0,
+    lead_id TEXT REFERENCES users(user_id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_sla_pending
+    ON reviews(assigned_at) WHERE reminded_at IS NULL OR escalated_at IS NULL;
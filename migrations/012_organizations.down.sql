@@ -0,0 +1,55 @@
+-- Откат возможен, только пока id PR и имена команд уникальны глобально; данные всех
+-- организаций остаются в общих таблицах.
+DROP INDEX IF EXISTS idx_notifications_org_id;
+DROP INDEX IF EXISTS idx_webhooks_org_id;
+DROP INDEX IF EXISTS idx_users_org_id;
+
+ALTER TABLE inbound_deliveries DROP CONSTRAINT inbound_deliveries_pkey;
+ALTER TABLE inbound_deliveries ADD PRIMARY KEY (provider, delivery_id);
+ALTER TABLE user_identities DROP CONSTRAINT user_identities_pkey;
+ALTER TABLE user_identities ADD PRIMARY KEY (provider, login);
+
+DROP INDEX IF EXISTS idx_reviewer_syncs_pull_request_id;
+CREATE INDEX IF NOT EXISTS idx_reviewer_syncs_pull_request_id ON reviewer_syncs(pull_request_id);
+DROP INDEX IF EXISTS idx_pr_events_pull_request_id;
+CREATE INDEX IF NOT EXISTS idx_pr_events_pull_request_id ON pr_events(pull_request_id);
+DROP INDEX IF EXISTS idx_assignment_log_pull_request_id;
+CREATE INDEX IF NOT EXISTS idx_assignment_log_pull_request_id ON assignment_log(pull_request_id);
+
+ALTER TABLE reviewer_syncs DROP CONSTRAINT reviewer_syncs_pull_request_fkey;
+ALTER TABLE pr_events DROP CONSTRAINT pr_events_pull_request_fkey;
+ALTER TABLE assignment_log DROP CONSTRAINT assignment_log_pull_request_fkey;
+ALTER TABLE reviews DROP CONSTRAINT reviews_pull_request_fkey;
+
+ALTER TABLE reviews DROP CONSTRAINT reviews_pkey;
+ALTER TABLE reviews ADD PRIMARY KEY (pull_request_id, reviewer_id);
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_pkey;
+ALTER TABLE pull_requests ADD PRIMARY KEY (pull_request_id);
+
+ALTER TABLE reviews ADD CONSTRAINT reviews_pull_request_id_fkey
+    FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE assignment_log ADD CONSTRAINT assignment_log_pull_request_id_fkey
+    FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pr_events ADD CONSTRAINT pr_events_pull_request_id_fkey
+    FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id);
+ALTER TABLE reviewer_syncs ADD CONSTRAINT reviewer_syncs_pull_request_id_fkey
+    FOREIGN KEY (pull_request_id) REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE;
+
+ALTER TABLE teams DROP CONSTRAINT teams_org_id_team_name_key;
+ALTER TABLE teams ADD CONSTRAINT teams_team_name_key UNIQUE (team_name);
+
+ALTER TABLE api_tokens DROP COLUMN org_id;
+ALTER TABLE notifications DROP COLUMN org_id;
+ALTER TABLE outbox_events DROP COLUMN org_id;
+ALTER TABLE webhooks DROP COLUMN org_id;
+ALTER TABLE inbound_deliveries DROP COLUMN org_id;
+ALTER TABLE user_identities DROP COLUMN org_id;
+ALTER TABLE reviewer_syncs DROP COLUMN org_id;
+ALTER TABLE pr_events DROP COLUMN org_id;
+ALTER TABLE assignment_log DROP COLUMN org_id;
+ALTER TABLE reviews DROP COLUMN org_id;
+ALTER TABLE pull_requests DROP COLUMN org_id;
+ALTER TABLE users DROP COLUMN org_id;
+ALTER TABLE teams DROP COLUMN org_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,79 @@
+-- Организации изолируют данные подразделений. Существующие данные переходят в организацию
+-- default (id = 1). Имена команд и id PR уникальны в пределах организации, поэтому
+-- ссылки на pull_requests становятся составными (org_id, pull_request_id).
+CREATE TABLE IF NOT EXISTS organizations (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO organizations (id, name) VALUES (1, 'default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), (SELECT MAX(id) FROM organizations));
+
+-- DEFAULT 1 нужен только для заполнения существующих строк и ниже снимается:
+-- каждый запрос обязан указывать организацию явно.
+ALTER TABLE teams ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE users ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE pull_requests ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE reviews ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE assignment_log ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE pr_events ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE reviewer_syncs ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE user_identities ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE inbound_deliveries ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE webhooks ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE outbox_events ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE notifications ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE api_tokens ADD COLUMN org_id BIGINT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+
+ALTER TABLE teams ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pull_requests ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE reviews ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE assignment_log ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE pr_events ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE reviewer_syncs ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE user_identities ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE inbound_deliveries ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE webhooks ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE outbox_events ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE notifications ALTER COLUMN org_id DROP DEFAULT;
+ALTER TABLE api_tokens ALTER COLUMN org_id DROP DEFAULT;
+
+ALTER TABLE teams DROP CONSTRAINT teams_team_name_key;
+ALTER TABLE teams ADD CONSTRAINT teams_org_id_team_name_key UNIQUE (org_id, team_name);
+
+ALTER TABLE reviews DROP CONSTRAINT reviews_pull_request_id_fkey;
+ALTER TABLE assignment_log DROP CONSTRAINT assignment_log_pull_request_id_fkey;
+ALTER TABLE pr_events DROP CONSTRAINT pr_events_pull_request_id_fkey;
+ALTER TABLE reviewer_syncs DROP CONSTRAINT reviewer_syncs_pull_request_id_fkey;
+
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_pkey;
+ALTER TABLE pull_requests ADD PRIMARY KEY (org_id, pull_request_id);
+ALTER TABLE reviews DROP CONSTRAINT reviews_pkey;
+ALTER TABLE reviews ADD PRIMARY KEY (org_id, pull_request_id, reviewer_id);
+
+ALTER TABLE reviews ADD CONSTRAINT reviews_pull_request_fkey
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE assignment_log ADD CONSTRAINT assignment_log_pull_request_fkey
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pr_events ADD CONSTRAINT pr_events_pull_request_fkey
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id);
+ALTER TABLE reviewer_syncs ADD CONSTRAINT reviewer_syncs_pull_request_fkey
+    FOREIGN KEY (org_id, pull_request_id) REFERENCES pull_requests(org_id, pull_request_id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_assignment_log_pull_request_id;
+CREATE INDEX IF NOT EXISTS idx_assignment_log_pull_request_id ON assignment_log(org_id, pull_request_id);
+DROP INDEX IF EXISTS idx_pr_events_pull_request_id;
+CREATE INDEX IF NOT EXISTS idx_pr_events_pull_request_id ON pr_events(org_id, pull_request_id);
+DROP INDEX IF EXISTS idx_reviewer_syncs_pull_request_id;
+CREATE INDEX IF NOT EXISTS idx_reviewer_syncs_pull_request_id ON reviewer_syncs(org_id, pull_request_id);
+
+ALTER TABLE user_identities DROP CONSTRAINT user_identities_pkey;
+ALTER TABLE user_identities ADD PRIMARY KEY (org_id, provider, login);
+ALTER TABLE inbound_deliveries DROP CONSTRAINT inbound_deliveries_pkey;
+ALTER TABLE inbound_deliveries ADD PRIMARY KEY (org_id, provider, delivery_id);
+
+CREATE INDEX IF NOT EXISTS idx_users_org_id ON users(org_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_org_id ON webhooks(org_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_org_id ON notifications(org_id, id);
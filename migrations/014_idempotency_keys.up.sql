@@ -0,0 +1,14 @@
+-- Ключи идемпотентности запросов и сохранённые ответы; status = 0 - запрос ещё выполняется.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    org_id BIGINT NOT NULL REFERENCES organizations(id),
+    client TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    response BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (org_id, client, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,5 @@
+-- Версии для оптимистичной блокировки: растут при каждом изменении строки, клиент
+-- получает их в ETag и передаёт обратно в If-Match.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
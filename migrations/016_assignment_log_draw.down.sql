@@ -0,0 +1,2 @@
+UPDATE assignment_log SET draw = 0 WHERE draw IS NULL;
+ALTER TABLE assignment_log ALTER COLUMN draw SET NOT NULL;
@@ -0,0 +1,4 @@
+-- Ревьюеры стратегии WHOLE_POOL назначаются без розыгрыша, поэтому выпавшего значения
+-- у них нет; раньше в draw записывался порядковый номер кандидата.
+ALTER TABLE assignment_log ALTER COLUMN draw DROP NOT NULL;
+UPDATE assignment_log SET draw = NULL WHERE strategy = 'WHOLE_POOL';
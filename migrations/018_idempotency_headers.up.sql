@@ -0,0 +1,2 @@
+-- Заголовки сохранённого ответа (ETag, Location), которые повторяются вместе с ним.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS response_headers JSONB;
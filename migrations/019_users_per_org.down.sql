@@ -0,0 +1,31 @@
+-- Откат возможен, только пока user_id уникальны глобально.
+ALTER TABLE review_slas DROP CONSTRAINT review_slas_lead_fkey;
+ALTER TABLE email_preferences DROP CONSTRAINT email_preferences_user_fkey;
+ALTER TABLE user_identities DROP CONSTRAINT user_identities_user_fkey;
+ALTER TABLE assignment_log DROP CONSTRAINT assignment_log_replaced_reviewer_fkey;
+ALTER TABLE assignment_log DROP CONSTRAINT assignment_log_reviewer_fkey;
+ALTER TABLE reviews DROP CONSTRAINT reviews_reviewer_fkey;
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_author_fkey;
+
+ALTER TABLE email_preferences DROP CONSTRAINT email_preferences_pkey;
+ALTER TABLE email_preferences ADD PRIMARY KEY (user_id);
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD PRIMARY KEY (user_id);
+
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES users(user_id);
+ALTER TABLE reviews ADD CONSTRAINT reviews_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES users(user_id);
+ALTER TABLE assignment_log ADD CONSTRAINT assignment_log_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES users(user_id);
+ALTER TABLE assignment_log ADD CONSTRAINT assignment_log_replaced_reviewer_id_fkey
+    FOREIGN KEY (replaced_reviewer_id) REFERENCES users(user_id);
+ALTER TABLE user_identities ADD CONSTRAINT user_identities_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE email_preferences ADD CONSTRAINT email_preferences_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE;
+ALTER TABLE review_slas ADD CONSTRAINT review_slas_lead_id_fkey
+    FOREIGN KEY (lead_id) REFERENCES users(user_id);
+
+ALTER TABLE review_slas DROP COLUMN org_id;
+ALTER TABLE email_preferences DROP COLUMN org_id;
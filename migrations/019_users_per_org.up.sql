@@ -0,0 +1,39 @@
+-- user_id уникален в пределах организации, а не глобально: организации могут
+-- использовать одинаковые id, а попытка занять чужой id не выдаёт существование
+-- пользователя в другой организации. Ссылки на users становятся составными
+-- (org_id, user_id); email_preferences и review_slas получают свою колонку org_id.
+ALTER TABLE email_preferences ADD COLUMN org_id BIGINT;
+UPDATE email_preferences p SET org_id = u.org_id FROM users u WHERE u.user_id = p.user_id;
+ALTER TABLE email_preferences ALTER COLUMN org_id SET NOT NULL;
+
+ALTER TABLE review_slas ADD COLUMN org_id BIGINT;
+UPDATE review_slas s SET org_id = t.org_id FROM teams t WHERE t.id = s.team_id;
+ALTER TABLE review_slas ALTER COLUMN org_id SET NOT NULL;
+
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_author_id_fkey;
+ALTER TABLE reviews DROP CONSTRAINT reviews_reviewer_id_fkey;
+ALTER TABLE assignment_log DROP CONSTRAINT assignment_log_reviewer_id_fkey;
+ALTER TABLE assignment_log DROP CONSTRAINT assignment_log_replaced_reviewer_id_fkey;
+ALTER TABLE user_identities DROP CONSTRAINT user_identities_user_id_fkey;
+ALTER TABLE email_preferences DROP CONSTRAINT email_preferences_user_id_fkey;
+ALTER TABLE review_slas DROP CONSTRAINT review_slas_lead_id_fkey;
+
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD PRIMARY KEY (org_id, user_id);
+ALTER TABLE email_preferences DROP CONSTRAINT email_preferences_pkey;
+ALTER TABLE email_preferences ADD PRIMARY KEY (org_id, user_id);
+
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_author_fkey
+    FOREIGN KEY (org_id, author_id) REFERENCES users(org_id, user_id);
+ALTER TABLE reviews ADD CONSTRAINT reviews_reviewer_fkey
+    FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id);
+ALTER TABLE assignment_log ADD CONSTRAINT assignment_log_reviewer_fkey
+    FOREIGN KEY (org_id, reviewer_id) REFERENCES users(org_id, user_id);
+ALTER TABLE assignment_log ADD CONSTRAINT assignment_log_replaced_reviewer_fkey
+    FOREIGN KEY (org_id, replaced_reviewer_id) REFERENCES users(org_id, user_id);
+ALTER TABLE user_identities ADD CONSTRAINT user_identities_user_fkey
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+ALTER TABLE email_preferences ADD CONSTRAINT email_preferences_user_fkey
+    FOREIGN KEY (org_id, user_id) REFERENCES users(org_id, user_id) ON DELETE CASCADE;
+ALTER TABLE review_slas ADD CONSTRAINT review_slas_lead_fkey
+    FOREIGN KEY (org_id, lead_id) REFERENCES users(org_id, user_id);
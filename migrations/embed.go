@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции PostgreSQL в бинарник.
+// Файлы остаются в формате golang-migrate (NNN_name.up.sql / NNN_name.down.sql).
+package migrations
+
+import "embed"
+
+// FS - встроенные файлы миграций.
+//
+//go:embed *.sql
+var FS embed.FS